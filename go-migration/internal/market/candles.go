@@ -0,0 +1,117 @@
+package market
+
+import (
+	"sort"
+	"time"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+// GapPolicy controls how intervals without any ticks are handled
+type GapPolicy int
+
+const (
+	// GapSkip omits intervals that received no ticks
+	GapSkip GapPolicy = iota
+	// GapForwardFill emits a flat candle at the previous close with zero volume
+	GapForwardFill
+)
+
+// AggregateCandles buckets tick-level price updates into OHLCV candles
+// aligned to interval boundaries. Empty intervals are skipped.
+func AggregateCandles(updates []types.PriceUpdate, interval time.Duration) []types.Candle {
+	return AggregateCandlesWithPolicy(updates, interval, GapSkip)
+}
+
+// AggregateCandlesWithPolicy buckets price updates into candles, handling
+// empty intervals according to the given gap policy. Updates for
+// different symbols are aggregated separately, and the candles are
+// returned by symbol, then by time.
+func AggregateCandlesWithPolicy(updates []types.PriceUpdate, interval time.Duration, policy GapPolicy) []types.Candle {
+	if len(updates) == 0 || interval <= 0 {
+		return nil
+	}
+
+	// Group a copy by symbol so callers can pass mixed ticks in arrival
+	// order
+	bySymbol := make(map[string][]types.PriceUpdate)
+	for _, update := range updates {
+		bySymbol[update.Symbol] = append(bySymbol[update.Symbol], update)
+	}
+	symbols := make([]string, 0, len(bySymbol))
+	for symbol := range bySymbol {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	var candles []types.Candle
+	for _, symbol := range symbols {
+		candles = append(candles, aggregateSymbol(symbol, bySymbol[symbol], interval, policy)...)
+	}
+	return candles
+}
+
+// aggregateSymbol buckets one symbol's ticks into candles
+func aggregateSymbol(symbol string, ticks []types.PriceUpdate, interval time.Duration, policy GapPolicy) []types.Candle {
+	sort.SliceStable(ticks, func(i, j int) bool {
+		return ticks[i].Timestamp.Before(ticks[j].Timestamp)
+	})
+
+	var candles []types.Candle
+	var current *types.Candle
+
+	for _, tick := range ticks {
+		openTime := tick.Timestamp.Truncate(interval)
+
+		if current != nil && !openTime.Equal(current.OpenTime) {
+			candles = append(candles, *current)
+
+			if policy == GapForwardFill {
+				for gap := current.OpenTime.Add(interval); gap.Before(openTime); gap = gap.Add(interval) {
+					candles = append(candles, flatCandle(symbol, current.Close, gap, interval))
+				}
+			}
+			current = nil
+		}
+
+		if current == nil {
+			current = &types.Candle{
+				Symbol:    symbol,
+				Open:      tick.Price,
+				High:      tick.Price,
+				Low:       tick.Price,
+				Close:     tick.Price,
+				OpenTime:  openTime,
+				CloseTime: openTime.Add(interval),
+			}
+		}
+
+		if tick.Price > current.High {
+			current.High = tick.Price
+		}
+		if tick.Price < current.Low {
+			current.Low = tick.Price
+		}
+		current.Close = tick.Price
+		current.Volume += tick.Volume
+		current.Trades++
+	}
+
+	if current != nil {
+		candles = append(candles, *current)
+	}
+
+	return candles
+}
+
+func flatCandle(symbol string, price float64, openTime time.Time, interval time.Duration) types.Candle {
+	return types.Candle{
+		Symbol:    symbol,
+		Open:      price,
+		High:      price,
+		Low:       price,
+		Close:     price,
+		OpenTime:  openTime,
+		CloseTime: openTime.Add(interval),
+	}
+}
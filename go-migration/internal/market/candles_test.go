@@ -0,0 +1,127 @@
+package market
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+func TestAggregateCandles_OneMinute(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// One tick every 5 seconds for a full minute
+	prices := []float64{10, 11, 9, 12, 10.5, 10, 13, 8, 9.5, 10, 11, 11.5}
+	updates := make([]types.PriceUpdate, len(prices))
+	for i, price := range prices {
+		updates[i] = types.PriceUpdate{
+			Symbol:    "TEST/SOL",
+			Price:     price,
+			Volume:    1,
+			Timestamp: start.Add(time.Duration(i*5) * time.Second),
+		}
+	}
+
+	candles := AggregateCandles(updates, time.Minute)
+	require.Len(t, candles, 1)
+
+	candle := candles[0]
+	assert.Equal(t, "TEST/SOL", candle.Symbol)
+	assert.Equal(t, 10.0, candle.Open)
+	assert.Equal(t, 13.0, candle.High)
+	assert.Equal(t, 8.0, candle.Low)
+	assert.Equal(t, 11.5, candle.Close)
+	assert.Equal(t, 12.0, candle.Volume)
+	assert.Equal(t, 12, candle.Trades)
+	assert.Equal(t, start, candle.OpenTime)
+	assert.Equal(t, start.Add(time.Minute), candle.CloseTime)
+}
+
+func TestAggregateCandles_AlignsToBoundaries(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 30, 0, time.UTC)
+	updates := []types.PriceUpdate{
+		{Symbol: "TEST/SOL", Price: 1, Volume: 2, Timestamp: start.Add(20 * time.Second)},
+		{Symbol: "TEST/SOL", Price: 2, Volume: 3, Timestamp: start},
+		{Symbol: "TEST/SOL", Price: 3, Volume: 4, Timestamp: start.Add(40 * time.Second)},
+	}
+
+	candles := AggregateCandles(updates, time.Minute)
+	require.Len(t, candles, 2)
+
+	assert.Equal(t, time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC), candles[0].OpenTime)
+	assert.Equal(t, 2.0, candles[0].Open)
+	assert.Equal(t, 1.0, candles[0].Close)
+	assert.Equal(t, 5.0, candles[0].Volume)
+
+	assert.Equal(t, time.Date(2024, 1, 1, 12, 1, 0, 0, time.UTC), candles[1].OpenTime)
+	assert.Equal(t, 3.0, candles[1].Open)
+	assert.Equal(t, 4.0, candles[1].Volume)
+}
+
+func TestAggregateCandles_Gaps(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	updates := []types.PriceUpdate{
+		{Symbol: "TEST/SOL", Price: 10, Volume: 1, Timestamp: start.Add(10 * time.Second)},
+		{Symbol: "TEST/SOL", Price: 12, Volume: 1, Timestamp: start.Add(3*time.Minute + 10*time.Second)},
+	}
+
+	t.Run("Skip", func(t *testing.T) {
+		candles := AggregateCandles(updates, time.Minute)
+		require.Len(t, candles, 2)
+		assert.Equal(t, start, candles[0].OpenTime)
+		assert.Equal(t, start.Add(3*time.Minute), candles[1].OpenTime)
+	})
+
+	t.Run("ForwardFill", func(t *testing.T) {
+		candles := AggregateCandlesWithPolicy(updates, time.Minute, GapForwardFill)
+		require.Len(t, candles, 4)
+
+		for i, candle := range candles {
+			assert.Equal(t, start.Add(time.Duration(i)*time.Minute), candle.OpenTime)
+		}
+		for _, filled := range candles[1:3] {
+			assert.Equal(t, 10.0, filled.Open)
+			assert.Equal(t, 10.0, filled.High)
+			assert.Equal(t, 10.0, filled.Low)
+			assert.Equal(t, 10.0, filled.Close)
+			assert.Zero(t, filled.Volume)
+			assert.Zero(t, filled.Trades)
+		}
+		assert.Equal(t, 12.0, candles[3].Close)
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		assert.Empty(t, AggregateCandles(nil, time.Minute))
+		assert.Empty(t, AggregateCandles(updates, 0))
+	})
+}
+
+func TestAggregateCandles_MixedSymbols(t *testing.T) {
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	updates := []types.PriceUpdate{
+		{Symbol: "BBB/SOL", Price: 100, Volume: 1, Timestamp: start},
+		{Symbol: "AAA/SOL", Price: 1, Volume: 2, Timestamp: start.Add(10 * time.Second)},
+		{Symbol: "BBB/SOL", Price: 110, Volume: 1, Timestamp: start.Add(20 * time.Second)},
+		{Symbol: "AAA/SOL", Price: 2, Volume: 3, Timestamp: start.Add(70 * time.Second)},
+	}
+
+	// Interleaved ticks don't blend into one candle
+	candles := AggregateCandles(updates, time.Minute)
+	require.Len(t, candles, 3)
+
+	assert.Equal(t, "AAA/SOL", candles[0].Symbol)
+	assert.Equal(t, start, candles[0].OpenTime)
+	assert.Equal(t, 1.0, candles[0].High)
+	assert.Equal(t, "AAA/SOL", candles[1].Symbol)
+	assert.Equal(t, start.Add(time.Minute), candles[1].OpenTime)
+	assert.Equal(t, 2.0, candles[1].Close)
+
+	assert.Equal(t, "BBB/SOL", candles[2].Symbol)
+	assert.Equal(t, 100.0, candles[2].Open)
+	assert.Equal(t, 110.0, candles[2].Close)
+	assert.Equal(t, 100.0, candles[2].Low)
+	assert.Equal(t, 2, candles[2].Trades)
+}
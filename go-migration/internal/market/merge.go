@@ -0,0 +1,112 @@
+package market
+
+import (
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+// mergeBatchLimit caps how many ready ticks are taken from one stream per
+// batch so a busy stream can't starve the others
+const mergeBatchLimit = 64
+
+// mergedTick is a tick tagged with the index of the stream it came from
+type mergedTick struct {
+	stream int
+	update *types.PriceUpdate
+}
+
+// MergeStreams merges price streams into one, listed in priority order
+// with the primary first. Ticks that are ready together are emitted in
+// timestamp order, with the earlier stream winning ties. A tick that is
+// not newer than the last one emitted for its symbol is dropped as a
+// duplicate or out of order. The merged stream closes once every input
+// has closed.
+func MergeStreams(chans ...<-chan *types.PriceUpdate) <-chan *types.PriceUpdate {
+	out := make(chan *types.PriceUpdate, 100)
+
+	go func() {
+		defer close(out)
+
+		cases := make([]reflect.SelectCase, len(chans))
+		for i, ch := range chans {
+			cases[i] = reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ch)}
+		}
+		open := len(chans)
+		closeStream := func(i int) {
+			cases[i].Chan = reflect.Value{}
+			open--
+		}
+		last := make(map[string]time.Time)
+
+		for open > 0 {
+			var batch []mergedTick
+			for i, ch := range chans {
+				if !cases[i].Chan.IsValid() {
+					continue
+				}
+				updates, closed := pollReady(ch)
+				for _, update := range updates {
+					batch = append(batch, mergedTick{stream: i, update: update})
+				}
+				if closed {
+					closeStream(i)
+				}
+			}
+
+			if len(batch) == 0 {
+				if open == 0 {
+					return
+				}
+				i, value, ok := reflect.Select(cases)
+				if !ok {
+					closeStream(i)
+					continue
+				}
+				if update := value.Interface().(*types.PriceUpdate); update != nil {
+					batch = append(batch, mergedTick{stream: i, update: update})
+				}
+			}
+
+			sort.SliceStable(batch, func(i, j int) bool {
+				a, b := batch[i], batch[j]
+				if !a.update.Timestamp.Equal(b.update.Timestamp) {
+					return a.update.Timestamp.Before(b.update.Timestamp)
+				}
+				return a.stream < b.stream
+			})
+
+			for _, tick := range batch {
+				update := tick.update
+				if prev, seen := last[update.Symbol]; seen && !update.Timestamp.After(prev) {
+					continue
+				}
+				last[update.Symbol] = update.Timestamp
+				out <- update
+			}
+		}
+	}()
+
+	return out
+}
+
+// pollReady takes up to mergeBatchLimit non-nil ticks already waiting on ch
+// without blocking, reporting whether ch has closed
+func pollReady(ch <-chan *types.PriceUpdate) (updates []*types.PriceUpdate, closed bool) {
+	for len(updates) < mergeBatchLimit {
+		select {
+		case update, ok := <-ch:
+			if !ok {
+				return updates, true
+			}
+			if update != nil {
+				updates = append(updates, update)
+			}
+		default:
+			return updates, false
+		}
+	}
+	return updates, false
+}
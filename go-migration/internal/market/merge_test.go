@@ -0,0 +1,73 @@
+package market
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+func filledStream(updates ...*types.PriceUpdate) <-chan *types.PriceUpdate {
+	ch := make(chan *types.PriceUpdate, len(updates))
+	for _, update := range updates {
+		ch <- update
+	}
+	close(ch)
+	return ch
+}
+
+func TestMergeStreams(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	tick := func(symbol string, sec int, price float64) *types.PriceUpdate {
+		return &types.PriceUpdate{Symbol: symbol, Price: price, Timestamp: base.Add(time.Duration(sec) * time.Second)}
+	}
+
+	primary := filledStream(
+		tick("A", 1, 1.0), tick("A", 3, 3.0), tick("A", 3, 3.0), tick("A", 5, 5.0), tick("B", 2, 20.0),
+	)
+	backup := filledStream(
+		tick("A", 1, 1.1), tick("A", 2, 2.1), tick("A", 4, 4.1), tick("B", 2, 20.1), tick("B", 3, 30.1),
+	)
+
+	var got []*types.PriceUpdate
+	for update := range MergeStreams(primary, backup) {
+		got = append(got, update)
+	}
+
+	last := make(map[string]time.Time)
+	prices := make(map[string][]float64)
+	for _, update := range got {
+		if prev, ok := last[update.Symbol]; ok {
+			assert.True(t, update.Timestamp.After(prev), "%s not monotonic at %s", update.Symbol, update.Timestamp)
+		}
+		last[update.Symbol] = update.Timestamp
+		prices[update.Symbol] = append(prices[update.Symbol], update.Price)
+	}
+	// Ties go to the primary, gaps are filled from the backup
+	assert.Equal(t, []float64{1.0, 2.1, 3.0, 4.1, 5.0}, prices["A"])
+	assert.Equal(t, []float64{20.0, 30.1}, prices["B"])
+}
+
+func TestMergeStreams_DropsLateTicks(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	primary := make(chan *types.PriceUpdate)
+	backup := make(chan *types.PriceUpdate)
+	merged := MergeStreams(primary, backup)
+
+	primary <- &types.PriceUpdate{Symbol: "A", Price: 5, Timestamp: base.Add(5 * time.Second)}
+	require.Equal(t, 5.0, (<-merged).Price)
+
+	// The backup lagging behind what was already emitted is dropped
+	backup <- &types.PriceUpdate{Symbol: "A", Price: 4, Timestamp: base.Add(4 * time.Second)}
+	backup <- &types.PriceUpdate{Symbol: "A", Price: 5.1, Timestamp: base.Add(5 * time.Second)}
+	backup <- &types.PriceUpdate{Symbol: "A", Price: 6, Timestamp: base.Add(6 * time.Second)}
+	require.Equal(t, 6.0, (<-merged).Price)
+
+	close(primary)
+	close(backup)
+	_, open := <-merged
+	assert.False(t, open)
+}
@@ -0,0 +1,127 @@
+package pump
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/kwanRoshi/B/go-migration/internal/metrics"
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+// SubscribeBondingCurve streams curve state for symbols as their reserves
+// change, for timing exits ahead of graduation. Updates share the price
+// stream's connection and are resubscribed when it reconnects. Symbols
+// that can't be subscribed are logged and skipped; an error is returned
+// only when none of the symbols could be subscribed.
+func (p *Provider) SubscribeBondingCurve(ctx context.Context, symbols []string) (<-chan *types.BondingCurve, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.wsClient.Connect(ctx); err != nil {
+		return nil, fmt.Errorf("failed to connect WebSocket: %w", err)
+	}
+
+	result, err := p.wsClient.SubscribeCurves(symbols)
+	if err != nil {
+		return nil, fmt.Errorf("failed to subscribe to bonding curves: %w", err)
+	}
+
+	for symbol, reason := range result.Rejected {
+		p.logger.Warn("Skipping bonding curve that failed to subscribe",
+			zap.String("symbol", symbol),
+			zap.Error(reason))
+	}
+
+	if len(symbols) > 0 && len(result.Accepted) == 0 {
+		return nil, fmt.Errorf("failed to subscribe to any of %d bonding curves", len(symbols))
+	}
+
+	return p.wsClient.GetCurveUpdates(), nil
+}
+
+// SubscribeCurves subscribes to bonding curve updates for symbols,
+// accepting or rejecting each one independently like Subscribe
+func (c *WSClient) SubscribeCurves(symbols []string) (*SubscribeResult, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+
+	result := &SubscribeResult{
+		Rejected: make(map[string]error),
+	}
+
+	for _, symbol := range symbols {
+		if c.curveSymbols[symbol] {
+			result.Accepted = append(result.Accepted, symbol)
+			continue
+		}
+
+		if err := validateSymbol(symbol); err != nil {
+			result.Rejected[symbol] = err
+			continue
+		}
+
+		if err := c.writeTradeMethod(c.conn, "subscribeBondingCurve", []string{symbol}); err != nil {
+			c.logger.Error("Failed to subscribe to bonding curve",
+				zap.String("symbol", symbol),
+				zap.Error(err))
+			result.Rejected[symbol] = fmt.Errorf("failed to subscribe to curve %s: %w", symbol, err)
+			continue
+		}
+
+		c.curveSymbols[symbol] = true
+		result.Accepted = append(result.Accepted, symbol)
+	}
+
+	return result, nil
+}
+
+// GetCurveUpdates returns the bonding curve updates channel
+func (c *WSClient) GetCurveUpdates() <-chan *types.BondingCurve {
+	return c.curves
+}
+
+// handleCurve emits a bonding curve message for a subscribed symbol.
+// Messages that repeat the last supply and price sent for the symbol are
+// dropped, so only reserve changes reach the channel.
+func (c *WSClient) handleCurve(msg []byte) {
+	var data struct {
+		Data types.BondingCurve `json:"data"`
+	}
+	if err := json.Unmarshal(msg, &data); err != nil {
+		c.logger.Error("Failed to parse bonding curve message",
+			zap.Error(err),
+			zap.String("raw_message", string(msg)))
+		return
+	}
+	curve := data.Data
+	if curve.UpdateTime.IsZero() {
+		curve.UpdateTime = time.Now()
+	}
+
+	c.mu.Lock()
+	last, seen := c.lastCurves[curve.Symbol]
+	if !c.curveSymbols[curve.Symbol] ||
+		(seen && last.Supply == curve.Supply && last.CurrentPrice == curve.CurrentPrice) {
+		c.mu.Unlock()
+		return
+	}
+	c.lastCurves[curve.Symbol] = curve
+	c.mu.Unlock()
+
+	select {
+	case c.curves <- &curve:
+		metrics.PumpBondingCurvePrice.WithLabelValues(curve.Symbol).Set(curve.CurrentPrice)
+	default:
+		c.logger.Warn("Curve channel full, dropping bonding curve update",
+			zap.String("symbol", curve.Symbol),
+			zap.Int64("supply", curve.Supply))
+	}
+}
@@ -0,0 +1,101 @@
+package pump
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// curveServer answers each subscribeBondingCurve request with a run of
+// curve updates for the key as its supply grows, repeating one update and
+// slipping in a curve nobody subscribed to
+func curveServer(t *testing.T) (*httptest.Server, string) {
+	t.Helper()
+	upgrader := websocket.Upgrader{
+		CheckOrigin: func(r *http.Request) bool { return true },
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		for {
+			var msg struct {
+				Method string   `json:"method"`
+				Keys   []string `json:"keys"`
+			}
+			if err := conn.ReadJSON(&msg); err != nil {
+				return
+			}
+			if msg.Method != "subscribeBondingCurve" {
+				continue
+			}
+			for _, key := range msg.Keys {
+				for _, supply := range []int64{100, 100, 200, 300} {
+					for _, symbol := range []string{key, "OTHER"} {
+						update := map[string]interface{}{
+							"method": "bondingCurve",
+							"data": map[string]interface{}{
+								"symbol":        symbol,
+								"current_price": 0.001 * float64(supply),
+								"supply":        supply,
+								"max_supply":    1000,
+							},
+						}
+						if err := conn.WriteJSON(update); err != nil {
+							return
+						}
+					}
+				}
+			}
+		}
+	}))
+
+	return server, "ws" + server.URL[4:]
+}
+
+func TestProvider_SubscribeBondingCurve(t *testing.T) {
+	server, wsURL := curveServer(t)
+	defer server.Close()
+
+	provider := NewProvider(Config{BaseURL: server.URL, WebSocketURL: wsURL, TimeoutSec: 1}, zap.NewNop())
+	defer provider.Close()
+
+	curves, err := provider.SubscribeBondingCurve(context.Background(), []string{"TOKEN1", "BAD SYMBOL"})
+	require.NoError(t, err)
+
+	// The repeated supply is dropped and OTHER was never subscribed
+	var supplies []int64
+	timeout := time.After(2 * time.Second)
+	for len(supplies) < 3 {
+		select {
+		case curve := <-curves:
+			assert.Equal(t, "TOKEN1", curve.Symbol)
+			assert.Equal(t, int64(1000), curve.MaxSupply)
+			assert.False(t, curve.UpdateTime.IsZero())
+			supplies = append(supplies, curve.Supply)
+		case <-timeout:
+			t.Fatalf("expected three curve updates, got %v", supplies)
+		}
+	}
+	assert.Equal(t, []int64{100, 200, 300}, supplies)
+
+	select {
+	case curve := <-curves:
+		t.Fatalf("unexpected curve update %+v", curve)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	_, err = provider.SubscribeBondingCurve(context.Background(), []string{"BAD SYMBOL"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,37 @@
+package pump
+
+import (
+	"time"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+// lastTick is the last price update emitted for a symbol
+type lastTick struct {
+	price     float64
+	volume    float64
+	emittedAt time.Time
+}
+
+// suppressDuplicate reports whether update repeats the price and volume
+// last emitted for its symbol and should be dropped. A repeat is still
+// let through once DuplicateHeartbeat has passed since the last emitted
+// update, so consumers watching for stale feeds keep seeing ticks.
+// Updates that are let through are recorded as the latest.
+func (c *WSClient) suppressDuplicate(update *types.PriceUpdate) bool {
+	if !c.suppressDuplicates {
+		return false
+	}
+
+	now := c.now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	last, seen := c.lastTicks[update.Symbol]
+	if seen && last.price == update.Price && last.volume == update.Volume &&
+		(c.duplicateHeartbeat <= 0 || now.Sub(last.emittedAt) < c.duplicateHeartbeat) {
+		return true
+	}
+	c.lastTicks[update.Symbol] = lastTick{price: update.Price, volume: update.Volume, emittedAt: now}
+	return false
+}
@@ -0,0 +1,72 @@
+package pump
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+func tradeMessage(symbol string, price, volume float64) []byte {
+	return []byte(fmt.Sprintf(`{"method":"trade","data":{"address":%q,"price":%g,"volume":%g,"blockTime":1700000000}}`,
+		symbol, price, volume))
+}
+
+func drainUpdates(c *WSClient) []*types.PriceUpdate {
+	var updates []*types.PriceUpdate
+	for {
+		select {
+		case update := <-c.updates:
+			updates = append(updates, update)
+		default:
+			return updates
+		}
+	}
+}
+
+func TestWSClient_SuppressDuplicates(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	client := NewWSClient("ws://unused", zap.NewNop(), WSConfig{
+		SuppressDuplicates: true,
+		DuplicateHeartbeat: 10 * time.Second,
+	})
+	client.now = func() time.Time { return now }
+	client.symbols["TOKEN1"] = true
+	client.symbols["TOKEN2"] = true
+
+	client.handleMessage(nil, tradeMessage("TOKEN1", 1.5, 100))
+	client.handleMessage(nil, tradeMessage("TOKEN1", 1.5, 100))
+	client.handleMessage(nil, tradeMessage("TOKEN2", 1.5, 100))
+	require.Len(t, drainUpdates(client), 2, "repeat for TOKEN1 should be dropped, TOKEN2 has its own history")
+
+	client.handleMessage(nil, tradeMessage("TOKEN1", 1.5, 200))
+	client.handleMessage(nil, tradeMessage("TOKEN1", 1.6, 200))
+	assert.Len(t, drainUpdates(client), 2, "changed volume or price always passes")
+
+	now = now.Add(9 * time.Second)
+	client.handleMessage(nil, tradeMessage("TOKEN1", 1.6, 200))
+	assert.Empty(t, drainUpdates(client))
+
+	now = now.Add(time.Second)
+	client.handleMessage(nil, tradeMessage("TOKEN1", 1.6, 200))
+	updates := drainUpdates(client)
+	require.Len(t, updates, 1, "heartbeat should let a repeat through")
+	assert.Equal(t, 1.6, updates[0].Price)
+
+	client.handleMessage(nil, tradeMessage("TOKEN1", 1.6, 200))
+	assert.Empty(t, drainUpdates(client), "heartbeat restarts from the last emitted update")
+}
+
+func TestWSClient_DuplicatesPassByDefault(t *testing.T) {
+	client := NewWSClient("ws://unused", zap.NewNop(), WSConfig{})
+	client.symbols["TOKEN1"] = true
+
+	client.handleMessage(nil, tradeMessage("TOKEN1", 1.5, 100))
+	client.handleMessage(nil, tradeMessage("TOKEN1", 1.5, 100))
+	assert.Len(t, drainUpdates(client), 2)
+}
@@ -0,0 +1,143 @@
+package pump
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/kwanRoshi/B/go-migration/internal/metrics"
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+// OverflowPolicy controls what happens when new token consumers fall behind
+type OverflowPolicy string
+
+const (
+	// OverflowBlock waits for the consumer, pausing polling once the
+	// buffers are full
+	OverflowBlock OverflowPolicy = "block"
+	// OverflowDropOldest discards the oldest buffered token to make room,
+	// so polling never stalls
+	OverflowDropOldest OverflowPolicy = "drop_oldest"
+)
+
+// newTokenSettings holds the resolved new token pipeline configuration
+type newTokenSettings struct {
+	pollInterval time.Duration
+	buffer       int
+	workers      int
+	overflow     OverflowPolicy
+}
+
+func newTokenSettingsFromConfig(config Config) newTokenSettings {
+	settings := newTokenSettings{
+		pollInterval: config.NewTokenPollInterval,
+		buffer:       config.NewTokenBuffer,
+		workers:      config.NewTokenWorkers,
+		overflow:     config.NewTokenOverflow,
+	}
+	if settings.pollInterval <= 0 {
+		settings.pollInterval = 30 * time.Second
+	}
+	if settings.buffer <= 0 {
+		settings.buffer = 100
+	}
+	if settings.workers <= 0 {
+		settings.workers = 1
+	}
+	if settings.overflow == "" {
+		settings.overflow = OverflowBlock
+	}
+	return settings
+}
+
+// SubscribeNewTokens implements MarketDataProvider interface. Polled tokens
+// are handed to a pool of NewTokenWorkers that deliver them to a channel of
+// NewTokenBuffer entries according to NewTokenOverflow. With more than one
+// worker, delivery order is not guaranteed.
+func (p *Provider) SubscribeNewTokens(ctx context.Context) (<-chan *types.TokenInfo, error) {
+	settings := p.newTokens
+	updates := make(chan *types.TokenInfo, settings.buffer)
+	jobs := make(chan *types.TokenInfo, settings.buffer)
+
+	var wg sync.WaitGroup
+	for i := 0; i < settings.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for token := range jobs {
+				p.deliverToken(ctx, updates, token, settings.overflow)
+			}
+		}()
+	}
+
+	go func() {
+		defer func() {
+			close(jobs)
+			wg.Wait()
+			close(updates)
+		}()
+
+		ticker := time.NewTicker(settings.pollInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				tokens, err := p.fetchNewTokens(ctx)
+				if err != nil {
+					p.logger.Error("Failed to get new tokens", zap.Error(err))
+					continue
+				}
+
+				for _, token := range tokens {
+					select {
+					case jobs <- token:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+		}
+	}()
+
+	return updates, nil
+}
+
+// deliverToken sends token to out, applying the overflow policy when the
+// consumer is behind
+func (p *Provider) deliverToken(ctx context.Context, out chan *types.TokenInfo, token *types.TokenInfo, policy OverflowPolicy) {
+	if policy != OverflowDropOldest {
+		select {
+		case out <- token:
+		case <-ctx.Done():
+		}
+		return
+	}
+
+	for {
+		select {
+		case out <- token:
+			return
+		default:
+		}
+
+		select {
+		case dropped := <-out:
+			metrics.PumpNewTokensDropped.Inc()
+			p.logger.Warn("New token consumer behind, dropping oldest token",
+				zap.String("symbol", dropped.Symbol))
+		default:
+		}
+	}
+}
+
+func (p *Provider) fetchNewTokens(ctx context.Context) ([]*types.TokenInfo, error) {
+	url := fmt.Sprintf("%s/api/v1/new-tokens", p.baseURL)
+	return getJSONList[*types.TokenInfo](ctx, p, "get new tokens", url)
+}
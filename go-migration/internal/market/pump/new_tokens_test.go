@@ -0,0 +1,117 @@
+package pump
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+// newTokenServer returns three new tokens with increasing sequence
+// numbers on every poll
+func newTokenServer(t *testing.T) (*httptest.Server, *int64) {
+	t.Helper()
+	var polls, seq int64
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&polls, 1)
+		tokens := make([]types.TokenInfo, 3)
+		for i := range tokens {
+			tokens[i] = types.TokenInfo{Symbol: fmt.Sprintf("T%d", atomic.AddInt64(&seq, 1))}
+		}
+		json.NewEncoder(w).Encode(tokens)
+	}))
+	return server, &polls
+}
+
+func tokenSeq(t *testing.T, token *types.TokenInfo) int {
+	t.Helper()
+	n, err := strconv.Atoi(strings.TrimPrefix(token.Symbol, "T"))
+	require.NoError(t, err)
+	return n
+}
+
+func TestProvider_SubscribeNewTokens_Overflow(t *testing.T) {
+	t.Run("DropOldest", func(t *testing.T) {
+		server, polls := newTokenServer(t)
+		defer server.Close()
+
+		provider := NewProvider(Config{
+			BaseURL:              server.URL,
+			TimeoutSec:           1,
+			NewTokenPollInterval: 5 * time.Millisecond,
+			NewTokenBuffer:       2,
+			NewTokenOverflow:     OverflowDropOldest,
+		}, zap.NewNop())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		updates, err := provider.SubscribeNewTokens(ctx)
+		require.NoError(t, err)
+
+		// Slow consumer: polling keeps going while nothing is read
+		require.Eventually(t, func() bool { return atomic.LoadInt64(polls) >= 10 },
+			2*time.Second, 5*time.Millisecond)
+
+		first := <-updates
+		assert.Greater(t, tokenSeq(t, first), 6, "oldest tokens should have been dropped")
+	})
+
+	t.Run("Block", func(t *testing.T) {
+		server, polls := newTokenServer(t)
+		defer server.Close()
+
+		provider := NewProvider(Config{
+			BaseURL:              server.URL,
+			TimeoutSec:           1,
+			NewTokenPollInterval: 5 * time.Millisecond,
+			NewTokenBuffer:       2,
+			NewTokenOverflow:     OverflowBlock,
+		}, zap.NewNop())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		updates, err := provider.SubscribeNewTokens(ctx)
+		require.NoError(t, err)
+
+		time.Sleep(100 * time.Millisecond)
+
+		// Buffers hold at most five tokens, so polling stalls and nothing
+		// is dropped
+		assert.LessOrEqual(t, atomic.LoadInt64(polls), int64(3))
+		for want := 1; want <= 5; want++ {
+			select {
+			case token := <-updates:
+				assert.Equal(t, want, tokenSeq(t, token))
+			case <-time.After(time.Second):
+				t.Fatalf("token %d not delivered", want)
+			}
+		}
+
+		cancel()
+		assert.Eventually(t, func() bool {
+			for {
+				select {
+				case _, ok := <-updates:
+					if !ok {
+						return true
+					}
+				default:
+					return false
+				}
+			}
+		}, time.Second, 5*time.Millisecond)
+	})
+}
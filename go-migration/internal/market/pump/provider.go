@@ -2,7 +2,6 @@ package pump
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"net/http"
 	"sync"
@@ -10,6 +9,7 @@ import (
 
 	"go.uber.org/zap"
 
+	"github.com/kwanRoshi/B/go-migration/internal/metrics"
 	"github.com/kwanRoshi/B/go-migration/internal/types"
 )
 
@@ -20,6 +20,7 @@ type Provider struct {
 	baseURL      string
 	wsClient     *WSClient
 	tokenMonitor *TokenMonitor
+	newTokens    newTokenSettings
 	mu           sync.RWMutex
 }
 
@@ -28,18 +29,54 @@ type Config struct {
 	BaseURL      string `json:"base_url"`
 	WebSocketURL string `json:"websocket_url"`
 	TimeoutSec   int    `json:"timeout_sec"`
+	APIKey       string `json:"api_key"`
+
+	// New token pipeline; zero values use a 30s poll, a 100 token buffer,
+	// one worker and the block policy
+	NewTokenPollInterval time.Duration  `json:"new_token_poll_interval"`
+	NewTokenBuffer       int            `json:"new_token_buffer"`
+	NewTokenWorkers      int            `json:"new_token_workers"`
+	NewTokenOverflow     OverflowPolicy `json:"new_token_overflow"`
+
+	// SuppressDuplicateTicks drops price updates that repeat a symbol's
+	// last price and volume, still emitting one every DuplicateHeartbeat
+	SuppressDuplicateTicks bool          `json:"suppress_duplicate_ticks"`
+	DuplicateHeartbeat     time.Duration `json:"duplicate_heartbeat"`
+
+	// SubscribeAckTimeout is how long subscribing waits for the server to
+	// accept or reject each symbol; zero doesn't wait
+	SubscribeAckTimeout time.Duration `json:"subscribe_ack_timeout"`
+
+	// Token monitor polling; a failed poll is retried after a backoff
+	// that doubles up to TokenMonitorMaxBackoff. Zero values use
+	// DefaultTokenMonitorConfig.
+	TokenMonitorPollInterval time.Duration `json:"token_monitor_poll_interval"`
+	TokenMonitorRetryBackoff time.Duration `json:"token_monitor_retry_backoff"`
+	TokenMonitorMaxBackoff   time.Duration `json:"token_monitor_max_backoff"`
 }
 
 // NewProvider creates a new Pump.fun provider
 func NewProvider(config Config, logger *zap.Logger) *Provider {
+	wsConfig := DefaultWSConfig()
+	wsConfig.APIKey = config.APIKey
+	wsConfig.SuppressDuplicates = config.SuppressDuplicateTicks
+	wsConfig.DuplicateHeartbeat = config.DuplicateHeartbeat
+	wsConfig.SubscribeAckTimeout = config.SubscribeAckTimeout
+
+	monitorConfig := DefaultTokenMonitorConfig()
+	monitorConfig.PollInterval = config.TokenMonitorPollInterval
+	monitorConfig.RetryBackoff = config.TokenMonitorRetryBackoff
+	monitorConfig.MaxBackoff = config.TokenMonitorMaxBackoff
+
 	return &Provider{
 		logger: logger,
 		client: &http.Client{
 			Timeout: time.Duration(config.TimeoutSec) * time.Second,
 		},
 		baseURL:      config.BaseURL,
-		wsClient:     NewWSClient(config.WebSocketURL, logger),
-		tokenMonitor: NewTokenMonitor(config.BaseURL, logger),
+		wsClient:     NewWSClient(config.WebSocketURL, logger, wsConfig),
+		tokenMonitor: NewTokenMonitor(config.BaseURL, logger, monitorConfig),
+		newTokens:    newTokenSettingsFromConfig(config),
 	}
 }
 
@@ -47,81 +84,98 @@ func NewProvider(config Config, logger *zap.Logger) *Provider {
 func (p *Provider) GetPrice(ctx context.Context, symbol string) (float64, error) {
 	url := fmt.Sprintf("%s/api/v1/price/%s", p.baseURL, symbol)
 
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return 0, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	resp, err := p.client.Do(req)
-	if err != nil {
-		return 0, fmt.Errorf("failed to get price: %w", err)
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return 0, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-	}
-
 	var result struct {
 		Price  float64 `json:"price"`
 		Volume float64 `json:"volume"`
 		Time   int64   `json:"time"`
 	}
-
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return 0, fmt.Errorf("failed to decode response: %w", err)
+	if err := p.getJSON(ctx, "get price", url, &result); err != nil {
+		return 0, err
 	}
 
 	return result.Price, nil
 }
 
-// SubscribePrices implements MarketDataProvider interface
+// SubscribePrices implements MarketDataProvider interface. Symbols that
+// can't be subscribed are logged and skipped; an error is returned only
+// when none of the symbols could be subscribed.
 func (p *Provider) SubscribePrices(ctx context.Context, symbols []string) (<-chan *types.PriceUpdate, error) {
+	updates, result, err := p.SubscribePricesWithResult(ctx, symbols)
+	if err != nil {
+		return nil, err
+	}
+
+	for symbol, reason := range result.Rejected {
+		p.logger.Warn("Skipping symbol that failed to subscribe",
+			zap.String("symbol", symbol),
+			zap.Error(reason))
+	}
+
+	if len(symbols) > 0 && len(result.Accepted) == 0 {
+		return nil, fmt.Errorf("failed to subscribe to any of %d symbols", len(symbols))
+	}
+
+	return updates, nil
+}
+
+// SubscribePricesWithResult subscribes to symbols and reports which ones
+// were accepted or rejected
+func (p *Provider) SubscribePricesWithResult(ctx context.Context, symbols []string) (<-chan *types.PriceUpdate, *SubscribeResult, error) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
 	// Connect WebSocket client if not connected
 	if err := p.wsClient.Connect(ctx); err != nil {
-		return nil, fmt.Errorf("failed to connect WebSocket: %w", err)
+		return nil, nil, fmt.Errorf("failed to connect WebSocket: %w", err)
 	}
 
-	// Subscribe to symbols
-	if err := p.wsClient.Subscribe(symbols); err != nil {
-		return nil, fmt.Errorf("failed to subscribe: %w", err)
+	result, err := p.wsClient.Subscribe(symbols)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to subscribe: %w", err)
 	}
 
-	// Return updates channel
-	return p.wsClient.GetUpdates(), nil
+	return p.wsClient.GetUpdates(), result, nil
 }
 
-// GetHistoricalPrices implements MarketDataProvider interface
-func (p *Provider) GetHistoricalPrices(ctx context.Context, symbol string, interval string, limit int) ([]types.PriceUpdate, error) {
-	url := fmt.Sprintf("%s/api/v1/historical/%s?interval=%s&limit=%d",
-		p.baseURL, symbol, interval, limit)
+// AddSymbols subscribes symbols on the existing connection; their updates
+// flow through the channel returned by SubscribePrices
+func (p *Provider) AddSymbols(symbols []string) (*SubscribeResult, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
 
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	result, err := p.wsClient.Subscribe(symbols)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to add symbols: %w", err)
 	}
 
-	resp, err := p.client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get historical prices: %w", err)
+	for symbol, reason := range result.Rejected {
+		p.logger.Warn("Failed to add symbol",
+			zap.String("symbol", symbol),
+			zap.Error(reason))
 	}
-	defer resp.Body.Close()
+	return result, nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-	}
+// RemoveSymbols unsubscribes symbols on the existing connection
+func (p *Provider) RemoveSymbols(symbols []string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
 
-	var result []struct {
-		Time   int64   `json:"time"`
-		Price  float64 `json:"price"`
-		Volume float64 `json:"volume"`
+	if err := p.wsClient.Unsubscribe(symbols); err != nil {
+		return fmt.Errorf("failed to remove symbols: %w", err)
 	}
+	return nil
+}
+
+// GetHistoricalPrices implements MarketDataProvider interface. Malformed
+// points are logged and skipped.
+func (p *Provider) GetHistoricalPrices(ctx context.Context, symbol string, interval string, limit int) ([]types.PriceUpdate, error) {
+	url := fmt.Sprintf("%s/api/v1/historical/%s?interval=%s&limit=%d",
+		p.baseURL, symbol, interval, limit)
 
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	result, err := getJSONList[historicalPoint](ctx, p, "get historical prices", url)
+	if err != nil {
+		return nil, err
 	}
 
 	updates := make([]types.PriceUpdate, len(result))
@@ -137,90 +191,72 @@ func (p *Provider) GetHistoricalPrices(ctx context.Context, symbol string, inter
 	return updates, nil
 }
 
+type historicalPoint struct {
+	Time   int64   `json:"time"`
+	Price  float64 `json:"price"`
+	Volume float64 `json:"volume"`
+}
+
 // GetBondingCurve implements MarketDataProvider interface
 func (p *Provider) GetBondingCurve(ctx context.Context, symbol string) (*types.BondingCurve, error) {
 	url := fmt.Sprintf("%s/api/v1/bonding-curve/%s", p.baseURL, symbol)
 
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+	var curve types.BondingCurve
+	if err := p.getJSON(ctx, "get bonding curve", url, &curve); err != nil {
+		return nil, err
 	}
 
-	resp, err := p.client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get bonding curve: %w", err)
-	}
-	defer resp.Body.Close()
+	// Update metrics
+	metrics.PumpBondingCurvePrice.WithLabelValues(symbol).Set(curve.CurrentPrice)
+
+	return &curve, nil
+}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+// GetTokenMetadata returns the mint metadata for a token, including whether
+// its mint and freeze authorities are renounced
+func (p *Provider) GetTokenMetadata(ctx context.Context, mint string) (*types.TokenMetadata, error) {
+	if mint == "" {
+		return nil, fmt.Errorf("empty mint address")
 	}
+	url := fmt.Sprintf("%s/api/v1/token/%s/metadata", p.baseURL, mint)
 
-	var curve types.BondingCurve
-	if err := json.NewDecoder(resp.Body).Decode(&curve); err != nil {
-		return nil, fmt.Errorf("failed to decode response: %w", err)
+	var metadata types.TokenMetadata
+	if err := p.getJSON(ctx, "get token metadata", url, &metadata); err != nil {
+		return nil, err
+	}
+	if metadata.Mint == "" {
+		metadata.Mint = mint
+	} else if metadata.Mint != mint {
+		return nil, fmt.Errorf("metadata mint mismatch: requested %s, got %s", mint, metadata.Mint)
 	}
 
-	// Update metrics
-	metrics.PumpBondingCurvePrice.WithLabelValues(symbol).Set(curve.CurrentPrice)
+	return &metadata, nil
+}
 
-	return &curve, nil
+// StartTokenMonitor starts polling for minted tokens until ctx is done or
+// the provider is closed; updates arrive on TokenUpdates
+func (p *Provider) StartTokenMonitor(ctx context.Context) error {
+	return p.tokenMonitor.Start(ctx)
 }
 
-// SubscribeNewTokens implements MarketDataProvider interface
-func (p *Provider) SubscribeNewTokens(ctx context.Context) (<-chan *types.TokenInfo, error) {
-	updates := make(chan *types.TokenInfo, 100)
-
-	go func() {
-		defer close(updates)
-
-		ticker := time.NewTicker(30 * time.Second)
-		defer ticker.Stop()
-
-		for {
-			select {
-			case <-ctx.Done():
-				return
-			case <-ticker.C:
-				url := fmt.Sprintf("%s/api/v1/new-tokens", p.baseURL)
-				req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-				if err != nil {
-					p.logger.Error("Failed to create request", zap.Error(err))
-					continue
-				}
-
-				resp, err := p.client.Do(req)
-				if err != nil {
-					p.logger.Error("Failed to get new tokens", zap.Error(err))
-					continue
-				}
-
-				var tokens []types.TokenInfo
-				if err := json.NewDecoder(resp.Body).Decode(&tokens); err != nil {
-					p.logger.Error("Failed to decode response", zap.Error(err))
-					resp.Body.Close()
-					continue
-				}
-				resp.Body.Close()
-
-				for _, token := range tokens {
-					select {
-					case updates <- &token:
-					case <-ctx.Done():
-						return
-					}
-				}
-			}
-		}
-	}()
+// TokenUpdates returns the token monitor's update channel
+func (p *Provider) TokenUpdates() <-chan *TokenUpdate {
+	return p.tokenMonitor.GetUpdates()
+}
 
-	return updates, nil
+// TokenMonitorHealth reports whether the token monitor is polling
+// successfully
+func (p *Provider) TokenMonitorHealth() TokenMonitorHealth {
+	return p.tokenMonitor.Health()
 }
-// Close closes the provider and its WebSocket client
+
+// Close stops the token monitor and closes the WebSocket client
 func (p *Provider) Close() error {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
+	p.tokenMonitor.Stop()
+
 	if p.wsClient != nil {
 		return p.wsClient.Close()
 	}
@@ -2,12 +2,18 @@ package pump
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 
 	"github.com/kwanRoshi/B/go-migration/internal/market"
+	"github.com/kwanRoshi/B/go-migration/internal/types"
 )
 
 func TestPumpProvider(t *testing.T) {
@@ -50,3 +56,103 @@ func TestPumpProvider(t *testing.T) {
 		}
 	})
 }
+
+func TestProvider_GetTokenMetadata(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/token/safe/metadata":
+			json.NewEncoder(w).Encode(types.TokenMetadata{
+				Name:                     "Safe Token",
+				Symbol:                   "SAFE",
+				Decimals:                 6,
+				Creator:                  "creator1",
+				MintAuthorityRenounced:   true,
+				FreezeAuthorityRenounced: true,
+			})
+		case "/api/v1/token/rug/metadata":
+			json.NewEncoder(w).Encode(types.TokenMetadata{
+				Mint:                     "rug",
+				Symbol:                   "RUG",
+				Decimals:                 6,
+				Creator:                  "creator2",
+				MintAuthority:            "creator2",
+				FreezeAuthorityRenounced: true,
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	provider := NewProvider(Config{BaseURL: server.URL, TimeoutSec: 1}, zap.NewNop())
+	ctx := context.Background()
+
+	safe, err := provider.GetTokenMetadata(ctx, "safe")
+	require.NoError(t, err)
+	assert.Equal(t, "safe", safe.Mint)
+	assert.Equal(t, uint8(6), safe.Decimals)
+	assert.True(t, safe.MintAuthorityRenounced)
+	assert.True(t, safe.FreezeAuthorityRenounced)
+
+	rug, err := provider.GetTokenMetadata(ctx, "rug")
+	require.NoError(t, err)
+	assert.False(t, rug.MintAuthorityRenounced)
+	assert.Equal(t, "creator2", rug.MintAuthority)
+
+	_, err = provider.GetTokenMetadata(ctx, "missing")
+	assert.Error(t, err)
+}
+
+func TestProvider_MalformedResponses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/price/DOWN":
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"error":"maintenance"}`))
+		case "/api/v1/price/TRUNC":
+			w.Write([]byte(`{"price": 1.5, "vol`))
+		case "/api/v1/price/EXTRA":
+			w.Write([]byte(`{"price": 2.5, "volume": 10, "new_field": {"nested": true}}`))
+		case "/api/v1/historical/MIXED":
+			w.Write([]byte(`[{"time": 1, "price": 1.0}, {"time": "bad", "price": 2.0}, {"time": 3, "price": 3.0}]`))
+		case "/api/v1/new-tokens":
+			w.Write([]byte(`[null, {"symbol": "NEW"}, null]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	provider := NewProvider(Config{BaseURL: server.URL, TimeoutSec: 1}, zap.NewNop())
+	ctx := context.Background()
+
+	_, err := provider.GetPrice(ctx, "DOWN")
+	var statusErr *StatusError
+	require.ErrorAs(t, err, &statusErr)
+	assert.Equal(t, http.StatusServiceUnavailable, statusErr.StatusCode)
+	assert.Contains(t, statusErr.Body, "maintenance")
+
+	_, err = provider.GetPrice(ctx, "TRUNC")
+	var decodeErr *DecodeError
+	assert.ErrorAs(t, err, &decodeErr)
+
+	price, err := provider.GetPrice(ctx, "EXTRA")
+	require.NoError(t, err, "unknown fields are ignored")
+	assert.Equal(t, 2.5, price)
+
+	points, err := provider.GetHistoricalPrices(ctx, "MIXED", "1m", 3)
+	require.NoError(t, err)
+	require.Len(t, points, 2, "the bad element is skipped")
+	assert.Equal(t, 1.0, points[0].Price)
+	assert.Equal(t, 3.0, points[1].Price)
+
+	tokens, err := provider.fetchNewTokens(ctx)
+	require.NoError(t, err)
+	require.Len(t, tokens, 1, "null elements are skipped")
+	assert.Equal(t, "NEW", tokens[0].Symbol)
+
+	closed := NewProvider(Config{BaseURL: "http://127.0.0.1:1", TimeoutSec: 1}, zap.NewNop())
+	_, err = closed.GetPrice(ctx, "TEST")
+	var transportErr *TransportError
+	assert.ErrorAs(t, err, &transportErr)
+}
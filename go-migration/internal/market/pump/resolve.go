@@ -0,0 +1,96 @@
+package pump
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+// ErrNoTokenMatch is returned by ResolveSymbol when no token matches the
+// query
+var ErrNoTokenMatch = errors.New("no token matches")
+
+// resolveSearchLimit is how many search results ResolveSymbol ranks
+const resolveSearchLimit = 20
+
+// Match ranks for ResolveSymbol, best first
+const (
+	matchMint = iota
+	matchSymbol
+	matchName
+	matchSymbolPrefix
+	matchNameContains
+	matchNone
+)
+
+// matchRank returns how well token matches query. Mints are
+// case-sensitive base58; tickers and names match case-insensitively.
+func matchRank(token types.TokenInfo, query string) int {
+	if token.Mint != "" && token.Mint == query {
+		return matchMint
+	}
+	query = strings.ToLower(query)
+	symbol, name := strings.ToLower(token.Symbol), strings.ToLower(token.Name)
+	switch {
+	case symbol == query:
+		return matchSymbol
+	case name == query:
+		return matchName
+	case strings.HasPrefix(symbol, query):
+		return matchSymbolPrefix
+	case strings.Contains(name, query):
+		return matchNameContains
+	}
+	return matchNone
+}
+
+// ResolveSymbol resolves a mint address, ticker or partial name to a
+// token. Search results are ranked by how closely they match query: mint,
+// then exact ticker, exact name, ticker prefix and name substring, with
+// the larger market cap first on a tie. The best match is returned with
+// the other matches as alternatives, so callers can flag ambiguity;
+// results that don't match query at all are dropped.
+func (p *Provider) ResolveSymbol(ctx context.Context, query string) (*types.TokenInfo, []types.TokenInfo, error) {
+	query = strings.TrimSpace(strings.TrimPrefix(query, "$"))
+	if query == "" {
+		return nil, nil, fmt.Errorf("empty token query")
+	}
+
+	searchURL := fmt.Sprintf("%s/api/v1/search?q=%s&limit=%d", p.baseURL, url.QueryEscape(query), resolveSearchLimit)
+	results, err := getJSONList[types.TokenInfo](ctx, p, "search tokens", searchURL)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	type rankedToken struct {
+		token types.TokenInfo
+		rank  int
+	}
+	var matches []rankedToken
+	for _, token := range results {
+		if rank := matchRank(token, query); rank != matchNone {
+			matches = append(matches, rankedToken{token: token, rank: rank})
+		}
+	}
+	if len(matches) == 0 {
+		return nil, nil, fmt.Errorf("%w %q", ErrNoTokenMatch, query)
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].rank != matches[j].rank {
+			return matches[i].rank < matches[j].rank
+		}
+		return matches[i].token.MarketCap > matches[j].token.MarketCap
+	})
+	best := matches[0].token
+	alternatives := make([]types.TokenInfo, 0, len(matches)-1)
+	for _, match := range matches[1:] {
+		alternatives = append(alternatives, match.token)
+	}
+	return &best, alternatives, nil
+}
@@ -0,0 +1,69 @@
+package pump
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestProvider_ResolveSymbol(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/search", r.URL.Path)
+		switch r.URL.Query().Get("q") {
+		case "bonk", "BoNkMint111":
+			w.Write([]byte(`[
+				{"mint": "BonkPepeMint", "symbol": "BONKPEPE", "name": "Bonk Pepe", "market_cap": 900000},
+				{"mint": "BoNkMint111", "symbol": "BONK", "name": "Bonk", "market_cap": 50000},
+				{"mint": "Unrelated", "symbol": "WIF", "name": "dogwifhat", "market_cap": 10000000}
+			]`))
+		case "dog":
+			w.Write([]byte(`[
+				{"mint": "DogMintA", "symbol": "DOGA", "name": "Moon Dog", "market_cap": 1000},
+				{"mint": "DogMintB", "symbol": "WOOF", "name": "Space Dog", "market_cap": 5000},
+				{"mint": "DogMintC", "symbol": "DOGGO", "name": "Doggo", "market_cap": 3000}
+			]`))
+		default:
+			w.Write([]byte(`[]`))
+		}
+	}))
+	defer server.Close()
+
+	provider := NewProvider(Config{BaseURL: server.URL, TimeoutSec: 1}, zap.NewNop())
+	ctx := context.Background()
+
+	t.Run("ExactTicker", func(t *testing.T) {
+		// The exact ticker beats a bigger token that only starts with it
+		best, alternatives, err := provider.ResolveSymbol(ctx, "$bonk")
+		require.NoError(t, err)
+		assert.Equal(t, "BoNkMint111", best.Mint)
+		assert.Equal(t, "BONK", best.Symbol)
+		require.Len(t, alternatives, 1, "unrelated results are dropped")
+		assert.Equal(t, "BONKPEPE", alternatives[0].Symbol)
+
+		best, _, err = provider.ResolveSymbol(ctx, "BoNkMint111")
+		require.NoError(t, err)
+		assert.Equal(t, "BONK", best.Symbol, "resolved by mint")
+	})
+
+	t.Run("AmbiguousName", func(t *testing.T) {
+		// Ticker prefixes rank above name matches, then market cap decides
+		best, alternatives, err := provider.ResolveSymbol(ctx, "dog")
+		require.NoError(t, err)
+		assert.Equal(t, "DOGGO", best.Symbol)
+		require.Len(t, alternatives, 2)
+		assert.Equal(t, "DOGA", alternatives[0].Symbol)
+		assert.Equal(t, "WOOF", alternatives[1].Symbol)
+	})
+
+	t.Run("NoMatch", func(t *testing.T) {
+		_, _, err := provider.ResolveSymbol(ctx, "nothing")
+		assert.ErrorIs(t, err, ErrNoTokenMatch)
+		_, _, err = provider.ResolveSymbol(ctx, "  ")
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,114 @@
+package pump
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+
+	"go.uber.org/zap"
+)
+
+// maxErrorBody caps how much of a non-200 response body is kept
+const maxErrorBody = 4096
+
+// TransportError is returned when a request could not be sent or no
+// response was received
+type TransportError struct {
+	Op  string
+	Err error
+}
+
+func (e *TransportError) Error() string {
+	return fmt.Sprintf("failed to %s: %v", e.Op, e.Err)
+}
+
+func (e *TransportError) Unwrap() error {
+	return e.Err
+}
+
+// StatusError is returned for non-200 responses. Body holds the start of
+// the response body.
+type StatusError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	if e.Body == "" {
+		return fmt.Sprintf("unexpected status code: %d", e.StatusCode)
+	}
+	return fmt.Sprintf("unexpected status code: %d: %s", e.StatusCode, e.Body)
+}
+
+// DecodeError is returned when a response body is not valid JSON for the
+// expected type
+type DecodeError struct {
+	Err error
+}
+
+func (e *DecodeError) Error() string {
+	return fmt.Sprintf("failed to decode response: %v", e.Err)
+}
+
+func (e *DecodeError) Unwrap() error {
+	return e.Err
+}
+
+// getJSON fetches url and decodes the JSON body into out. Unknown fields
+// are ignored so additions to the API don't break decoding.
+func (p *Provider) getJSON(ctx context.Context, op, url string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return &TransportError{Op: op, Err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return &StatusError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return &DecodeError{Err: err}
+	}
+	return nil
+}
+
+// getJSONList fetches a JSON array from url and decodes each element as T.
+// Elements that fail to decode are logged and skipped instead of failing
+// the whole batch, as are null elements, which would otherwise decode to
+// nil pointers; only a body that isn't an array is an error.
+func getJSONList[T any](ctx context.Context, p *Provider, op, url string) ([]T, error) {
+	var raw []json.RawMessage
+	if err := p.getJSON(ctx, op, url, &raw); err != nil {
+		return nil, err
+	}
+
+	items := make([]T, 0, len(raw))
+	for i, msg := range raw {
+		if bytes.Equal(bytes.TrimSpace(msg), []byte("null")) {
+			p.logger.Warn("Skipping null item in response",
+				zap.String("op", op),
+				zap.Int("index", i))
+			continue
+		}
+		var item T
+		if err := json.Unmarshal(msg, &item); err != nil {
+			p.logger.Warn("Skipping malformed item in response",
+				zap.String("op", op),
+				zap.Int("index", i),
+				zap.Error(err))
+			continue
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
@@ -10,43 +10,99 @@ import (
 
 	"go.uber.org/zap"
 
-	"github.com/kwanRoshi/B/go-migration/internal/types"
+	"github.com/kwanRoshi/B/go-migration/internal/metrics"
 )
 
+// TokenMonitorConfig holds token monitor polling and retry settings
+type TokenMonitorConfig struct {
+	PollInterval time.Duration
+	Timeout      time.Duration
+	// MaxRetries is the number of attempts per poll. When a poll fails
+	// the monitor waits RetryBackoff, doubling up to MaxBackoff on each
+	// further failure, before polling again.
+	MaxRetries   int
+	RetryBackoff time.Duration
+	MaxBackoff   time.Duration
+}
+
+// DefaultTokenMonitorConfig returns the token monitor settings used when
+// none are configured
+func DefaultTokenMonitorConfig() TokenMonitorConfig {
+	return TokenMonitorConfig{
+		PollInterval: 5 * time.Second,
+		Timeout:      10 * time.Second,
+		MaxRetries:   3,
+		RetryBackoff: time.Second,
+		MaxBackoff:   30 * time.Second,
+	}
+}
+
+// TokenMonitorHealth reports whether the token monitor is polling
+// successfully
+type TokenMonitorHealth struct {
+	Active              bool      `json:"active"`
+	Healthy             bool      `json:"healthy"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	Restarts            int       `json:"restarts"`
+	LastSuccess         time.Time `json:"last_success"`
+	LastError           string    `json:"last_error,omitempty"`
+}
+
 type TokenMonitor struct {
 	logger     *zap.Logger
 	client     *http.Client
 	baseURL    string
-	updateChan chan *types.TokenUpdate
+	config     TokenMonitorConfig
+	updateChan chan *TokenUpdate
 	mu         sync.RWMutex
 	active     bool
+	cancel     context.CancelFunc
+	health     TokenMonitorHealth
 }
 
 type TokenUpdate struct {
-	Symbol     string    `json:"symbol"`
-	MintTime   time.Time `json:"mint_time"`
-	InitPrice  float64   `json:"init_price"`
-	TotalMint  int64     `json:"total_mint"`
+	Symbol    string    `json:"symbol"`
+	MintTime  time.Time `json:"mint_time"`
+	InitPrice float64   `json:"init_price"`
+	TotalMint int64     `json:"total_mint"`
 }
 
-func NewTokenMonitor(baseURL string, logger *zap.Logger) *TokenMonitor {
+func NewTokenMonitor(baseURL string, logger *zap.Logger, config TokenMonitorConfig) *TokenMonitor {
+	defaults := DefaultTokenMonitorConfig()
+	if config.PollInterval <= 0 {
+		config.PollInterval = defaults.PollInterval
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = defaults.Timeout
+	}
+	if config.MaxRetries <= 0 {
+		config.MaxRetries = defaults.MaxRetries
+	}
+	if config.RetryBackoff <= 0 {
+		config.RetryBackoff = defaults.RetryBackoff
+	}
+	if config.MaxBackoff < config.RetryBackoff {
+		config.MaxBackoff = max(defaults.MaxBackoff, config.RetryBackoff)
+	}
+
 	return &TokenMonitor{
 		logger:     logger,
-		client:     &http.Client{Timeout: 10 * time.Second},
+		client:     &http.Client{Timeout: config.Timeout},
 		baseURL:    baseURL,
-		updateChan: make(chan *types.TokenUpdate, 100),
+		config:     config,
+		updateChan: make(chan *TokenUpdate, 100),
 		active:     false,
 	}
 }
 
 func (tm *TokenMonitor) Start(ctx context.Context) error {
 	tm.mu.Lock()
+	defer tm.mu.Unlock()
 	if tm.active {
-		tm.mu.Unlock()
 		return nil
 	}
+	ctx, tm.cancel = context.WithCancel(ctx)
 	tm.active = true
-	tm.mu.Unlock()
 
 	go tm.monitorNewTokens(ctx)
 	return nil
@@ -54,107 +110,159 @@ func (tm *TokenMonitor) Start(ctx context.Context) error {
 
 func (tm *TokenMonitor) Stop() {
 	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	if tm.cancel != nil {
+		tm.cancel()
+		tm.cancel = nil
+	}
 	tm.active = false
-	tm.mu.Unlock()
 }
 
-func (tm *TokenMonitor) GetUpdates() <-chan *types.TokenUpdate {
+func (tm *TokenMonitor) GetUpdates() <-chan *TokenUpdate {
 	return tm.updateChan
 }
 
+// Health returns the monitor's current polling health
+func (tm *TokenMonitor) Health() TokenMonitorHealth {
+	tm.mu.RLock()
+	defer tm.mu.RUnlock()
+	health := tm.health
+	health.Active = tm.active
+	return health
+}
+
+// monitorNewTokens polls until ctx is done. A failed poll doesn't end the
+// loop: the monitor backs off and restarts polling, so discovery resumes
+// once the API recovers.
 func (tm *TokenMonitor) monitorNewTokens(ctx context.Context) {
-	ticker := time.NewTicker(5 * time.Second)
-	defer ticker.Stop()
+	backoff := tm.config.RetryBackoff
+	timer := time.NewTimer(tm.config.PollInterval)
+	defer timer.Stop()
 
 	for {
 		select {
 		case <-ctx.Done():
 			return
-		case <-ticker.C:
-			updates, err := tm.fetchNewTokens(ctx)
-			if err != nil {
-				tm.logger.Error("failed to fetch new tokens", zap.Error(err))
-				continue
+		case <-timer.C:
+		}
+
+		updates, err := tm.fetchNewTokens(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
 			}
+			failures := tm.recordFailure(err)
+			metrics.PumpTokenMonitorRestarts.Inc()
+			tm.logger.Error("Token monitor poll failed, restarting after backoff",
+				zap.Error(err),
+				zap.Int("consecutive_failures", failures),
+				zap.Duration("backoff", backoff))
+			timer.Reset(backoff)
+			backoff = min(backoff*2, tm.config.MaxBackoff)
+			continue
+		}
+
+		if failures := tm.recordSuccess(); failures > 0 {
+			tm.logger.Info("Token monitor recovered",
+				zap.Int("failed_polls", failures))
+		}
+		backoff = tm.config.RetryBackoff
 
-			for _, update := range updates {
-				select {
-				case tm.updateChan <- update:
-				default:
-					tm.logger.Warn("update channel full, dropping token update")
-				}
+		for _, update := range updates {
+			select {
+			case tm.updateChan <- update:
+			default:
+				tm.logger.Warn("update channel full, dropping token update")
 			}
 		}
+		timer.Reset(tm.config.PollInterval)
 	}
 }
 
-func (tm *TokenMonitor) fetchNewTokens(ctx context.Context) ([]*types.TokenUpdate, error) {
+// recordFailure marks a failed poll and returns the consecutive failures
+func (tm *TokenMonitor) recordFailure(err error) int {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	tm.health.Healthy = false
+	tm.health.ConsecutiveFailures++
+	tm.health.Restarts++
+	tm.health.LastError = err.Error()
+	return tm.health.ConsecutiveFailures
+}
+
+// recordSuccess marks a successful poll and returns how many failed polls
+// preceded it
+func (tm *TokenMonitor) recordSuccess() int {
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+	failures := tm.health.ConsecutiveFailures
+	tm.health.Healthy = true
+	tm.health.ConsecutiveFailures = 0
+	tm.health.LastSuccess = time.Now()
+	tm.health.LastError = ""
+	return failures
+}
+
+func (tm *TokenMonitor) fetchNewTokens(ctx context.Context) ([]*TokenUpdate, error) {
 	url := fmt.Sprintf("%s/api/v1/new-tokens", tm.baseURL)
-	
-	backoff := time.Second
-	maxBackoff := 30 * time.Second
-	maxRetries := 3
-	
-	var updates []*types.TokenUpdate
+
+	backoff := tm.config.RetryBackoff
 	var lastErr error
-	
-	for retry := 0; retry < maxRetries; retry++ {
+
+	for retry := 0; retry < tm.config.MaxRetries; retry++ {
 		if retry > 0 {
 			select {
 			case <-ctx.Done():
 				return nil, ctx.Err()
 			case <-time.After(backoff):
-				backoff = time.Duration(float64(backoff) * 1.5)
-				if backoff > maxBackoff {
-					backoff = maxBackoff
-				}
+				backoff = min(time.Duration(float64(backoff)*1.5), tm.config.MaxBackoff)
 			}
 		}
-		
-		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-		if err != nil {
-			lastErr = fmt.Errorf("failed to create request: %w", err)
-			continue
-		}
-		
-		resp, err := tm.client.Do(req)
-		if err != nil {
-			metrics.PumpAPIErrors.WithLabelValues("fetch_new_tokens").Inc()
-			lastErr = fmt.Errorf("failed to fetch new tokens: %w", err)
-			tm.logger.Error("failed to fetch new tokens", 
-				zap.Error(err),
-				zap.Int("retry", retry),
-				zap.Duration("backoff", backoff))
-			continue
-		}
-		defer resp.Body.Close()
-		
-		if resp.StatusCode != http.StatusOK {
-			metrics.PumpAPIErrors.WithLabelValues("fetch_new_tokens").Inc()
-			lastErr = fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-			tm.logger.Error("unexpected status code",
-				zap.Int("status_code", resp.StatusCode),
-				zap.Int("retry", retry),
-				zap.Duration("backoff", backoff))
-			if resp.StatusCode == http.StatusTooManyRequests {
-				continue
-			}
-			return nil, lastErr
+
+		updates, retryable, err := tm.fetchOnce(ctx, url)
+		if err == nil {
+			// Update metrics for successful fetch
+			metrics.PumpNewTokens.Add(float64(len(updates)))
+			return updates, nil
 		}
-		
-		if err := json.NewDecoder(resp.Body).Decode(&updates); err != nil {
-			metrics.PumpAPIErrors.WithLabelValues("fetch_new_tokens").Inc()
-			lastErr = fmt.Errorf("failed to decode response: %w", err)
-			tm.logger.Error("failed to decode response",
-				zap.Error(err),
-				zap.Int("retry", retry))
-			continue
+		lastErr = err
+		tm.logger.Error("failed to fetch new tokens",
+			zap.Error(err),
+			zap.Int("retry", retry),
+			zap.Duration("backoff", backoff))
+		if !retryable {
+			return nil, err
 		}
-		
-		// Update metrics for successful fetch
-		metrics.PumpNewTokens.Add(float64(len(updates)))
-		return updates, nil
 	}
-	
+
 	return nil, fmt.Errorf("max retries exceeded: %w", lastErr)
 }
+
+// fetchOnce makes a single new tokens request, reporting whether a failure
+// is worth retrying
+func (tm *TokenMonitor) fetchOnce(ctx context.Context, url string) ([]*TokenUpdate, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	resp, err := tm.client.Do(req)
+	if err != nil {
+		metrics.PumpAPIErrors.WithLabelValues("fetch_new_tokens").Inc()
+		return nil, true, fmt.Errorf("failed to fetch new tokens: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		metrics.PumpAPIErrors.WithLabelValues("fetch_new_tokens").Inc()
+		retryable := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= http.StatusInternalServerError
+		return nil, retryable, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	var updates []*TokenUpdate
+	if err := json.NewDecoder(resp.Body).Decode(&updates); err != nil {
+		metrics.PumpAPIErrors.WithLabelValues("fetch_new_tokens").Inc()
+		return nil, true, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return updates, false, nil
+}
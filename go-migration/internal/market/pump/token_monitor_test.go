@@ -0,0 +1,80 @@
+package pump
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestProvider_TokenMonitorRecovers(t *testing.T) {
+	// Each poll makes three attempts, so the first two polls fail outright
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) <= 6 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		json.NewEncoder(w).Encode([]*TokenUpdate{{Symbol: "NEW", InitPrice: 0.001}})
+	}))
+	defer server.Close()
+
+	provider := NewProvider(Config{
+		BaseURL:                  server.URL,
+		TokenMonitorPollInterval: 10 * time.Millisecond,
+		TokenMonitorRetryBackoff: time.Millisecond,
+		TokenMonitorMaxBackoff:   5 * time.Millisecond,
+	}, zap.NewNop())
+	defer provider.Close()
+
+	assert.False(t, provider.TokenMonitorHealth().Active)
+	require.NoError(t, provider.StartTokenMonitor(context.Background()))
+
+	select {
+	case update := <-provider.TokenUpdates():
+		assert.Equal(t, "NEW", update.Symbol)
+	case <-time.After(2 * time.Second):
+		t.Fatal("token monitor did not resume emitting after failures")
+	}
+
+	health := provider.TokenMonitorHealth()
+	assert.True(t, health.Active)
+	assert.True(t, health.Healthy)
+	assert.Equal(t, 2, health.Restarts)
+	assert.Zero(t, health.ConsecutiveFailures)
+	assert.Empty(t, health.LastError)
+	assert.False(t, health.LastSuccess.IsZero())
+
+	require.NoError(t, provider.Close())
+	assert.False(t, provider.TokenMonitorHealth().Active)
+}
+
+func TestTokenMonitor_ReportsFailures(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	monitor := NewTokenMonitor(server.URL, zap.NewNop(), TokenMonitorConfig{
+		PollInterval: time.Millisecond,
+		RetryBackoff: time.Millisecond,
+		MaxBackoff:   2 * time.Millisecond,
+	})
+	require.NoError(t, monitor.Start(context.Background()))
+	defer monitor.Stop()
+
+	require.Eventually(t, func() bool {
+		return monitor.Health().ConsecutiveFailures >= 3
+	}, 2*time.Second, time.Millisecond, "monitor should keep retrying after failures")
+
+	health := monitor.Health()
+	assert.False(t, health.Healthy)
+	assert.Contains(t, health.LastError, "404")
+}
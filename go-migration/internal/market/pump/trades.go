@@ -0,0 +1,63 @@
+package pump
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+// recentTrade is one executed swap as returned by the trades endpoint
+type recentTrade struct {
+	TxHash    string  `json:"txHash"`
+	Side      string  `json:"side"`
+	Price     float64 `json:"price"`
+	Amount    float64 `json:"amount"`
+	BlockTime int64   `json:"blockTime"`
+}
+
+// GetRecentTrades returns up to limit of the latest swaps in symbol, newest
+// first as the API orders them. Side is the aggressor: buys took tokens
+// from the curve and sells returned them. Trades with an unknown side or
+// a non-positive price or amount are logged and skipped; a symbol with no
+// trades returns an empty slice.
+func (p *Provider) GetRecentTrades(ctx context.Context, symbol string, limit int) ([]types.Trade, error) {
+	if symbol == "" {
+		return nil, fmt.Errorf("empty symbol")
+	}
+	if limit <= 0 {
+		return nil, fmt.Errorf("invalid trades limit %d: must be positive", limit)
+	}
+
+	url := fmt.Sprintf("%s/api/v1/trades/%s?limit=%d", p.baseURL, symbol, limit)
+	raw, err := getJSONList[recentTrade](ctx, p, "get recent trades", url)
+	if err != nil {
+		return nil, err
+	}
+
+	trades := make([]types.Trade, 0, len(raw))
+	for _, item := range raw {
+		side := types.OrderSide(item.Side)
+		if (side != types.OrderSideBuy && side != types.OrderSideSell) || item.Price <= 0 || item.Amount <= 0 {
+			p.logger.Warn("Skipping malformed trade",
+				zap.String("symbol", symbol),
+				zap.String("tx_hash", item.TxHash),
+				zap.String("side", item.Side),
+				zap.Float64("price", item.Price),
+				zap.Float64("amount", item.Amount))
+			continue
+		}
+		trades = append(trades, types.Trade{
+			ID:        item.TxHash,
+			Symbol:    symbol,
+			Side:      side,
+			Price:     item.Price,
+			Quantity:  item.Amount,
+			Timestamp: time.Unix(item.BlockTime, 0),
+		})
+	}
+	return trades, nil
+}
@@ -0,0 +1,61 @@
+package pump
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+func TestProvider_GetRecentTrades(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "2", r.URL.Query().Get("limit"))
+		switch r.URL.Path {
+		case "/api/v1/trades/TOKEN1":
+			w.Write([]byte(`[
+				{"txHash": "tx2", "side": "sell", "price": 0.00021, "amount": 150000, "blockTime": 1700000060},
+				{"txHash": "tx1", "side": "buy", "price": 0.0002, "amount": 500000, "blockTime": 1700000000, "user": "ignored"},
+				{"txHash": "tx0", "side": "swap", "price": 0.0002, "amount": 1, "blockTime": 1699999999}
+			]`))
+		case "/api/v1/trades/QUIET":
+			w.Write([]byte(`[]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	provider := NewProvider(Config{BaseURL: server.URL, TimeoutSec: 1}, zap.NewNop())
+	ctx := context.Background()
+
+	trades, err := provider.GetRecentTrades(ctx, "TOKEN1", 2)
+	require.NoError(t, err)
+	require.Len(t, trades, 2, "trades with an unknown side are skipped")
+	assert.Equal(t, types.Trade{
+		ID:        "tx2",
+		Symbol:    "TOKEN1",
+		Side:      types.OrderSideSell,
+		Price:     0.00021,
+		Quantity:  150000,
+		Timestamp: time.Unix(1700000060, 0),
+	}, trades[0])
+	assert.Equal(t, types.OrderSideBuy, trades[1].Side)
+	assert.Equal(t, 500000.0, trades[1].Quantity)
+
+	trades, err = provider.GetRecentTrades(ctx, "QUIET", 2)
+	require.NoError(t, err)
+	assert.NotNil(t, trades)
+	assert.Empty(t, trades)
+
+	_, err = provider.GetRecentTrades(ctx, "TOKEN1", 0)
+	assert.Error(t, err)
+	_, err = provider.GetRecentTrades(ctx, "", 2)
+	assert.Error(t, err)
+}
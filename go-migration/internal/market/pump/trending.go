@@ -0,0 +1,33 @@
+package pump
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+// Rankings accepted by GetTrending
+const (
+	TrendingByVolume        = "volume"
+	TrendingByPriceChange   = "price-change"
+	TrendingByHoldersGrowth = "holders-growth"
+)
+
+// GetTrending returns up to limit tokens ranked by volume, price change or
+// holder growth, best first. The ranking from the API is kept as is;
+// malformed entries are logged and skipped.
+func (p *Provider) GetTrending(ctx context.Context, by string, limit int) ([]types.TokenInfo, error) {
+	switch by {
+	case TrendingByVolume, TrendingByPriceChange, TrendingByHoldersGrowth:
+	default:
+		return nil, fmt.Errorf("invalid trending ranking %q: must be %s, %s or %s",
+			by, TrendingByVolume, TrendingByPriceChange, TrendingByHoldersGrowth)
+	}
+	if limit <= 0 {
+		return nil, fmt.Errorf("invalid trending limit %d: must be positive", limit)
+	}
+
+	url := fmt.Sprintf("%s/api/v1/trending?by=%s&limit=%d", p.baseURL, by, limit)
+	return getJSONList[types.TokenInfo](ctx, p, "get trending tokens", url)
+}
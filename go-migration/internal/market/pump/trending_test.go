@@ -0,0 +1,47 @@
+package pump
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestProvider_GetTrending(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/trending" {
+			http.NotFound(w, r)
+			return
+		}
+		assert.Equal(t, "volume", r.URL.Query().Get("by"))
+		assert.Equal(t, "3", r.URL.Query().Get("limit"))
+		// Ranked by volume, though neither symbol nor market cap is sorted
+		w.Write([]byte(`[
+			{"symbol": "ZED", "volume": 900, "market_cap": 10},
+			{"symbol": "ALPHA", "volume": 500, "market_cap": 5000},
+			"bad",
+			{"symbol": "MID", "volume": 100, "market_cap": 300}
+		]`))
+	}))
+	defer server.Close()
+
+	provider := NewProvider(Config{BaseURL: server.URL, TimeoutSec: 1}, zap.NewNop())
+	ctx := context.Background()
+
+	tokens, err := provider.GetTrending(ctx, TrendingByVolume, 3)
+	require.NoError(t, err)
+	require.Len(t, tokens, 3)
+	assert.Equal(t, "ZED", tokens[0].Symbol)
+	assert.Equal(t, "ALPHA", tokens[1].Symbol)
+	assert.Equal(t, "MID", tokens[2].Symbol)
+	assert.Equal(t, 900.0, tokens[0].Volume)
+
+	_, err = provider.GetTrending(ctx, "market-cap", 3)
+	assert.Error(t, err)
+	_, err = provider.GetTrending(ctx, TrendingByHoldersGrowth, 0)
+	assert.Error(t, err)
+}
@@ -6,12 +6,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/gorilla/websocket"
 	"go.uber.org/zap"
 
+	"github.com/kwanRoshi/B/go-migration/internal/metrics"
 	"github.com/kwanRoshi/B/go-migration/internal/types"
 )
 
@@ -23,6 +25,35 @@ type WSConfig struct {
 	PongWait     time.Duration
 	MaxRetries   int
 	APIKey       string
+	// SuppressDuplicates drops consecutive updates repeating a symbol's
+	// price and volume, letting one through every DuplicateHeartbeat;
+	// a zero heartbeat drops every repeat
+	SuppressDuplicates bool
+	DuplicateHeartbeat time.Duration
+	// SubscribeAckTimeout is how long Subscribe waits for the server to
+	// acknowledge or reject each new symbol. Symbols without an answer by
+	// then are accepted; zero doesn't wait, and a later rejection only
+	// drops the symbol.
+	SubscribeAckTimeout time.Duration
+}
+
+// DefaultWSConfig returns the WebSocket settings used when none are configured
+func DefaultWSConfig() WSConfig {
+	return WSConfig{
+		DialTimeout:  10 * time.Second,
+		WriteTimeout: 10 * time.Second,
+		ReadTimeout:  60 * time.Second,
+		PongWait:     60 * time.Second,
+		MaxRetries:   3,
+	}
+}
+
+// SubscribeResult reports the outcome of a subscription request per
+// symbol. Rejected holds both symbols that failed validation or the write
+// and those the server rejected while Subscribe waited for its answer.
+type SubscribeResult struct {
+	Accepted []string         `json:"accepted"`
+	Rejected map[string]error `json:"-"`
 }
 
 // WSClient handles WebSocket connections for real-time price updates
@@ -41,10 +72,39 @@ type WSClient struct {
 	pingPeriod   time.Duration
 	maxRetries   int
 	apiKey       string
+	started      bool
+	closed       bool
+
+	suppressDuplicates bool
+	duplicateHeartbeat time.Duration
+	lastTicks          map[string]lastTick
+	now                func() time.Time
+
+	ackTimeout time.Duration
+	acks       map[string]chan error
+
+	curves       chan *types.BondingCurve
+	curveSymbols map[string]bool
+	lastCurves   map[string]types.BondingCurve
 }
 
-// NewWSClient creates a new WebSocket client
+// NewWSClient creates a new WebSocket client. Zero durations in config
+// fall back to DefaultWSConfig.
 func NewWSClient(wsURL string, logger *zap.Logger, config WSConfig) *WSClient {
+	defaults := DefaultWSConfig()
+	if config.DialTimeout <= 0 {
+		config.DialTimeout = defaults.DialTimeout
+	}
+	if config.WriteTimeout <= 0 {
+		config.WriteTimeout = defaults.WriteTimeout
+	}
+	if config.ReadTimeout <= 0 {
+		config.ReadTimeout = defaults.ReadTimeout
+	}
+	if config.PongWait <= 0 {
+		config.PongWait = defaults.PongWait
+	}
+
 	return &WSClient{
 		logger:       logger,
 		updates:      make(chan *types.PriceUpdate, 1000),
@@ -58,6 +118,18 @@ func NewWSClient(wsURL string, logger *zap.Logger, config WSConfig) *WSClient {
 		pingPeriod:   (config.PongWait * 9) / 10,
 		maxRetries:   config.MaxRetries,
 		apiKey:       config.APIKey,
+
+		suppressDuplicates: config.SuppressDuplicates,
+		duplicateHeartbeat: config.DuplicateHeartbeat,
+		lastTicks:          make(map[string]lastTick),
+		now:                time.Now,
+
+		ackTimeout: config.SubscribeAckTimeout,
+		acks:       make(map[string]chan error),
+
+		curves:       make(chan *types.BondingCurve, 1000),
+		curveSymbols: make(map[string]bool),
+		lastCurves:   make(map[string]types.BondingCurve),
 	}
 }
 
@@ -66,10 +138,34 @@ func (c *WSClient) Connect(ctx context.Context) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
+	if c.closed {
+		return fmt.Errorf("client closed")
+	}
 	if c.conn != nil {
 		return nil
 	}
 
+	conn, err := c.dial(ctx)
+	if err != nil {
+		return err
+	}
+	c.conn = conn
+
+	// Start keepalive for this connection; the message handler runs once
+	// for the lifetime of the client and survives reconnects
+	go c.keepAlive(ctx, conn)
+	if !c.started {
+		c.started = true
+		go c.handleMessages()
+	}
+
+	c.logger.Info("Started message handler and keepalive routines")
+	return nil
+}
+
+// dial opens a new connection with retries and replays existing
+// subscriptions. Must be called with c.mu held.
+func (c *WSClient) dial(ctx context.Context) (*websocket.Conn, error) {
 	headers := http.Header{}
 	headers.Add("X-API-Key", c.apiKey)
 	headers.Add("Authorization", fmt.Sprintf("Bearer %s", c.apiKey))
@@ -83,7 +179,7 @@ func (c *WSClient) Connect(ctx context.Context) error {
 			InsecureSkipVerify: true,
 		},
 		EnableCompression: true,
-		Proxy:            http.ProxyFromEnvironment,
+		Proxy:             http.ProxyFromEnvironment,
 	}
 
 	backoff := time.Second
@@ -98,6 +194,12 @@ func (c *WSClient) Connect(ctx context.Context) error {
 			zap.Duration("backoff", backoff))
 
 		conn, resp, err := dialer.DialContext(ctx, c.wsURL, headers)
+		if err == nil {
+			err = c.initConnection(conn)
+			if err != nil {
+				conn.Close()
+			}
+		}
 		if err != nil {
 			metrics.PumpAPIErrors.WithLabelValues("websocket_connect").Inc()
 			if resp != nil {
@@ -105,13 +207,17 @@ func (c *WSClient) Connect(ctx context.Context) error {
 					zap.Int("status", resp.StatusCode),
 					zap.String("status_text", resp.Status))
 			}
-			
+
 			if retries >= c.maxRetries {
-				return fmt.Errorf("max retries reached: %w", err)
+				return nil, fmt.Errorf("max retries reached: %w", err)
 			}
-			
+
 			retries++
-			time.Sleep(backoff)
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(backoff):
+			}
 			backoff = time.Duration(float64(backoff) * 1.5)
 			if backoff > maxBackoff {
 				backoff = maxBackoff
@@ -122,185 +228,235 @@ func (c *WSClient) Connect(ctx context.Context) error {
 		c.logger.Info("WebSocket connection established",
 			zap.String("url", c.wsURL))
 		metrics.PumpWebsocketConnections.Inc()
+		return conn, nil
+	}
+}
+
+// initConnection sends the new token subscription, replays trade and
+// bonding curve subscriptions and configures deadlines on a fresh
+// connection
+func (c *WSClient) initConnection(conn *websocket.Conn) error {
+	newTokenMsg := struct {
+		Method string `json:"method"`
+		APIKey string `json:"api_key"`
+	}{
+		Method: "subscribeNewToken",
+		APIKey: c.apiKey,
+	}
+
+	if err := conn.WriteJSON(newTokenMsg); err != nil {
+		c.logger.Error("Failed to send new token subscription",
+			zap.Error(err))
+		return fmt.Errorf("failed to send new token subscription: %w", err)
+	}
 
-		// Send new token subscription
-		newTokenMsg := struct {
-			Method string `json:"method"`
-			APIKey string `json:"api_key"`
-		}{
-			Method: "subscribeNewToken",
-			APIKey: c.apiKey,
+	for symbol := range c.symbols {
+		if err := c.writeSubscription(conn, symbol); err != nil {
+			c.logger.Error("Failed to send trade subscription",
+				zap.Error(err),
+				zap.String("symbol", symbol))
+			return fmt.Errorf("failed to resubscribe to %s: %w", symbol, err)
 		}
-		
-		if err := conn.WriteJSON(newTokenMsg); err != nil {
-			c.logger.Error("Failed to send new token subscription",
-				zap.Error(err))
-			conn.Close()
-			metrics.PumpWebsocketConnections.Dec()
-			continue
+	}
+	for symbol := range c.curveSymbols {
+		if err := c.writeTradeMethod(conn, "subscribeBondingCurve", []string{symbol}); err != nil {
+			c.logger.Error("Failed to send bonding curve subscription",
+				zap.Error(err),
+				zap.String("symbol", symbol))
+			return fmt.Errorf("failed to resubscribe to curve %s: %w", symbol, err)
 		}
+	}
+
+	conn.SetReadDeadline(time.Now().Add(c.readTimeout))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(c.pongWait))
+	})
+	return nil
+}
 
-		// Send trade subscription for each symbol
-		for symbol := range c.symbols {
-			tradeMsg := struct {
-				Method string   `json:"method"`
-				Keys   []string `json:"keys"`
-				APIKey string   `json:"api_key"`
-			}{
-				Method: "subscribeTokenTrade",
-				Keys:   []string{symbol},
-				APIKey: c.apiKey,
+// Subscribe subscribes to price updates for symbols. Each symbol is
+// accepted or rejected independently so one bad symbol doesn't fail the
+// rest; an error is returned only when the client isn't connected. With
+// a SubscribeAckTimeout, new symbols the server rejects in time are
+// reported as rejected.
+func (c *WSClient) Subscribe(symbols []string) (*SubscribeResult, error) {
+	result, pending, err := c.sendSubscriptions(symbols)
+	if err != nil || len(pending) == 0 {
+		return result, err
+	}
+
+	// Symbols without an answer by the deadline are accepted as written
+	deadline := time.After(c.ackTimeout)
+	expired := false
+	accepted := make(map[string]bool, len(pending))
+	for _, symbol := range result.Accepted {
+		accepted[symbol] = true
+	}
+	for symbol, ack := range pending {
+		var err error
+		if expired {
+			select {
+			case err = <-ack:
+			default:
 			}
-			
-			if err := conn.WriteJSON(tradeMsg); err != nil {
-				c.logger.Error("Failed to send trade subscription",
-					zap.Error(err),
-					zap.String("symbol", symbol))
-				conn.Close()
-				metrics.PumpWebsocketConnections.Dec()
-				continue
+		} else {
+			select {
+			case err = <-ack:
+			case <-deadline:
+				expired = true
 			}
 		}
+		if err != nil {
+			delete(accepted, symbol)
+			result.Rejected[symbol] = err
+		}
+	}
 
-		conn.SetReadDeadline(time.Now().Add(c.readTimeout))
-		conn.SetWriteDeadline(time.Now().Add(c.writeTimeout))
-		conn.SetPongHandler(func(string) error {
-			return conn.SetReadDeadline(time.Now().Add(c.pongWait))
-		})
+	c.mu.Lock()
+	for symbol, ack := range pending {
+		if c.acks[symbol] == ack {
+			delete(c.acks, symbol)
+		}
+	}
+	c.mu.Unlock()
 
-		c.mu.Lock()
-		c.conn = conn
-		c.mu.Unlock()
+	kept := result.Accepted[:0]
+	for _, symbol := range result.Accepted {
+		if accepted[symbol] {
+			kept = append(kept, symbol)
+		}
+	}
+	result.Accepted = kept
+	return result, nil
+}
 
-		// Start message handler and keepalive routines
-		go c.handleMessages()
-		go c.keepAlive(ctx)
+// sendSubscriptions writes a subscription for each new valid symbol and
+// returns the channels their server answers arrive on when Subscribe
+// waits for them
+func (c *WSClient) sendSubscriptions(symbols []string) (*SubscribeResult, map[string]chan error, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 
-		c.logger.Info("Started message handler and keepalive routines")
-		return nil
+	if c.conn == nil {
+		return nil, nil, fmt.Errorf("not connected")
 	}
-	for {
-		c.logger.Info("Attempting WebSocket connection",
-			zap.String("url", c.wsURL),
-			zap.Int("retry", retries),
-			zap.Int("max_retries", c.maxRetries),
-			zap.Duration("backoff", backoff),
-			zap.String("api_key_length", fmt.Sprintf("%d", len(c.apiKey))))
 
-		conn, resp, err := dialer.DialContext(ctx, c.wsURL, headers)
-		if err != nil {
-			if resp != nil {
-				c.logger.Error("WebSocket connection failed",
-					zap.Int("status", resp.StatusCode),
-					zap.String("status_text", resp.Status))
-			}
-			
-			if retries >= c.maxRetries {
-				return fmt.Errorf("max retries reached: %w", err)
-			}
-			
-			retries++
-			time.Sleep(backoff)
-			backoff = time.Duration(float64(backoff) * 1.5)
-			if backoff > maxBackoff {
-				backoff = maxBackoff
-			}
+	result := &SubscribeResult{
+		Rejected: make(map[string]error),
+	}
+	pending := make(map[string]chan error)
+
+	for _, symbol := range symbols {
+		if c.symbols[symbol] {
+			result.Accepted = append(result.Accepted, symbol)
 			continue
 		}
 
-		c.logger.Info("WebSocket connection established",
-			zap.String("url", c.wsURL))
-
-		// Send new token subscription
-		newTokenMsg := struct {
-			Method string `json:"method"`
-			APIKey string `json:"api_key"`
-		}{
-			Method: "subscribeNewToken",
-			APIKey: c.apiKey,
+		if err := validateSymbol(symbol); err != nil {
+			result.Rejected[symbol] = err
+			continue
 		}
-		
-		if err := conn.WriteJSON(newTokenMsg); err != nil {
-			c.logger.Error("Failed to send new token subscription",
+
+		if err := c.writeSubscription(c.conn, symbol); err != nil {
+			c.logger.Error("Failed to subscribe",
+				zap.String("symbol", symbol),
 				zap.Error(err))
-			conn.Close()
+			result.Rejected[symbol] = fmt.Errorf("failed to subscribe to %s: %w", symbol, err)
 			continue
 		}
 
-		// Send trade subscription for each symbol
-		for symbol := range c.symbols {
-			tradeMsg := struct {
-				Method string   `json:"method"`
-				Keys   []string `json:"keys"`
-				APIKey string   `json:"api_key"`
-			}{
-				Method: "subscribeTokenTrade",
-				Keys:   []string{symbol},
-				APIKey: c.apiKey,
-			}
-			
-			if err := conn.WriteJSON(tradeMsg); err != nil {
-				c.logger.Error("Failed to send trade subscription",
-					zap.Error(err),
-					zap.String("symbol", symbol))
-				conn.Close()
-				continue
-			}
+		c.symbols[symbol] = true
+		result.Accepted = append(result.Accepted, symbol)
+		if c.ackTimeout > 0 {
+			ack := make(chan error, 1)
+			c.acks[symbol] = ack
+			pending[symbol] = ack
 		}
+	}
 
-		conn.SetReadDeadline(time.Now().Add(c.readTimeout))
-		conn.SetWriteDeadline(time.Now().Add(c.writeTimeout))
-		conn.SetPongHandler(func(string) error {
-			return conn.SetReadDeadline(time.Now().Add(c.pongWait))
-		})
-
-		c.mu.Lock()
-		c.conn = conn
-		c.mu.Unlock()
+	return result, pending, nil
+}
 
-		// Start message handler and keepalive routines
-		go c.handleMessages()
-		go c.keepAlive(ctx)
+// resolveAck passes the server's answer to a subscription of symbol to
+// the Subscribe call waiting for it, if any
+func (c *WSClient) resolveAck(symbol string, err error) {
+	c.mu.Lock()
+	ack, waiting := c.acks[symbol]
+	delete(c.acks, symbol)
+	c.mu.Unlock()
 
-		c.logger.Info("Started message handler and keepalive routines")
-		return nil
+	if waiting {
+		ack <- err
 	}
 }
 
-// Subscribe subscribes to price updates for symbols
-func (c *WSClient) Subscribe(symbols []string) error {
+// Unsubscribe stops price updates for symbols on the live connection.
+// Symbols are forgotten locally even if the write fails so they are not
+// replayed on reconnect.
+func (c *WSClient) Unsubscribe(symbols []string) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	if c.conn == nil {
-		return fmt.Errorf("not connected")
-	}
-
+	var remove []string
 	for _, symbol := range symbols {
 		if c.symbols[symbol] {
-			continue
+			delete(c.symbols, symbol)
+			remove = append(remove, symbol)
 		}
+	}
 
-		msg := struct {
-			Method string   `json:"method"`
-			Keys   []string `json:"keys"`
-			APIKey string   `json:"api_key"`
-		}{
-			Method: "subscribeTokenTrade",
-			Keys:   []string{symbol},
-			APIKey: c.apiKey,
-		}
+	if len(remove) == 0 || c.conn == nil {
+		return nil
+	}
 
-		if err := c.conn.WriteJSON(msg); err != nil {
-			c.logger.Error("Failed to subscribe",
-				zap.String("symbol", symbol),
-				zap.Error(err))
-			return fmt.Errorf("failed to subscribe to %s: %w", symbol, err)
-		}
+	if err := c.writeTradeMethod(c.conn, "unsubscribeTokenTrade", remove); err != nil {
+		return fmt.Errorf("failed to unsubscribe: %w", err)
+	}
+	return nil
+}
 
-		c.symbols[symbol] = true
+// Symbols returns the currently subscribed symbols
+func (c *WSClient) Symbols() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	symbols := make([]string, 0, len(c.symbols))
+	for symbol := range c.symbols {
+		symbols = append(symbols, symbol)
+	}
+	return symbols
+}
+
+func (c *WSClient) writeSubscription(conn *websocket.Conn, symbol string) error {
+	return c.writeTradeMethod(conn, "subscribeTokenTrade", []string{symbol})
+}
+
+func (c *WSClient) writeTradeMethod(conn *websocket.Conn, method string, keys []string) error {
+	msg := struct {
+		Method string   `json:"method"`
+		Keys   []string `json:"keys"`
+		APIKey string   `json:"api_key"`
+	}{
+		Method: method,
+		Keys:   keys,
+		APIKey: c.apiKey,
 	}
 
+	conn.SetWriteDeadline(time.Now().Add(c.writeTimeout))
+	return conn.WriteJSON(msg)
+}
+
+// validateSymbol rejects symbols the API can never resolve
+func validateSymbol(symbol string) error {
+	if symbol == "" {
+		return fmt.Errorf("empty symbol")
+	}
+	if len(symbol) > 64 {
+		return fmt.Errorf("symbol too long: %d characters", len(symbol))
+	}
+	if strings.ContainsAny(symbol, " \t\r\n") {
+		return fmt.Errorf("symbol contains whitespace: %q", symbol)
+	}
 	return nil
 }
 
@@ -314,18 +470,24 @@ func (c *WSClient) Close() error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	if c.conn != nil {
+	if !c.closed {
+		c.closed = true
 		close(c.done)
-		if err := c.conn.Close(); err != nil {
+	}
+
+	if c.conn != nil {
+		err := c.conn.Close()
+		c.conn = nil
+		metrics.PumpWebsocketConnections.Dec()
+		if err != nil {
 			return fmt.Errorf("failed to close WebSocket: %w", err)
 		}
-		c.conn = nil
 	}
 
 	return nil
 }
 
-func (c *WSClient) keepAlive(ctx context.Context) {
+func (c *WSClient) keepAlive(ctx context.Context, conn *websocket.Conn) {
 	ticker := time.NewTicker(c.pingPeriod)
 	defer ticker.Stop()
 
@@ -333,27 +495,41 @@ func (c *WSClient) keepAlive(ctx context.Context) {
 		select {
 		case <-ctx.Done():
 			return
+		case <-c.done:
+			return
 		case <-ticker.C:
-			c.mu.Lock()
-			if c.conn == nil {
-				c.mu.Unlock()
+			c.mu.RLock()
+			current := c.conn
+			c.mu.RUnlock()
+			if current != conn {
 				return
 			}
 
-			if err := c.conn.WriteControl(websocket.PingMessage, []byte{}, time.Now().Add(c.writeTimeout)); err != nil {
+			if err := conn.WriteControl(websocket.PingMessage, []byte{}, time.Now().Add(c.writeTimeout)); err != nil {
 				c.logger.Error("failed to write ping message", zap.Error(err))
-				c.conn.Close()
-				c.conn = nil
-				c.mu.Unlock()
+				c.dropConnection(conn)
 				return
 			}
-			c.mu.Unlock()
 		}
 	}
 }
 
+// dropConnection closes conn if it is still the active connection so the
+// message handler reconnects
+func (c *WSClient) dropConnection(conn *websocket.Conn) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn == conn {
+		c.conn.Close()
+		c.conn = nil
+		metrics.PumpWebsocketConnections.Dec()
+	}
+}
+
 func (c *WSClient) handleMessages() {
 	defer close(c.updates)
+	defer close(c.curves)
 
 	reconnectTicker := time.NewTicker(5 * time.Second)
 	defer reconnectTicker.Stop()
@@ -362,145 +538,156 @@ func (c *WSClient) handleMessages() {
 		select {
 		case <-c.done:
 			return
-		case <-reconnectTicker.C:
-			c.mu.Lock()
-			if c.conn == nil {
-				ctx := context.Background()
-				if err := c.Connect(ctx); err != nil {
-					c.logger.Error("Failed to reconnect", zap.Error(err))
-					c.mu.Unlock()
-					continue
-				}
-				
-				// Resubscribe to existing symbols
-				symbols := make([]string, 0, len(c.symbols))
-				for symbol := range c.symbols {
-					symbols = append(symbols, symbol)
-				}
-				c.mu.Unlock()
-				
-				if err := c.Subscribe(symbols); err != nil {
-					c.logger.Error("Failed to resubscribe", zap.Error(err))
-				}
-				continue
-			}
-			c.mu.Unlock()
 		default:
-			c.conn.SetReadDeadline(time.Now().Add(c.readTimeout))
-			_, msg, err := c.conn.ReadMessage()
-			if err != nil {
-				if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
-					c.logger.Error("WebSocket read error", zap.Error(err))
-					metrics.PumpAPIErrors.WithLabelValues("websocket_read").Inc()
-				}
-				c.mu.Lock()
-				if c.conn != nil {
-					c.conn.Close()
-					c.conn = nil
-					metrics.PumpWebsocketConnections.Dec()
-				}
-				c.mu.Unlock()
-				continue
-			}
+		}
 
-			var data struct {
-				Method string `json:"method"`
-				Data   struct {
-					Address    string  `json:"address"`
-					Price      float64 `json:"price"`
-					Volume     float64 `json:"volume"`
-					Time       int64   `json:"time"`
-					TxHash     string  `json:"txHash"`
-					BlockTime  int64   `json:"blockTime"`
-					Error      string  `json:"error,omitempty"`
-					TokenName  string  `json:"tokenName,omitempty"`
-					MarketCap  float64 `json:"marketCap,omitempty"`
-					TotalSupply float64 `json:"totalSupply,omitempty"`
-				} `json:"data"`
-				Error string `json:"error,omitempty"`
-				Status string `json:"status,omitempty"`
+		c.mu.RLock()
+		conn := c.conn
+		c.mu.RUnlock()
+
+		if conn == nil {
+			select {
+			case <-c.done:
+				return
+			case <-reconnectTicker.C:
+				// Connect replays existing subscriptions on the new connection
+				if err := c.Connect(context.Background()); err != nil {
+					c.logger.Error("Failed to reconnect", zap.Error(err))
+				}
 			}
+			continue
+		}
 
-			// Update metrics and send updates
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
 			select {
-			case c.updates <- update:
-				c.logger.Info("Trade update sent",
-					zap.String("token", data.Data.TokenName),
-					zap.Float64("price", data.Data.Price),
-					zap.Float64("market_cap", data.Data.MarketCap))
-				
-				metrics.PumpTokenPrice.WithLabelValues(data.Data.Address).Set(data.Data.Price)
-				metrics.PumpTokenVolume.WithLabelValues(data.Data.Address).Set(data.Data.Volume)
+			case <-c.done:
+				return
 			default:
-				c.logger.Warn("Update channel full",
-					zap.String("token", data.Data.TokenName),
-					zap.Float64("price", data.Data.Price))
-			c.logger.Info("Received WebSocket message",
-				zap.String("raw_message", string(msg)),
-				zap.String("connection_status", "active"))
-
-			if err := json.Unmarshal(msg, &data); err != nil {
-				c.logger.Error("Failed to parse WebSocket message", 
-					zap.Error(err),
-					zap.String("raw_message", string(msg)))
-				continue
 			}
-
-			if data.Error != "" || (data.Status != "" && data.Status != "success") {
-				c.logger.Error("Received error in WebSocket message",
-					zap.String("error", data.Error),
-					zap.String("status", data.Status))
-				if data.Error == "unauthorized" || data.Error == "invalid_token" {
-					c.mu.Lock()
-					if c.conn != nil {
-						c.conn.Close()
-						c.conn = nil
-					}
-					c.mu.Unlock()
-				}
-				continue
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) {
+				c.logger.Error("WebSocket read error", zap.Error(err))
+				metrics.PumpAPIErrors.WithLabelValues("websocket_read").Inc()
 			}
+			c.dropConnection(conn)
+			continue
+		}
+		conn.SetReadDeadline(time.Now().Add(c.readTimeout))
 
-			switch data.Method {
-			case "trade":
-				c.logger.Debug("Received trade event",
-					zap.String("address", data.Data.Address),
-					zap.String("token_name", data.Data.TokenName),
-					zap.Float64("price", data.Data.Price),
-					zap.Float64("volume", data.Data.Volume),
-					zap.Float64("market_cap", data.Data.MarketCap),
-					zap.Float64("total_supply", data.Data.TotalSupply),
-					zap.String("txHash", data.Data.TxHash))
-
-				update := &types.PriceUpdate{
-					Symbol:      data.Data.Address,
-					TokenName:   data.Data.TokenName,
-					Price:      data.Data.Price,
-					Volume:     data.Data.Volume,
-					MarketCap:  data.Data.MarketCap,
-					TotalSupply: data.Data.TotalSupply,
-					Timestamp:  time.Unix(data.Data.BlockTime, 0),
-				}
+		c.handleMessage(conn, msg)
+	}
+}
 
-				select {
-				case c.updates <- update:
-					c.logger.Info("Trade update sent",
-						zap.String("token", data.Data.TokenName),
-						zap.Float64("price", data.Data.Price),
-						zap.Float64("market_cap", data.Data.MarketCap))
-				default:
-					c.logger.Warn("Update channel full, dropping trade update",
-						zap.String("token", data.Data.TokenName),
-						zap.Float64("price", data.Data.Price),
-						zap.Float64("market_cap", data.Data.MarketCap))
-				}
-			case "subscribed":
-				c.logger.Info("Successfully subscribed to updates",
-					zap.String("method", data.Method))
-			case "error":
-				c.logger.Error("Subscription error",
-					zap.String("error", data.Data.Error))
-			}
+func (c *WSClient) handleMessage(conn *websocket.Conn, msg []byte) {
+	c.logger.Debug("Received WebSocket message",
+		zap.String("raw_message", string(msg)),
+		zap.String("connection_status", "active"))
+
+	var data struct {
+		Method string `json:"method"`
+		Data   struct {
+			Address     string  `json:"address"`
+			Price       float64 `json:"price"`
+			Volume      float64 `json:"volume"`
+			Time        int64   `json:"time"`
+			TxHash      string  `json:"txHash"`
+			BlockTime   int64   `json:"blockTime"`
+			Error       string  `json:"error,omitempty"`
+			TokenName   string  `json:"tokenName,omitempty"`
+			MarketCap   float64 `json:"marketCap,omitempty"`
+			TotalSupply float64 `json:"totalSupply,omitempty"`
+		} `json:"data"`
+		Error  string `json:"error,omitempty"`
+		Status string `json:"status,omitempty"`
+	}
+
+	if err := json.Unmarshal(msg, &data); err != nil {
+		c.logger.Error("Failed to parse WebSocket message",
+			zap.Error(err),
+			zap.String("raw_message", string(msg)))
+		return
+	}
+
+	if data.Error != "" || (data.Status != "" && data.Status != "success") {
+		c.logger.Error("Received error in WebSocket message",
+			zap.String("error", data.Error),
+			zap.String("status", data.Status))
+		if data.Error == "unauthorized" || data.Error == "invalid_token" {
+			c.dropConnection(conn)
+		}
+		return
+	}
+
+	switch data.Method {
+	case "trade":
+		c.logger.Debug("Received trade event",
+			zap.String("address", data.Data.Address),
+			zap.String("token_name", data.Data.TokenName),
+			zap.Float64("price", data.Data.Price),
+			zap.Float64("volume", data.Data.Volume),
+			zap.Float64("market_cap", data.Data.MarketCap),
+			zap.Float64("total_supply", data.Data.TotalSupply),
+			zap.String("txHash", data.Data.TxHash))
+
+		// Trades can still arrive for a symbol between Unsubscribe and the
+		// server processing it
+		c.mu.RLock()
+		subscribed := c.symbols[data.Data.Address]
+		c.mu.RUnlock()
+		if !subscribed {
+			return
+		}
+
+		timestamp := time.Unix(data.Data.BlockTime, 0)
+		if data.Data.BlockTime == 0 {
+			timestamp = time.Now()
+		}
+
+		update := &types.PriceUpdate{
+			Symbol:      data.Data.Address,
+			TokenName:   data.Data.TokenName,
+			Price:       data.Data.Price,
+			Volume:      data.Data.Volume,
+			MarketCap:   data.Data.MarketCap,
+			TotalSupply: data.Data.TotalSupply,
+			Timestamp:   timestamp,
+		}
+		if c.suppressDuplicate(update) {
+			return
+		}
+
+		select {
+		case c.updates <- update:
+			metrics.PumpTokenPrice.WithLabelValues(data.Data.Address).Set(data.Data.Price)
+			metrics.PumpTokenVolume.WithLabelValues(data.Data.Address).Set(data.Data.Volume)
+		default:
+			c.logger.Warn("Update channel full, dropping trade update",
+				zap.String("token", data.Data.TokenName),
+				zap.Float64("price", data.Data.Price),
+				zap.Float64("market_cap", data.Data.MarketCap))
+		}
+	case "bondingCurve":
+		c.handleCurve(msg)
+	case "subscribed":
+		c.logger.Info("Successfully subscribed to updates",
+			zap.String("method", data.Method),
+			zap.String("symbol", data.Data.Address))
+		if data.Data.Address != "" {
+			c.resolveAck(data.Data.Address, nil)
+		}
+	case "error":
+		c.logger.Error("Subscription error",
+			zap.String("symbol", data.Data.Address),
+			zap.String("error", data.Data.Error))
+
+		// A symbol rejected by the server is dropped so it isn't replayed
+		// on reconnect; other subscriptions keep streaming
+		if data.Data.Address != "" {
+			c.mu.Lock()
+			delete(c.symbols, data.Data.Address)
+			c.mu.Unlock()
+			c.resolveAck(data.Data.Address, fmt.Errorf("server rejected subscription to %s: %s",
+				data.Data.Address, data.Data.Error))
 		}
 	}
 }
@@ -0,0 +1,231 @@
+package pump
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// tradeServer answers every subscribeTokenTrade request with a trade
+// message for each key subscribed so far on the connection. It ignores
+// unsubscribes so tests can check that the client filters stale trades.
+func tradeServer(t *testing.T) (*httptest.Server, string) {
+	t.Helper()
+	upgrader := websocket.Upgrader{
+		CheckOrigin: func(r *http.Request) bool { return true },
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		var keys []string
+		for {
+			var msg struct {
+				Method string   `json:"method"`
+				Keys   []string `json:"keys"`
+			}
+			if err := conn.ReadJSON(&msg); err != nil {
+				return
+			}
+			if msg.Method != "subscribeTokenTrade" {
+				continue
+			}
+			keys = append(keys, msg.Keys...)
+			for _, key := range keys {
+				trade := map[string]interface{}{
+					"method": "trade",
+					"data": map[string]interface{}{
+						"address": key,
+						"price":   1.5,
+						"volume":  10.0,
+					},
+				}
+				if err := conn.WriteJSON(trade); err != nil {
+					return
+				}
+			}
+		}
+	}))
+
+	return server, "ws" + server.URL[4:]
+}
+
+func TestWSClient_PartialSubscribeFailure(t *testing.T) {
+	server, wsURL := tradeServer(t)
+	defer server.Close()
+
+	client := NewWSClient(wsURL, zap.NewNop(), WSConfig{})
+	defer client.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, client.Connect(ctx))
+
+	result, err := client.Subscribe([]string{"TOKEN1", "BAD SYMBOL", "TOKEN2"})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"TOKEN1", "TOKEN2"}, result.Accepted)
+	require.Len(t, result.Rejected, 1)
+	assert.Error(t, result.Rejected["BAD SYMBOL"])
+
+	received := make(map[string]bool)
+	timeout := time.After(2 * time.Second)
+	for len(received) < 2 {
+		select {
+		case update := <-client.GetUpdates():
+			received[update.Symbol] = true
+		case <-timeout:
+			t.Fatalf("expected updates for accepted symbols, got %v", received)
+		}
+	}
+	assert.True(t, received["TOKEN1"])
+	assert.True(t, received["TOKEN2"])
+}
+
+// ackServer answers each subscribeTokenTrade key with a subscribed ack,
+// an error for keys starting with "DEAD", and nothing for keys starting
+// with "QUIET"
+func ackServer(t *testing.T) (*httptest.Server, string) {
+	t.Helper()
+	upgrader := websocket.Upgrader{
+		CheckOrigin: func(r *http.Request) bool { return true },
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		for {
+			var msg struct {
+				Method string   `json:"method"`
+				Keys   []string `json:"keys"`
+			}
+			if err := conn.ReadJSON(&msg); err != nil {
+				return
+			}
+			if msg.Method != "subscribeTokenTrade" {
+				continue
+			}
+			for _, key := range msg.Keys {
+				reply := map[string]interface{}{
+					"method": "subscribed",
+					"data":   map[string]interface{}{"address": key},
+				}
+				switch {
+				case strings.HasPrefix(key, "QUIET"):
+					continue
+				case strings.HasPrefix(key, "DEAD"):
+					reply = map[string]interface{}{
+						"method": "error",
+						"data":   map[string]interface{}{"address": key, "error": "unknown token"},
+					}
+				}
+				if err := conn.WriteJSON(reply); err != nil {
+					return
+				}
+			}
+		}
+	}))
+
+	return server, "ws" + server.URL[4:]
+}
+
+func TestWSClient_SubscribeServerRejection(t *testing.T) {
+	server, wsURL := ackServer(t)
+	defer server.Close()
+
+	client := NewWSClient(wsURL, zap.NewNop(), WSConfig{SubscribeAckTimeout: 200 * time.Millisecond})
+	defer client.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	require.NoError(t, client.Connect(ctx))
+
+	result, err := client.Subscribe([]string{"TOKEN1", "DEAD1", "QUIET1", "BAD SYMBOL"})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"TOKEN1", "QUIET1"}, result.Accepted, "unanswered symbols are accepted")
+	require.Len(t, result.Rejected, 2)
+	assert.ErrorContains(t, result.Rejected["DEAD1"], "unknown token")
+	assert.Error(t, result.Rejected["BAD SYMBOL"])
+	assert.ElementsMatch(t, []string{"TOKEN1", "QUIET1"}, client.Symbols())
+}
+
+func TestProvider_AddRemoveSymbols(t *testing.T) {
+	server, wsURL := tradeServer(t)
+	defer server.Close()
+
+	provider := NewProvider(Config{BaseURL: server.URL, WebSocketURL: wsURL, TimeoutSec: 1}, zap.NewNop())
+	defer provider.Close()
+
+	updates, err := provider.SubscribePrices(context.Background(), []string{"TOKEN1"})
+	require.NoError(t, err)
+
+	waitFor := func(symbol string) {
+		t.Helper()
+		timeout := time.After(2 * time.Second)
+		for {
+			select {
+			case update := <-updates:
+				if update.Symbol == symbol {
+					return
+				}
+			case <-timeout:
+				t.Fatalf("no update for %s", symbol)
+			}
+		}
+	}
+	waitFor("TOKEN1")
+
+	result, err := provider.AddSymbols([]string{"TOKEN2"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"TOKEN2"}, result.Accepted)
+	waitFor("TOKEN2")
+
+	require.NoError(t, provider.RemoveSymbols([]string{"TOKEN1"}))
+	assert.ElementsMatch(t, []string{"TOKEN2"}, provider.wsClient.Symbols())
+
+	// The server keeps sending TOKEN1 trades; none should reach the channel
+	_, err = provider.AddSymbols([]string{"TOKEN3"})
+	require.NoError(t, err)
+	timeout := time.After(2 * time.Second)
+	for seen := map[string]bool{}; !seen["TOKEN3"]; {
+		select {
+		case update := <-updates:
+			assert.NotEqual(t, "TOKEN1", update.Symbol)
+			seen[update.Symbol] = true
+		case <-timeout:
+			t.Fatal("no update for TOKEN3")
+		}
+	}
+}
+
+func TestWSClient_SubscribeNotConnected(t *testing.T) {
+	client := NewWSClient("ws://127.0.0.1:0", zap.NewNop(), WSConfig{})
+	_, err := client.Subscribe([]string{"TOKEN1"})
+	assert.Error(t, err)
+}
+
+func TestProvider_SubscribePricesAllRejected(t *testing.T) {
+	server, wsURL := tradeServer(t)
+	defer server.Close()
+
+	provider := NewProvider(Config{BaseURL: server.URL, WebSocketURL: wsURL, TimeoutSec: 1}, zap.NewNop())
+	defer provider.Close()
+
+	_, err := provider.SubscribePrices(context.Background(), []string{"", "BAD SYMBOL"})
+	assert.Error(t, err)
+}
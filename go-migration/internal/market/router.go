@@ -0,0 +1,131 @@
+package market
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+// Quote is a venue's executable price for an order
+type Quote struct {
+	Venue       string  `json:"venue"`
+	Price       float64 `json:"price"`        // average execution price including impact
+	FeeRate     float64 `json:"fee_rate"`     // fee as a fraction of notional
+	PriceImpact float64 `json:"price_impact"` // fractional price move caused by the order
+	Slippage    float64 `json:"slippage"`     // expected fractional slippage
+	Liquidity   float64 `json:"liquidity"`    // available depth, used to break ties
+}
+
+// NetPrice returns the quote price after fees for side
+func (q *Quote) NetPrice(side types.OrderSide) float64 {
+	if side == types.OrderSideSell {
+		return q.Price * (1 - q.FeeRate)
+	}
+	return q.Price * (1 + q.FeeRate)
+}
+
+// QuoteSource provides executable quotes from a single venue
+type QuoteSource interface {
+	Name() string
+	Quote(ctx context.Context, symbol string, side types.OrderSide, qty float64) (*Quote, error)
+}
+
+// Route is the venue selected for an order
+type Route struct {
+	Venue    string  `json:"venue"`
+	Quote    *Quote  `json:"quote"`
+	NetPrice float64 `json:"net_price"`
+}
+
+// Router selects the venue with the best net execution price
+type Router struct {
+	logger  *zap.Logger
+	sources []QuoteSource
+}
+
+// NewRouter creates a router over the given quote sources
+func NewRouter(logger *zap.Logger, sources ...QuoteSource) *Router {
+	return &Router{
+		logger:  logger,
+		sources: sources,
+	}
+}
+
+// Route queries every venue concurrently and returns the one with the best
+// net price after fees, breaking ties by liquidity. It also returns a copy
+// of order carrying the chosen venue, price impact and slippage for risk
+// checks. Venues that fail to quote are skipped.
+func (r *Router) Route(ctx context.Context, order *types.Order) (*Route, *types.Order, error) {
+	if len(r.sources) == 0 {
+		return nil, nil, fmt.Errorf("no quote sources configured")
+	}
+
+	quotes := make([]*Quote, len(r.sources))
+	var wg sync.WaitGroup
+	for i, source := range r.sources {
+		wg.Add(1)
+		go func(i int, source QuoteSource) {
+			defer wg.Done()
+			quote, err := source.Quote(ctx, order.Symbol, order.Side, order.Quantity)
+			if err != nil {
+				r.logger.Warn("Failed to get quote",
+					zap.String("venue", source.Name()),
+					zap.String("symbol", order.Symbol),
+					zap.Error(err))
+				return
+			}
+			if quote.Venue == "" {
+				quote.Venue = source.Name()
+			}
+			quotes[i] = quote
+		}(i, source)
+	}
+	wg.Wait()
+
+	var best *Quote
+	for _, quote := range quotes {
+		if quote == nil || quote.Price <= 0 {
+			continue
+		}
+		if best == nil || betterQuote(order.Side, quote, best) {
+			best = quote
+		}
+	}
+	if best == nil {
+		return nil, nil, fmt.Errorf("no venue could quote %s", order.Symbol)
+	}
+
+	enriched := *order
+	enriched.Venue = best.Venue
+	enriched.PriceImpact = best.PriceImpact
+	enriched.Slippage = best.Slippage
+
+	route := &Route{
+		Venue:    best.Venue,
+		Quote:    best,
+		NetPrice: best.NetPrice(order.Side),
+	}
+
+	r.logger.Debug("Routed order",
+		zap.String("symbol", order.Symbol),
+		zap.String("venue", route.Venue),
+		zap.Float64("net_price", route.NetPrice))
+
+	return route, &enriched, nil
+}
+
+// betterQuote reports whether a beats b for side
+func betterQuote(side types.OrderSide, a, b *Quote) bool {
+	na, nb := a.NetPrice(side), b.NetPrice(side)
+	if na == nb {
+		return a.Liquidity > b.Liquidity
+	}
+	if side == types.OrderSideSell {
+		return na > nb
+	}
+	return na < nb
+}
@@ -0,0 +1,80 @@
+package market
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+type staticVenue struct {
+	name  string
+	quote *Quote
+	err   error
+}
+
+func (v *staticVenue) Name() string { return v.name }
+
+func (v *staticVenue) Quote(ctx context.Context, symbol string, side types.OrderSide, qty float64) (*Quote, error) {
+	if v.err != nil {
+		return nil, v.err
+	}
+	q := *v.quote
+	return &q, nil
+}
+
+func TestRouter_SelectsBestNetPrice(t *testing.T) {
+	venues := []QuoteSource{
+		// Cheapest headline price but the fee makes it the worst
+		&staticVenue{name: "raydium", quote: &Quote{Price: 99, FeeRate: 0.03, PriceImpact: 0.01, Liquidity: 1000}},
+		&staticVenue{name: "orca", quote: &Quote{Price: 100, FeeRate: 0.001, PriceImpact: 0.02, Slippage: 0.004, Liquidity: 500}},
+		&staticVenue{name: "jupiter", quote: &Quote{Price: 101, FeeRate: 0, PriceImpact: 0.005, Liquidity: 2000}},
+	}
+	router := NewRouter(zap.NewNop(), venues...)
+
+	order := &types.Order{ID: "o1", Symbol: "TEST/SOL", Side: types.OrderSideBuy, Quantity: 10}
+	route, enriched, err := router.Route(context.Background(), order)
+	require.NoError(t, err)
+	assert.Equal(t, "orca", route.Venue)
+	assert.InDelta(t, 100.1, route.NetPrice, 1e-9)
+	assert.Equal(t, "orca", enriched.Venue)
+	assert.Equal(t, 0.02, enriched.PriceImpact)
+	assert.Equal(t, 0.004, enriched.Slippage)
+	assert.Empty(t, order.Venue, "input order must not be modified")
+
+	t.Run("Sell", func(t *testing.T) {
+		sell := &types.Order{Symbol: "TEST/SOL", Side: types.OrderSideSell, Quantity: 10}
+		route, _, err := router.Route(context.Background(), sell)
+		require.NoError(t, err)
+		assert.Equal(t, "jupiter", route.Venue)
+	})
+
+	t.Run("TieBrokenByLiquidity", func(t *testing.T) {
+		router := NewRouter(zap.NewNop(),
+			&staticVenue{name: "shallow", quote: &Quote{Price: 100, Liquidity: 10}},
+			&staticVenue{name: "deep", quote: &Quote{Price: 100, Liquidity: 1000}},
+		)
+		route, _, err := router.Route(context.Background(), order)
+		require.NoError(t, err)
+		assert.Equal(t, "deep", route.Venue)
+	})
+
+	t.Run("FailingVenuesSkipped", func(t *testing.T) {
+		router := NewRouter(zap.NewNop(),
+			&staticVenue{name: "down", err: errors.New("timeout")},
+			&staticVenue{name: "up", quote: &Quote{Price: 100}},
+		)
+		route, _, err := router.Route(context.Background(), order)
+		require.NoError(t, err)
+		assert.Equal(t, "up", route.Venue)
+
+		_, _, err = NewRouter(zap.NewNop(), &staticVenue{name: "down", err: errors.New("timeout")}).
+			Route(context.Background(), order)
+		assert.Error(t, err)
+	})
+}
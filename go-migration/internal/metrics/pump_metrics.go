@@ -33,10 +33,20 @@ var (
 		Help: "Number of active WebSocket connections",
 	})
 
+	PumpNewTokensDropped = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "pump_new_tokens_dropped_total",
+		Help: "Total number of new tokens dropped because the consumer fell behind",
+	})
+
 	PumpAPIErrors = promauto.NewCounterVec(prometheus.CounterOpts{
 		Name: "pump_api_errors_total",
 		Help: "Total number of API errors",
 	}, []string{"operation"})
+
+	PumpTokenMonitorRestarts = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "pump_token_monitor_restarts_total",
+		Help: "Total number of times the token monitor backed off and restarted polling after a failure",
+	})
 )
 
 type PumpMetrics struct {
@@ -0,0 +1,23 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	RiskLimitWarnings = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "risk_limit_warnings_total",
+		Help: "Total number of checks that passed within the warn band of a limit",
+	}, []string{"limit"})
+
+	RiskStalePositions = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "risk_stale_positions_total",
+		Help: "Total number of alerts for positions held past the max holding period",
+	})
+
+	RiskKillSwitch = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "risk_kill_switch_engaged",
+		Help: "Whether the global kill switch is blocking opening orders (1) or not (0)",
+	})
+)
@@ -0,0 +1,70 @@
+package risk
+
+import (
+	"context"
+	"errors"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+// Adjustment describes one change Adjust made to an order
+type Adjustment struct {
+	Field string  `json:"field"`
+	Limit string  `json:"limit"`
+	From  float64 `json:"from"`
+	To    float64 `json:"to"`
+}
+
+// Adjust returns a copy of order changed to pass the order checks, and the
+// changes made. Only order size and slippage are adjusted, down to their
+// limits; any other failure, or one the adjustments can't fix, is
+// returned as the error. The checks run on a clone of the manager, so
+// Adjust doesn't start a cooldown or record violations.
+func (m *Manager) Adjust(ctx context.Context, order *types.Order) (*types.Order, []Adjustment, error) {
+	dryRun := m.Clone()
+	adjusted := *order
+	var adjustments []Adjustment
+
+	// Each limit is adjusted at most once, so the loop ends
+	for {
+		err := dryRun.CheckOrderRisk(ctx, &adjusted)
+		if err == nil {
+			return &adjusted, adjustments, nil
+		}
+
+		var limitErr *LimitError
+		if !errors.As(err, &limitErr) || adjustedLimit(adjustments, limitErr.Limit) {
+			return nil, nil, err
+		}
+
+		var field *float64
+		var name string
+		switch limitErr.Limit {
+		case LimitMaxPositionSize:
+			field, name = &adjusted.Quantity, "quantity"
+		case LimitMaxSlippage:
+			field, name = &adjusted.Slippage, "slippage"
+		}
+		if field == nil || limitErr.Threshold <= 0 {
+			return nil, nil, err
+		}
+
+		adjustments = append(adjustments, Adjustment{
+			Field: name,
+			Limit: limitErr.Limit,
+			From:  *field,
+			To:    limitErr.Threshold,
+		})
+		*field = limitErr.Threshold
+	}
+}
+
+// adjustedLimit reports whether adjustments already includes limit
+func adjustedLimit(adjustments []Adjustment, limit string) bool {
+	for _, adj := range adjustments {
+		if adj.Limit == limit {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,60 @@
+package risk
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+func TestManager_Adjust(t *testing.T) {
+	ctx := context.Background()
+	limits := testLimits()
+	limits.MaxSlippage = 0.01
+	limits.MinOrderInterval = time.Minute
+	manager := NewManager(limits, zap.NewNop())
+
+	order := &types.Order{ID: "o1", UserID: "user1", Symbol: "TEST/SOL", Side: types.OrderSideBuy,
+		Type: types.OrderTypeMarket, Quantity: 1500, Slippage: 0.05}
+	adjusted, adjustments, err := manager.Adjust(ctx, order)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1000.0, adjusted.Quantity, "downsized to the max allowed")
+	assert.Equal(t, 0.01, adjusted.Slippage)
+	assert.Equal(t, []Adjustment{
+		{Field: "quantity", Limit: LimitMaxPositionSize, From: 1500, To: 1000},
+		{Field: "slippage", Limit: LimitMaxSlippage, From: 0.05, To: 0.01},
+	}, adjustments)
+	assert.Equal(t, 1500.0, order.Quantity, "the original order is untouched")
+
+	// The dry run neither starts the cooldown nor records violations
+	assert.NoError(t, manager.CheckOrderRisk(ctx, adjusted))
+	assert.Empty(t, manager.RecentViolations())
+
+	t.Run("CompliantOrder", func(t *testing.T) {
+		fresh := NewManager(limits, zap.NewNop())
+		small := &types.Order{ID: "o2", UserID: "user1", Symbol: "TEST/SOL", Quantity: 10}
+		adjusted, adjustments, err := fresh.Adjust(ctx, small)
+		require.NoError(t, err)
+		assert.Empty(t, adjustments)
+		assert.Equal(t, *small, *adjusted)
+	})
+
+	t.Run("NotAdjustable", func(t *testing.T) {
+		// A cooldown from an accepted order can't be adjusted away
+		manager.RecordOrder(order.UserID, order.Symbol)
+		_, adjustments, err := manager.Adjust(ctx, order)
+		assert.ErrorIs(t, err, ErrOrderCooldown)
+		assert.Nil(t, adjustments)
+
+		fresh := NewManager(limits, zap.NewNop())
+		input := &types.Order{ID: "o3", UserID: "user1", Symbol: "TEST/SOL", Quantity: -1}
+		_, _, err = fresh.Adjust(ctx, input)
+		assert.ErrorIs(t, err, ErrInvalidInput)
+	})
+}
@@ -0,0 +1,61 @@
+package risk
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+// BalanceSource supplies an account's cash balance: deposits plus realized
+// PnL, before the unrealized PnL of its open positions
+type BalanceSource interface {
+	Balance(userID string) (float64, error)
+}
+
+// BalanceSourceFunc adapts a function to BalanceSource
+type BalanceSourceFunc func(userID string) (float64, error)
+
+func (f BalanceSourceFunc) Balance(userID string) (float64, error) {
+	return f(userID)
+}
+
+// SetBalanceSource sets the source account equity is measured from. With
+// one set, equity is the balance plus unrealized PnL and the MaxLeverage
+// check is enforced; without one, the leverage check is skipped and
+// metrics value positions as if they were the account's only equity.
+func (m *Manager) SetBalanceSource(source BalanceSource) {
+	m.balances = source
+}
+
+// accountEquity returns the balance of the positions' account plus their
+// unrealized PnL, and false when no balance source is set
+func (m *Manager) accountEquity(positions []*types.Position) (float64, bool, error) {
+	if m.balances == nil {
+		return 0, false, nil
+	}
+
+	userID := ""
+	equity := 0.0
+	for _, pos := range positions {
+		if userID == "" {
+			userID = pos.UserID
+		}
+		equity += pos.UnrealizedPnL
+	}
+	balance, err := m.balances.Balance(userID)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to get balance for user %s: %w", userID, err)
+	}
+	return balance + equity, true, nil
+}
+
+// marketExposure returns the gross value of positions at their mark
+// price, which their unrealized PnL moves away from their cost
+func marketExposure(positions []*types.Position) float64 {
+	exposure := 0.0
+	for _, pos := range positions {
+		exposure += math.Abs(pos.Quantity*pos.AvgPrice + pos.UnrealizedPnL)
+	}
+	return exposure
+}
@@ -0,0 +1,70 @@
+package risk
+
+import (
+	"math"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+// UnknownBetaPolicy selects how PortfolioBeta treats positions whose beta
+// isn't known
+type UnknownBetaPolicy string
+
+const (
+	// UnknownBetaMarket assumes the position moves with the benchmark,
+	// i.e. a beta of 1
+	UnknownBetaMarket UnknownBetaPolicy = "market"
+	// UnknownBetaExclude leaves the position out of the portfolio beta
+	// entirely
+	UnknownBetaExclude UnknownBetaPolicy = "exclude"
+)
+
+// SetUnknownBetaPolicy sets how PortfolioBeta treats positions without a
+// known beta. The default is UnknownBetaMarket.
+func (m *Manager) SetUnknownBetaPolicy(policy UnknownBetaPolicy) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.betaPolicy = policy
+}
+
+// PortfolioBeta returns the notional-weighted beta of positions to the
+// benchmark betas are measured against: each position's beta weighted by
+// its signed notional over the gross notional, so shorts offset longs.
+// Multiplied by the gross notional it gives the benchmark exposure to
+// hedge. Positions are valued at the mark price when a resolver is set
+// and otherwise at their average price. It is zero when there is no
+// exposure.
+func (m *Manager) PortfolioBeta(positions []*types.Position, betas map[string]float64) float64 {
+	m.mu.Lock()
+	policy := m.betaPolicy
+	m.mu.Unlock()
+
+	var weighted, gross float64
+	for _, pos := range positions {
+		beta, ok := betas[pos.Symbol]
+		if !ok {
+			if policy == UnknownBetaExclude {
+				continue
+			}
+			beta = 1
+		}
+
+		price := pos.AvgPrice
+		if m.markPrices != nil {
+			if mark, err := m.markPrices.MarkPrice(pos.Symbol); err == nil {
+				price = mark
+			}
+		}
+		notional := pos.Quantity * price
+		if !isFinite(notional) || !isFinite(beta) {
+			continue
+		}
+		weighted += notional * beta
+		gross += math.Abs(notional)
+	}
+
+	if gross == 0 {
+		return 0
+	}
+	return weighted / gross
+}
@@ -0,0 +1,51 @@
+package risk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+func TestManager_PortfolioBeta(t *testing.T) {
+	manager := NewManager(testLimits(), zap.NewNop())
+	positions := []*types.Position{
+		{Symbol: "BONK/SOL", Quantity: 100, AvgPrice: 3}, // 300 notional
+		{Symbol: "JUP/SOL", Quantity: 50, AvgPrice: 2},   // 100 notional
+	}
+	betas := map[string]float64{"BONK/SOL": 2, "JUP/SOL": 0.8}
+
+	// (300*2 + 100*0.8) / 400
+	assert.InDelta(t, 1.7, manager.PortfolioBeta(positions, betas), 1e-9)
+
+	t.Run("ShortsOffset", func(t *testing.T) {
+		short := []*types.Position{positions[0], {Symbol: "JUP/SOL", Quantity: -50, AvgPrice: 2}}
+		// (600 - 80) / 400
+		assert.InDelta(t, 1.3, manager.PortfolioBeta(short, betas), 1e-9)
+	})
+
+	t.Run("UnknownBeta", func(t *testing.T) {
+		withUnknown := append([]*types.Position{{Symbol: "NEW/SOL", Quantity: 10, AvgPrice: 10}}, positions...)
+		// (100*1 + 600 + 80) / 500
+		assert.InDelta(t, 1.56, manager.PortfolioBeta(withUnknown, betas), 1e-9)
+
+		manager := NewManager(testLimits(), zap.NewNop())
+		manager.SetUnknownBetaPolicy(UnknownBetaExclude)
+		assert.InDelta(t, 1.7, manager.PortfolioBeta(withUnknown, betas), 1e-9)
+	})
+
+	t.Run("MarkPrice", func(t *testing.T) {
+		manager := NewManager(testLimits(), zap.NewNop())
+		resolver := NewMarkPriceResolver(MarkPriceLast)
+		resolver.UpdateLast("JUP/SOL", 6)
+		manager.SetMarkPriceResolver(resolver)
+		// (300*2 + 300*0.8) / 600
+		assert.InDelta(t, 1.4, manager.PortfolioBeta(positions, betas), 1e-9)
+	})
+
+	t.Run("NoExposure", func(t *testing.T) {
+		assert.Zero(t, manager.PortfolioBeta(nil, betas))
+	})
+}
@@ -0,0 +1,82 @@
+package risk
+
+import (
+	"math"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+// BondingCurveImpact estimates the price impact and average execution
+// price of trading qty tokens along a linear bonding curve where the price
+// at supply s is BasePrice + Slope*s. Buys move supply up the curve and
+// sells move it down. Buys are capped at MaxSupply (graduation) and sells
+// at zero supply, so avgPrice covers only the fillable quantity.
+func (m *Manager) BondingCurveImpact(curve *types.BondingCurve, side types.OrderSide, qty float64) (impact, avgPrice float64) {
+	if curve == nil || qty <= 0 {
+		return 0, 0
+	}
+
+	supply := float64(curve.Supply)
+	start := curve.BasePrice + curve.Slope*supply
+	if start <= 0 {
+		return 0, 0
+	}
+
+	var end float64
+	if side == types.OrderSideBuy {
+		end = supply + qty
+		if curve.MaxSupply > 0 {
+			end = math.Min(end, float64(curve.MaxSupply))
+		}
+	} else {
+		end = math.Max(supply-qty, 0)
+	}
+
+	filled := math.Abs(end - supply)
+	if filled == 0 {
+		return 0, start
+	}
+
+	// Integral of BasePrice + Slope*s over [supply, end] divided by the
+	// quantity gives the average fill price
+	cost := curve.BasePrice*filled + curve.Slope*math.Abs(end*end-supply*supply)/2
+	avgPrice = cost / filled
+
+	endPrice := curve.BasePrice + curve.Slope*end
+	impact = math.Abs(endPrice-start) / start
+	return impact, avgPrice
+}
+
+// RecordBondingCurve records the current state of symbol's bonding curve.
+// CheckOrderRisk then sets the PriceImpact of orders in symbol from it
+// with ApplyBondingCurveImpact before the price impact check.
+func (m *Manager) RecordBondingCurve(symbol string, curve types.BondingCurve) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.curves[symbol] = curve
+}
+
+// applyRecordedCurve applies the symbol's recorded bonding curve, if any,
+// to order
+func (m *Manager) applyRecordedCurve(order *types.Order) {
+	m.mu.Lock()
+	curve, known := m.curves[order.Symbol]
+	m.mu.Unlock()
+	if known {
+		m.ApplyBondingCurveImpact(order, &curve)
+	}
+}
+
+// ApplyBondingCurveImpact sets order.PriceImpact from the token's bonding
+// curve and returns the expected average execution price. The curve's
+// progress toward graduation is kept for the symbol's price impact limit.
+func (m *Manager) ApplyBondingCurveImpact(order *types.Order, curve *types.BondingCurve) float64 {
+	if curve != nil {
+		m.mu.Lock()
+		m.graduation[order.Symbol] = curveProgress(curve)
+		m.mu.Unlock()
+	}
+	impact, avgPrice := m.BondingCurveImpact(curve, order.Side, order.Quantity)
+	order.PriceImpact = impact
+	return avgPrice
+}
@@ -0,0 +1,90 @@
+package risk
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+func TestManager_BondingCurveImpact(t *testing.T) {
+	manager := NewManager(testLimits(), zap.NewNop())
+
+	t.Run("FarFromGraduation", func(t *testing.T) {
+		curve := &types.BondingCurve{BasePrice: 1, Slope: 0.01, Supply: 100, MaxSupply: 10000}
+
+		// Price goes from 2 to 3 over 100 tokens
+		impact, avg := manager.BondingCurveImpact(curve, types.OrderSideBuy, 100)
+		assert.InDelta(t, 0.5, impact, 1e-9)
+		assert.InDelta(t, 2.5, avg, 1e-9)
+
+		// Selling 50 takes the price from 2 to 1.5
+		impact, avg = manager.BondingCurveImpact(curve, types.OrderSideSell, 50)
+		assert.InDelta(t, 0.25, impact, 1e-9)
+		assert.InDelta(t, 1.75, avg, 1e-9)
+	})
+
+	t.Run("NearGraduation", func(t *testing.T) {
+		curve := &types.BondingCurve{BasePrice: 1, Slope: 0.01, Supply: 9990, MaxSupply: 10000}
+
+		// Only 10 tokens are left before graduation
+		impact, avg := manager.BondingCurveImpact(curve, types.OrderSideBuy, 100)
+		start := 1 + 0.01*9990
+		assert.InDelta(t, 0.1/start, impact, 1e-9)
+		assert.InDelta(t, start+0.05, avg, 1e-9)
+
+		impact, avg = manager.BondingCurveImpact(&types.BondingCurve{BasePrice: 1, Slope: 0.01, Supply: 10000, MaxSupply: 10000},
+			types.OrderSideBuy, 1)
+		assert.Zero(t, impact)
+		assert.InDelta(t, 101.0, avg, 1e-9)
+	})
+
+	t.Run("SellCappedAtZeroSupply", func(t *testing.T) {
+		curve := &types.BondingCurve{BasePrice: 1, Slope: 0.01, Supply: 10, MaxSupply: 10000}
+		impact, avg := manager.BondingCurveImpact(curve, types.OrderSideSell, 100)
+		assert.InDelta(t, 0.1/1.1, impact, 1e-9)
+		assert.InDelta(t, 1.05, avg, 1e-9)
+	})
+
+	t.Run("ApplyToOrder", func(t *testing.T) {
+		curve := &types.BondingCurve{BasePrice: 1, Slope: 0.01, Supply: 100, MaxSupply: 10000}
+		order := &types.Order{Side: types.OrderSideBuy, Quantity: 100}
+		avg := manager.ApplyBondingCurveImpact(order, curve)
+		assert.InDelta(t, 0.5, order.PriceImpact, 1e-9)
+		assert.InDelta(t, 2.5, avg, 1e-9)
+	})
+}
+
+func TestManager_CheckOrderRisk_RecordedBondingCurve(t *testing.T) {
+	ctx := context.Background()
+	limits := testLimits()
+	limits.MaxPriceImpact = 0.2
+	limits.GraduationTiers = []GraduationTier{{Progress: 0.9, Factor: 0.5}}
+	require.NoError(t, limits.Validate())
+	manager := NewManager(limits, zap.NewNop())
+	manager.RecordBondingCurve("PUMP/SOL", types.BondingCurve{BasePrice: 1, Slope: 0.01, Supply: 100, MaxSupply: 10000})
+
+	order := func(qty float64) *types.Order {
+		return &types.Order{ID: "o1", Symbol: "PUMP/SOL", Side: types.OrderSideBuy, Type: types.OrderTypeMarket, Quantity: qty}
+	}
+
+	// Buying 10 takes the price from 2 to 2.1; buying 100 takes it to 3
+	small := order(10)
+	assert.NoError(t, manager.CheckOrderRisk(ctx, small))
+	assert.InDelta(t, 0.05, small.PriceImpact, 1e-9)
+	assert.ErrorIs(t, manager.CheckOrderRisk(ctx, order(100)), ErrPriceImpactExceeded)
+
+	// The recorded curve's progress tightens the limit near graduation
+	manager.RecordBondingCurve("PUMP/SOL", types.BondingCurve{BasePrice: 1, Slope: 0.01, Supply: 9500, MaxSupply: 10000})
+	assert.NoError(t, manager.CheckOrderRisk(ctx, order(500)))
+	assert.InDelta(t, 0.1, manager.EffectiveMaxPriceImpact("PUMP/SOL"), 1e-9)
+
+	// Symbols without a recorded curve keep the impact they came with
+	other := &types.Order{ID: "o2", Symbol: "OTHER/SOL", Side: types.OrderSideBuy, Type: types.OrderTypeMarket, Quantity: 100}
+	assert.NoError(t, manager.CheckOrderRisk(ctx, other))
+	assert.Zero(t, other.PriceImpact)
+}
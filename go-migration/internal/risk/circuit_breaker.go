@@ -0,0 +1,139 @@
+package risk
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+// ErrTradingHalted is returned for orders on a symbol halted by the
+// circuit breaker
+var ErrTradingHalted = errors.New("trading halted")
+
+// CircuitBreakerConfig halts a symbol when its price moves more than
+// MaxMove (as a fraction) within Window. New orders are rejected until
+// Cooldown has passed. A zero MaxMove disables the breaker.
+//
+// With ResumeMaxMove set, the halt also lasts until the price has moved
+// no more than ResumeMaxMove within the last ResumeWindow, so a symbol
+// still swinging when the cooldown ends stays halted.
+type CircuitBreakerConfig struct {
+	MaxMove       float64       `json:"max_move"`
+	Window        time.Duration `json:"window"`
+	Cooldown      time.Duration `json:"cooldown"`
+	ResumeMaxMove float64       `json:"resume_max_move"`
+	ResumeWindow  time.Duration `json:"resume_window"`
+}
+
+type pricePoint struct {
+	price float64
+	at    time.Time
+}
+
+// symbolBreaker tracks recent prices and halt state for one symbol.
+// resuming is set while a halt waits for the price to settle.
+type symbolBreaker struct {
+	prices      []pricePoint
+	haltedUntil time.Time
+	resuming    bool
+}
+
+// priceMove returns the largest move between prices at or after since as
+// a fraction of the lowest one
+func priceMove(prices []pricePoint, since time.Time) float64 {
+	lo, hi := math.Inf(1), 0.0
+	for _, p := range prices {
+		if p.at.Before(since) {
+			continue
+		}
+		lo, hi = math.Min(lo, p.price), math.Max(hi, p.price)
+	}
+	if hi == 0 {
+		return 0
+	}
+	return (hi - lo) / lo
+}
+
+// UpdatePrice feeds a price update to the circuit breaker
+func (m *Manager) UpdatePrice(update *types.PriceUpdate) {
+	cfg := m.limits.CircuitBreaker
+	if cfg.MaxMove <= 0 || update.Price <= 0 {
+		return
+	}
+
+	now := m.now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	b, exists := m.breakers[update.Symbol]
+	if !exists {
+		b = &symbolBreaker{}
+		m.breakers[update.Symbol] = b
+	}
+
+	// Drop prices that fell out of both windows
+	cutoff := now.Add(-max(cfg.Window, cfg.ResumeWindow))
+	keep := b.prices[:0]
+	for _, p := range b.prices {
+		if !p.at.Before(cutoff) {
+			keep = append(keep, p)
+		}
+	}
+	b.prices = append(keep, pricePoint{price: update.Price, at: now})
+
+	if now.Before(b.haltedUntil) {
+		// Still halted: a move beyond ResumeMaxMove pushes the resume back
+		// until a full ResumeWindow without one
+		if b.resuming {
+			if move := priceMove(b.prices, now.Add(-cfg.ResumeWindow)); move > cfg.ResumeMaxMove {
+				if resume := now.Add(cfg.ResumeWindow); resume.After(b.haltedUntil) {
+					b.haltedUntil = resume
+					m.logger.Info("Circuit breaker halt extended by continued volatility",
+						zap.String("symbol", update.Symbol),
+						zap.Float64("move", move),
+						zap.Float64("resume_max_move", cfg.ResumeMaxMove),
+						zap.Time("halted_until", b.haltedUntil))
+				}
+			}
+		}
+		return
+	}
+	b.resuming = false
+
+	move := priceMove(b.prices, now.Add(-cfg.Window))
+	if move > cfg.MaxMove {
+		b.haltedUntil = now.Add(cfg.Cooldown)
+		b.resuming = cfg.ResumeMaxMove > 0
+		b.prices = nil
+		m.logger.Warn("Circuit breaker halted trading",
+			zap.String("symbol", update.Symbol),
+			zap.Float64("move", move),
+			zap.Float64("max_move", cfg.MaxMove),
+			zap.Time("halted_until", b.haltedUntil))
+	}
+}
+
+// HaltedUntil returns when trading in symbol resumes, or the zero time if
+// it isn't halted
+func (m *Manager) HaltedUntil(symbol string) time.Time {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	b, exists := m.breakers[symbol]
+	if !exists || !m.now().Before(b.haltedUntil) {
+		return time.Time{}
+	}
+	return b.haltedUntil
+}
+
+func (m *Manager) checkHalted(symbol string) error {
+	if until := m.HaltedUntil(symbol); !until.IsZero() {
+		return fmt.Errorf("%w: %s until %s", ErrTradingHalted, symbol, until.Format(time.RFC3339))
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package risk
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+func TestManager_CircuitBreaker(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	limits := testLimits()
+	limits.CircuitBreaker = CircuitBreakerConfig{
+		MaxMove:  0.3,
+		Window:   time.Minute,
+		Cooldown: 5 * time.Minute,
+	}
+	manager := NewManager(limits, zap.NewNop())
+	manager.now = func() time.Time { return now }
+
+	order := &types.Order{Symbol: "TEST/SOL", Quantity: 1}
+	other := &types.Order{Symbol: "OTHER/SOL", Quantity: 1}
+
+	manager.UpdatePrice(&types.PriceUpdate{Symbol: "TEST/SOL", Price: 100})
+	now = now.Add(10 * time.Second)
+	manager.UpdatePrice(&types.PriceUpdate{Symbol: "TEST/SOL", Price: 120})
+	assert.NoError(t, manager.CheckOrderRisk(ctx, order))
+
+	// 100 -> 140 within the window is a 40% move
+	now = now.Add(10 * time.Second)
+	manager.UpdatePrice(&types.PriceUpdate{Symbol: "TEST/SOL", Price: 140})
+	assert.ErrorIs(t, manager.CheckOrderRisk(ctx, order), ErrTradingHalted)
+	assert.NoError(t, manager.CheckOrderRisk(ctx, other))
+
+	now = now.Add(4 * time.Minute)
+	assert.ErrorIs(t, manager.CheckOrderRisk(ctx, order), ErrTradingHalted)
+
+	now = now.Add(time.Minute)
+	assert.NoError(t, manager.CheckOrderRisk(ctx, order))
+	assert.True(t, manager.HaltedUntil("TEST/SOL").IsZero())
+
+	t.Run("SlowMoveOutsideWindow", func(t *testing.T) {
+		manager := NewManager(limits, zap.NewNop())
+		manager.now = func() time.Time { return now }
+
+		manager.UpdatePrice(&types.PriceUpdate{Symbol: "TEST/SOL", Price: 100})
+		now = now.Add(2 * time.Minute)
+		manager.UpdatePrice(&types.PriceUpdate{Symbol: "TEST/SOL", Price: 140})
+		assert.NoError(t, manager.CheckOrderRisk(ctx, order))
+	})
+}
+
+func TestManager_CircuitBreakerResumeStability(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	limits := testLimits()
+	limits.CircuitBreaker = CircuitBreakerConfig{
+		MaxMove:       0.3,
+		Window:        time.Minute,
+		Cooldown:      5 * time.Minute,
+		ResumeMaxMove: 0.05,
+		ResumeWindow:  30 * time.Second,
+	}
+	require.NoError(t, limits.Validate())
+	manager := NewManager(limits, zap.NewNop())
+	manager.now = func() time.Time { return now }
+
+	order := &types.Order{Symbol: "TEST/SOL", Quantity: 1}
+	price := func(p float64, after time.Duration) {
+		now = now.Add(after)
+		manager.UpdatePrice(&types.PriceUpdate{Symbol: "TEST/SOL", Price: p})
+	}
+
+	price(100, 0)
+	price(140, 10*time.Second)
+	require.ErrorIs(t, manager.CheckOrderRisk(ctx, order), ErrTradingHalted)
+
+	// The price keeps swinging 10% up to the end of the cooldown
+	for i := 0; i < 10; i++ {
+		price(140+14*float64(i%2), 29*time.Second)
+	}
+	now = now.Add(20 * time.Second)
+	assert.True(t, now.After(time.Date(2024, 1, 1, 12, 5, 10, 0, time.UTC)), "cooldown has elapsed")
+	assert.ErrorIs(t, manager.CheckOrderRisk(ctx, order), ErrTradingHalted, "still volatile")
+
+	// Small moves don't extend the halt, which ends a resume window after
+	// the last big one
+	price(150, 0)
+	price(151, 5*time.Second)
+	assert.ErrorIs(t, manager.CheckOrderRisk(ctx, order), ErrTradingHalted)
+	now = now.Add(10 * time.Second)
+	assert.NoError(t, manager.CheckOrderRisk(ctx, order))
+
+	limits.CircuitBreaker.ResumeWindow = 0
+	assert.Error(t, limits.Validate())
+}
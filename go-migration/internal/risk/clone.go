@@ -0,0 +1,167 @@
+package risk
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+// Clone returns a manager with a deep copy of the limits, circuit breaker
+// state, order cooldowns, volatility estimates, social score history,
+// holder counts, bonding curves and their progress, pool reserves,
+// spreads, recent violations, hysteresis outcomes, the kill switch and
+// correlations.
+// The logger, mark price resolver, gas estimator, currency converter,
+// balance source and metrics precision are shared, since they don't
+// change during checks. The clone starts with its own empty in-memory
+// state store, so saving a shadow's state never overwrites the primary's;
+// give it a store with SetStateStore to persist it.
+func (m *Manager) Clone() *Manager {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	clone := &Manager{
+		logger:     m.logger,
+		limits:     m.limits.clone(),
+		markPrices: m.markPrices,
+		gas:        m.gas,
+		converter:  m.converter,
+		balances:   m.balances,
+		store:      NewMemoryStateStore(),
+		kill:       m.kill,
+		corr:       make(CorrelationMatrix, len(m.corr)),
+		precision:  m.precision,
+		breakers:   make(map[string]*symbolBreaker, len(m.breakers)),
+		lastOrders: make(map[orderKey]time.Time, len(m.lastOrders)),
+		volatility: make(map[string]float64, len(m.volatility)),
+		social:     make(map[string][]scorePoint, len(m.social)),
+		hysteresis: make(map[hysteresisKey]bool, len(m.hysteresis)),
+		holders:    make(map[string]int, len(m.holders)),
+		graduation: make(map[string]float64, len(m.graduation)),
+		curves:     make(map[string]types.BondingCurve, len(m.curves)),
+		pools:      make(map[string]PoolReserves, len(m.pools)),
+		spreads:    make(map[string]recordedSpread, len(m.spreads)),
+		violations: append([]Violation(nil), m.violations...),
+		betaPolicy: m.betaPolicy,
+		now:        m.now,
+	}
+	for symbol, vol := range m.volatility {
+		clone.volatility[symbol] = vol
+	}
+	for symbol, scores := range m.social {
+		clone.social[symbol] = append([]scorePoint(nil), scores...)
+	}
+	for symbol, holders := range m.holders {
+		clone.holders[symbol] = holders
+	}
+	for symbol, progress := range m.graduation {
+		clone.graduation[symbol] = progress
+	}
+	for symbol, curve := range m.curves {
+		clone.curves[symbol] = curve
+	}
+	for symbol, pool := range m.pools {
+		clone.pools[symbol] = pool
+	}
+	for symbol, spread := range m.spreads {
+		clone.spreads[symbol] = spread
+	}
+	for symbol, row := range m.corr {
+		clone.corr[symbol] = make(map[string]float64, len(row))
+		for other, rho := range row {
+			clone.corr[symbol][other] = rho
+		}
+	}
+	for key, failed := range m.hysteresis {
+		clone.hysteresis[key] = failed
+	}
+	for key, at := range m.lastOrders {
+		clone.lastOrders[key] = at
+	}
+	for symbol, breaker := range m.breakers {
+		clone.breakers[symbol] = &symbolBreaker{
+			prices:      append([]pricePoint(nil), breaker.prices...),
+			haltedUntil: breaker.haltedUntil,
+			resuming:    breaker.resuming,
+		}
+	}
+	return clone
+}
+
+// Limits returns a copy of the manager's limits
+func (m *Manager) Limits() Limits {
+	return m.limits.clone()
+}
+
+// SetLimits replaces the manager's limits. It must not be called while
+// checks are running on the manager.
+func (m *Manager) SetLimits(limits Limits) {
+	m.limits = limits.clone()
+}
+
+// clone copies l including its maps
+func (l Limits) clone() Limits {
+	out := l
+	if l.MaxCategoryConcentration != nil {
+		out.MaxCategoryConcentration = make(map[string]float64, len(l.MaxCategoryConcentration))
+		for k, v := range l.MaxCategoryConcentration {
+			out.MaxCategoryConcentration[k] = v
+		}
+	}
+	if l.MinOrderIntervals != nil {
+		out.MinOrderIntervals = make(map[string]time.Duration, len(l.MinOrderIntervals))
+		for k, v := range l.MinOrderIntervals {
+			out.MinOrderIntervals[k] = v
+		}
+	}
+	if l.WarnRatios != nil {
+		out.WarnRatios = make(map[string]float64, len(l.WarnRatios))
+		for k, v := range l.WarnRatios {
+			out.WarnRatios[k] = v
+		}
+	}
+	if l.HysteresisBuffers != nil {
+		out.HysteresisBuffers = make(map[string]float64, len(l.HysteresisBuffers))
+		for k, v := range l.HysteresisBuffers {
+			out.HysteresisBuffers[k] = v
+		}
+	}
+	out.GraduationTiers = append([]GraduationTier(nil), l.GraduationTiers...)
+	if l.SymbolSessions != nil {
+		out.SymbolSessions = make(map[string]SessionSchedule, len(l.SymbolSessions))
+		for k, v := range l.SymbolSessions {
+			out.SymbolSessions[k] = v
+		}
+	}
+	return out
+}
+
+// ShadowCheckOrderRisk runs order through both the primary and shadow
+// managers and logs when their decisions differ. Only the primary result
+// is returned; the shadow never affects order flow.
+func ShadowCheckOrderRisk(ctx context.Context, primary, shadow *Manager, order *types.Order) error {
+	primaryErr := primary.CheckOrderRisk(ctx, order)
+	shadowErr := shadow.CheckOrderRisk(ctx, order)
+
+	if (primaryErr == nil) != (shadowErr == nil) {
+		fields := []zap.Field{
+			zap.String("order_id", order.ID),
+			zap.String("correlation_id", order.CorrelationID),
+			zap.String("symbol", order.Symbol),
+			zap.Bool("primary_passed", primaryErr == nil),
+			zap.Bool("shadow_passed", shadowErr == nil),
+		}
+		if primaryErr != nil {
+			fields = append(fields, zap.NamedError("primary_error", primaryErr))
+		}
+		if shadowErr != nil {
+			fields = append(fields, zap.NamedError("shadow_error", shadowErr))
+		}
+		primary.logger.Info("Shadow risk decision diverged", fields...)
+	}
+
+	return primaryErr
+}
@@ -0,0 +1,79 @@
+package risk
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+func TestManager_Clone(t *testing.T) {
+	limits := testLimits()
+	limits.WarnRatios = map[string]float64{LimitMaxPositionSize: 0.9}
+	limits.MaxCategoryConcentration = map[string]float64{"memecoins": 0.5}
+	manager := NewManager(limits, zap.NewNop())
+
+	clone := manager.Clone()
+	candidate := clone.Limits()
+	candidate.MaxPositionSize = 10
+	candidate.WarnRatios[LimitMaxPositionSize] = 0.5
+	candidate.MaxCategoryConcentration["memecoins"] = 0.1
+	clone.SetLimits(candidate)
+
+	assert.Equal(t, 10.0, clone.Limits().MaxPositionSize)
+	assert.Equal(t, 1000.0, manager.Limits().MaxPositionSize)
+	assert.Equal(t, 0.9, manager.Limits().WarnRatios[LimitMaxPositionSize])
+	assert.Equal(t, 0.5, manager.Limits().MaxCategoryConcentration["memecoins"])
+
+	order := &types.Order{ID: "o1", Symbol: "TEST/SOL", Quantity: 50}
+	assert.NoError(t, manager.CheckOrderRisk(context.Background(), order))
+	assert.ErrorIs(t, clone.CheckOrderRisk(context.Background(), order), ErrPositionSizeExceeded)
+}
+
+func TestManager_CloneStateStore(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStateStore()
+	limits := testLimits()
+	limits.MinOrderInterval = time.Hour
+	manager := NewManager(limits, zap.NewNop())
+	manager.SetStateStore(store)
+	manager.RecordOrder("user1", "TEST/SOL")
+	require.NoError(t, manager.SaveState(ctx))
+
+	// The shadow's cooldowns go to its own store, not the primary's
+	shadow := manager.Clone()
+	shadow.RecordOrder("user2", "TEST/SOL")
+	require.NoError(t, shadow.SaveState(ctx))
+
+	saved, err := store.LoadRiskState(ctx)
+	require.NoError(t, err)
+	require.Len(t, saved.Cooldowns, 1)
+	assert.Equal(t, "user1", saved.Cooldowns[0].UserID)
+}
+
+func TestShadowCheckOrderRisk(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	primary := NewManager(testLimits(), zap.New(core))
+	shadow := primary.Clone()
+	candidate := shadow.Limits()
+	candidate.MaxPositionSize = 10
+	shadow.SetLimits(candidate)
+
+	ctx := context.Background()
+	require.NoError(t, ShadowCheckOrderRisk(ctx, primary, shadow, &types.Order{ID: "o1", Quantity: 5}))
+	assert.Zero(t, logs.FilterMessage("Shadow risk decision diverged").Len())
+
+	require.NoError(t, ShadowCheckOrderRisk(ctx, primary, shadow, &types.Order{ID: "o2", Quantity: 50}),
+		"the shadow rejection must not block the order")
+	diverged := logs.FilterMessage("Shadow risk decision diverged").All()
+	require.Len(t, diverged, 1)
+	assert.Equal(t, "o2", diverged[0].ContextMap()["order_id"])
+	assert.Equal(t, false, diverged[0].ContextMap()["shadow_passed"])
+}
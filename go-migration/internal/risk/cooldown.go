@@ -0,0 +1,60 @@
+package risk
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrOrderCooldown is returned for orders placed sooner than the minimum
+// order interval after the previous accepted order for the same user and
+// symbol
+var ErrOrderCooldown = errors.New("order cooldown active")
+
+// orderKey identifies a user's orders in one symbol
+type orderKey struct {
+	userID string
+	symbol string
+}
+
+// minOrderInterval returns the cooldown configured for symbol
+func (l Limits) minOrderInterval(symbol string) time.Duration {
+	if interval, ok := l.MinOrderIntervals[symbol]; ok {
+		return interval
+	}
+	return l.MinOrderInterval
+}
+
+// RecordOrder starts the order cooldown for userID in symbol. Call it once
+// an order that passed CheckOrderRisk has actually been accepted, so
+// orders rejected further down the line don't hold up the next one.
+func (m *Manager) RecordOrder(userID, symbol string) {
+	now := m.now()
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastOrders[orderKey{userID: userID, symbol: symbol}] = now
+}
+
+// checkCooldown rejects orders within the minimum interval of the last
+// order recorded for the same user and symbol
+func (m *Manager) checkCooldown(userID, symbol string) error {
+	interval := m.limits.minOrderInterval(symbol)
+	if interval <= 0 {
+		return nil
+	}
+
+	now := m.now()
+	key := orderKey{userID: userID, symbol: symbol}
+
+	m.mu.Lock()
+	last, exists := m.lastOrders[key]
+	m.mu.Unlock()
+
+	if exists {
+		if wait := last.Add(interval).Sub(now); wait > 0 {
+			return fmt.Errorf("%w: %s for %s, retry in %s", ErrOrderCooldown, symbol, userID, wait)
+		}
+	}
+	return nil
+}
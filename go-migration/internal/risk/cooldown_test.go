@@ -0,0 +1,61 @@
+package risk
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+func TestManager_OrderCooldown(t *testing.T) {
+	ctx := context.Background()
+	limits := testLimits()
+	limits.MinOrderInterval = time.Minute
+	limits.MinOrderIntervals = map[string]time.Duration{"FAST/SOL": time.Second}
+	manager := NewManager(limits, zap.NewNop())
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	manager.now = func() time.Time { return now }
+
+	order := &types.Order{ID: "o1", UserID: "user1", Symbol: "TEST/SOL", Quantity: 1}
+	require.NoError(t, manager.CheckOrderRisk(ctx, order))
+
+	// Passing the check alone doesn't start the cooldown
+	require.NoError(t, manager.CheckOrderRisk(ctx, order))
+	manager.RecordOrder("user1", "TEST/SOL")
+
+	now = now.Add(30 * time.Second)
+	err := manager.CheckOrderRisk(ctx, order)
+	assert.ErrorIs(t, err, ErrOrderCooldown)
+
+	// Other users and symbols are tracked separately
+	assert.NoError(t, manager.CheckOrderRisk(ctx, &types.Order{UserID: "user2", Symbol: "TEST/SOL", Quantity: 1}))
+	assert.NoError(t, manager.CheckOrderRisk(ctx, &types.Order{UserID: "user1", Symbol: "FAST/SOL", Quantity: 1}))
+
+	// The cooldown runs from the last recorded order, not the rejected one
+	now = now.Add(30 * time.Second)
+	assert.NoError(t, manager.CheckOrderRisk(ctx, order))
+
+	t.Run("Revalidate", func(t *testing.T) {
+		manager.RecordOrder("user1", "TEST/SOL")
+		assert.ErrorIs(t, manager.CheckOrderRisk(ctx, order), ErrOrderCooldown)
+		assert.NoError(t, manager.RevalidateOrderRisk(ctx, order))
+		assert.ErrorIs(t, manager.RevalidateOrderRisk(ctx, &types.Order{UserID: "user1", Symbol: "TEST/SOL", Quantity: 5000}),
+			ErrPositionSizeExceeded)
+	})
+
+	t.Run("PerSymbolOverride", func(t *testing.T) {
+		fast := &types.Order{UserID: "user1", Symbol: "FAST/SOL", Quantity: 1}
+		require.NoError(t, manager.CheckOrderRisk(ctx, fast))
+		manager.RecordOrder("user1", "FAST/SOL")
+		now = now.Add(500 * time.Millisecond)
+		assert.ErrorIs(t, manager.CheckOrderRisk(ctx, fast), ErrOrderCooldown)
+		now = now.Add(time.Second)
+		assert.NoError(t, manager.CheckOrderRisk(ctx, fast))
+	})
+}
@@ -0,0 +1,49 @@
+package risk
+
+import (
+	"fmt"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+// CurrencyConverter returns the rate that converts an amount in one
+// currency into another
+type CurrencyConverter interface {
+	Rate(from, to string) (float64, error)
+}
+
+// CurrencyConverterFunc adapts a function to CurrencyConverter
+type CurrencyConverterFunc func(from, to string) (float64, error)
+
+func (f CurrencyConverterFunc) Rate(from, to string) (float64, error) {
+	return f(from, to)
+}
+
+// SetCurrencyConverter sets the converter used to express daily PnL in
+// Limits.BaseCurrency
+func (m *Manager) SetCurrencyConverter(converter CurrencyConverter) {
+	m.converter = converter
+}
+
+// dailyPnL returns metrics.DailyPnL in Limits.BaseCurrency. PnL is used as
+// is when no base currency is configured or the metrics don't name their
+// currency. A missing converter or failed conversion is an error, so the
+// daily loss limit is never compared across currencies.
+func (m *Manager) dailyPnL(metrics *types.RiskMetrics) (float64, error) {
+	base := m.limits.BaseCurrency
+	if base == "" || metrics.Currency == "" || metrics.Currency == base {
+		return metrics.DailyPnL, nil
+	}
+	if m.converter == nil {
+		return 0, fmt.Errorf("%w: no converter for daily PnL in %s to %s", ErrInvalidInput, metrics.Currency, base)
+	}
+
+	rate, err := m.converter.Rate(metrics.Currency, base)
+	if err != nil {
+		return 0, fmt.Errorf("failed to convert daily PnL from %s to %s: %w", metrics.Currency, base, err)
+	}
+	if !isFinite(rate) || rate <= 0 {
+		return 0, fmt.Errorf("%w: rate %f from %s to %s", ErrInvalidInput, rate, metrics.Currency, base)
+	}
+	return metrics.DailyPnL * rate, nil
+}
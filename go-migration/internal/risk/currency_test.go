@@ -0,0 +1,49 @@
+package risk
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+func TestManager_DailyLossBaseCurrency(t *testing.T) {
+	ctx := context.Background()
+	limits := testLimits()
+	limits.BaseCurrency = "USD"
+	manager := NewManager(limits, zap.NewNop())
+
+	// 5 SOL lost stays under the 1000 USD limit until it's converted
+	metrics := &types.RiskMetrics{UserID: "user1", DailyPnL: -5, Currency: "SOL", MarginLevel: 1000}
+	assert.ErrorIs(t, manager.CheckAccountRisk(ctx, metrics), ErrInvalidInput, "no converter")
+
+	rates := map[string]float64{"SOL": 250, "USDC": 1}
+	manager.SetCurrencyConverter(CurrencyConverterFunc(func(from, to string) (float64, error) {
+		rate, ok := rates[from]
+		if !ok || to != "USD" {
+			return 0, errors.New("no rate")
+		}
+		return rate, nil
+	}))
+	assert.ErrorIs(t, manager.CheckAccountRisk(ctx, metrics), ErrDailyLossExceeded, "5 SOL is 1250 USD")
+
+	metrics.DailyPnL = -3
+	assert.NoError(t, manager.CheckAccountRisk(ctx, metrics), "3 SOL is 750 USD")
+
+	// USDC-quoted PnL is held to the same limit
+	usdc := &types.RiskMetrics{UserID: "user2", DailyPnL: -1200, Currency: "USDC", MarginLevel: 1000}
+	assert.ErrorIs(t, manager.CheckAccountRisk(ctx, usdc), ErrDailyLossExceeded)
+
+	// PnL already in the base currency or without one is used as is
+	usd := &types.RiskMetrics{UserID: "user3", DailyPnL: -900, Currency: "USD", MarginLevel: 1000}
+	assert.NoError(t, manager.CheckAccountRisk(ctx, usd))
+	unknown := &types.RiskMetrics{UserID: "user3", DailyPnL: -900, MarginLevel: 1000}
+	assert.NoError(t, manager.CheckAccountRisk(ctx, unknown))
+
+	bonk := &types.RiskMetrics{UserID: "user4", DailyPnL: -1, Currency: "BONK", MarginLevel: 1000}
+	assert.ErrorContains(t, manager.CheckAccountRisk(ctx, bonk), "no rate")
+}
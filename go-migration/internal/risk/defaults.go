@@ -0,0 +1,125 @@
+package risk
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TradingMode selects a set of default limits
+type TradingMode string
+
+const (
+	// TradingModeDEX is for established tokens on DEX order books and
+	// aggregators
+	TradingModeDEX TradingMode = "dex"
+	// TradingModePumpFun is for new pump.fun tokens: smaller sizes, wider
+	// slippage and breaker bands, and liquidity, holding and spread checks on
+	TradingModePumpFun TradingMode = "pump_fun"
+)
+
+// DefaultLimits returns the default limits for mode. Unknown modes get the
+// DEX defaults. Pump.fun tokens are bought outright, so their MaxLeverage
+// of 1 allows a fully invested book however much it loses, but no
+// borrowed exposure.
+func DefaultLimits(mode TradingMode) Limits {
+	if mode == TradingModePumpFun {
+		return Limits{
+			MaxPositionSize:  100000,
+			MaxDrawdown:      0.3,
+			MaxDailyLoss:     500,
+			MaxLeverage:      1,
+			MinMarginLevel:   100,
+			MaxConcentration: 0.1,
+			WarnRatio:        0.8,
+			CircuitBreaker: CircuitBreakerConfig{
+				MaxMove:  0.5,
+				Window:   time.Minute,
+				Cooldown: 5 * time.Minute,
+			},
+			MinOrderInterval:        5 * time.Second,
+			MaxHoldingPeriod:        24 * time.Hour,
+			MinLiquidityToMarketCap: 0.05,
+			MaxSlippage:             0.05,
+			MaxSpread:               0.1,
+		}
+	}
+
+	return Limits{
+		MaxPositionSize:  1000000,
+		MaxDrawdown:      0.1,
+		MaxDailyLoss:     1000,
+		MaxLeverage:      3,
+		MinMarginLevel:   150,
+		MaxConcentration: 0.25,
+		WarnRatio:        0.8,
+		CircuitBreaker: CircuitBreakerConfig{
+			MaxMove:  0.2,
+			Window:   time.Minute,
+			Cooldown: 5 * time.Minute,
+		},
+		MaxSlippage:  0.01,
+		MaxPoolShare: 0.1,
+	}
+}
+
+// LoadLimits parses JSON or YAML limits from r and merges them over the
+// defaults for the config's "mode" key (DEX when absent), adjusted by the
+// "profile" key (balanced when absent), so a partial config only needs the
+// values it changes. Keys use the JSON field names;
+// durations are nanoseconds. Unknown keys and limits that fail Validate are
+// errors.
+func LoadLimits(r io.Reader) (Limits, error) {
+	var raw map[string]interface{}
+	if err := yaml.NewDecoder(r).Decode(&raw); err != nil && err != io.EOF {
+		return Limits{}, fmt.Errorf("failed to parse limits: %w", err)
+	}
+
+	mode := TradingModeDEX
+	if value, ok := raw["mode"]; ok {
+		name, isString := value.(string)
+		if !isString {
+			return Limits{}, fmt.Errorf("invalid mode %v", value)
+		}
+		mode = TradingMode(name)
+		if mode != TradingModeDEX && mode != TradingModePumpFun {
+			return Limits{}, fmt.Errorf("unknown trading mode %q", name)
+		}
+		delete(raw, "mode")
+	}
+
+	profile := ProfileBalanced
+	if value, ok := raw["profile"]; ok {
+		name, isString := value.(string)
+		if !isString {
+			return Limits{}, fmt.Errorf("invalid profile %v", value)
+		}
+		profile = Profile(name)
+		delete(raw, "profile")
+	}
+
+	limits, err := ProfileLimits(profile, mode)
+	if err != nil {
+		return Limits{}, err
+	}
+	if len(raw) > 0 {
+		data, err := json.Marshal(raw)
+		if err != nil {
+			return Limits{}, fmt.Errorf("failed to parse limits: %w", err)
+		}
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&limits); err != nil {
+			return Limits{}, fmt.Errorf("failed to parse limits: %w", err)
+		}
+	}
+
+	if err := limits.Validate(); err != nil {
+		return Limits{}, err
+	}
+	return limits, nil
+}
@@ -0,0 +1,106 @@
+package risk
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+func TestDefaultLimits(t *testing.T) {
+	dex := DefaultLimits(TradingModeDEX)
+	pump := DefaultLimits(TradingModePumpFun)
+
+	for _, limits := range []Limits{dex, pump} {
+		assert.Positive(t, limits.MaxPositionSize)
+		assert.Positive(t, limits.MaxDrawdown)
+		assert.Positive(t, limits.MaxSlippage)
+		assert.NoError(t, limits.checkNonNegative())
+	}
+
+	assert.Less(t, pump.MaxPositionSize, dex.MaxPositionSize)
+	assert.Greater(t, pump.MaxSlippage, dex.MaxSlippage)
+	assert.Positive(t, pump.MinLiquidityToMarketCap)
+	assert.Equal(t, dex, DefaultLimits("unknown"))
+
+	t.Run("PumpFunLeverage", func(t *testing.T) {
+		ctx := context.Background()
+		pump.MaxConcentration = 0
+		pump.MaxDrawdown = 1
+		manager := NewManager(pump, zap.NewNop())
+		balance := 1000.0
+		manager.SetBalanceSource(BalanceSourceFunc(func(userID string) (float64, error) { return balance, nil }))
+
+		// The whole balance invested and down by half is still 1x
+		book := []*types.Position{
+			{Symbol: "AAA/SOL", Quantity: 1000, AvgPrice: 0.5, UnrealizedPnL: -300},
+			{Symbol: "BBB/SOL", Quantity: 1000, AvgPrice: 0.5, UnrealizedPnL: -200},
+		}
+		assert.NoError(t, manager.CheckPortfolioRisk(ctx, book))
+
+		// Buying more than the balance pays for is not
+		balance = 800
+		assert.ErrorIs(t, manager.CheckPortfolioRisk(ctx, book), ErrLeverageExceeded)
+	})
+}
+
+func TestLoadLimits(t *testing.T) {
+	t.Run("PartialYAML", func(t *testing.T) {
+		limits, err := LoadLimits(strings.NewReader(`
+mode: pump_fun
+max_position_size: 5000
+circuit_breaker:
+  max_move: 0.4
+max_category_concentration:
+  meme: 0.2
+`))
+		require.NoError(t, err)
+
+		defaults := DefaultLimits(TradingModePumpFun)
+		assert.Equal(t, 5000.0, limits.MaxPositionSize)
+		assert.Equal(t, 0.4, limits.CircuitBreaker.MaxMove)
+		assert.Equal(t, defaults.CircuitBreaker.Window, limits.CircuitBreaker.Window, "unset nested fields keep defaults")
+		assert.Equal(t, map[string]float64{"meme": 0.2}, limits.MaxCategoryConcentration)
+		assert.Equal(t, defaults.MaxSlippage, limits.MaxSlippage)
+		assert.Equal(t, defaults.MinOrderInterval, limits.MinOrderInterval)
+	})
+
+	t.Run("PartialJSON", func(t *testing.T) {
+		limits, err := LoadLimits(strings.NewReader(`{"max_slippage": 0.02, "min_order_interval": 1000000000}`))
+		require.NoError(t, err)
+
+		defaults := DefaultLimits(TradingModeDEX)
+		assert.Equal(t, 0.02, limits.MaxSlippage)
+		assert.Equal(t, time.Second, limits.MinOrderInterval)
+		assert.Equal(t, defaults.MaxPositionSize, limits.MaxPositionSize)
+	})
+
+	t.Run("Empty", func(t *testing.T) {
+		limits, err := LoadLimits(strings.NewReader(""))
+		require.NoError(t, err)
+		assert.Equal(t, DefaultLimits(TradingModeDEX), limits)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		for name, config := range map[string]string{
+			"negative":     `max_drawdown: -0.1`,
+			"nested":       `circuit_breaker: {max_move: -1}`,
+			"category":     `max_category_concentration: {meme: -0.5}`,
+			"unknown key":  `max_positon_size: 10`,
+			"unknown mode": `mode: futures`,
+			"wrong type":   `max_daily_loss: lots`,
+		} {
+			_, err := LoadLimits(strings.NewReader(config))
+			assert.Error(t, err, name)
+		}
+
+		_, err := LoadLimits(strings.NewReader(`max_drawdown: -0.1`))
+		assert.ErrorContains(t, err, LimitMaxDrawdown)
+	})
+}
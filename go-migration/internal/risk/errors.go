@@ -0,0 +1,59 @@
+package risk
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrInvalidInput is returned for orders and positions with zero,
+// negative, NaN or infinite values that would break the ratio math
+var ErrInvalidInput = errors.New("invalid risk input")
+
+// LimitError reports a risk check that failed because an observed value
+// breached a configured limit. Use errors.As to read the values and
+// errors.Is with the Err* sentinels to branch on the limit.
+type LimitError struct {
+	Limit     string
+	Observed  float64
+	Threshold float64
+	msg       string
+}
+
+func (e *LimitError) Error() string {
+	return e.msg
+}
+
+// Is matches sentinels for the same limit
+func (e *LimitError) Is(target error) bool {
+	t, ok := target.(*LimitError)
+	return ok && t.Limit == e.Limit
+}
+
+func newLimitError(limit string, observed, threshold float64, format string, args ...interface{}) *LimitError {
+	return &LimitError{
+		Limit:     limit,
+		Observed:  observed,
+		Threshold: threshold,
+		msg:       fmt.Sprintf(format, args...),
+	}
+}
+
+// Sentinels for errors.Is
+var (
+	ErrPositionSizeExceeded          = &LimitError{Limit: LimitMaxPositionSize, msg: "position size exceeds limit"}
+	ErrDrawdownExceeded              = &LimitError{Limit: LimitMaxDrawdown, msg: "drawdown exceeds limit"}
+	ErrDailyLossExceeded             = &LimitError{Limit: LimitMaxDailyLoss, msg: "daily loss exceeds limit"}
+	ErrMarginLevelTooLow             = &LimitError{Limit: LimitMinMarginLevel, msg: "margin level below limit"}
+	ErrConcentrationExceeded         = &LimitError{Limit: LimitMaxConcentration, msg: "concentration exceeds limit"}
+	ErrCategoryConcentrationExceeded = &LimitError{Limit: LimitMaxCategoryConcentration, msg: "category concentration exceeds limit"}
+	ErrLeverageExceeded              = &LimitError{Limit: LimitMaxLeverage, msg: "leverage exceeds limit"}
+	ErrLiquidityTooLow               = &LimitError{Limit: LimitMinLiquidityToMarketCap, msg: "liquidity to market cap ratio below limit"}
+	ErrSlippageExceeded              = &LimitError{Limit: LimitMaxSlippage, msg: "slippage exceeds limit"}
+	ErrSocialScoreTooLow             = &LimitError{Limit: LimitMinSocialScore, msg: "social score below limit"}
+	ErrSocialScoreDeclining          = &LimitError{Limit: LimitMaxSocialScoreDecline, msg: "social score declining faster than limit"}
+	ErrBookImbalanced                = &LimitError{Limit: LimitMaxBookImbalance, msg: "order book imbalance exceeds limit"}
+	ErrGasToNotionalExceeded         = &LimitError{Limit: LimitMaxGasToNotional, msg: "gas to notional ratio exceeds limit"}
+	ErrPriceImpactExceeded           = &LimitError{Limit: LimitMaxPriceImpact, msg: "price impact exceeds limit"}
+	ErrSpreadExceeded                = &LimitError{Limit: LimitMaxSpread, msg: "spread exceeds limit"}
+	ErrPoolShareExceeded             = &LimitError{Limit: LimitMaxPoolShare, msg: "pool share exceeds limit"}
+)
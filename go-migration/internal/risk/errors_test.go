@@ -0,0 +1,44 @@
+package risk
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+func TestLimitError(t *testing.T) {
+	ctx := context.Background()
+	limits := testLimits()
+	limits.MaxPositionSize = 100
+	manager := NewManager(limits, zap.NewNop())
+
+	err := manager.CheckOrderRisk(ctx, &types.Order{Quantity: 150})
+	require.Error(t, err)
+	assert.Equal(t, "order size exceeds limit: 150.000000 > 100.000000", err.Error())
+
+	var limitErr *LimitError
+	require.True(t, errors.As(err, &limitErr))
+	assert.Equal(t, 150.0, limitErr.Observed)
+	assert.Equal(t, 100.0, limitErr.Threshold)
+	assert.ErrorIs(t, err, ErrPositionSizeExceeded)
+	assert.NotErrorIs(t, err, ErrDrawdownExceeded)
+
+	t.Run("Wrapped", func(t *testing.T) {
+		limits := testLimits()
+		limits.MaxConcentration = 0.5
+		manager := NewManager(limits, zap.NewNop())
+
+		err := manager.CheckProposedPortfolio(ctx, nil, []*types.Order{
+			{ID: "o1", Symbol: "AAA/SOL", Side: types.OrderSideBuy, Quantity: 1, Price: 1},
+		})
+		require.ErrorIs(t, err, ErrConcentrationExceeded)
+		require.True(t, errors.As(err, &limitErr))
+		assert.Equal(t, 1.0, limitErr.Observed)
+	})
+}
@@ -0,0 +1,63 @@
+package risk
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"go.uber.org/zap"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+// GasEstimator estimates the network gas a swap for order will cost, in
+// the same quote currency as its price
+type GasEstimator interface {
+	EstimateGas(ctx context.Context, order *types.Order) (float64, error)
+}
+
+// GasEstimatorFunc adapts a function to GasEstimator
+type GasEstimatorFunc func(ctx context.Context, order *types.Order) (float64, error)
+
+func (f GasEstimatorFunc) EstimateGas(ctx context.Context, order *types.Order) (float64, error) {
+	return f(ctx, order)
+}
+
+// SetGasEstimator sets the estimator used by the MaxGasToNotional check
+func (m *Manager) SetGasEstimator(estimator GasEstimator) {
+	m.gas = estimator
+}
+
+// checkGas rejects orders whose estimated gas is more than
+// MaxGasToNotional of their notional. Orders without a price use the mark
+// price and are skipped when there is none. A failed estimate rejects the
+// order rather than letting an unpriced swap through.
+func (m *Manager) checkGas(ctx context.Context, order *types.Order, fields []zap.Field) error {
+	max := m.limits.MaxGasToNotional
+	if max <= 0 || m.gas == nil {
+		return nil
+	}
+
+	price := order.Price
+	if price <= 0 && m.markPrices != nil {
+		if mark, err := m.markPrices.MarkPrice(order.Symbol); err == nil {
+			price = mark
+		}
+	}
+	notional := math.Abs(order.Quantity * price)
+	if notional <= 0 {
+		return nil
+	}
+
+	gas, err := m.gas.EstimateGas(ctx, order)
+	if err != nil {
+		return fmt.Errorf("failed to estimate gas for order %s: %w", order.ID, err)
+	}
+	ratio := gas / notional
+	if ratio > max {
+		return newLimitError(LimitMaxGasToNotional, ratio, max,
+			"gas is too large for order notional: %f of %f (%f > %f)", gas, notional, ratio, max)
+	}
+	m.warnNearMax(LimitMaxGasToNotional, ratio, max, fields...)
+	return nil
+}
@@ -0,0 +1,62 @@
+package risk
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+func TestManager_CheckGas(t *testing.T) {
+	limits := testLimits()
+	limits.MaxGasToNotional = 0.01
+	manager := NewManager(limits, zap.NewNop())
+	manager.SetGasEstimator(GasEstimatorFunc(func(ctx context.Context, order *types.Order) (float64, error) {
+		return 0.05, nil
+	}))
+
+	order := func(qty float64) *types.Order {
+		return &types.Order{ID: "o1", UserID: "user1", Symbol: "TEST/SOL",
+			Side: types.OrderSideBuy, Type: types.OrderTypeLimit, Price: 1, Quantity: qty}
+	}
+
+	err := manager.CheckOrderRisk(context.Background(), order(2))
+	var limitErr *LimitError
+	require.True(t, errors.As(err, &limitErr), "micro-swap should be rejected, got %v", err)
+	assert.Equal(t, LimitMaxGasToNotional, limitErr.Limit)
+	assert.InDelta(t, 0.025, limitErr.Observed, 1e-9)
+
+	assert.NoError(t, manager.CheckOrderRisk(context.Background(), order(10)))
+
+	t.Run("MarkPrice", func(t *testing.T) {
+		manager := NewManager(limits, zap.NewNop())
+		resolver := NewMarkPriceResolver(MarkPriceLast)
+		resolver.UpdateLast("TEST/SOL", 0.5)
+		manager.SetMarkPriceResolver(resolver)
+		manager.SetGasEstimator(GasEstimatorFunc(func(ctx context.Context, order *types.Order) (float64, error) {
+			return 0.05, nil
+		}))
+
+		market := order(8)
+		market.Type, market.Price = types.OrderTypeMarket, 0
+		assert.Error(t, manager.CheckOrderRisk(context.Background(), market))
+	})
+
+	t.Run("EstimateFails", func(t *testing.T) {
+		manager := NewManager(limits, zap.NewNop())
+		manager.SetGasEstimator(GasEstimatorFunc(func(ctx context.Context, order *types.Order) (float64, error) {
+			return 0, errors.New("rpc unavailable")
+		}))
+		assert.Error(t, manager.CheckOrderRisk(context.Background(), order(10)))
+	})
+
+	t.Run("NoEstimator", func(t *testing.T) {
+		manager := NewManager(limits, zap.NewNop())
+		assert.NoError(t, manager.CheckOrderRisk(context.Background(), order(2)))
+	})
+}
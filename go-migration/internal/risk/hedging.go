@@ -0,0 +1,73 @@
+package risk
+
+import (
+	"math"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+// CorrelationMatrix holds pairwise return correlations between symbols.
+// Each pair needs to be present under only one of its symbols.
+type CorrelationMatrix map[string]map[string]float64
+
+// SetCorrelations sets the correlations used to net hedged exposure when
+// Limits.NetCorrelatedExposure is on
+func (m *Manager) SetCorrelations(matrix CorrelationMatrix) {
+	corr := make(CorrelationMatrix, len(matrix))
+	for symbol, row := range matrix {
+		corr[symbol] = make(map[string]float64, len(row))
+		for other, rho := range row {
+			corr[symbol][other] = rho
+		}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.corr = corr
+}
+
+// correlation returns the correlation between a and b and whether it is
+// known. Must be called with m.mu held.
+func (m *Manager) correlation(a, b string) (float64, bool) {
+	if a == b {
+		return 1, true
+	}
+	if rho, ok := m.corr[a][b]; ok {
+		return rho, true
+	}
+	rho, ok := m.corr[b][a]
+	return rho, ok
+}
+
+// NetExposure returns the correlation-adjusted exposure of positions, the
+// square root of their signed market values weighted by their
+// correlations. A
+// long hedged by a short in a correlated symbol nets down toward zero.
+// Pairs without a known correlation get no netting credit: they add up as
+// gross exposure, whatever their sides.
+func (m *Manager) NetExposure(positions []*types.Position) float64 {
+	bySymbol := make(map[string]float64)
+	var symbols []string
+	for _, pos := range positions {
+		if _, seen := bySymbol[pos.Symbol]; !seen {
+			symbols = append(symbols, pos.Symbol)
+		}
+		bySymbol[pos.Symbol] += pos.Quantity*pos.AvgPrice + pos.UnrealizedPnL
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	variance := 0.0
+	for _, a := range symbols {
+		for _, b := range symbols {
+			wa, wb := bySymbol[a], bySymbol[b]
+			rho, ok := m.correlation(a, b)
+			if !ok {
+				rho = math.Copysign(1, wa*wb)
+			}
+			variance += wa * wb * math.Max(-1, math.Min(rho, 1))
+		}
+	}
+	return math.Sqrt(math.Max(variance, 0))
+}
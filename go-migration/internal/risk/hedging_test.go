@@ -0,0 +1,52 @@
+package risk
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+func TestManager_NetCorrelatedExposure(t *testing.T) {
+	ctx := context.Background()
+	limits := testLimits()
+	limits.MaxLeverage = 1.2
+	balance := BalanceSourceFunc(func(userID string) (float64, error) { return 150, nil })
+	manager := NewManager(limits, zap.NewNop())
+	manager.SetBalanceSource(balance)
+
+	// A long hedged by a short in a closely correlated token: 140 gross
+	// market value against 90 equity
+	book := []*types.Position{
+		{Symbol: "AAA/SOL", Quantity: 10, AvgPrice: 10, UnrealizedPnL: -60},
+		{Symbol: "BBB/SOL", Quantity: -10, AvgPrice: 10},
+	}
+	assert.ErrorIs(t, manager.CheckPortfolioRisk(ctx, book), ErrLeverageExceeded)
+
+	limits.NetCorrelatedExposure = true
+	manager = NewManager(limits, zap.NewNop())
+	manager.SetBalanceSource(balance)
+
+	// Without a known correlation the legs get no netting credit
+	assert.InDelta(t, 140, manager.NetExposure(book), 1e-9)
+	assert.ErrorIs(t, manager.CheckPortfolioRisk(ctx, book), ErrLeverageExceeded)
+
+	manager.SetCorrelations(CorrelationMatrix{"AAA/SOL": {"BBB/SOL": 0.95}})
+	assert.InDelta(t, 63.2455532, manager.NetExposure(book), 1e-6)
+	assert.NoError(t, manager.CheckPortfolioRisk(ctx, book))
+
+	// The same correlation adds up two longs instead of netting them
+	book[1].Quantity = 10
+	assert.Greater(t, manager.NetExposure(book), 130.0)
+	assert.ErrorIs(t, manager.CheckPortfolioRisk(ctx, book), ErrLeverageExceeded)
+
+	// Clones keep their own copy of the correlations
+	clone := manager.Clone()
+	manager.SetCorrelations(nil)
+	book[1].Quantity = -10
+	assert.NoError(t, clone.CheckPortfolioRisk(ctx, book))
+	assert.ErrorIs(t, manager.CheckPortfolioRisk(ctx, book), ErrLeverageExceeded)
+}
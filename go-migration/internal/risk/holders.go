@@ -0,0 +1,75 @@
+package risk
+
+import (
+	"math"
+)
+
+// HolderCurve maps a token's holder count to the fraction of the size
+// range it is allowed, from 0 with no holders to 1 at fullSizeHolders
+type HolderCurve func(holders, fullSizeHolders int) float64
+
+// LogHolderCurve grows quickly over the first holders and flattens out,
+// since each extra holder matters less the more a token already has
+func LogHolderCurve(holders, fullSizeHolders int) float64 {
+	return math.Log1p(float64(holders)) / math.Log1p(float64(fullSizeHolders))
+}
+
+// LinearHolderCurve grows in proportion to the holder count
+func LinearHolderCurve(holders, fullSizeHolders int) float64 {
+	return float64(holders) / float64(fullSizeHolders)
+}
+
+// HolderScaling shrinks the order size limit for tokens with few holders,
+// which are easier to manipulate and harder to exit
+type HolderScaling struct {
+	// FullSizeHolders is the holder count from which the full
+	// MaxPositionSize applies; zero disables scaling
+	FullSizeHolders int `json:"full_size_holders"`
+	// MinFraction of MaxPositionSize is allowed however few holders a
+	// token has, and for tokens with no recorded holder count
+	MinFraction float64 `json:"min_fraction"`
+	// Curve shapes the size between the two; nil uses LogHolderCurve
+	Curve HolderCurve `json:"-"`
+}
+
+// Fraction returns the fraction of MaxPositionSize allowed with holders
+func (s HolderScaling) Fraction(holders int) float64 {
+	if s.FullSizeHolders <= 0 || holders >= s.FullSizeHolders {
+		return 1
+	}
+	curve := s.Curve
+	if curve == nil {
+		curve = LogHolderCurve
+	}
+	progress := math.Min(math.Max(curve(max(holders, 0), s.FullSizeHolders), 0), 1)
+	return s.MinFraction + (1-s.MinFraction)*progress
+}
+
+// RecordHolders records the current holder count of symbol for holder
+// scaling
+func (m *Manager) RecordHolders(symbol string, holders int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.holders[symbol] = holders
+}
+
+// maxOrderSize returns the order size limit for symbol: the session limit
+// scaled down by its holder count
+func (m *Manager) maxOrderSize(symbol string) (float64, error) {
+	maxSize, err := m.sessionMaxPositionSize(symbol)
+	if err != nil {
+		return 0, err
+	}
+	scaling := m.limits.HolderScaling
+	if scaling.FullSizeHolders <= 0 || maxSize == Unlimited {
+		return maxSize, nil
+	}
+
+	m.mu.Lock()
+	holders, known := m.holders[symbol]
+	m.mu.Unlock()
+	if !known {
+		return maxSize * scaling.MinFraction, nil
+	}
+	return maxSize * scaling.Fraction(holders), nil
+}
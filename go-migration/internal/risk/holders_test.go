@@ -0,0 +1,59 @@
+package risk
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+func TestManager_HolderScaling(t *testing.T) {
+	limits := testLimits()
+	limits.HolderScaling = HolderScaling{FullSizeHolders: 5000, MinFraction: 0.1}
+	manager := NewManager(limits, zap.NewNop())
+	manager.RecordHolders("THIN/SOL", 50)
+	manager.RecordHolders("WIDE/SOL", 5000)
+
+	allowed := func(symbol string) float64 {
+		qty, binding := manager.MaxQuantity(context.Background(),
+			&types.Order{Symbol: symbol, Side: types.OrderSideBuy, Price: 1}, nil, 1e9, 1e9)
+		assert.Equal(t, LimitMaxPositionSize, binding)
+		return qty
+	}
+
+	thin := allowed("THIN/SOL")
+	expected := 1000 * (0.1 + 0.9*math.Log1p(50)/math.Log1p(5000))
+	assert.InDelta(t, expected, thin, 1e-9)
+	assert.InDelta(t, 1000.0, allowed("WIDE/SOL"), 1e-9)
+	assert.InDelta(t, 100.0, allowed("UNKNOWN/SOL"), 1e-9, "unknown holder counts get the minimum")
+
+	order := &types.Order{ID: "o1", UserID: "user1", Symbol: "THIN/SOL", Side: types.OrderSideBuy,
+		Type: types.OrderTypeLimit, Price: 1, Quantity: 600}
+	err := manager.CheckOrderRisk(context.Background(), order)
+	var limitErr *LimitError
+	require.True(t, errors.As(err, &limitErr))
+	assert.Equal(t, LimitMaxPositionSize, limitErr.Limit)
+	order.Symbol = "WIDE/SOL"
+	assert.NoError(t, manager.CheckOrderRisk(context.Background(), order))
+
+	t.Run("Curve", func(t *testing.T) {
+		scaling := HolderScaling{FullSizeHolders: 1000, Curve: LinearHolderCurve}
+		assert.InDelta(t, 0.25, scaling.Fraction(250), 1e-9)
+		assert.Equal(t, 1.0, scaling.Fraction(2000))
+		assert.Equal(t, 1.0, HolderScaling{}.Fraction(1), "zero scaling is disabled")
+	})
+
+	t.Run("Validate", func(t *testing.T) {
+		limits := testLimits()
+		limits.HolderScaling = HolderScaling{FullSizeHolders: 100, MinFraction: 1.5}
+		assert.Error(t, limits.Validate())
+		limits.HolderScaling = HolderScaling{FullSizeHolders: -1}
+		assert.Error(t, limits.Validate())
+	})
+}
@@ -0,0 +1,44 @@
+package risk
+
+import (
+	"go.uber.org/zap"
+
+	"github.com/kwanRoshi/B/go-migration/internal/metrics"
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+// CheckHoldingPeriods logs an alert for every open position held longer
+// than Limits.MaxHoldingPeriod and returns them. A position's age runs
+// from its OpenedAt, or from its last update when that isn't set.
+func (m *Manager) CheckHoldingPeriods(positions []*types.Position) []*types.Position {
+	maxAge := m.limits.MaxHoldingPeriod
+	if maxAge <= 0 {
+		return nil
+	}
+
+	now := m.now()
+	var stale []*types.Position
+	for _, pos := range positions {
+		if pos.Quantity == 0 {
+			continue
+		}
+		opened := pos.OpenedAt
+		if opened.IsZero() {
+			opened = pos.UpdatedAt
+		}
+		age := now.Sub(opened)
+		if age <= maxAge {
+			continue
+		}
+
+		stale = append(stale, pos)
+		metrics.RiskStalePositions.Inc()
+		m.logger.Warn("Position exceeds max holding period",
+			zap.String("user_id", pos.UserID),
+			zap.String("symbol", pos.Symbol),
+			zap.Float64("quantity", pos.Quantity),
+			zap.Duration("age", age),
+			zap.Duration("max_holding_period", maxAge))
+	}
+	return stale
+}
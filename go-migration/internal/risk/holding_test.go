@@ -0,0 +1,46 @@
+package risk
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+func TestManager_CheckHoldingPeriods(t *testing.T) {
+	core, logs := observer.New(zapcore.WarnLevel)
+	limits := testLimits()
+	limits.MaxHoldingPeriod = 12 * time.Hour
+	manager := NewManager(limits, zap.New(core))
+
+	now := time.Date(2024, 1, 2, 9, 0, 0, 0, time.UTC)
+	manager.now = func() time.Time { return now }
+
+	positions := []*types.Position{
+		{Symbol: "BAG/SOL", Quantity: 100, UpdatedAt: now.Add(-20 * time.Hour)},
+		{Symbol: "FRESH/SOL", Quantity: 5, UpdatedAt: now.Add(-time.Hour)},
+		{Symbol: "FLAT/SOL", Quantity: 0, UpdatedAt: now.Add(-48 * time.Hour)},
+		// Added to recently, but opened long ago
+		{Symbol: "OLD/SOL", Quantity: 3, OpenedAt: now.Add(-30 * time.Hour), UpdatedAt: now.Add(-time.Minute)},
+	}
+
+	stale := manager.CheckHoldingPeriods(positions)
+	require.Len(t, stale, 2)
+	assert.Equal(t, "BAG/SOL", stale[0].Symbol)
+	assert.Equal(t, "OLD/SOL", stale[1].Symbol)
+
+	alerts := logs.FilterMessage("Position exceeds max holding period").All()
+	require.Len(t, alerts, 2)
+	assert.Equal(t, "BAG/SOL", alerts[0].ContextMap()["symbol"])
+
+	t.Run("Disabled", func(t *testing.T) {
+		manager := NewManager(testLimits(), zap.NewNop())
+		assert.Empty(t, manager.CheckHoldingPeriods(positions))
+	})
+}
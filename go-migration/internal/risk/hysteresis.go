@@ -0,0 +1,68 @@
+package risk
+
+// hysteresisKey identifies the subject of a repeated limit check, such as
+// a position's symbol or an account's user ID
+type hysteresisKey struct {
+	limit   string
+	subject string
+}
+
+// exceedsMax reports whether value breaches the maximum limit for
+// subject, and the threshold it was compared against. With a hysteresis
+// buffer for the limit, a subject that last passed only fails above
+// max*(1+buffer) and one that last failed only passes again at or below
+// max*(1-buffer), so values jittering around the limit don't flip the
+// outcome on every check. Only live checks update the subject's state;
+// what-if checks read it without moving it.
+func (m *Manager) exceedsMax(limit, subject string, value, max float64, live bool) (bool, float64) {
+	buffer := m.limits.HysteresisBuffers[limit]
+	if buffer <= 0 {
+		return value > max, max
+	}
+
+	key := hysteresisKey{limit: limit, subject: subject}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	threshold := max
+	if failed, seen := m.hysteresis[key]; seen {
+		if failed {
+			threshold = max * (1 - buffer)
+		} else {
+			threshold = max * (1 + buffer)
+		}
+	}
+	breached := value > threshold
+	if live {
+		m.hysteresis[key] = breached
+	}
+	return breached, threshold
+}
+
+// belowMin is exceedsMax for minimum limits: a subject that last passed
+// only fails below min*(1-buffer) and one that last failed only passes
+// again at or above min*(1+buffer)
+func (m *Manager) belowMin(limit, subject string, value, min float64, live bool) (bool, float64) {
+	buffer := m.limits.HysteresisBuffers[limit]
+	if buffer <= 0 {
+		return value < min, min
+	}
+
+	key := hysteresisKey{limit: limit, subject: subject}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	threshold := min
+	if failed, seen := m.hysteresis[key]; seen {
+		if failed {
+			threshold = min * (1 + buffer)
+		} else {
+			threshold = min * (1 - buffer)
+		}
+	}
+	breached := value < threshold
+	if live {
+		m.hysteresis[key] = breached
+	}
+	return breached, threshold
+}
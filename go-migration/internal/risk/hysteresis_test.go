@@ -0,0 +1,103 @@
+package risk
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+func TestManager_HysteresisBuffer(t *testing.T) {
+	ctx := context.Background()
+	limits := testLimits()
+	limits.MaxDrawdown = 0.1
+	plain := NewManager(limits, zap.NewNop())
+	limits.HysteresisBuffers = map[string]float64{LimitMaxDrawdown: 0.05}
+	manager := NewManager(limits, zap.NewNop())
+
+	// Drawdown of pnl/1000 on a notional of 1000
+	check := func(m *Manager, pnl float64) error {
+		return m.CheckPositionRisk(ctx, &types.Position{Symbol: "TEST/SOL", Quantity: 10, AvgPrice: 100, UnrealizedPnL: pnl})
+	}
+
+	// Oscillating just around the 10% limit flips the plain manager every
+	// time but leaves the buffered one passing
+	for _, pnl := range []float64{-98, -102, -99, -104, -97, -103} {
+		assert.NoError(t, check(manager, pnl), "pnl %v", pnl)
+	}
+	assert.NoError(t, check(plain, -99))
+	assert.ErrorIs(t, check(plain, -102), ErrDrawdownExceeded)
+	assert.NoError(t, check(plain, -99))
+
+	// Past the buffer it fails, and then stays failed while jittering
+	assert.ErrorIs(t, check(manager, -106), ErrDrawdownExceeded)
+	for _, pnl := range []float64{-99, -102, -96, -101} {
+		assert.ErrorIs(t, check(manager, pnl), ErrDrawdownExceeded, "pnl %v", pnl)
+	}
+
+	// Recovering below the buffer passes again, and a profit resets it
+	assert.NoError(t, check(manager, -94))
+	assert.NoError(t, check(manager, -102))
+	assert.NoError(t, check(manager, 10))
+
+	limits.HysteresisBuffers[LimitMaxDrawdown] = 1
+	assert.Error(t, limits.Validate())
+}
+
+func TestManager_HysteresisMarginLevel(t *testing.T) {
+	ctx := context.Background()
+	limits := testLimits()
+	limits.MinMarginLevel = 150
+	limits.MaxDailyLoss = 1000
+	limits.HysteresisBuffers = map[string]float64{LimitMinMarginLevel: 0.1}
+	manager := NewManager(limits, zap.NewNop())
+
+	check := func(level float64) error {
+		return manager.CheckAccountRisk(ctx, &types.RiskMetrics{UserID: "user1", MarginLevel: level})
+	}
+
+	assert.NoError(t, check(151))
+	assert.NoError(t, check(140), "within the buffer below the minimum")
+	assert.ErrorIs(t, check(130), ErrMarginLevelTooLow)
+	assert.ErrorIs(t, check(160), ErrMarginLevelTooLow, "within the buffer above the minimum")
+	assert.NoError(t, check(170))
+}
+
+func TestManager_HysteresisWhatIf(t *testing.T) {
+	ctx := context.Background()
+	limits := testLimits()
+	limits.MaxDrawdown = 0.1
+	limits.HysteresisBuffers = map[string]float64{LimitMaxDrawdown: 0.05}
+	manager := NewManager(limits, zap.NewNop())
+
+	position := func(pnl float64) *types.Position {
+		return &types.Position{Symbol: "TEST/SOL", Quantity: 10, AvgPrice: 100, UnrealizedPnL: pnl}
+	}
+
+	// What-if checks past the buffer fail without latching the failure
+	require.NoError(t, manager.CheckPositionRisk(ctx, position(-98)))
+	stop, err := manager.ShouldStopOut(ctx, position(-106))
+	require.NoError(t, err)
+	assert.True(t, stop)
+	results := manager.CheckPositions(ctx, []*types.Position{position(-106)})
+	assert.ErrorIs(t, results[0].Err, ErrDrawdownExceeded)
+	assert.Error(t, manager.CheckProposedPortfolio(ctx, []*types.Position{position(-106)}, nil))
+	assert.NoError(t, manager.CheckPositionRisk(ctx, position(-102)), "the what-if checks left the state passing")
+
+	// The live failure latches, and the error reports the buffered
+	// threshold it was compared against
+	err = manager.CheckPositionRisk(ctx, position(-106))
+	var limitErr *LimitError
+	require.True(t, errors.As(err, &limitErr))
+	assert.InDelta(t, 0.105, limitErr.Threshold, 1e-9)
+	assert.Contains(t, err.Error(), "0.105")
+
+	err = manager.CheckPositionRisk(ctx, position(-99))
+	require.True(t, errors.As(err, &limitErr))
+	assert.InDelta(t, 0.095, limitErr.Threshold, 1e-9)
+}
@@ -0,0 +1,53 @@
+package risk
+
+import (
+	"math"
+
+	"go.uber.org/zap"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+// BookImbalance returns how heavily the book is stacked against side: ask
+// volume over bid volume for buys and bid over ask for sells. It is +Inf
+// when the order's own side of the book is empty and 0 when both are.
+func BookImbalance(side types.OrderSide, bidVolume, askVolume float64) float64 {
+	against, with := askVolume, bidVolume
+	if side == types.OrderSideSell {
+		against, with = bidVolume, askVolume
+	}
+	if with <= 0 {
+		if against <= 0 {
+			return 0
+		}
+		return math.Inf(1)
+	}
+	return against / with
+}
+
+// CheckBookImbalance rejects, or with BookImbalanceWarnOnly warns on,
+// orders trading into a book whose opposite side outweighs their own by
+// more than MaxBookImbalance. bidVolume and askVolume are the summed depth
+// on each side.
+func (m *Manager) CheckBookImbalance(order *types.Order, bidVolume, askVolume float64) error {
+	max := m.limits.MaxBookImbalance
+	if max <= 0 {
+		return nil
+	}
+
+	imbalance := BookImbalance(order.Side, bidVolume, askVolume)
+	if imbalance <= max {
+		return nil
+	}
+
+	if m.limits.BookImbalanceWarnOnly {
+		m.warn(LimitMaxBookImbalance, imbalance, max,
+			zap.String("order_id", order.ID),
+			zap.String("symbol", order.Symbol),
+			zap.String("side", string(order.Side)))
+		return nil
+	}
+	return newLimitError(LimitMaxBookImbalance, imbalance, max,
+		"order book imbalance against %s %s exceeds limit: %f > %f",
+		order.Side, order.Symbol, imbalance, max)
+}
@@ -0,0 +1,42 @@
+package risk
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+func TestManager_CheckBookImbalance(t *testing.T) {
+	limits := testLimits()
+	limits.MaxBookImbalance = 3
+	manager := NewManager(limits, zap.NewNop())
+
+	buy := &types.Order{ID: "buy1", Symbol: "TEST/SOL", Side: types.OrderSideBuy, Quantity: 1}
+	sell := &types.Order{ID: "sell1", Symbol: "TEST/SOL", Side: types.OrderSideSell, Quantity: 1}
+
+	// Asks outweigh bids five to one
+	bids, asks := 100.0, 500.0
+	assert.ErrorIs(t, manager.CheckBookImbalance(buy, bids, asks), ErrBookImbalanced)
+	assert.NoError(t, manager.CheckBookImbalance(sell, bids, asks), "sells into a bid-light book are not gated")
+	assert.ErrorIs(t, manager.CheckBookImbalance(sell, asks, bids), ErrBookImbalanced)
+	assert.NoError(t, manager.CheckBookImbalance(buy, 100, 250))
+
+	assert.True(t, math.IsInf(BookImbalance(types.OrderSideBuy, 0, 10), 1))
+	assert.Zero(t, BookImbalance(types.OrderSideBuy, 0, 0))
+
+	t.Run("WarnOnly", func(t *testing.T) {
+		core, logs := observer.New(zapcore.WarnLevel)
+		limits := limits
+		limits.BookImbalanceWarnOnly = true
+		manager := NewManager(limits, zap.New(core))
+
+		assert.NoError(t, manager.CheckBookImbalance(buy, bids, asks))
+		assert.Equal(t, 1, logs.FilterField(zap.String("limit", LimitMaxBookImbalance)).Len())
+	})
+}
@@ -0,0 +1,77 @@
+package risk
+
+import (
+	"fmt"
+	"math"
+
+	"go.uber.org/zap"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+// GraduationTier tightens MaxPriceImpact once a bonding curve has sold
+// Progress of its MaxSupply: the limit is multiplied by Factor. Liquidity
+// migrates at graduation, so impact estimates from the curve stop holding
+// close to it.
+type GraduationTier struct {
+	Progress float64 `json:"progress"`
+	Factor   float64 `json:"factor"`
+}
+
+// curveProgress returns the fraction of curve's max supply sold
+func curveProgress(curve *types.BondingCurve) float64 {
+	if curve == nil || curve.MaxSupply <= 0 {
+		return 0
+	}
+	return math.Min(float64(curve.Supply)/float64(curve.MaxSupply), 1)
+}
+
+// EffectiveMaxPriceImpact returns the price impact limit for symbol: the
+// base MaxPriceImpact times the tightest factor among the graduation tiers
+// its last seen bonding curve has reached
+func (m *Manager) EffectiveMaxPriceImpact(symbol string) float64 {
+	limit := m.limits.MaxPriceImpact
+	if limit <= 0 || len(m.limits.GraduationTiers) == 0 {
+		return limit
+	}
+
+	m.mu.Lock()
+	progress := m.graduation[symbol]
+	m.mu.Unlock()
+
+	factor := 1.0
+	for _, tier := range m.limits.GraduationTiers {
+		if progress >= tier.Progress {
+			factor = math.Min(factor, tier.Factor)
+		}
+	}
+	return limit * factor
+}
+
+// checkPriceImpact rejects orders whose expected price impact exceeds the
+// symbol's effective impact limit
+func (m *Manager) checkPriceImpact(order *types.Order, fields []zap.Field) error {
+	if m.limits.MaxPriceImpact <= 0 {
+		return nil
+	}
+
+	limit := m.EffectiveMaxPriceImpact(order.Symbol)
+	if order.PriceImpact > limit {
+		return newLimitError(LimitMaxPriceImpact, order.PriceImpact, limit,
+			"price impact exceeds limit: %f > %f", order.PriceImpact, limit)
+	}
+	m.warnNearMax(LimitMaxPriceImpact, order.PriceImpact, limit, fields...)
+	return nil
+}
+
+// validateGraduationTiers checks each tier's progress and factor are
+// fractions
+func validateGraduationTiers(tiers []GraduationTier) error {
+	for _, tier := range tiers {
+		if !(tier.Progress >= 0 && tier.Progress <= 1) || !(tier.Factor >= 0 && tier.Factor <= 1) {
+			return fmt.Errorf("invalid graduation tier: progress %v and factor %v must be in [0, 1]",
+				tier.Progress, tier.Factor)
+		}
+	}
+	return nil
+}
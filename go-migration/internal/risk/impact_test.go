@@ -0,0 +1,50 @@
+package risk
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+func TestManager_PriceImpactNearGraduation(t *testing.T) {
+	limits := testLimits()
+	limits.MaxPriceImpact = 0.1
+	limits.GraduationTiers = []GraduationTier{
+		{Progress: 0.8, Factor: 0.5},
+		{Progress: 0.9, Factor: 0.25},
+	}
+	require.NoError(t, limits.Validate())
+	manager := NewManager(limits, zap.NewNop())
+
+	check := func(supply int64) error {
+		curve := &types.BondingCurve{Symbol: "PUMP/SOL", BasePrice: 1, Slope: 0.0001, Supply: supply, MaxSupply: 10000}
+		order := &types.Order{ID: "o1", Symbol: "PUMP/SOL", Side: types.OrderSideBuy,
+			Type: types.OrderTypeMarket, Quantity: 10}
+		manager.ApplyBondingCurveImpact(order, curve)
+		order.PriceImpact = 0.05
+		return manager.CheckOrderRisk(context.Background(), order)
+	}
+
+	// The same order passes early in the curve and is rejected near graduation
+	assert.NoError(t, check(4000))
+	assert.InDelta(t, 0.1, manager.EffectiveMaxPriceImpact("PUMP/SOL"), 1e-9)
+
+	err := check(9500)
+	assert.ErrorIs(t, err, ErrPriceImpactExceeded)
+	assert.InDelta(t, 0.025, manager.EffectiveMaxPriceImpact("PUMP/SOL"), 1e-9)
+
+	// Between tiers only the reached one applies
+	assert.NoError(t, check(8500))
+	assert.InDelta(t, 0.05, manager.EffectiveMaxPriceImpact("PUMP/SOL"), 1e-9)
+
+	// Symbols without a curve use the base limit
+	assert.InDelta(t, 0.1, manager.EffectiveMaxPriceImpact("OTHER/SOL"), 1e-9)
+
+	limits.GraduationTiers = []GraduationTier{{Progress: 1.5, Factor: 0.5}}
+	assert.Error(t, limits.Validate())
+}
@@ -0,0 +1,70 @@
+package risk
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/kwanRoshi/B/go-migration/internal/metrics"
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+// ErrKillSwitchEngaged is returned for opening orders while the kill
+// switch is on
+var ErrKillSwitchEngaged = errors.New("kill switch engaged")
+
+// KillSwitch is the state of the global kill switch
+type KillSwitch struct {
+	Engaged bool      `json:"engaged"`
+	Reason  string    `json:"reason,omitempty"`
+	Since   time.Time `json:"since,omitempty"`
+}
+
+// SetKillSwitch turns the global kill switch on or off. While it is on,
+// CheckOrderRisk rejects every order that isn't reduce-only, whatever its
+// symbol or size, with an error carrying reason.
+func (m *Manager) SetKillSwitch(on bool, reason string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.setKillSwitch(on, reason)
+}
+
+// setKillSwitch updates the kill switch state and metric. Must be called
+// with m.mu held.
+func (m *Manager) setKillSwitch(on bool, reason string) {
+	if !on {
+		if m.kill.Engaged {
+			m.logger.Warn("Kill switch released", zap.String("reason", m.kill.Reason))
+		}
+		m.kill = KillSwitch{}
+		metrics.RiskKillSwitch.Set(0)
+		return
+	}
+
+	since := m.kill.Since
+	if !m.kill.Engaged {
+		since = m.now()
+	}
+	m.kill = KillSwitch{Engaged: true, Reason: reason, Since: since}
+	metrics.RiskKillSwitch.Set(1)
+	m.logger.Warn("Kill switch engaged", zap.String("reason", reason))
+}
+
+// KillSwitchState returns the current kill switch state
+func (m *Manager) KillSwitchState() KillSwitch {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.kill
+}
+
+// checkKillSwitch rejects orders that could add risk while the kill
+// switch is on. Only reduce-only orders are known to close.
+func (m *Manager) checkKillSwitch(order *types.Order) error {
+	kill := m.KillSwitchState()
+	if !kill.Engaged || order.ReduceOnly {
+		return nil
+	}
+	return fmt.Errorf("%w: %s", ErrKillSwitchEngaged, kill.Reason)
+}
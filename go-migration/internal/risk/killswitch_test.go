@@ -0,0 +1,47 @@
+package risk
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+func TestManager_KillSwitch(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	manager := NewManager(testLimits(), zap.NewNop())
+	manager.now = func() time.Time { return now }
+
+	open := &types.Order{ID: "open", UserID: "user1", Symbol: "TEST/SOL", Side: types.OrderSideBuy, Quantity: 1}
+	closing := &types.Order{ID: "close", UserID: "user1", Symbol: "TEST/SOL", Side: types.OrderSideSell,
+		Quantity: 1, ReduceOnly: true}
+
+	manager.SetKillSwitch(true, "RPC incident")
+	err := manager.CheckOrderRisk(ctx, open)
+	assert.ErrorIs(t, err, ErrKillSwitchEngaged)
+	assert.ErrorContains(t, err, "RPC incident")
+	assert.NoError(t, manager.CheckOrderRisk(ctx, closing), "closes still pass")
+
+	report, err := manager.Report(ctx, nil, &types.RiskMetrics{})
+	require.NoError(t, err)
+	assert.Equal(t, KillSwitch{Engaged: true, Reason: "RPC incident", Since: now}, report.KillSwitch)
+
+	// The switch survives a restart through the state store
+	store := NewMemoryStateStore()
+	manager.SetStateStore(store)
+	require.NoError(t, manager.SaveState(ctx))
+	restarted := NewManager(testLimits(), zap.NewNop())
+	restarted.SetStateStore(store)
+	require.NoError(t, restarted.LoadState(ctx))
+	assert.Equal(t, manager.KillSwitchState(), restarted.KillSwitchState())
+
+	manager.SetKillSwitch(false, "")
+	assert.NoError(t, manager.CheckOrderRisk(ctx, open))
+	assert.False(t, manager.KillSwitchState().Engaged)
+}
@@ -0,0 +1,69 @@
+package risk
+
+import (
+	"math"
+	"time"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+// NoLiquidation is returned by TimeToLiquidation when the position is not
+// expected to reach its liquidation price
+const NoLiquidation = time.Duration(math.MaxInt64)
+
+// liquidationSigmas is how many standard deviations of adverse noise
+// TimeToLiquidation assumes on top of the drift
+const liquidationSigmas = 2
+
+// TimeToLiquidation estimates how long position takes to reach liqPrice
+// from markPrice when the log price drifts by drift per day with daily
+// volatility volatility. The estimate is conservative: it is the time the
+// price reaches liqPrice moving by the drift plus two standard deviations
+// of noise against the position, and NoLiquidation when even that path
+// never gets there. Positions already at or past liqPrice, or with an
+// unusable mark price, return zero; a flat position or a zero liqPrice
+// returns NoLiquidation.
+func (m *Manager) TimeToLiquidation(position *types.Position, markPrice, drift, volatility, liqPrice float64) time.Duration {
+	if position.Quantity == 0 || liqPrice <= 0 || !isFinite(liqPrice) {
+		return NoLiquidation
+	}
+	if !isFinite(markPrice) || markPrice <= 0 || !isFinite(drift) || !isFinite(volatility) {
+		return 0
+	}
+
+	// distance is the adverse log move to liquidation and adverse the
+	// drift toward it, both positive when heading for liquidation
+	distance := math.Log(markPrice / liqPrice)
+	adverse := -(drift - volatility*volatility/2)
+	if position.Quantity < 0 {
+		distance, adverse = -distance, -adverse
+	}
+	if distance <= 0 {
+		return 0
+	}
+
+	// Solve adverse*t + k*volatility*sqrt(t) = distance for s = sqrt(t)
+	noise := liquidationSigmas * math.Abs(volatility)
+	var s float64
+	switch {
+	case adverse == 0:
+		if noise == 0 {
+			return NoLiquidation
+		}
+		s = distance / noise
+	case adverse > 0:
+		s = (-noise + math.Sqrt(noise*noise+4*adverse*distance)) / (2 * adverse)
+	default:
+		disc := noise*noise + 4*adverse*distance
+		if disc < 0 {
+			return NoLiquidation
+		}
+		s = (noise - math.Sqrt(disc)) / (-2 * adverse)
+	}
+
+	days := s * s
+	if days*float64(24*time.Hour) >= float64(NoLiquidation) {
+		return NoLiquidation
+	}
+	return time.Duration(days * float64(24*time.Hour))
+}
@@ -0,0 +1,51 @@
+package risk
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+func TestManager_TimeToLiquidation(t *testing.T) {
+	manager := NewManager(testLimits(), zap.NewNop())
+	long := &types.Position{Symbol: "TEST/SOL", Quantity: 10, AvgPrice: 100}
+	short := &types.Position{Symbol: "TEST/SOL", Quantity: -10, AvgPrice: 100}
+	day := float64(24 * time.Hour)
+
+	t.Run("DriftingToward", func(t *testing.T) {
+		// Without noise the price falls ln(2) per day and halves in a day
+		ttl := manager.TimeToLiquidation(long, 100, -math.Ln2, 0, 50)
+		assert.InDelta(t, day, float64(ttl), float64(time.Second))
+
+		// Noise only brings liquidation closer
+		noisy := manager.TimeToLiquidation(long, 100, -math.Ln2, 0.2, 50)
+		assert.Less(t, noisy, ttl)
+		assert.Positive(t, noisy)
+
+		// Rallies liquidate shorts
+		assert.InDelta(t, day, float64(manager.TimeToLiquidation(short, 50, math.Ln2, 0, 100)), float64(time.Second))
+	})
+
+	t.Run("DriftingAway", func(t *testing.T) {
+		assert.Equal(t, NoLiquidation, manager.TimeToLiquidation(long, 100, 0.5, 0, 50))
+		assert.Equal(t, NoLiquidation, manager.TimeToLiquidation(long, 100, 0.5, 0.1, 50),
+			"drift outruns the noise")
+		assert.Equal(t, NoLiquidation, manager.TimeToLiquidation(short, 50, -0.5, 0.1, 100))
+
+		// Enough noise can still reach liquidation against the drift
+		assert.NotEqual(t, NoLiquidation, manager.TimeToLiquidation(long, 100, 0.05, 0.5, 50))
+	})
+
+	t.Run("EdgeCases", func(t *testing.T) {
+		assert.Zero(t, manager.TimeToLiquidation(long, 40, 0, 0.1, 50), "already past liquidation")
+		assert.Zero(t, manager.TimeToLiquidation(long, 0, 0, 0.1, 50))
+		assert.Equal(t, NoLiquidation, manager.TimeToLiquidation(long, 100, -1, 0.1, 0))
+		assert.Equal(t, NoLiquidation, manager.TimeToLiquidation(&types.Position{}, 100, -1, 0.1, 50))
+		assert.Equal(t, NoLiquidation, manager.TimeToLiquidation(long, 100, 0, 0, 50))
+	})
+}
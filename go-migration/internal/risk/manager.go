@@ -4,10 +4,12 @@ import (
 	"context"
 	"fmt"
 	"math"
+	"sync"
 	"time"
 
 	"go.uber.org/zap"
 
+	"github.com/kwanRoshi/B/go-migration/internal/metrics"
 	"github.com/kwanRoshi/B/go-migration/internal/types"
 )
 
@@ -19,54 +21,384 @@ type Limits struct {
 	MaxLeverage      float64 `json:"max_leverage"`
 	MinMarginLevel   float64 `json:"min_margin_level"`
 	MaxConcentration float64 `json:"max_concentration"`
+
+	// MaxCategoryConcentration caps the share of exposure per category.
+	// Uncategorized positions count toward DefaultCategory.
+	MaxCategoryConcentration map[string]float64 `json:"max_category_concentration"`
+
+	// WarnRatio logs a warning when a value passes but is within this
+	// fraction of its limit (e.g. 0.8 warns at 80%). Zero disables
+	// warnings. WarnRatios overrides it per limit name.
+	WarnRatio  float64            `json:"warn_ratio"`
+	WarnRatios map[string]float64 `json:"warn_ratios"`
+
+	// CircuitBreaker halts new orders for a symbol after an extreme move
+	CircuitBreaker CircuitBreakerConfig `json:"circuit_breaker"`
+
+	// MinOrderInterval is the minimum time between accepted orders for the
+	// same user and symbol. MinOrderIntervals overrides it per symbol.
+	// Zero disables the cooldown.
+	MinOrderInterval  time.Duration            `json:"min_order_interval"`
+	MinOrderIntervals map[string]time.Duration `json:"min_order_intervals"`
+
+	// MaxHoldingPeriod alerts on open positions held for longer than
+	// this since they were opened. Zero disables the alert.
+	MaxHoldingPeriod time.Duration `json:"max_holding_period"`
+
+	// MinLiquidityToMarketCap rejects tokens whose liquidity is below this
+	// fraction of their market cap. Zero disables the check.
+	MinLiquidityToMarketCap float64 `json:"min_liquidity_to_market_cap"`
+
+	// MaxSlippage rejects orders whose expected slippage exceeds this
+	// fraction. SlippageScaling optionally widens or tightens it by the
+	// symbol's recent volatility. Zero disables the check.
+	MaxSlippage     float64         `json:"max_slippage"`
+	SlippageScaling SlippageScaling `json:"slippage_scaling"`
+
+	// MinSocialScore rejects tokens whose social score, time-weighted over
+	// SocialScoreWindow, is below it. MaxSocialScoreDecline also rejects
+	// tokens whose latest score has fallen more than this fraction from
+	// the start of the window. A zero window uses the latest score only;
+	// zero MinSocialScore disables both checks.
+	MinSocialScore        float64       `json:"min_social_score"`
+	SocialScoreWindow     time.Duration `json:"social_score_window"`
+	MaxSocialScoreDecline float64       `json:"max_social_score_decline"`
+
+	// MaxBookImbalance rejects buys when ask volume exceeds bid volume by
+	// more than this ratio, and sells the other way round. With
+	// BookImbalanceWarnOnly the order passes and a warning is logged
+	// instead. Zero disables the check.
+	MaxBookImbalance      float64 `json:"max_book_imbalance"`
+	BookImbalanceWarnOnly bool    `json:"book_imbalance_warn_only"`
+
+	// MarginRate is the fraction of position value held as margin. Zero
+	// uses DefaultMarginRate.
+	MarginRate float64 `json:"margin_rate"`
+
+	// Sessions restricts when orders are accepted, e.g. to reduce exposure
+	// on weekends; SymbolSessions overrides it per symbol. The zero
+	// schedule is always open.
+	Sessions       SessionSchedule            `json:"sessions"`
+	SymbolSessions map[string]SessionSchedule `json:"symbol_sessions"`
+
+	// HysteresisBuffers holds a fraction per limit name by which repeated
+	// position and account checks must move past the limit before their
+	// outcome flips, e.g. 0.05 for a drawdown limit of 0.1 fails at 0.105
+	// and passes again at 0.095. Unset limits flip exactly at the limit.
+	HysteresisBuffers map[string]float64 `json:"hysteresis_buffers"`
+
+	// MaxGasToNotional rejects DEX orders whose estimated gas, from the
+	// manager's GasEstimator, exceeds this fraction of their notional.
+	// Zero, or no estimator, disables the check.
+	MaxGasToNotional float64 `json:"max_gas_to_notional"`
+
+	// HolderScaling shrinks the order size limit for tokens with few
+	// holders, as recorded with RecordHolders
+	HolderScaling HolderScaling `json:"holder_scaling"`
+
+	// MaxPriceImpact rejects orders whose expected price impact exceeds
+	// this fraction. Orders in tokens with a curve recorded with
+	// RecordBondingCurve have their impact estimated from it.
+	// GraduationTiers tighten the limit for bonding curve tokens close to
+	// graduation, as last seen by ApplyBondingCurveImpact. Zero disables
+	// the check.
+	MaxPriceImpact  float64          `json:"max_price_impact"`
+	GraduationTiers []GraduationTier `json:"graduation_tiers"`
+
+	// MaxPoolShare rejects orders that take more than this fraction of
+	// the base reserve of the symbol's pool, as recorded with RecordPool.
+	// Orders against a recorded pool are also held to MaxPriceImpact at
+	// their constant-product impact. Zero disables the share check.
+	MaxPoolShare float64 `json:"max_pool_share"`
+
+	// MaxSpread rejects orders and flags positions in symbols whose last
+	// recorded bid-ask spread, as a fraction of the mid price, exceeds
+	// this. Zero disables the check.
+	MaxSpread float64 `json:"max_spread"`
+
+	// BaseCurrency is the currency MaxDailyLoss is set in. Daily PnL
+	// reported in another currency is converted to it before the check.
+	// Empty compares PnL as reported.
+	BaseCurrency string `json:"base_currency"`
+
+	// NetCorrelatedExposure measures leverage on the correlation-netted
+	// exposure from SetCorrelations instead of gross market value, so
+	// hedged books aren't held to the gross size of their legs. Leverage
+	// is measured against account equity and only checked once a
+	// BalanceSource is set.
+	NetCorrelatedExposure bool `json:"net_correlated_exposure"`
+}
+
+// DefaultCategory is the concentration bucket for uncategorized positions
+const DefaultCategory = "default"
+
+// Limit names used for warn ratio overrides and metrics
+const (
+	LimitMaxPositionSize = "max_position_size"
+	LimitMaxDrawdown     = "max_drawdown"
+	LimitMaxDailyLoss    = "max_daily_loss"
+	LimitMinMarginLevel  = "min_margin_level"
+
+	LimitMaxConcentration         = "max_concentration"
+	LimitMaxCategoryConcentration = "max_category_concentration"
+	LimitMaxLeverage              = "max_leverage"
+	LimitMinLiquidityToMarketCap  = "min_liquidity_to_market_cap"
+	LimitMaxSlippage              = "max_slippage"
+	LimitMinSocialScore           = "min_social_score"
+	LimitMaxSocialScoreDecline    = "max_social_score_decline"
+	LimitMaxBookImbalance         = "max_book_imbalance"
+	LimitMaxGasToNotional         = "max_gas_to_notional"
+	LimitMaxPriceImpact           = "max_price_impact"
+	LimitMaxSpread                = "max_spread"
+	LimitMaxPoolShare             = "max_pool_share"
+)
+
+// warnRatio returns the warn ratio configured for limit
+func (l Limits) warnRatio(limit string) float64 {
+	if ratio, ok := l.WarnRatios[limit]; ok {
+		return ratio
+	}
+	return l.WarnRatio
 }
 
 // Manager handles risk management
 type Manager struct {
-	logger *zap.Logger
-	limits Limits
+	logger     *zap.Logger
+	limits     Limits
+	markPrices *MarkPriceResolver
+	gas        GasEstimator
+	converter  CurrencyConverter
+	balances   BalanceSource
+	store      RiskStateStore
+	kill       KillSwitch
+	corr       CorrelationMatrix
+	precision  *MetricsPrecision
+	breakers   map[string]*symbolBreaker
+	lastOrders map[orderKey]time.Time
+	volatility map[string]float64
+	social     map[string][]scorePoint
+	hysteresis map[hysteresisKey]bool
+	holders    map[string]int
+	graduation map[string]float64
+	curves     map[string]types.BondingCurve
+	pools      map[string]PoolReserves
+	spreads    map[string]recordedSpread
+	violations []Violation
+	betaPolicy UnknownBetaPolicy
+	now        func() time.Time
+	mu         sync.Mutex
 }
 
 // NewManager creates a new risk manager
 func NewManager(limits Limits, logger *zap.Logger) *Manager {
 	return &Manager{
-		logger: logger,
-		limits: limits,
+		logger:     logger,
+		limits:     limits,
+		breakers:   make(map[string]*symbolBreaker),
+		lastOrders: make(map[orderKey]time.Time),
+		volatility: make(map[string]float64),
+		social:     make(map[string][]scorePoint),
+		hysteresis: make(map[hysteresisKey]bool),
+		holders:    make(map[string]int),
+		graduation: make(map[string]float64),
+		curves:     make(map[string]types.BondingCurve),
+		pools:      make(map[string]PoolReserves),
+		spreads:    make(map[string]recordedSpread),
+		betaPolicy: UnknownBetaMarket,
+		store:      NewMemoryStateStore(),
+		now:        time.Now,
 	}
 }
 
+// SetMarkPriceResolver sets the resolver used to mark positions before
+// position risk checks
+func (m *Manager) SetMarkPriceResolver(resolver *MarkPriceResolver) {
+	m.markPrices = resolver
+}
+
+// MarkPosition recomputes the position's unrealized PnL at the mark price.
+// It is a no-op when no resolver is configured.
+func (m *Manager) MarkPosition(position *types.Position) error {
+	if m.markPrices == nil || position.Quantity == 0 {
+		return nil
+	}
+
+	mark, err := m.markPrices.MarkPrice(position.Symbol)
+	if err != nil {
+		return fmt.Errorf("failed to resolve mark price: %w", err)
+	}
+	position.UnrealizedPnL = (mark - position.AvgPrice) * position.Quantity
+	return nil
+}
+
+// ShouldStopOut reports whether the position breaches risk limits at the
+// current mark price and must be closed. It reads but doesn't update
+// hysteresis state.
+func (m *Manager) ShouldStopOut(ctx context.Context, position *types.Position) (bool, error) {
+	if err := m.MarkPosition(position); err != nil {
+		return false, err
+	}
+
+	err := m.checkPositionRisk(ctx, position, false)
+	m.recordViolation(err)
+	if err != nil {
+		m.logger.Warn("Position breaches risk limits",
+			zap.String("symbol", position.Symbol),
+			zap.Float64("unrealized_pnl", position.UnrealizedPnL),
+			zap.Error(err))
+		return true, nil
+	}
+	return false, nil
+}
+
+// warnNearMax logs and counts a passing value within the warn band below
+// a maximum limit
+func (m *Manager) warnNearMax(limit string, value, max float64, fields ...zap.Field) {
+	ratio := m.limits.warnRatio(limit)
+	if ratio <= 0 || max <= 0 || value < max*ratio {
+		return
+	}
+	m.warn(limit, value, max, fields...)
+}
+
+// warnNearMin logs and counts a passing value within the warn band above
+// a minimum limit
+func (m *Manager) warnNearMin(limit string, value, min float64, fields ...zap.Field) {
+	ratio := m.limits.warnRatio(limit)
+	if ratio <= 0 || min <= 0 || value > min/ratio {
+		return
+	}
+	m.warn(limit, value, min, fields...)
+}
+
+func (m *Manager) warn(limit string, value, threshold float64, fields ...zap.Field) {
+	metrics.RiskLimitWarnings.WithLabelValues(limit).Inc()
+	m.logger.Warn("Approaching risk limit", append([]zap.Field{
+		zap.String("limit", limit),
+		zap.Float64("value", value),
+		zap.Float64("threshold", threshold),
+	}, fields...)...)
+}
+
 // CheckOrderRisk checks if an order complies with risk limits
 func (m *Manager) CheckOrderRisk(ctx context.Context, order *types.Order) error {
-	// Check order size
-	if order.Quantity > m.limits.MaxPositionSize {
-		return fmt.Errorf("order size exceeds limit: %f > %f",
-			order.Quantity, m.limits.MaxPositionSize)
+	return m.runOrderCheck(ctx, order, true)
+}
+
+// RevalidateOrderRisk re-checks an order that was already accepted, e.g.
+// before an aged order fills. It runs the CheckOrderRisk checks except the
+// order cooldown, which the order itself started.
+func (m *Manager) RevalidateOrderRisk(ctx context.Context, order *types.Order) error {
+	return m.runOrderCheck(ctx, order, false)
+}
+
+func (m *Manager) runOrderCheck(ctx context.Context, order *types.Order, cooldown bool) error {
+	fields := []zap.Field{
+		zap.String("order_id", order.ID),
+		zap.String("correlation_id", order.CorrelationID),
+		zap.String("symbol", order.Symbol),
 	}
 
+	err := m.checkOrderRisk(ctx, order, fields, cooldown)
+	if err != nil {
+		m.recordViolation(err)
+		m.logger.Info("Order failed risk check", append(fields, zap.Error(err))...)
+		return err
+	}
+	m.logger.Debug("Order passed risk check", fields...)
+	return nil
+}
+
+func (m *Manager) checkOrderRisk(ctx context.Context, order *types.Order, fields []zap.Field, cooldown bool) error {
+	if err := validateOrderInput(order); err != nil {
+		return err
+	}
+
+	if err := m.checkKillSwitch(order); err != nil {
+		return err
+	}
+	if err := m.checkHalted(order.Symbol); err != nil {
+		return err
+	}
+
+	// Check order size, which may be tighter outside trading sessions and
+	// for tokens with few holders
+	maxSize, err := m.maxOrderSize(order.Symbol)
+	if err != nil {
+		return err
+	}
+	if order.Quantity > maxSize {
+		return newLimitError(LimitMaxPositionSize, order.Quantity, maxSize,
+			"order size exceeds limit: %f > %f", order.Quantity, maxSize)
+	}
+	m.warnNearMax(LimitMaxPositionSize, order.Quantity, maxSize, fields...)
+
 	// TODO: Implement more order risk checks
 	// - Check margin requirements
 	// - Check concentration limits
 	// - Check daily loss limits
 
-	return nil
+	if err := m.checkSlippage(order, fields); err != nil {
+		return err
+	}
+	m.applyRecordedCurve(order)
+	if err := m.checkPriceImpact(order, fields); err != nil {
+		return err
+	}
+	if err := m.checkPool(order, fields); err != nil {
+		return err
+	}
+	if err := m.checkSpread(order.Symbol, fields...); err != nil {
+		return err
+	}
+	if err := m.checkGas(ctx, order, fields); err != nil {
+		return err
+	}
+
+	if !cooldown {
+		return nil
+	}
+	return m.checkCooldown(order.UserID, order.Symbol)
 }
 
 // CheckPositionRisk checks if a position complies with risk limits
-func (m *Manager) CheckPositionRisk(ctx context.Context, position *types.Position) error {
+func (m *Manager) CheckPositionRisk(ctx context.Context, position *types.Position) (err error) {
+	defer func() { m.recordViolation(err) }()
+	return m.checkPositionRisk(ctx, position, true)
+}
+
+// checkPositionRisk runs the position checks. Only live checks move the
+// hysteresis state of the position's limits.
+func (m *Manager) checkPositionRisk(ctx context.Context, position *types.Position, live bool) error {
+	if err := validatePositionInput(position); err != nil {
+		return err
+	}
+
 	// Check position size
-	if math.Abs(position.Quantity) > m.limits.MaxPositionSize {
-		return fmt.Errorf("position size exceeds limit: %f > %f",
-			math.Abs(position.Quantity), m.limits.MaxPositionSize)
-	}
-
-	// Check drawdown
-	if position.UnrealizedPnL < 0 {
-		drawdown := math.Abs(position.UnrealizedPnL) /
-			(math.Abs(position.AvgPrice * position.Quantity))
-		if drawdown > m.limits.MaxDrawdown {
-			return fmt.Errorf("drawdown exceeds limit: %f > %f",
-				drawdown, m.limits.MaxDrawdown)
+	size := math.Abs(position.Quantity)
+	if breached, threshold := m.exceedsMax(LimitMaxPositionSize, position.Symbol, size, m.limits.MaxPositionSize, live); breached {
+		return newLimitError(LimitMaxPositionSize, size, threshold,
+			"position size exceeds limit: %f > %f", size, threshold)
+	}
+	m.warnNearMax(LimitMaxPositionSize, size, m.limits.MaxPositionSize,
+		zap.String("symbol", position.Symbol))
+
+	// Check drawdown; a position with no notional has no meaningful one.
+	// Profitable positions are checked at zero so their hysteresis state
+	// resets.
+	notional := math.Abs(position.AvgPrice * position.Quantity)
+	if notional > 0 {
+		drawdown := math.Max(-position.UnrealizedPnL, 0) / notional
+		if breached, threshold := m.exceedsMax(LimitMaxDrawdown, position.Symbol, drawdown, m.limits.MaxDrawdown, live); breached {
+			return newLimitError(LimitMaxDrawdown, drawdown, threshold,
+				"drawdown exceeds limit: %f > %f", drawdown, threshold)
 		}
+		m.warnNearMax(LimitMaxDrawdown, drawdown, m.limits.MaxDrawdown,
+			zap.String("symbol", position.Symbol))
+	}
+
+	if err := m.checkSpread(position.Symbol, zap.String("symbol", position.Symbol)); err != nil {
+		return err
 	}
 
 	// TODO: Implement more position risk checks
@@ -78,18 +410,26 @@ func (m *Manager) CheckPositionRisk(ctx context.Context, position *types.Positio
 }
 
 // CheckAccountRisk checks overall account risk
-func (m *Manager) CheckAccountRisk(ctx context.Context, metrics *types.RiskMetrics) error {
-	// Check daily loss
-	if metrics.DailyPnL < -m.limits.MaxDailyLoss {
-		return fmt.Errorf("daily loss exceeds limit: %f < -%f",
-			metrics.DailyPnL, m.limits.MaxDailyLoss)
+func (m *Manager) CheckAccountRisk(ctx context.Context, metrics *types.RiskMetrics) (err error) {
+	defer func() { m.recordViolation(err) }()
+
+	// Check daily loss, in the base currency when one is configured
+	dailyPnL, err := m.dailyPnL(metrics)
+	if err != nil {
+		return err
 	}
+	if breached, threshold := m.exceedsMax(LimitMaxDailyLoss, metrics.UserID, -dailyPnL, m.limits.MaxDailyLoss, true); breached {
+		return newLimitError(LimitMaxDailyLoss, -dailyPnL, threshold,
+			"daily loss exceeds limit: %f < -%f", dailyPnL, threshold)
+	}
+	m.warnNearMax(LimitMaxDailyLoss, -dailyPnL, m.limits.MaxDailyLoss)
 
 	// Check margin level
-	if metrics.MarginLevel < m.limits.MinMarginLevel {
-		return fmt.Errorf("margin level below limit: %f < %f",
-			metrics.MarginLevel, m.limits.MinMarginLevel)
+	if breached, threshold := m.belowMin(LimitMinMarginLevel, metrics.UserID, metrics.MarginLevel, m.limits.MinMarginLevel, true); breached {
+		return newLimitError(LimitMinMarginLevel, metrics.MarginLevel, threshold,
+			"margin level below limit: %f < %f", metrics.MarginLevel, threshold)
 	}
+	m.warnNearMin(LimitMinMarginLevel, metrics.MarginLevel, m.limits.MinMarginLevel)
 
 	// TODO: Implement more account risk checks
 	// - Check total exposure
@@ -99,8 +439,20 @@ func (m *Manager) CheckAccountRisk(ctx context.Context, metrics *types.RiskMetri
 	return nil
 }
 
-// CalculateMetrics calculates risk metrics
+// CalculateMetrics calculates risk metrics, rounded to the configured
+// metrics precision if one is set
 func (m *Manager) CalculateMetrics(ctx context.Context, positions []*types.Position) (*types.RiskMetrics, error) {
+	metrics, err := m.CalculateRawMetrics(ctx, positions)
+	if err != nil || m.precision == nil {
+		return metrics, err
+	}
+	return m.precision.Round(metrics), nil
+}
+
+// CalculateRawMetrics calculates risk metrics at full precision. Equity
+// is the account's balance plus unrealized PnL when a BalanceSource is
+// set, and the positions' value otherwise.
+func (m *Manager) CalculateRawMetrics(ctx context.Context, positions []*types.Position) (*types.RiskMetrics, error) {
 	metrics := &types.RiskMetrics{
 		UserID:     "",
 		UpdateTime: time.Now(),
@@ -109,10 +461,15 @@ func (m *Manager) CalculateMetrics(ctx context.Context, positions []*types.Posit
 	// Calculate metrics from positions
 	for _, pos := range positions {
 		positionValue := math.Abs(pos.Quantity * pos.AvgPrice)
-		metrics.UsedMargin += positionValue * 0.1 // Example margin requirement
+		metrics.UsedMargin += positionValue * m.marginRate()
 		metrics.TotalEquity += positionValue + pos.UnrealizedPnL
 		metrics.DailyPnL += pos.UnrealizedPnL + pos.RealizedPnL
 	}
+	if equity, ok, err := m.accountEquity(positions); err != nil {
+		return nil, err
+	} else if ok {
+		metrics.TotalEquity = equity
+	}
 
 	metrics.AvailableMargin = metrics.TotalEquity - metrics.UsedMargin
 	if metrics.UsedMargin > 0 {
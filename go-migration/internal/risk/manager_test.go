@@ -0,0 +1,60 @@
+package risk
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+func TestManager_WarnRatio(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("WarnsWithoutRejecting", func(t *testing.T) {
+		core, logs := observer.New(zapcore.WarnLevel)
+		limits := testLimits()
+		limits.MaxPositionSize = 100
+		limits.WarnRatio = 0.8
+		manager := NewManager(limits, zap.New(core))
+
+		err := manager.CheckOrderRisk(ctx, &types.Order{ID: "o1", Symbol: "TEST/SOL", Quantity: 85})
+		require.NoError(t, err)
+
+		warnings := logs.FilterMessage("Approaching risk limit").All()
+		require.Len(t, warnings, 1)
+		assert.Equal(t, LimitMaxPositionSize, warnings[0].ContextMap()["limit"])
+
+		// Below the band nothing is logged
+		require.NoError(t, manager.CheckOrderRisk(ctx, &types.Order{ID: "o2", Quantity: 50}))
+		assert.Equal(t, 1, logs.FilterMessage("Approaching risk limit").Len())
+	})
+
+	t.Run("PerLimitOverride", func(t *testing.T) {
+		core, logs := observer.New(zapcore.WarnLevel)
+		limits := testLimits()
+		limits.MaxPositionSize = 100
+		limits.WarnRatio = 0.8
+		limits.WarnRatios = map[string]float64{LimitMaxPositionSize: 0.9}
+		manager := NewManager(limits, zap.New(core))
+
+		require.NoError(t, manager.CheckOrderRisk(ctx, &types.Order{Quantity: 85}))
+		assert.Zero(t, logs.Len())
+	})
+
+	t.Run("MinMarginLevel", func(t *testing.T) {
+		core, logs := observer.New(zapcore.WarnLevel)
+		limits := testLimits()
+		limits.MinMarginLevel = 100
+		limits.WarnRatio = 0.8
+		manager := NewManager(limits, zap.New(core))
+
+		require.NoError(t, manager.CheckAccountRisk(ctx, &types.RiskMetrics{MarginLevel: 120}))
+		assert.Equal(t, 1, logs.FilterMessage("Approaching risk limit").Len())
+	})
+}
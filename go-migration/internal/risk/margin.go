@@ -0,0 +1,51 @@
+package risk
+
+import (
+	"math"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+// DefaultMarginRate is the margin held per unit of position value when
+// Limits.MarginRate is unset
+const DefaultMarginRate = 0.1
+
+// marginRate returns the configured margin rate or the default
+func (m *Manager) marginRate() float64 {
+	if m.limits.MarginRate > 0 {
+		return m.limits.MarginRate
+	}
+	return DefaultMarginRate
+}
+
+// MarginImpact predicts how filling order changes current, valuing it the
+// way CalculateRawMetrics does: the order adds its margin to used margin
+// and a reduce-only order releases it. A fill swaps balance for position
+// value, so equity is left as it is; current should measure equity with
+// a BalanceSource. Orders without a price are valued at the mark price
+// when a resolver is set. It returns the change in used margin and the
+// margin level after the fill, or zero and the current level when the
+// order can't be priced.
+func (m *Manager) MarginImpact(order *types.Order, current *types.RiskMetrics) (usedDelta, newMarginLevel float64) {
+	price := order.Price
+	if price <= 0 && m.markPrices != nil {
+		if mark, err := m.markPrices.MarkPrice(order.Symbol); err == nil {
+			price = mark
+		}
+	}
+	if !isFinite(price) || price <= 0 || !isFinite(order.Quantity) || order.Quantity <= 0 {
+		return 0, current.MarginLevel
+	}
+
+	usedDelta = order.Quantity * price * m.marginRate()
+	if order.ReduceOnly {
+		usedDelta = -usedDelta
+	}
+	used := math.Max(current.UsedMargin+usedDelta, 0)
+	usedDelta = used - current.UsedMargin
+
+	if used > 0 {
+		newMarginLevel = current.TotalEquity / used * 100
+	}
+	return usedDelta, newMarginLevel
+}
@@ -0,0 +1,57 @@
+package risk
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+func TestManager_MarginImpact(t *testing.T) {
+	ctx := context.Background()
+	limits := testLimits()
+	limits.MarginRate = 0.2
+	manager := NewManager(limits, zap.NewNop())
+	manager.SetBalanceSource(BalanceSourceFunc(func(userID string) (float64, error) { return 2000, nil }))
+
+	held := &types.Position{Symbol: "HELD/SOL", Quantity: 10, AvgPrice: 100}
+	current, err := manager.CalculateRawMetrics(ctx, []*types.Position{held})
+	require.NoError(t, err)
+	assert.InDelta(t, 200.0, current.UsedMargin, 1e-9)
+
+	buy := &types.Order{Symbol: "NEW/SOL", Side: types.OrderSideBuy, Type: types.OrderTypeLimit, Price: 50, Quantity: 4}
+	usedDelta, level := manager.MarginImpact(buy, current)
+
+	after, err := manager.CalculateRawMetrics(ctx, []*types.Position{
+		held,
+		{Symbol: "NEW/SOL", Quantity: 4, AvgPrice: 50},
+	})
+	require.NoError(t, err)
+	assert.InDelta(t, after.UsedMargin-current.UsedMargin, usedDelta, 1e-9)
+	assert.InDelta(t, after.MarginLevel, level, 1e-9)
+
+	reduce := &types.Order{Symbol: "HELD/SOL", Side: types.OrderSideSell, Type: types.OrderTypeLimit,
+		Price: 100, Quantity: 4, ReduceOnly: true}
+	usedDelta, level = manager.MarginImpact(reduce, current)
+
+	after, err = manager.CalculateRawMetrics(ctx, []*types.Position{{Symbol: "HELD/SOL", Quantity: 6, AvgPrice: 100}})
+	require.NoError(t, err)
+	assert.InDelta(t, -80.0, usedDelta, 1e-9)
+	assert.InDelta(t, after.MarginLevel, level, 1e-9)
+
+	// A market order without a mark price can't be priced
+	market := &types.Order{Symbol: "NEW/SOL", Side: types.OrderSideBuy, Type: types.OrderTypeMarket, Quantity: 4}
+	usedDelta, level = manager.MarginImpact(market, current)
+	assert.Zero(t, usedDelta)
+	assert.Equal(t, current.MarginLevel, level)
+
+	resolver := NewMarkPriceResolver(MarkPriceLast)
+	resolver.UpdateLast("NEW/SOL", 50)
+	manager.SetMarkPriceResolver(resolver)
+	usedDelta, _ = manager.MarginImpact(market, current)
+	assert.InDelta(t, 40.0, usedDelta, 1e-9)
+}
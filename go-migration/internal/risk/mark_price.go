@@ -0,0 +1,103 @@
+package risk
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+// MarkPriceSource selects the price used to mark positions
+type MarkPriceSource string
+
+const (
+	// MarkPriceLast marks at the last traded price
+	MarkPriceLast MarkPriceSource = "last"
+	// MarkPriceMid marks at the order book mid price
+	MarkPriceMid MarkPriceSource = "mid"
+	// MarkPriceIndex marks at an externally supplied index price
+	MarkPriceIndex MarkPriceSource = "index"
+)
+
+// MarkPriceResolver tracks the latest prices per symbol and resolves the
+// mark price for the configured source
+type MarkPriceResolver struct {
+	source MarkPriceSource
+	last   map[string]float64
+	bids   map[string]float64
+	asks   map[string]float64
+	index  map[string]float64
+	mu     sync.RWMutex
+}
+
+// NewMarkPriceResolver creates a resolver for source
+func NewMarkPriceResolver(source MarkPriceSource) *MarkPriceResolver {
+	return &MarkPriceResolver{
+		source: source,
+		last:   make(map[string]float64),
+		bids:   make(map[string]float64),
+		asks:   make(map[string]float64),
+		index:  make(map[string]float64),
+	}
+}
+
+// Source returns the configured mark price source
+func (r *MarkPriceResolver) Source() MarkPriceSource {
+	return r.source
+}
+
+// UpdateLast records the last traded price for symbol
+func (r *MarkPriceResolver) UpdateLast(symbol string, price float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.last[symbol] = price
+}
+
+// UpdatePrice records the last traded price from a price update
+func (r *MarkPriceResolver) UpdatePrice(update *types.PriceUpdate) {
+	r.UpdateLast(update.Symbol, update.Price)
+}
+
+// UpdateQuote records the best bid and ask for symbol
+func (r *MarkPriceResolver) UpdateQuote(symbol string, bid, ask float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.bids[symbol] = bid
+	r.asks[symbol] = ask
+}
+
+// UpdateIndex records the external index price for symbol
+func (r *MarkPriceResolver) UpdateIndex(symbol string, price float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.index[symbol] = price
+}
+
+// MarkPrice returns the mark price for symbol from the configured source
+func (r *MarkPriceResolver) MarkPrice(symbol string) (float64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	switch r.source {
+	case MarkPriceLast:
+		price, ok := r.last[symbol]
+		if !ok || price <= 0 {
+			return 0, fmt.Errorf("no last price for %s", symbol)
+		}
+		return price, nil
+	case MarkPriceMid:
+		bid, ask := r.bids[symbol], r.asks[symbol]
+		if bid <= 0 || ask <= 0 || ask < bid {
+			return 0, fmt.Errorf("no valid quote for %s: bid %f ask %f", symbol, bid, ask)
+		}
+		return (bid + ask) / 2, nil
+	case MarkPriceIndex:
+		price, ok := r.index[symbol]
+		if !ok || price <= 0 {
+			return 0, fmt.Errorf("no index price for %s", symbol)
+		}
+		return price, nil
+	default:
+		return 0, fmt.Errorf("unknown mark price source: %s", r.source)
+	}
+}
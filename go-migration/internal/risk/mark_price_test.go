@@ -0,0 +1,102 @@
+package risk
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+func testLimits() Limits {
+	return Limits{
+		MaxPositionSize: 1000,
+		MaxDrawdown:     0.2,
+		MaxDailyLoss:    1000,
+		MinMarginLevel:  0,
+	}
+}
+
+func TestMarkPriceResolver(t *testing.T) {
+	t.Run("Last", func(t *testing.T) {
+		r := NewMarkPriceResolver(MarkPriceLast)
+		_, err := r.MarkPrice("TEST/SOL")
+		assert.Error(t, err)
+
+		r.UpdatePrice(&types.PriceUpdate{Symbol: "TEST/SOL", Price: 42})
+		price, err := r.MarkPrice("TEST/SOL")
+		require.NoError(t, err)
+		assert.Equal(t, 42.0, price)
+	})
+
+	t.Run("Mid", func(t *testing.T) {
+		r := NewMarkPriceResolver(MarkPriceMid)
+		r.UpdateQuote("TEST/SOL", 99, 101)
+		price, err := r.MarkPrice("TEST/SOL")
+		require.NoError(t, err)
+		assert.Equal(t, 100.0, price)
+
+		r.UpdateQuote("TEST/SOL", 101, 99)
+		_, err = r.MarkPrice("TEST/SOL")
+		assert.Error(t, err)
+	})
+
+	t.Run("Index", func(t *testing.T) {
+		r := NewMarkPriceResolver(MarkPriceIndex)
+		r.UpdateLast("TEST/SOL", 1)
+		_, err := r.MarkPrice("TEST/SOL")
+		assert.Error(t, err)
+
+		r.UpdateIndex("TEST/SOL", 100.5)
+		price, err := r.MarkPrice("TEST/SOL")
+		require.NoError(t, err)
+		assert.Equal(t, 100.5, price)
+	})
+}
+
+func TestManager_ShouldStopOut_MarkSource(t *testing.T) {
+	ctx := context.Background()
+
+	// A single bad print at 70 against a 99/101 book
+	feed := func(r *MarkPriceResolver) {
+		r.UpdateLast("TEST/SOL", 70)
+		r.UpdateQuote("TEST/SOL", 99, 101)
+	}
+
+	tests := []struct {
+		name     string
+		source   MarkPriceSource
+		stopOut  bool
+		unrealPL float64
+	}{
+		{"LastPrice", MarkPriceLast, true, -300},
+		{"MidPrice", MarkPriceMid, false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver := NewMarkPriceResolver(tt.source)
+			feed(resolver)
+
+			manager := NewManager(testLimits(), zap.NewNop())
+			manager.SetMarkPriceResolver(resolver)
+
+			position := &types.Position{Symbol: "TEST/SOL", Quantity: 10, AvgPrice: 100}
+			stopOut, err := manager.ShouldStopOut(ctx, position)
+			require.NoError(t, err)
+			assert.Equal(t, tt.stopOut, stopOut)
+			assert.InDelta(t, tt.unrealPL, position.UnrealizedPnL, 1e-9)
+		})
+	}
+
+	t.Run("MissingMark", func(t *testing.T) {
+		manager := NewManager(testLimits(), zap.NewNop())
+		manager.SetMarkPriceResolver(NewMarkPriceResolver(MarkPriceMid))
+
+		_, err := manager.ShouldStopOut(ctx, &types.Position{Symbol: "NONE/SOL", Quantity: 1, AvgPrice: 1})
+		assert.Error(t, err)
+	})
+}
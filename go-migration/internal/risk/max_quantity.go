@@ -0,0 +1,126 @@
+package risk
+
+import (
+	"context"
+	"errors"
+	"math"
+
+	"go.uber.org/zap"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+// Constraints MaxQuantity can report besides limit names
+const (
+	// ConstraintMargin binds when the order's margin would use up the
+	// available margin
+	ConstraintMargin = "available_margin"
+	// ConstraintPosition binds a reduce-only order at the size of the
+	// position it reduces
+	ConstraintPosition = "position"
+	// ConstraintPrice is reported when the order has no price and no mark
+	// price is available, so only zero is known to be safe
+	ConstraintPrice = "price"
+	// ConstraintBlocked is reported when no quantity passes for a reason
+	// other than a limit, e.g. while the symbol is halted or the kill
+	// switch is on
+	ConstraintBlocked = "blocked"
+)
+
+// errMarginExceeded fails MaxQuantity's dry runs over the available margin
+var errMarginExceeded = errors.New("order margin exceeds available margin")
+
+// MaxQuantity returns the largest quantity of template that passes every
+// order check, the position and portfolio checks on current with the
+// order filled, and the available margin, together with the constraint
+// that binds. Leverage is measured against equity. The quantity is found
+// by bisecting over dry runs on a clone, as Adjust does, so MaxQuantity
+// doesn't start a cooldown or record violations; template's own Quantity
+// is ignored. Orders without a price use the mark price. The search
+// assumes larger orders only fail more checks; zero is returned with the
+// failing limit, or ConstraintBlocked, when no quantity passes, e.g. for
+// a book already over a limit.
+func (m *Manager) MaxQuantity(ctx context.Context, template *types.Order, current []*types.Position, equity, availableMargin float64) (float64, string) {
+	price := template.Price
+	if price <= 0 && m.markPrices != nil {
+		if mark, err := m.markPrices.MarkPrice(template.Symbol); err == nil {
+			price = mark
+		}
+	}
+	if !isFinite(price) || price <= 0 {
+		return 0, ConstraintPrice
+	}
+
+	dryRun := m.Clone()
+	dryRun.logger = zap.NewNop()
+	unrealized := 0.0
+	for _, pos := range current {
+		unrealized += pos.UnrealizedPnL
+	}
+	dryRun.SetBalanceSource(BalanceSourceFunc(func(userID string) (float64, error) {
+		return equity - unrealized, nil
+	}))
+
+	check := func(qty float64) error {
+		order := *template
+		order.Quantity = qty
+		if err := dryRun.CheckOrderRisk(ctx, &order); err != nil {
+			return err
+		}
+		if !template.ReduceOnly && qty*price*m.marginRate() > availableMargin {
+			return errMarginExceeded
+		}
+		order.Price = price
+		return dryRun.CheckProposedPortfolio(ctx, current, []*types.Order{&order})
+	}
+
+	// The largest quantity worth trying: what the margin pays for, or the
+	// position a reduce-only order closes
+	var hi float64
+	var bound string
+	if template.ReduceOnly {
+		held := 0.0
+		for _, pos := range current {
+			if pos.Symbol == template.Symbol {
+				held += pos.Quantity
+			}
+		}
+		hi, bound = math.Abs(held), ConstraintPosition
+	} else {
+		hi, bound = math.Max(availableMargin, 0)/(price*m.marginRate()), ConstraintMargin
+	}
+	if hi <= 0 || math.IsNaN(hi) {
+		return 0, bound
+	}
+	hi = math.Min(hi, math.MaxFloat64)
+
+	err := check(hi)
+	if err == nil {
+		return hi, bound
+	}
+
+	// Non-negative floats order like their bit patterns, so bisecting the
+	// bits finds the largest passing quantity in at most 64 dry runs
+	lo, hiBits := uint64(0), math.Float64bits(hi)
+	for hiBits-lo > 1 {
+		mid := lo + (hiBits-lo)/2
+		if midErr := check(math.Float64frombits(mid)); midErr == nil {
+			lo = mid
+		} else {
+			hiBits, err = mid, midErr
+		}
+	}
+	return math.Float64frombits(lo), bindingConstraint(err)
+}
+
+// bindingConstraint names the constraint a failed dry run hit
+func bindingConstraint(err error) string {
+	if errors.Is(err, errMarginExceeded) {
+		return ConstraintMargin
+	}
+	var limitErr *LimitError
+	if errors.As(err, &limitErr) {
+		return limitErr.Limit
+	}
+	return ConstraintBlocked
+}
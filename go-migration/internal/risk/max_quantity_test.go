@@ -0,0 +1,119 @@
+package risk
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+func TestManager_MaxQuantity(t *testing.T) {
+	ctx := context.Background()
+	limits := testLimits()
+	limits.MaxPositionSize = 1000
+	limits.MaxConcentration = 0.5
+	limits.MarginRate = 0.1
+	manager := NewManager(limits, zap.NewNop())
+
+	template := &types.Order{ID: "max", UserID: "user1", Symbol: "TEST/SOL", Side: types.OrderSideBuy, Type: types.OrderTypeLimit, Price: 100}
+	book := []*types.Position{
+		{UserID: "user1", Symbol: "AAA/SOL", Quantity: 100, AvgPrice: 100},
+		{UserID: "user1", Symbol: "BBB/SOL", Quantity: 100, AvgPrice: 100},
+	}
+
+	t.Run("MarginBinds", func(t *testing.T) {
+		qty, constraint := manager.MaxQuantity(ctx, template, book, 20000, 200)
+		assert.Equal(t, ConstraintMargin, constraint)
+		assert.InDelta(t, 20.0, qty, 1e-9)
+
+		order := *template
+		order.Quantity = qty
+		require.NoError(t, manager.CheckOrderRisk(ctx, &order))
+	})
+
+	t.Run("ConcentrationBinds", func(t *testing.T) {
+		// TEST can grow to half of the book, 20000 against the 20000
+		// already held
+		qty, constraint := manager.MaxQuantity(ctx, template, book, 20000, 100000)
+		assert.Equal(t, LimitMaxConcentration, constraint)
+		assert.InDelta(t, 200.0, qty, 1e-9)
+
+		// A symbol alone in the book is all of it
+		qty, constraint = manager.MaxQuantity(ctx, template, nil, 20000, 100000)
+		assert.Equal(t, LimitMaxConcentration, constraint)
+		assert.Zero(t, qty)
+	})
+
+	t.Run("PositionSizeBinds", func(t *testing.T) {
+		unconcentrated := limits.clone()
+		unconcentrated.MaxConcentration = 0
+		manager := NewManager(unconcentrated, zap.NewNop())
+		cheap := *template
+		cheap.Price = 0.01
+		qty, constraint := manager.MaxQuantity(ctx, &cheap, book, 20000, 100000)
+		assert.Equal(t, LimitMaxPositionSize, constraint)
+		assert.InDelta(t, 1000.0, qty, 1e-9)
+
+		// The existing position counts toward the size limit
+		held := append([]*types.Position{{UserID: "user1", Symbol: "TEST/SOL", Quantity: 900, AvgPrice: 0.01}}, book...)
+		qty, constraint = manager.MaxQuantity(ctx, &cheap, held, 20000, 100000)
+		assert.Equal(t, LimitMaxPositionSize, constraint)
+		assert.InDelta(t, 100.0, qty, 1e-9)
+	})
+
+	t.Run("LeverageBinds", func(t *testing.T) {
+		levered := limits.clone()
+		levered.MaxConcentration = 0
+		levered.MaxLeverage = 3
+		qty, constraint := NewManager(levered, zap.NewNop()).MaxQuantity(ctx, template, book, 10000, 100000)
+		assert.Equal(t, LimitMaxLeverage, constraint)
+		assert.InDelta(t, 100.0, qty, 1e-9)
+	})
+
+	t.Run("ReduceOnly", func(t *testing.T) {
+		sell := *template
+		sell.Symbol, sell.Side, sell.ReduceOnly = "AAA/SOL", types.OrderSideSell, true
+		unconcentrated := limits.clone()
+		unconcentrated.MaxConcentration = 0
+		qty, constraint := NewManager(unconcentrated, zap.NewNop()).MaxQuantity(ctx, &sell, book, 20000, 0)
+		assert.Equal(t, ConstraintPosition, constraint)
+		assert.Equal(t, 100.0, qty)
+	})
+
+	t.Run("NoPrice", func(t *testing.T) {
+		market := *template
+		market.Type, market.Price = types.OrderTypeMarket, 0
+		qty, constraint := manager.MaxQuantity(ctx, &market, book, 20000, 100000)
+		assert.Equal(t, ConstraintPrice, constraint)
+		assert.Zero(t, qty)
+	})
+
+	t.Run("Blocked", func(t *testing.T) {
+		closed := limits.clone()
+		closed.Sessions = SessionSchedule{Sessions: []Session{{Start: "00:00", End: "00:00"}}}
+		qty, constraint := NewManager(closed, zap.NewNop()).MaxQuantity(ctx, template, book, 20000, 100000)
+		assert.Equal(t, ConstraintBlocked, constraint)
+		assert.Zero(t, qty)
+
+		killed := NewManager(limits, zap.NewNop())
+		killed.SetKillSwitch(true, "maintenance")
+		qty, constraint = killed.MaxQuantity(ctx, template, book, 20000, 100000)
+		assert.Equal(t, ConstraintBlocked, constraint)
+		assert.Zero(t, qty)
+
+		wide := limits.clone()
+		wide.MaxSpread = 0.05
+		spread := NewManager(wide, zap.NewNop())
+		spread.RecordSpread("TEST/SOL", 90, 110)
+		qty, constraint = spread.MaxQuantity(ctx, template, book, 20000, 100000)
+		assert.Equal(t, LimitMaxSpread, constraint)
+		assert.Zero(t, qty)
+	})
+
+	// The dry runs leave no violations behind
+	assert.Empty(t, manager.RecentViolations())
+}
@@ -0,0 +1,115 @@
+package risk
+
+import (
+	"context"
+	"sync"
+
+	"go.uber.org/zap"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+// MonitorPositions re-checks position risk as prices arrive until ctx is
+// done or prices closes. On each tick the positions returned by
+// positionsFn in the tick's symbol are marked, at the mark price resolver
+// fed the tick if one is set or else at the tick price, and passed to
+// CheckPositionRisk; onBreach is called with the marked copy and the
+// error for each that fails. positionsFn's positions are not modified.
+//
+// Checks run on their own goroutine so a slow positionsFn or onBreach
+// never blocks the price channel; ticks that arrive meanwhile are
+// coalesced to the latest per symbol. It returns ctx.Err() if ctx ends
+// the loop and nil once prices closes and the last ticks are checked.
+func (m *Manager) MonitorPositions(ctx context.Context, positionsFn func() []*types.Position,
+	prices <-chan *types.PriceUpdate, onBreach func(*types.Position, error)) error {
+	var (
+		mu      sync.Mutex
+		pending = make(map[string]float64)
+	)
+	wake := make(chan struct{}, 1)
+	stop := make(chan struct{})
+	done := make(chan struct{})
+
+	check := func() {
+		mu.Lock()
+		ticks := pending
+		pending = make(map[string]float64)
+		mu.Unlock()
+		if len(ticks) > 0 {
+			m.checkMarkedPositions(ctx, positionsFn(), ticks, onBreach)
+		}
+	}
+
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-wake:
+				check()
+			case <-stop:
+				check()
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			<-done
+			return ctx.Err()
+		case update, ok := <-prices:
+			if !ok {
+				close(stop)
+				<-done
+				return nil
+			}
+			if update == nil || !isFinite(update.Price) || update.Price <= 0 {
+				continue
+			}
+			if m.markPrices != nil {
+				m.markPrices.UpdatePrice(update)
+			}
+			mu.Lock()
+			pending[update.Symbol] = update.Price
+			mu.Unlock()
+			select {
+			case wake <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+// checkMarkedPositions checks copies of the open positions whose symbol
+// has a new price in ticks
+func (m *Manager) checkMarkedPositions(ctx context.Context, positions []*types.Position,
+	ticks map[string]float64, onBreach func(*types.Position, error)) {
+	for _, pos := range positions {
+		if ctx.Err() != nil {
+			return
+		}
+		price, ok := ticks[pos.Symbol]
+		if !ok || pos.Quantity == 0 {
+			continue
+		}
+
+		marked := *pos
+		if m.markPrices != nil {
+			if err := m.MarkPosition(&marked); err != nil {
+				m.logger.Warn("Failed to mark monitored position",
+					zap.String("symbol", pos.Symbol),
+					zap.Error(err))
+				continue
+			}
+		} else {
+			marked.UnrealizedPnL = (price - marked.AvgPrice) * marked.Quantity
+		}
+
+		if err := m.CheckPositionRisk(ctx, &marked); err != nil {
+			onBreach(&marked, err)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package risk
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+type breach struct {
+	position *types.Position
+	err      error
+}
+
+func TestManager_MonitorPositions(t *testing.T) {
+	manager := NewManager(testLimits(), zap.NewNop())
+	positions := []*types.Position{
+		{Symbol: "TEST/SOL", Quantity: 10, AvgPrice: 1},
+		{Symbol: "OTHER/SOL", Quantity: 10, AvgPrice: 1},
+	}
+
+	prices := make(chan *types.PriceUpdate)
+	breaches := make(chan breach, 10)
+	result := make(chan error, 1)
+	go func() {
+		result <- manager.MonitorPositions(context.Background(),
+			func() []*types.Position { return positions },
+			prices,
+			func(pos *types.Position, err error) { breaches <- breach{pos, err} })
+	}()
+
+	prices <- &types.PriceUpdate{Symbol: "TEST/SOL", Price: 0.95}
+	prices <- &types.PriceUpdate{Symbol: "TEST/SOL", Price: 0.7}
+	close(prices)
+	require.NoError(t, <-result)
+
+	require.Len(t, breaches, 1, "only the tick past the drawdown limit breaches")
+	got := <-breaches
+	assert.Equal(t, "TEST/SOL", got.position.Symbol)
+	assert.InDelta(t, -3.0, got.position.UnrealizedPnL, 1e-9)
+	var limitErr *LimitError
+	require.True(t, errors.As(got.err, &limitErr))
+	assert.Equal(t, LimitMaxDrawdown, limitErr.Limit)
+	assert.Zero(t, positions[0].UnrealizedPnL, "caller's positions are not modified")
+
+	t.Run("MarkPriceResolver", func(t *testing.T) {
+		manager := NewManager(testLimits(), zap.NewNop())
+		resolver := NewMarkPriceResolver(MarkPriceLast)
+		manager.SetMarkPriceResolver(resolver)
+
+		prices := make(chan *types.PriceUpdate, 1)
+		prices <- &types.PriceUpdate{Symbol: "TEST/SOL", Price: 0.5}
+		close(prices)
+
+		var breached []*types.Position
+		err := manager.MonitorPositions(context.Background(),
+			func() []*types.Position { return positions[:1] },
+			prices,
+			func(pos *types.Position, err error) { breached = append(breached, pos) })
+		require.NoError(t, err)
+		require.Len(t, breached, 1)
+
+		mark, err := resolver.MarkPrice("TEST/SOL")
+		require.NoError(t, err)
+		assert.Equal(t, 0.5, mark)
+	})
+
+	t.Run("DoesNotBlockPrices", func(t *testing.T) {
+		manager := NewManager(testLimits(), zap.NewNop())
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		release := make(chan struct{})
+		prices := make(chan *types.PriceUpdate)
+		result := make(chan error, 1)
+		go func() {
+			result <- manager.MonitorPositions(ctx,
+				func() []*types.Position { return positions },
+				prices,
+				func(*types.Position, error) { <-release })
+		}()
+
+		sent := make(chan struct{})
+		go func() {
+			for i := 0; i < 100; i++ {
+				prices <- &types.PriceUpdate{Symbol: "TEST/SOL", Price: 0.5}
+			}
+			close(sent)
+		}()
+		select {
+		case <-sent:
+		case <-time.After(time.Second):
+			t.Fatal("price channel blocked behind a slow breach handler")
+		}
+
+		close(release)
+		cancel()
+		assert.ErrorIs(t, <-result, context.Canceled)
+	})
+}
@@ -0,0 +1,83 @@
+package risk
+
+import (
+	"math"
+
+	"go.uber.org/zap"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+// PoolReserves are the reserves of a constant-product AMM pool: Base in
+// the traded token and Quote in the token it is priced in
+type PoolReserves struct {
+	Base  float64 `json:"base"`
+	Quote float64 `json:"quote"`
+}
+
+// RecordPool records the current reserves of symbol's pool for the pool
+// share and AMM price impact checks
+func (m *Manager) RecordPool(symbol string, reserves PoolReserves) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.pools[symbol] = reserves
+}
+
+// PoolImpact returns the flat share of pool's base reserve that trading
+// qty takes, and the price impact of the trade on a constant-product
+// curve, where Base*Quote stays fixed and the price is Quote/Base. The AMM
+// impact grows faster than the share as the pool is drained: a buy of 5%
+// of the reserve moves the price about 10.8%, and a buy of the whole
+// reserve has infinite impact.
+func (m *Manager) PoolImpact(pool PoolReserves, side types.OrderSide, qty float64) (share, impact float64) {
+	if pool.Base <= 0 || pool.Quote <= 0 || qty <= 0 {
+		return 0, 0
+	}
+
+	share = qty / pool.Base
+	if side == types.OrderSideBuy {
+		if qty >= pool.Base {
+			return share, math.Inf(1)
+		}
+		ratio := pool.Base / (pool.Base - qty)
+		return share, ratio*ratio - 1
+	}
+	ratio := pool.Base / (pool.Base + qty)
+	return share, 1 - ratio*ratio
+}
+
+// checkPool rejects orders that take more than MaxPoolShare of the
+// symbol's recorded pool, or whose AMM price impact on it exceeds the
+// symbol's effective price impact limit. Symbols without a recorded pool
+// pass.
+func (m *Manager) checkPool(order *types.Order, fields []zap.Field) error {
+	if m.limits.MaxPoolShare <= 0 && m.limits.MaxPriceImpact <= 0 {
+		return nil
+	}
+
+	m.mu.Lock()
+	pool, known := m.pools[order.Symbol]
+	m.mu.Unlock()
+	if !known {
+		return nil
+	}
+
+	share, impact := m.PoolImpact(pool, order.Side, order.Quantity)
+	if limit := m.limits.MaxPoolShare; limit > 0 {
+		if share > limit {
+			return newLimitError(LimitMaxPoolShare, share, limit,
+				"order takes %f of the %s pool, above limit %f", share, order.Symbol, limit)
+		}
+		m.warnNearMax(LimitMaxPoolShare, share, limit, fields...)
+	}
+	if m.limits.MaxPriceImpact > 0 {
+		limit := m.EffectiveMaxPriceImpact(order.Symbol)
+		if impact > limit {
+			observed := math.Min(impact, math.MaxFloat64)
+			return newLimitError(LimitMaxPriceImpact, observed, limit,
+				"AMM price impact exceeds limit: %f > %f", impact, limit)
+		}
+		m.warnNearMax(LimitMaxPriceImpact, impact, limit, fields...)
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package risk
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+func TestManager_PoolImpact(t *testing.T) {
+	manager := NewManager(testLimits(), zap.NewNop())
+	pool := PoolReserves{Base: 1000, Quote: 50}
+
+	// The same size is a flat 5% of the pool either way, but the AMM
+	// impact is larger for buys, which drain the reserve, than for sells
+	share, impact := manager.PoolImpact(pool, types.OrderSideBuy, 50)
+	assert.InDelta(t, 0.05, share, 1e-9)
+	assert.InDelta(t, math.Pow(1000.0/950, 2)-1, impact, 1e-9)
+	assert.Greater(t, impact, share)
+
+	share, impact = manager.PoolImpact(pool, types.OrderSideSell, 50)
+	assert.InDelta(t, 0.05, share, 1e-9)
+	assert.InDelta(t, 1-math.Pow(1000.0/1050, 2), impact, 1e-9)
+	assert.Greater(t, impact, share)
+
+	_, impact = manager.PoolImpact(pool, types.OrderSideBuy, 1000)
+	assert.True(t, math.IsInf(impact, 1), "buying the whole reserve")
+	share, impact = manager.PoolImpact(PoolReserves{}, types.OrderSideBuy, 50)
+	assert.Zero(t, share)
+	assert.Zero(t, impact)
+}
+
+func TestManager_CheckPool(t *testing.T) {
+	ctx := context.Background()
+	limits := testLimits()
+	limits.MaxPoolShare = 0.1
+	limits.MaxPriceImpact = 0.1
+	require.NoError(t, limits.Validate())
+	manager := NewManager(limits, zap.NewNop())
+	manager.RecordPool("DEX/SOL", PoolReserves{Base: 1000, Quote: 50})
+
+	order := func(side types.OrderSide, qty float64) *types.Order {
+		return &types.Order{ID: "o1", Symbol: "DEX/SOL", Side: side, Type: types.OrderTypeMarket, Quantity: qty}
+	}
+
+	assert.NoError(t, manager.CheckOrderRisk(ctx, order(types.OrderSideBuy, 40)))
+
+	// 5% of the pool is within the share limit, but a buy that size moves
+	// the price more than the 10% impact limit; the flat ratio alone would
+	// have passed it
+	err := manager.CheckOrderRisk(ctx, order(types.OrderSideBuy, 50))
+	assert.ErrorIs(t, err, ErrPriceImpactExceeded)
+	assert.NoError(t, manager.CheckOrderRisk(ctx, order(types.OrderSideSell, 50)))
+
+	err = manager.CheckOrderRisk(ctx, order(types.OrderSideSell, 150))
+	assert.ErrorIs(t, err, ErrPoolShareExceeded)
+	var limitErr *LimitError
+	require.ErrorAs(t, err, &limitErr)
+	assert.InDelta(t, 0.15, limitErr.Observed, 1e-9)
+
+	// Symbols without a recorded pool aren't checked
+	assert.NoError(t, manager.CheckOrderRisk(ctx, &types.Order{ID: "o2", Symbol: "OTHER/SOL",
+		Side: types.OrderSideBuy, Type: types.OrderTypeMarket, Quantity: 500}))
+
+	limits.MaxPoolShare = -0.1
+	assert.Error(t, limits.Validate())
+}
@@ -0,0 +1,162 @@
+package risk
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+// CheckProposedPortfolio applies orders to a copy of the current positions
+// and runs account-level checks on the resulting book, catching orders
+// that are fine individually but breach a limit together. Orders without
+// a price are valued at the position's average price. The what-if check
+// leaves hysteresis state and recorded violations untouched.
+func (m *Manager) CheckProposedPortfolio(ctx context.Context, current []*types.Position, orders []*types.Order) error {
+	proposed, err := applyOrders(current, orders)
+	if err != nil {
+		return err
+	}
+
+	for _, pos := range proposed {
+		if err := m.checkPositionRisk(ctx, pos, false); err != nil {
+			return fmt.Errorf("proposed position %s: %w", pos.Symbol, err)
+		}
+	}
+
+	return m.checkPortfolio(ctx, proposed)
+}
+
+// CheckPortfolioRisk runs account-level concentration and leverage checks
+// over the positions
+func (m *Manager) CheckPortfolioRisk(ctx context.Context, positions []*types.Position) error {
+	err := m.checkPortfolio(ctx, positions)
+	m.recordViolation(err)
+	return err
+}
+
+// checkPortfolio runs exposure, concentration and leverage checks over a
+// set of positions
+func (m *Manager) checkPortfolio(ctx context.Context, positions []*types.Position) error {
+	exposure := 0.0
+	bySymbol := make(map[string]float64)
+	byCategory := make(map[string]float64)
+	for _, pos := range positions {
+		if err := validatePositionInput(pos); err != nil {
+			return err
+		}
+		notional := math.Abs(pos.Quantity * pos.AvgPrice)
+		exposure += notional
+		bySymbol[pos.Symbol] += notional
+
+		category := pos.Category
+		if category == "" {
+			category = DefaultCategory
+		}
+		byCategory[category] += notional
+	}
+
+	if exposure == 0 {
+		return nil
+	}
+
+	if m.limits.MaxConcentration > 0 {
+		for symbol, notional := range bySymbol {
+			concentration := notional / exposure
+			if concentration > m.limits.MaxConcentration {
+				return newLimitError(LimitMaxConcentration, concentration, m.limits.MaxConcentration,
+					"concentration in %s exceeds limit: %f > %f", symbol, concentration, m.limits.MaxConcentration)
+			}
+		}
+	}
+
+	for category, notional := range byCategory {
+		limit, ok := m.limits.MaxCategoryConcentration[category]
+		if !ok || limit <= 0 {
+			continue
+		}
+		concentration := notional / exposure
+		if concentration > limit {
+			return newLimitError(LimitMaxCategoryConcentration, concentration, limit,
+				"concentration in category %s exceeds limit: %f > %f", category, concentration, limit)
+		}
+	}
+
+	if m.limits.MaxLeverage > 0 {
+		equity, ok, err := m.accountEquity(positions)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return nil
+		}
+		if !isFinite(equity) || equity <= 0 {
+			return fmt.Errorf("non-positive equity: %f", equity)
+		}
+		exposure := marketExposure(positions)
+		if m.limits.NetCorrelatedExposure {
+			exposure = m.NetExposure(positions)
+		}
+		leverage := exposure / equity
+		if leverage > m.limits.MaxLeverage {
+			return newLimitError(LimitMaxLeverage, leverage, m.limits.MaxLeverage,
+				"leverage exceeds limit: %f > %f", leverage, m.limits.MaxLeverage)
+		}
+	}
+
+	return nil
+}
+
+// applyOrders returns copies of positions with orders applied as if fully
+// filled. The inputs are not modified.
+func applyOrders(current []*types.Position, orders []*types.Order) ([]*types.Position, error) {
+	bySymbol := make(map[string]*types.Position, len(current))
+	var result []*types.Position
+	for _, pos := range current {
+		cp := *pos
+		bySymbol[cp.Symbol] = &cp
+		result = append(result, &cp)
+	}
+
+	for _, order := range orders {
+		if !isFinite(order.Quantity) || order.Quantity <= 0 || !isFinite(order.Price) || order.Price < 0 {
+			return nil, fmt.Errorf("%w: order %s quantity %f, price %f",
+				ErrInvalidInput, order.ID, order.Quantity, order.Price)
+		}
+
+		pos, exists := bySymbol[order.Symbol]
+		if !exists {
+			pos = &types.Position{UserID: order.UserID, Symbol: order.Symbol, Category: order.Category}
+			bySymbol[order.Symbol] = pos
+			result = append(result, pos)
+		}
+
+		price := order.Price
+		if price <= 0 {
+			price = pos.AvgPrice
+		}
+		if price <= 0 {
+			return nil, fmt.Errorf("no price to value order %s", order.ID)
+		}
+
+		qty := order.Quantity
+		if order.Side == types.OrderSideSell {
+			qty = -qty
+		}
+
+		total := pos.Quantity + qty
+		switch {
+		case total == 0:
+			pos.AvgPrice = 0
+		case pos.Quantity == 0 || (pos.Quantity > 0) == (qty > 0):
+			pos.AvgPrice = (pos.AvgPrice*math.Abs(pos.Quantity) + price*math.Abs(qty)) /
+				math.Abs(total)
+		case (total > 0) != (pos.Quantity > 0):
+			pos.AvgPrice = price
+		}
+		pos.Quantity = total
+	}
+
+	return result, nil
+}
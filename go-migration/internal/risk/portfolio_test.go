@@ -0,0 +1,105 @@
+package risk
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+func TestManager_CheckProposedPortfolio(t *testing.T) {
+	ctx := context.Background()
+	limits := testLimits()
+	limits.MaxConcentration = 0.6
+	manager := NewManager(limits, zap.NewNop())
+
+	current := []*types.Position{
+		{Symbol: "AAA/SOL", Quantity: 10, AvgPrice: 10},
+		{Symbol: "BBB/SOL", Quantity: 10, AvgPrice: 10},
+	}
+	buy1 := &types.Order{ID: "o1", Symbol: "BBB/SOL", Side: types.OrderSideBuy, Quantity: 3, Price: 10}
+	buy2 := &types.Order{ID: "o2", Symbol: "BBB/SOL", Side: types.OrderSideBuy, Quantity: 3, Price: 10}
+
+	// Each buy alone keeps BBB under 60% of the book
+	require.NoError(t, manager.CheckProposedPortfolio(ctx, current, []*types.Order{buy1}))
+	require.NoError(t, manager.CheckProposedPortfolio(ctx, current, []*types.Order{buy2}))
+
+	err := manager.CheckProposedPortfolio(ctx, current, []*types.Order{buy1, buy2})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "concentration in BBB/SOL")
+
+	// The current book is not modified
+	assert.Equal(t, 10.0, current[1].Quantity)
+
+	t.Run("Leverage", func(t *testing.T) {
+		limits := testLimits()
+		limits.MaxLeverage = 2
+		limits.MaxDrawdown = 1
+		manager := NewManager(limits, zap.NewNop())
+
+		// Without a balance there is no equity to measure leverage against
+		losing := []*types.Position{{Symbol: "AAA/SOL", Quantity: 10, AvgPrice: 10, UnrealizedPnL: -60}}
+		buy := []*types.Order{{ID: "o3", Symbol: "AAA/SOL", Side: types.OrderSideBuy, Quantity: 1, Price: 10}}
+		require.NoError(t, manager.CheckProposedPortfolio(ctx, losing, buy))
+
+		// A book bought with the whole balance stays at 1x however much
+		// it loses
+		balance := 100.0
+		manager.SetBalanceSource(BalanceSourceFunc(func(userID string) (float64, error) { return balance, nil }))
+		require.NoError(t, manager.CheckPortfolioRisk(ctx, losing))
+
+		// Borrowing to add to it takes its 50 of market value past 2x the
+		// 20 left of an 80 balance
+		balance = 80
+		err := manager.CheckProposedPortfolio(ctx, losing, buy)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "leverage")
+
+		balance = 50
+		assert.ErrorContains(t, manager.CheckPortfolioRisk(ctx, losing), "non-positive equity")
+	})
+
+	t.Run("UnpricedOrder", func(t *testing.T) {
+		err := manager.CheckProposedPortfolio(ctx, nil, []*types.Order{
+			{ID: "o4", Symbol: "NEW/SOL", Side: types.OrderSideBuy, Quantity: 1},
+		})
+		assert.Error(t, err)
+	})
+}
+
+func TestManager_CategoryConcentration(t *testing.T) {
+	ctx := context.Background()
+	limits := testLimits()
+	limits.MaxConcentration = 0.5
+	limits.MaxCategoryConcentration = map[string]float64{"memecoins": 0.3}
+	manager := NewManager(limits, zap.NewNop())
+
+	positions := []*types.Position{
+		{Symbol: "DOGE/SOL", Category: "memecoins", Quantity: 20, AvgPrice: 1},
+		{Symbol: "BONK/SOL", Category: "memecoins", Quantity: 20, AvgPrice: 1},
+		{Symbol: "SOL/USDC", Quantity: 30, AvgPrice: 1},
+		{Symbol: "JUP/SOL", Quantity: 30, AvgPrice: 1},
+	}
+
+	// Each memecoin is 20% of the book, together they are 40%
+	err := manager.CheckPortfolioRisk(ctx, positions)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "category memecoins")
+
+	positions[1].Quantity = 5
+	require.NoError(t, manager.CheckPortfolioRisk(ctx, positions))
+
+	t.Run("DefaultBucket", func(t *testing.T) {
+		limits := testLimits()
+		limits.MaxCategoryConcentration = map[string]float64{DefaultCategory: 0.5}
+		manager := NewManager(limits, zap.NewNop())
+
+		err := manager.CheckPortfolioRisk(ctx, positions)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "category default")
+	})
+}
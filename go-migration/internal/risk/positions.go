@@ -0,0 +1,47 @@
+package risk
+
+import (
+	"context"
+	"errors"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+// PositionRiskResult is the outcome of CheckPositionRisk for one position
+type PositionRiskResult struct {
+	Position *types.Position
+	// Err is nil when the position passed
+	Err error
+	// Limit names the breached limit when Err is a LimitError
+	Limit string
+}
+
+// Passed reports whether the position passed every check
+func (r PositionRiskResult) Passed() bool {
+	return r.Err == nil
+}
+
+// CheckPositions runs the CheckPositionRisk checks over every position
+// and returns one result per position, in order. Unlike CheckPortfolioRisk
+// it doesn't stop at the first failure, and it leaves hysteresis state as
+// it is. Positions left unchecked when ctx is done
+// fail with the context's error.
+func (m *Manager) CheckPositions(ctx context.Context, positions []*types.Position) []PositionRiskResult {
+	results := make([]PositionRiskResult, len(positions))
+	for i, pos := range positions {
+		result := PositionRiskResult{Position: pos}
+		if err := ctx.Err(); err != nil {
+			result.Err = err
+		} else {
+			result.Err = m.checkPositionRisk(ctx, pos, false)
+			m.recordViolation(result.Err)
+		}
+
+		var limitErr *LimitError
+		if errors.As(result.Err, &limitErr) {
+			result.Limit = limitErr.Limit
+		}
+		results[i] = result
+	}
+	return results
+}
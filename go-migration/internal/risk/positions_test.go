@@ -0,0 +1,43 @@
+package risk
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+func TestManager_CheckPositions(t *testing.T) {
+	manager := NewManager(testLimits(), zap.NewNop())
+
+	positions := []*types.Position{
+		{Symbol: "OK/SOL", Quantity: 10, AvgPrice: 1},
+		{Symbol: "BIG/SOL", Quantity: 5000, AvgPrice: 1},
+		{Symbol: "LOSS/SOL", Quantity: 100, AvgPrice: 1, UnrealizedPnL: -50},
+		{Symbol: "PROFIT/SOL", Quantity: 100, AvgPrice: 1, UnrealizedPnL: 20},
+	}
+	results := manager.CheckPositions(context.Background(), positions)
+	require.Len(t, results, len(positions))
+
+	for i, result := range results {
+		assert.Same(t, positions[i], result.Position)
+	}
+	assert.True(t, results[0].Passed())
+	assert.ErrorIs(t, results[1].Err, ErrPositionSizeExceeded)
+	assert.Equal(t, LimitMaxPositionSize, results[1].Limit)
+	assert.ErrorIs(t, results[2].Err, ErrDrawdownExceeded)
+	assert.Equal(t, LimitMaxDrawdown, results[2].Limit)
+	assert.True(t, results[3].Passed())
+	assert.Empty(t, results[3].Limit)
+
+	t.Run("Canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		results := manager.CheckPositions(ctx, positions[:1])
+		assert.ErrorIs(t, results[0].Err, context.Canceled)
+	})
+}
@@ -0,0 +1,38 @@
+package risk
+
+import (
+	"math"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+// MetricsPrecision sets how many decimals CalculateMetrics rounds to.
+// Monetary values use MoneyDecimals and the margin level, a percentage,
+// uses MarginDecimals.
+type MetricsPrecision struct {
+	MoneyDecimals  int `json:"money_decimals"`
+	MarginDecimals int `json:"margin_decimals"`
+}
+
+// SetMetricsPrecision makes CalculateMetrics round its results.
+// CalculateRawMetrics and the risk checks keep full precision.
+func (m *Manager) SetMetricsPrecision(precision MetricsPrecision) {
+	m.precision = &precision
+}
+
+// Round returns a copy of metrics rounded to p
+func (p MetricsPrecision) Round(metrics *types.RiskMetrics) *types.RiskMetrics {
+	rounded := *metrics
+	rounded.TotalEquity = roundTo(metrics.TotalEquity, p.MoneyDecimals)
+	rounded.UsedMargin = roundTo(metrics.UsedMargin, p.MoneyDecimals)
+	rounded.AvailableMargin = roundTo(metrics.AvailableMargin, p.MoneyDecimals)
+	rounded.DailyPnL = roundTo(metrics.DailyPnL, p.MoneyDecimals)
+	rounded.MarginLevel = roundTo(metrics.MarginLevel, p.MarginDecimals)
+	return &rounded
+}
+
+// roundTo rounds v half away from zero to decimals places
+func roundTo(v float64, decimals int) float64 {
+	scale := math.Pow(10, float64(decimals))
+	return math.Round(v*scale) / scale
+}
@@ -0,0 +1,42 @@
+package risk
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+func TestManager_MetricsPrecision(t *testing.T) {
+	ctx := context.Background()
+	positions := []*types.Position{
+		{Symbol: "TEST/SOL", Quantity: 3, AvgPrice: 1.0 / 3, UnrealizedPnL: 0.123456, RealizedPnL: 0.000004},
+	}
+
+	manager := NewManager(testLimits(), zap.NewNop())
+	manager.SetMetricsPrecision(MetricsPrecision{MoneyDecimals: 4, MarginDecimals: 2})
+
+	raw, err := manager.CalculateRawMetrics(ctx, positions)
+	require.NoError(t, err)
+	assert.InDelta(t, 1123.456, raw.MarginLevel, 1e-3)
+	assert.NotEqual(t, 1123.46, raw.MarginLevel)
+
+	rounded, err := manager.CalculateMetrics(ctx, positions)
+	require.NoError(t, err)
+	assert.Equal(t, 1.1235, rounded.TotalEquity)
+	assert.Equal(t, 0.1, rounded.UsedMargin)
+	assert.Equal(t, 1.0235, rounded.AvailableMargin)
+	assert.Equal(t, 0.1235, rounded.DailyPnL)
+	assert.Equal(t, 1123.46, rounded.MarginLevel)
+
+	t.Run("Unset", func(t *testing.T) {
+		manager := NewManager(testLimits(), zap.NewNop())
+		metrics, err := manager.CalculateMetrics(ctx, positions)
+		require.NoError(t, err)
+		assert.Equal(t, raw.MarginLevel, metrics.MarginLevel)
+	})
+}
@@ -0,0 +1,109 @@
+package risk
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Profile names a preset risk appetite applied on top of a mode's
+// default limits
+type Profile string
+
+const (
+	// ProfileConservative halves sizes, losses and slippage and demands
+	// more margin and liquidity than the defaults
+	ProfileConservative Profile = "conservative"
+	// ProfileBalanced is the mode's DefaultLimits
+	ProfileBalanced Profile = "balanced"
+	// ProfileAggressive doubles sizes, losses and slippage and accepts
+	// less margin and liquidity than the defaults
+	ProfileAggressive Profile = "aggressive"
+)
+
+// profileScale is how far each profile loosens the defaults; below one
+// tightens them
+var profileScale = map[Profile]float64{
+	ProfileConservative: 0.5,
+	ProfileBalanced:     1,
+	ProfileAggressive:   2,
+}
+
+// ProfileLimits returns the limits for profile in mode
+func ProfileLimits(profile Profile, mode TradingMode) (Limits, error) {
+	scale, ok := profileScale[profile]
+	if !ok {
+		return Limits{}, fmt.Errorf("unknown risk profile %q", profile)
+	}
+	return DefaultLimits(mode).scaled(scale), nil
+}
+
+// NewManagerFromProfile creates a manager with the limits of the named
+// profile in mode. Each override is applied to the profile's limits in
+// order, and the result must pass Validate.
+func NewManagerFromProfile(name string, mode TradingMode, logger *zap.Logger, overrides ...func(*Limits)) (*Manager, error) {
+	limits, err := ProfileLimits(Profile(name), mode)
+	if err != nil {
+		return nil, err
+	}
+	for _, override := range overrides {
+		override(&limits)
+	}
+	if err := limits.Validate(); err != nil {
+		return nil, err
+	}
+	return NewManager(limits, logger), nil
+}
+
+// scaled loosens l by factor: caps and allowances are multiplied by it and
+// minimums and cooldowns divided by it, with fractions kept at most 1.
+// Disabled and Unlimited values are left as they are.
+func (l Limits) scaled(factor float64) Limits {
+	if factor == 1 {
+		return l.clone()
+	}
+	out := l.clone()
+
+	loosen := func(v float64) float64 {
+		if v == Unlimited {
+			return v
+		}
+		return v * factor
+	}
+	loosenFraction := func(v float64) float64 {
+		if v == Unlimited {
+			return v
+		}
+		return math.Min(v*factor, 1)
+	}
+	tighten := func(v float64) float64 {
+		return v / factor
+	}
+
+	out.MaxPositionSize = loosen(l.MaxPositionSize)
+	out.MaxDrawdown = loosenFraction(l.MaxDrawdown)
+	out.MaxDailyLoss = loosen(l.MaxDailyLoss)
+	out.MaxLeverage = loosen(l.MaxLeverage)
+	out.MinMarginLevel = tighten(l.MinMarginLevel)
+	out.MaxConcentration = loosenFraction(l.MaxConcentration)
+	for category, value := range out.MaxCategoryConcentration {
+		out.MaxCategoryConcentration[category] = loosenFraction(value)
+	}
+	out.CircuitBreaker.MaxMove = loosen(l.CircuitBreaker.MaxMove)
+	out.CircuitBreaker.ResumeMaxMove = loosen(l.CircuitBreaker.ResumeMaxMove)
+	out.MinOrderInterval = time.Duration(tighten(float64(l.MinOrderInterval)))
+	for symbol, interval := range out.MinOrderIntervals {
+		out.MinOrderIntervals[symbol] = time.Duration(tighten(float64(interval)))
+	}
+	out.MaxHoldingPeriod = time.Duration(loosen(float64(l.MaxHoldingPeriod)))
+	out.MinLiquidityToMarketCap = tighten(l.MinLiquidityToMarketCap)
+	out.MaxSlippage = loosenFraction(l.MaxSlippage)
+	out.MaxBookImbalance = loosen(l.MaxBookImbalance)
+	out.MaxGasToNotional = loosenFraction(l.MaxGasToNotional)
+	out.MaxPriceImpact = loosenFraction(l.MaxPriceImpact)
+	out.MaxPoolShare = loosenFraction(l.MaxPoolShare)
+	out.MaxSpread = loosen(l.MaxSpread)
+	return out
+}
@@ -0,0 +1,60 @@
+package risk
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestProfileLimits(t *testing.T) {
+	for _, mode := range []TradingMode{TradingModeDEX, TradingModePumpFun} {
+		conservative, err := ProfileLimits(ProfileConservative, mode)
+		require.NoError(t, err)
+		balanced, err := ProfileLimits(ProfileBalanced, mode)
+		require.NoError(t, err)
+		aggressive, err := ProfileLimits(ProfileAggressive, mode)
+		require.NoError(t, err)
+
+		assert.Equal(t, DefaultLimits(mode), balanced)
+		for _, limits := range []Limits{conservative, balanced, aggressive} {
+			assert.NoError(t, limits.Validate(), mode)
+		}
+
+		assert.Less(t, conservative.MaxSlippage, balanced.MaxSlippage)
+		assert.Greater(t, aggressive.MaxSlippage, balanced.MaxSlippage)
+		assert.Less(t, conservative.MaxPositionSize, aggressive.MaxPositionSize)
+		assert.Less(t, conservative.MaxDrawdown, aggressive.MaxDrawdown)
+		assert.Greater(t, conservative.MinMarginLevel, aggressive.MinMarginLevel)
+		assert.GreaterOrEqual(t, conservative.MinOrderInterval, aggressive.MinOrderInterval)
+		assert.LessOrEqual(t, aggressive.MaxConcentration, 1.0)
+	}
+
+	_, err := ProfileLimits("reckless", TradingModeDEX)
+	assert.Error(t, err)
+}
+
+func TestNewManagerFromProfile(t *testing.T) {
+	manager, err := NewManagerFromProfile("aggressive", TradingModePumpFun, zap.NewNop(),
+		func(l *Limits) { l.MaxSlippage = 0.03 })
+	require.NoError(t, err)
+
+	aggressive, _ := ProfileLimits(ProfileAggressive, TradingModePumpFun)
+	limits := manager.Limits()
+	assert.Equal(t, 0.03, limits.MaxSlippage)
+	assert.Equal(t, aggressive.MaxPositionSize, limits.MaxPositionSize)
+
+	_, err = NewManagerFromProfile("aggressive", TradingModeDEX, zap.NewNop(),
+		func(l *Limits) { l.MaxLeverage = -1 })
+	assert.Error(t, err)
+	_, err = NewManagerFromProfile("unknown", TradingModeDEX, zap.NewNop())
+	assert.Error(t, err)
+
+	loaded, err := LoadLimits(strings.NewReader("mode: pump_fun\nprofile: conservative\nmax_daily_loss: 100\n"))
+	require.NoError(t, err)
+	conservative, _ := ProfileLimits(ProfileConservative, TradingModePumpFun)
+	assert.Equal(t, conservative.MaxSlippage, loaded.MaxSlippage)
+	assert.Equal(t, 100.0, loaded.MaxDailyLoss)
+}
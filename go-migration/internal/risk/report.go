@@ -0,0 +1,165 @@
+package risk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+// RiskReportVersion is the current ReportJSON schema version. Bump it when
+// a field is renamed, removed or changes meaning; adding fields doesn't.
+const RiskReportVersion = 1
+
+// maxRecentViolations caps how many limit violations the manager keeps
+// for reports
+const maxRecentViolations = 50
+
+// RiskReport is the document ReportJSON produces
+type RiskReport struct {
+	Version     int                `json:"version"`
+	GeneratedAt time.Time          `json:"generated_at"`
+	Limits      Limits             `json:"limits"`
+	Metrics     *types.RiskMetrics `json:"metrics"`
+	Exposures   []SymbolExposure   `json:"exposures"`
+	Halts       []Halt             `json:"halts"`
+	KillSwitch  KillSwitch         `json:"kill_switch"`
+	Violations  []Violation        `json:"violations"`
+}
+
+// SymbolExposure is one open position valued at its mark price, or its
+// average price when no mark is available
+type SymbolExposure struct {
+	Symbol        string  `json:"symbol"`
+	Quantity      float64 `json:"quantity"`
+	Price         float64 `json:"price"`
+	Notional      float64 `json:"notional"`
+	UnrealizedPnL float64 `json:"unrealized_pnl"`
+	Drawdown      float64 `json:"drawdown"`
+}
+
+// Halt is a symbol halted by the circuit breaker. Resuming is set while
+// the halt is extended until the price settles.
+type Halt struct {
+	Symbol   string    `json:"symbol"`
+	Until    time.Time `json:"until"`
+	Resuming bool      `json:"resuming,omitempty"`
+}
+
+// Violation is a risk check that failed on a limit
+type Violation struct {
+	Limit     string    `json:"limit"`
+	Observed  float64   `json:"observed"`
+	Threshold float64   `json:"threshold"`
+	Message   string    `json:"message"`
+	At        time.Time `json:"at"`
+}
+
+// recordViolation keeps err for reports when it is a LimitError
+func (m *Manager) recordViolation(err error) {
+	var limitErr *LimitError
+	if !errors.As(err, &limitErr) {
+		return
+	}
+
+	now := m.now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.violations = append(m.violations, Violation{
+		Limit:     limitErr.Limit,
+		Observed:  limitErr.Observed,
+		Threshold: limitErr.Threshold,
+		Message:   limitErr.Error(),
+		At:        now,
+	})
+	if extra := len(m.violations) - maxRecentViolations; extra > 0 {
+		m.violations = append(m.violations[:0], m.violations[extra:]...)
+	}
+}
+
+// RecentViolations returns the latest limit violations from order,
+// position, portfolio and account checks, oldest first
+func (m *Manager) RecentViolations() []Violation {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Violation(nil), m.violations...)
+}
+
+// Report assembles the current risk state: limits, account metrics, the
+// exposure of each open position, active halts and recent violations.
+// When metrics is nil it is calculated from positions. Exposures and
+// halts are sorted by symbol.
+func (m *Manager) Report(ctx context.Context, positions []*types.Position, metrics *types.RiskMetrics) (*RiskReport, error) {
+	if metrics == nil {
+		var err error
+		if metrics, err = m.CalculateMetrics(ctx, positions); err != nil {
+			return nil, fmt.Errorf("failed to calculate metrics: %w", err)
+		}
+	}
+
+	now := m.now()
+	report := &RiskReport{
+		Version:     RiskReportVersion,
+		GeneratedAt: now.UTC(),
+		Limits:      m.Limits(),
+		Metrics:     metrics,
+		Exposures:   []SymbolExposure{},
+		Violations:  m.RecentViolations(),
+	}
+
+	for _, pos := range positions {
+		if pos.Quantity == 0 {
+			continue
+		}
+		price, unrealized := pos.AvgPrice, pos.UnrealizedPnL
+		if m.markPrices != nil {
+			if mark, err := m.markPrices.MarkPrice(pos.Symbol); err == nil {
+				price = mark
+				unrealized = (mark - pos.AvgPrice) * pos.Quantity
+			}
+		}
+		exposure := SymbolExposure{
+			Symbol:        pos.Symbol,
+			Quantity:      pos.Quantity,
+			Price:         price,
+			Notional:      math.Abs(pos.Quantity * price),
+			UnrealizedPnL: unrealized,
+		}
+		if entry := math.Abs(pos.Quantity * pos.AvgPrice); entry > 0 {
+			exposure.Drawdown = math.Max(-unrealized, 0) / entry
+		}
+		report.Exposures = append(report.Exposures, exposure)
+	}
+	sort.Slice(report.Exposures, func(i, j int) bool {
+		return report.Exposures[i].Symbol < report.Exposures[j].Symbol
+	})
+
+	m.mu.Lock()
+	report.Halts = m.activeHalts(now)
+	report.KillSwitch = m.kill
+	m.mu.Unlock()
+	if report.Violations == nil {
+		report.Violations = []Violation{}
+	}
+
+	return report, nil
+}
+
+// ReportJSON returns Report encoded as JSON. The schema is versioned by
+// RiskReportVersion.
+func (m *Manager) ReportJSON(ctx context.Context, positions []*types.Position, metrics *types.RiskMetrics) ([]byte, error) {
+	report, err := m.Report(ctx, positions, metrics)
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(report)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode risk report: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,79 @@
+package risk
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+func TestManager_ReportJSON(t *testing.T) {
+	limits := testLimits()
+	limits.CircuitBreaker = CircuitBreakerConfig{MaxMove: 0.5, Window: time.Minute, Cooldown: time.Hour}
+	manager := NewManager(limits, zap.NewNop())
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	manager.now = func() time.Time { return now }
+
+	positions := []*types.Position{
+		{Symbol: "WIF/SOL", Quantity: 10, AvgPrice: 2, UnrealizedPnL: -5},
+		{Symbol: "BONK/SOL", Quantity: 100, AvgPrice: 1, UnrealizedPnL: 10},
+		{Symbol: "FLAT/SOL"},
+	}
+
+	manager.UpdatePrice(&types.PriceUpdate{Symbol: "PUMP/SOL", Price: 1})
+	manager.UpdatePrice(&types.PriceUpdate{Symbol: "PUMP/SOL", Price: 2})
+	assert.Error(t, manager.CheckPositionRisk(context.Background(), positions[0]))
+
+	data, err := manager.ReportJSON(context.Background(), positions, nil)
+	require.NoError(t, err)
+	require.True(t, json.Valid(data))
+
+	var sections map[string]json.RawMessage
+	require.NoError(t, json.Unmarshal(data, &sections))
+	for _, key := range []string{"version", "generated_at", "limits", "metrics", "exposures", "halts", "violations"} {
+		assert.Contains(t, sections, key)
+	}
+
+	var report RiskReport
+	require.NoError(t, json.Unmarshal(data, &report))
+	assert.Equal(t, RiskReportVersion, report.Version)
+	assert.Equal(t, limits.MaxDrawdown, report.Limits.MaxDrawdown)
+	require.NotNil(t, report.Metrics)
+
+	require.Len(t, report.Exposures, 2, "flat positions are left out")
+	assert.Equal(t, "BONK/SOL", report.Exposures[0].Symbol)
+	assert.Equal(t, "WIF/SOL", report.Exposures[1].Symbol)
+	assert.InDelta(t, 20.0, report.Exposures[1].Notional, 1e-9)
+	assert.InDelta(t, 0.25, report.Exposures[1].Drawdown, 1e-9)
+
+	require.Len(t, report.Halts, 1)
+	assert.Equal(t, "PUMP/SOL", report.Halts[0].Symbol)
+	assert.True(t, report.Halts[0].Until.Equal(now.Add(time.Hour)))
+
+	require.Len(t, report.Violations, 1)
+	assert.Equal(t, LimitMaxDrawdown, report.Violations[0].Limit)
+	assert.True(t, report.Violations[0].At.Equal(now))
+
+	t.Run("EmptySections", func(t *testing.T) {
+		manager := NewManager(testLimits(), zap.NewNop())
+		data, err := manager.ReportJSON(context.Background(), nil, &types.RiskMetrics{UserID: "user1"})
+		require.NoError(t, err)
+		assert.Contains(t, string(data), `"exposures":[]`)
+		assert.Contains(t, string(data), `"halts":[]`)
+		assert.Contains(t, string(data), `"violations":[]`)
+	})
+
+	t.Run("ViolationsCapped", func(t *testing.T) {
+		manager := NewManager(testLimits(), zap.NewNop())
+		for i := 0; i < maxRecentViolations+5; i++ {
+			manager.CheckPositionRisk(context.Background(), &types.Position{Symbol: "X", Quantity: 5000, AvgPrice: 1})
+		}
+		assert.Len(t, manager.RecentViolations(), maxRecentViolations)
+	})
+}
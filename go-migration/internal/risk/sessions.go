@@ -0,0 +1,172 @@
+package risk
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// ErrOutsideSession is returned for orders placed outside the allowed
+// trading sessions or during a blackout
+var ErrOutsideSession = errors.New("outside trading session")
+
+// sessionClock is the layout of Session start and end times
+const sessionClock = "15:04"
+
+// SessionSchedule restricts when orders are accepted. With no Sessions
+// trading is always open apart from Blackouts. Outside the schedule orders
+// are rejected, unless OffHoursMaxPositionSize is set, in which case they
+// are accepted up to that size.
+type SessionSchedule struct {
+	Sessions  []Session  `json:"sessions"`
+	Blackouts []Blackout `json:"blackouts"`
+	// Location is the IANA time zone sessions are given in; empty is UTC
+	Location                string  `json:"location"`
+	OffHoursMaxPositionSize float64 `json:"off_hours_max_position_size"`
+}
+
+// Session is a daily window on the given weekdays, with Start and End as
+// HH:MM. An End before Start runs past midnight into the next day. Empty
+// Days means every day.
+type Session struct {
+	Days  []time.Weekday `json:"days"`
+	Start string         `json:"start"`
+	End   string         `json:"end"`
+}
+
+// Blackout is a closed period such as scheduled maintenance
+type Blackout struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// IsZero reports whether the schedule leaves trading always open
+func (s SessionSchedule) IsZero() bool {
+	return len(s.Sessions) == 0 && len(s.Blackouts) == 0
+}
+
+// sessionSchedule returns the schedule configured for symbol
+func (l Limits) sessionSchedule(symbol string) SessionSchedule {
+	if schedule, ok := l.SymbolSessions[symbol]; ok {
+		return schedule
+	}
+	return l.Sessions
+}
+
+// Open reports whether t falls inside an allowed session and outside
+// every blackout
+func (s SessionSchedule) Open(t time.Time) (bool, error) {
+	for _, blackout := range s.Blackouts {
+		if !t.Before(blackout.Start) && t.Before(blackout.End) {
+			return false, nil
+		}
+	}
+	if len(s.Sessions) == 0 {
+		return true, nil
+	}
+
+	loc := time.UTC
+	if s.Location != "" {
+		var err error
+		if loc, err = time.LoadLocation(s.Location); err != nil {
+			return false, fmt.Errorf("invalid session location %q: %w", s.Location, err)
+		}
+	}
+	local := t.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+
+	for _, session := range s.Sessions {
+		start, end, err := session.bounds()
+		if err != nil {
+			return false, err
+		}
+		if start <= end {
+			if minute >= start && minute < end && session.onDay(local.Weekday()) {
+				return true, nil
+			}
+			continue
+		}
+		// Overnight: the late part belongs to today, the early part to
+		// the session that started yesterday
+		if minute >= start && session.onDay(local.Weekday()) {
+			return true, nil
+		}
+		if minute < end && session.onDay((local.Weekday()+6)%7) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// bounds returns the session start and end as minutes after midnight
+func (s Session) bounds() (start, end int, err error) {
+	startTime, err := time.Parse(sessionClock, s.Start)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid session start %q: %w", s.Start, err)
+	}
+	endTime, err := time.Parse(sessionClock, s.End)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid session end %q: %w", s.End, err)
+	}
+	return startTime.Hour()*60 + startTime.Minute(), endTime.Hour()*60 + endTime.Minute(), nil
+}
+
+func (s Session) onDay(day time.Weekday) bool {
+	if len(s.Days) == 0 {
+		return true
+	}
+	for _, d := range s.Days {
+		if d == day {
+			return true
+		}
+	}
+	return false
+}
+
+// validate reports malformed sessions and blackouts
+func (s SessionSchedule) validate() error {
+	if s.Location != "" {
+		if _, err := time.LoadLocation(s.Location); err != nil {
+			return fmt.Errorf("invalid session location %q: %w", s.Location, err)
+		}
+	}
+	for _, session := range s.Sessions {
+		if _, _, err := session.bounds(); err != nil {
+			return err
+		}
+	}
+	for _, blackout := range s.Blackouts {
+		if !blackout.End.After(blackout.Start) {
+			return fmt.Errorf("invalid blackout: end %s is not after start %s", blackout.End, blackout.Start)
+		}
+	}
+	if s.OffHoursMaxPositionSize < 0 {
+		return fmt.Errorf("invalid limit off_hours_max_position_size: must not be negative, got %v",
+			s.OffHoursMaxPositionSize)
+	}
+	return nil
+}
+
+// sessionMaxPositionSize returns the order size limit in force for symbol
+// now: MaxPositionSize inside a session, the tighter off-hours size
+// outside one, or ErrOutsideSession when off-hours trading is disabled
+func (m *Manager) sessionMaxPositionSize(symbol string) (float64, error) {
+	schedule := m.limits.sessionSchedule(symbol)
+	if schedule.IsZero() {
+		return m.limits.MaxPositionSize, nil
+	}
+
+	now := m.now()
+	open, err := schedule.Open(now)
+	if err != nil {
+		return 0, err
+	}
+	if open {
+		return m.limits.MaxPositionSize, nil
+	}
+	if schedule.OffHoursMaxPositionSize <= 0 {
+		return 0, fmt.Errorf("%w: %s at %s", ErrOutsideSession, symbol, now.Format(time.RFC3339))
+	}
+	return math.Min(m.limits.MaxPositionSize, schedule.OffHoursMaxPositionSize), nil
+}
@@ -0,0 +1,84 @@
+package risk
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+func TestManager_TradingSessions(t *testing.T) {
+	ctx := context.Background()
+	weekdays := []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+
+	limits := testLimits()
+	limits.MaxPositionSize = 100
+	limits.Sessions = SessionSchedule{
+		Sessions:                []Session{{Days: weekdays, Start: "00:00", End: "23:59"}},
+		OffHoursMaxPositionSize: 10,
+	}
+	limits.SymbolSessions = map[string]SessionSchedule{
+		// Overnight session that rejects outside it
+		"NIGHT/SOL": {Sessions: []Session{{Days: []time.Weekday{time.Friday}, Start: "22:00", End: "02:00"}}},
+	}
+	maintenance := Blackout{
+		Start: time.Date(2024, 6, 5, 12, 0, 0, 0, time.UTC),
+		End:   time.Date(2024, 6, 5, 13, 0, 0, 0, time.UTC),
+	}
+	limits.Sessions.Blackouts = []Blackout{maintenance}
+	require.NoError(t, limits.Validate())
+
+	manager := NewManager(limits, zap.NewNop())
+	var now time.Time
+	manager.now = func() time.Time { return now }
+
+	order := func(symbol string, qty float64) *types.Order {
+		return &types.Order{ID: "o", Symbol: symbol, Side: types.OrderSideBuy, Type: types.OrderTypeMarket, Quantity: qty}
+	}
+
+	// Wednesday inside the session: the full size limit applies
+	now = time.Date(2024, 6, 5, 10, 0, 0, 0, time.UTC)
+	assert.NoError(t, manager.CheckOrderRisk(ctx, order("TEST/SOL", 50)))
+
+	// Saturday: outside the session, only the off-hours size is allowed
+	now = time.Date(2024, 6, 8, 10, 0, 0, 0, time.UTC)
+	assert.ErrorIs(t, manager.CheckOrderRisk(ctx, order("TEST/SOL", 50)), ErrPositionSizeExceeded)
+	assert.NoError(t, manager.CheckOrderRisk(ctx, order("TEST/SOL", 5)))
+
+	// Maintenance blackout on a weekday
+	now = time.Date(2024, 6, 5, 12, 30, 0, 0, time.UTC)
+	assert.ErrorIs(t, manager.CheckOrderRisk(ctx, order("TEST/SOL", 50)), ErrPositionSizeExceeded)
+
+	// The overnight session opens Friday night and runs into Saturday
+	now = time.Date(2024, 6, 8, 1, 0, 0, 0, time.UTC)
+	assert.NoError(t, manager.CheckOrderRisk(ctx, order("NIGHT/SOL", 50)))
+	now = time.Date(2024, 6, 8, 3, 0, 0, 0, time.UTC)
+	assert.ErrorIs(t, manager.CheckOrderRisk(ctx, order("NIGHT/SOL", 5)), ErrOutsideSession)
+	now = time.Date(2024, 6, 6, 23, 0, 0, 0, time.UTC)
+	assert.ErrorIs(t, manager.CheckOrderRisk(ctx, order("NIGHT/SOL", 5)), ErrOutsideSession)
+}
+
+func TestSessionSchedule_Validate(t *testing.T) {
+	limits := testLimits()
+	limits.Sessions = SessionSchedule{Sessions: []Session{{Start: "9am", End: "17:00"}}, Location: "Nowhere/City"}
+	assert.Error(t, limits.Validate())
+
+	limits.Sessions = SessionSchedule{
+		Sessions: []Session{{Start: "09:00", End: "17:00"}},
+		Location: "America/New_York",
+	}
+	require.NoError(t, limits.Validate())
+
+	// 14:00 UTC is 10:00 in New York during daylight saving time
+	open, err := limits.Sessions.Open(time.Date(2024, 6, 5, 14, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.True(t, open)
+	open, err = limits.Sessions.Open(time.Date(2024, 6, 5, 22, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	assert.False(t, open)
+}
@@ -0,0 +1,86 @@
+package risk
+
+import (
+	"math"
+
+	"go.uber.org/zap"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+// SlippageScaleFunc returns the slippage limit for a symbol given the base
+// MaxSlippage, its current volatility and the reference volatility
+type SlippageScaleFunc func(base, volatility, reference float64) float64
+
+// LinearSlippageScale scales the base limit in proportion to volatility,
+// so it equals the base limit at the reference volatility
+func LinearSlippageScale(base, volatility, reference float64) float64 {
+	return base * volatility / reference
+}
+
+// SlippageScaling adjusts MaxSlippage by recent volatility. The scaled
+// limit is clamped to [MinSlippage, MaxScaledSlippage]; a zero bound is
+// not applied. A zero ReferenceVolatility disables scaling.
+type SlippageScaling struct {
+	ReferenceVolatility float64 `json:"reference_volatility"`
+	MinSlippage         float64 `json:"min_slippage"`
+	MaxScaledSlippage   float64 `json:"max_scaled_slippage"`
+	// Scale defaults to LinearSlippageScale
+	Scale SlippageScaleFunc `json:"-"`
+}
+
+// SetVolatility records the recent volatility of symbol used to scale its
+// slippage limit
+func (m *Manager) SetVolatility(symbol string, volatility float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.volatility[symbol] = volatility
+}
+
+// EffectiveMaxSlippage returns the slippage limit for symbol after
+// volatility scaling. Symbols without a volatility estimate use
+// MaxSlippage unscaled.
+func (m *Manager) EffectiveMaxSlippage(symbol string) float64 {
+	base := m.limits.MaxSlippage
+	scaling := m.limits.SlippageScaling
+	if base <= 0 || scaling.ReferenceVolatility <= 0 {
+		return base
+	}
+
+	m.mu.Lock()
+	vol, ok := m.volatility[symbol]
+	m.mu.Unlock()
+	if !ok {
+		return base
+	}
+
+	scale := scaling.Scale
+	if scale == nil {
+		scale = LinearSlippageScale
+	}
+
+	limit := scale(base, vol, scaling.ReferenceVolatility)
+	if scaling.MinSlippage > 0 {
+		limit = math.Max(limit, scaling.MinSlippage)
+	}
+	if scaling.MaxScaledSlippage > 0 {
+		limit = math.Min(limit, scaling.MaxScaledSlippage)
+	}
+	return limit
+}
+
+// checkSlippage rejects orders whose expected slippage exceeds the
+// symbol's effective slippage limit
+func (m *Manager) checkSlippage(order *types.Order, fields []zap.Field) error {
+	if m.limits.MaxSlippage <= 0 {
+		return nil
+	}
+
+	limit := m.EffectiveMaxSlippage(order.Symbol)
+	if order.Slippage > limit {
+		return newLimitError(LimitMaxSlippage, order.Slippage, limit,
+			"slippage exceeds limit: %f > %f", order.Slippage, limit)
+	}
+	m.warnNearMax(LimitMaxSlippage, order.Slippage, limit, fields...)
+	return nil
+}
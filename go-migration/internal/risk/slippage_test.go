@@ -0,0 +1,49 @@
+package risk
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+func TestManager_SlippageScaling(t *testing.T) {
+	ctx := context.Background()
+	limits := testLimits()
+	limits.MaxSlippage = 0.01
+	limits.SlippageScaling = SlippageScaling{
+		ReferenceVolatility: 0.05,
+		MinSlippage:         0.005,
+		MaxScaledSlippage:   0.03,
+	}
+	manager := NewManager(limits, zap.NewNop())
+
+	order := &types.Order{ID: "o1", UserID: "user1", Symbol: "TEST/SOL", Quantity: 1, Slippage: 0.02}
+
+	// Without a volatility estimate the base limit applies
+	assert.Equal(t, 0.01, manager.EffectiveMaxSlippage("TEST/SOL"))
+	assert.ErrorIs(t, manager.CheckOrderRisk(ctx, order), ErrSlippageExceeded)
+
+	manager.SetVolatility("TEST/SOL", 0.1)
+	assert.InDelta(t, 0.02, manager.EffectiveMaxSlippage("TEST/SOL"), 1e-12, "the limit widens at high volatility")
+	assert.NoError(t, manager.CheckOrderRisk(ctx, order))
+
+	manager.SetVolatility("TEST/SOL", 1)
+	assert.Equal(t, 0.03, manager.EffectiveMaxSlippage("TEST/SOL"), "capped at the upper bound")
+
+	manager.SetVolatility("TEST/SOL", 0.001)
+	assert.Equal(t, 0.005, manager.EffectiveMaxSlippage("TEST/SOL"), "floored at the lower bound")
+
+	t.Run("CustomScale", func(t *testing.T) {
+		limits := limits
+		limits.SlippageScaling.Scale = func(base, volatility, reference float64) float64 {
+			return base * 2
+		}
+		manager := NewManager(limits, zap.NewNop())
+		manager.SetVolatility("TEST/SOL", 0.05)
+		assert.InDelta(t, 0.02, manager.EffectiveMaxSlippage("TEST/SOL"), 1e-12)
+	})
+}
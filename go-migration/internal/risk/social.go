@@ -0,0 +1,93 @@
+package risk
+
+import (
+	"time"
+)
+
+// scorePoint is a social score observed at a point in time
+type scorePoint struct {
+	score float64
+	at    time.Time
+}
+
+// RecordSocialScore adds a social score observation for symbol, dropping
+// observations older than SocialScoreWindow
+func (m *Manager) RecordSocialScore(symbol string, score float64) {
+	now := m.now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	history := append(m.social[symbol], scorePoint{score: score, at: now})
+	cutoff := now.Add(-m.limits.SocialScoreWindow)
+	start := 0
+	for start < len(history)-1 && history[start].at.Before(cutoff) {
+		start++
+	}
+	m.social[symbol] = append(history[:0], history[start:]...)
+}
+
+// CheckSocialScore rejects symbol when its time-weighted social score over
+// the window is below MinSocialScore, or when the latest score has fallen
+// more than MaxSocialScoreDecline from the start of the window. Symbols
+// with no recorded score are rejected.
+func (m *Manager) CheckSocialScore(symbol string) error {
+	minScore := m.limits.MinSocialScore
+	if minScore <= 0 {
+		return nil
+	}
+
+	now := m.now()
+	m.mu.Lock()
+	history := append([]scorePoint(nil), m.social[symbol]...)
+	m.mu.Unlock()
+
+	if len(history) == 0 {
+		return newLimitError(LimitMinSocialScore, 0, minScore,
+			"no social score recorded for %s", symbol)
+	}
+
+	first, latest := history[0], history[len(history)-1]
+	avg := timeWeightedScore(history, now)
+	if m.limits.SocialScoreWindow <= 0 {
+		avg = latest.score
+	}
+	if avg < minScore {
+		return newLimitError(LimitMinSocialScore, avg, minScore,
+			"social score below limit for %s: %f < %f", symbol, avg, minScore)
+	}
+
+	maxDecline := m.limits.MaxSocialScoreDecline
+	if maxDecline > 0 && first.score > 0 {
+		decline := (first.score - latest.score) / first.score
+		if decline > maxDecline {
+			return newLimitError(LimitMaxSocialScoreDecline, decline, maxDecline,
+				"social score for %s fell %.1f%% over the window (%f to %f)",
+				symbol, decline*100, first.score, latest.score)
+		}
+	}
+	return nil
+}
+
+// timeWeightedScore averages history weighting each score by how long it
+// held, the latest until now. Scores observed at the same instant count
+// equally.
+func timeWeightedScore(history []scorePoint, now time.Time) float64 {
+	var weighted, total float64
+	for i, point := range history {
+		end := now
+		if i+1 < len(history) {
+			end = history[i+1].at
+		}
+		held := end.Sub(point.at).Seconds()
+		weighted += point.score * held
+		total += held
+	}
+	if total <= 0 {
+		var sum float64
+		for _, point := range history {
+			sum += point.score
+		}
+		return sum / float64(len(history))
+	}
+	return weighted / total
+}
@@ -0,0 +1,50 @@
+package risk
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestManager_SocialScoreTrend(t *testing.T) {
+	limits := testLimits()
+	limits.MinSocialScore = 50
+	limits.SocialScoreWindow = 10 * time.Minute
+	limits.MaxSocialScoreDecline = 0.3
+	manager := NewManager(limits, zap.NewNop())
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	manager.now = func() time.Time { return now }
+
+	assert.ErrorIs(t, manager.CheckSocialScore("HYPE"), ErrSocialScoreTooLow, "no score recorded")
+
+	// Rising interest passes
+	for _, score := range []float64{60, 70, 80} {
+		manager.RecordSocialScore("HYPE", score)
+		now = now.Add(time.Minute)
+	}
+	assert.NoError(t, manager.CheckSocialScore("HYPE"))
+
+	// Still well above the minimum, but collapsing
+	for _, score := range []float64{100, 85, 65} {
+		manager.RecordSocialScore("FADE", score)
+		now = now.Add(time.Minute)
+	}
+	err := manager.CheckSocialScore("FADE")
+	assert.ErrorIs(t, err, ErrSocialScoreDeclining)
+
+	// The average holds a brief spike down above the minimum
+	for _, score := range []float64{80, 80, 40} {
+		manager.RecordSocialScore("DIP", score)
+		now = now.Add(time.Minute)
+	}
+	manager.limits.MaxSocialScoreDecline = 0
+	assert.NoError(t, manager.CheckSocialScore("DIP"), "time-weighted average is about 67")
+
+	// Old scores fall out of the window
+	now = now.Add(time.Hour)
+	manager.RecordSocialScore("DIP", 30)
+	assert.ErrorIs(t, manager.CheckSocialScore("DIP"), ErrSocialScoreTooLow)
+}
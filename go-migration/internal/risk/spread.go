@@ -0,0 +1,69 @@
+package risk
+
+import (
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+// ErrInvalidBook is returned for books the spread can't be quoted from:
+// one-sided books and crossed books whose bid is above their ask
+var ErrInvalidBook = errors.New("invalid order book")
+
+// recordedSpread is the last spread recorded for a symbol, or the reason
+// its book couldn't be quoted
+type recordedSpread struct {
+	spread float64
+	err    error
+}
+
+// QuotedSpread returns the bid-ask spread as a fraction of the mid price.
+// One-sided and crossed books fail with ErrInvalidBook.
+func QuotedSpread(bid, ask float64) (float64, error) {
+	if bid <= 0 || ask <= 0 {
+		return 0, fmt.Errorf("%w: one-sided book, bid %f, ask %f", ErrInvalidBook, bid, ask)
+	}
+	if bid > ask {
+		return 0, fmt.Errorf("%w: crossed book, bid %f > ask %f", ErrInvalidBook, bid, ask)
+	}
+	return (ask - bid) / ((ask + bid) / 2), nil
+}
+
+// RecordSpread records the current best bid and ask of symbol for the
+// spread check. An invalid book is recorded too, and blocks the symbol
+// until a valid one replaces it.
+func (m *Manager) RecordSpread(symbol string, bid, ask float64) {
+	spread, err := QuotedSpread(bid, ask)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.spreads[symbol] = recordedSpread{spread: spread, err: err}
+}
+
+// checkSpread rejects orders and positions in symbol while its last
+// recorded spread exceeds MaxSpread or its last book was invalid. Symbols
+// without a recorded spread pass.
+func (m *Manager) checkSpread(symbol string, fields ...zap.Field) error {
+	max := m.limits.MaxSpread
+	if max <= 0 {
+		return nil
+	}
+
+	m.mu.Lock()
+	recorded, ok := m.spreads[symbol]
+	m.mu.Unlock()
+	if !ok {
+		return nil
+	}
+	if recorded.err != nil {
+		return fmt.Errorf("%s: %w", symbol, recorded.err)
+	}
+
+	spread := recorded.spread
+	if spread > max {
+		return newLimitError(LimitMaxSpread, spread, max,
+			"%s spread exceeds limit: %f > %f", symbol, spread, max)
+	}
+	m.warnNearMax(LimitMaxSpread, spread, max, fields...)
+	return nil
+}
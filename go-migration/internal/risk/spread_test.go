@@ -0,0 +1,67 @@
+package risk
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+func TestQuotedSpread(t *testing.T) {
+	spread, err := QuotedSpread(99, 101)
+	require.NoError(t, err)
+	assert.InDelta(t, 0.02, spread, 1e-12)
+
+	_, err = QuotedSpread(0, 101)
+	assert.ErrorIs(t, err, ErrInvalidBook, "one-sided book")
+	_, err = QuotedSpread(101, 99)
+	assert.ErrorIs(t, err, ErrInvalidBook, "crossed book")
+}
+
+func TestManager_MaxSpread(t *testing.T) {
+	ctx := context.Background()
+	limits := DefaultLimits(TradingModePumpFun)
+	limits.MinOrderInterval = 0
+	manager := NewManager(limits, zap.NewNop())
+
+	position := &types.Position{Symbol: "MEME/SOL", Quantity: 1000, AvgPrice: 0.001}
+	order := &types.Order{ID: "o1", UserID: "user1", Symbol: "MEME/SOL", Quantity: 1000}
+
+	// Without a recorded spread nothing is flagged
+	assert.NoError(t, manager.CheckPositionRisk(ctx, position))
+
+	manager.RecordSpread("MEME/SOL", 0.00099, 0.00101)
+	assert.NoError(t, manager.CheckPositionRisk(ctx, position))
+	assert.NoError(t, manager.CheckOrderRisk(ctx, order))
+
+	// A 40% spread flags the position and blocks new orders
+	manager.RecordSpread("MEME/SOL", 0.0008, 0.0012)
+	assert.ErrorIs(t, manager.CheckPositionRisk(ctx, position), ErrSpreadExceeded)
+	assert.ErrorIs(t, manager.CheckOrderRisk(ctx, order), ErrSpreadExceeded)
+
+	// One-sided and crossed books block the symbol without counting as a
+	// limit violation, and the report still encodes
+	manager.RecordSpread("MEME/SOL", 0, 0.0012)
+	err := manager.CheckOrderRisk(ctx, order)
+	assert.ErrorIs(t, err, ErrInvalidBook)
+	var limitErr *LimitError
+	assert.False(t, errors.As(err, &limitErr))
+	assert.ErrorIs(t, manager.CheckPositionRisk(ctx, position), ErrInvalidBook)
+	_, err = manager.ReportJSON(ctx, []*types.Position{position}, &types.RiskMetrics{})
+	assert.NoError(t, err)
+
+	manager.RecordSpread("MEME/SOL", 0.0012, 0.0011)
+	assert.ErrorIs(t, manager.CheckOrderRisk(ctx, order), ErrInvalidBook)
+	manager.RecordSpread("MEME/SOL", 0.00099, 0.00101)
+	assert.NoError(t, manager.CheckOrderRisk(ctx, order))
+
+	// The DEX defaults leave the check off
+	dex := NewManager(DefaultLimits(TradingModeDEX), zap.NewNop())
+	dex.RecordSpread("MEME/SOL", 0.0008, 0.0012)
+	assert.NoError(t, dex.CheckPositionRisk(ctx, position))
+}
@@ -0,0 +1,172 @@
+package risk
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// RiskState is the manager state that must survive a restart so tripped
+// limits stay tripped: circuit breaker halts, order cooldowns and the
+// kill switch
+type RiskState struct {
+	SavedAt    time.Time  `json:"saved_at"`
+	Halts      []Halt     `json:"halts"`
+	Cooldowns  []Cooldown `json:"cooldowns"`
+	KillSwitch KillSwitch `json:"kill_switch"`
+}
+
+// Cooldown is the last accepted order of a user in a symbol
+type Cooldown struct {
+	UserID    string    `json:"user_id"`
+	Symbol    string    `json:"symbol"`
+	LastOrder time.Time `json:"last_order"`
+}
+
+// RiskStateStore persists RiskState. LoadRiskState returns nil when
+// nothing has been saved yet.
+type RiskStateStore interface {
+	LoadRiskState(ctx context.Context) (*RiskState, error)
+	SaveRiskState(ctx context.Context, state *RiskState) error
+}
+
+// MemoryStateStore keeps the last saved RiskState in memory. It is the
+// default store, which preserves nothing across restarts.
+type MemoryStateStore struct {
+	state *RiskState
+	mu    sync.Mutex
+}
+
+// NewMemoryStateStore creates an empty in-memory store
+func NewMemoryStateStore() *MemoryStateStore {
+	return &MemoryStateStore{}
+}
+
+func (s *MemoryStateStore) LoadRiskState(ctx context.Context) (*RiskState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.state, nil
+}
+
+func (s *MemoryStateStore) SaveRiskState(ctx context.Context, state *RiskState) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state = state
+	return nil
+}
+
+// SetStateStore sets the store SaveState and LoadState use
+func (m *Manager) SetStateStore(store RiskStateStore) {
+	m.store = store
+}
+
+// State returns the current halts and cooldowns. Expired halts and
+// cooldowns that no longer block an order are left out.
+func (m *Manager) State() *RiskState {
+	now := m.now()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state := &RiskState{SavedAt: now.UTC(), Halts: m.activeHalts(now), Cooldowns: []Cooldown{}, KillSwitch: m.kill}
+	for key, at := range m.lastOrders {
+		if now.Sub(at) >= m.limits.minOrderInterval(key.symbol) {
+			continue
+		}
+		state.Cooldowns = append(state.Cooldowns, Cooldown{UserID: key.userID, Symbol: key.symbol, LastOrder: at})
+	}
+	sort.Slice(state.Cooldowns, func(i, j int) bool {
+		a, b := state.Cooldowns[i], state.Cooldowns[j]
+		if a.UserID != b.UserID {
+			return a.UserID < b.UserID
+		}
+		return a.Symbol < b.Symbol
+	})
+	return state
+}
+
+// SaveState persists State to the state store
+func (m *Manager) SaveState(ctx context.Context) error {
+	if err := m.store.SaveRiskState(ctx, m.State()); err != nil {
+		return fmt.Errorf("failed to save risk state: %w", err)
+	}
+	return nil
+}
+
+// LoadState restores halts and cooldowns from the state store, keeping
+// whichever of the stored and current ones ends later, and re-engages a
+// stored kill switch. It should be called once on startup, before any
+// checks.
+func (m *Manager) LoadState(ctx context.Context) error {
+	state, err := m.store.LoadRiskState(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load risk state: %w", err)
+	}
+	if state == nil {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, halt := range state.Halts {
+		breaker, exists := m.breakers[halt.Symbol]
+		if !exists {
+			breaker = &symbolBreaker{}
+			m.breakers[halt.Symbol] = breaker
+		}
+		if halt.Until.After(breaker.haltedUntil) {
+			breaker.haltedUntil = halt.Until
+			breaker.resuming = halt.Resuming
+		}
+	}
+	for _, cooldown := range state.Cooldowns {
+		key := orderKey{userID: cooldown.UserID, symbol: cooldown.Symbol}
+		if cooldown.LastOrder.After(m.lastOrders[key]) {
+			m.lastOrders[key] = cooldown.LastOrder
+		}
+	}
+	if state.KillSwitch.Engaged && !m.kill.Engaged {
+		m.setKillSwitch(true, state.KillSwitch.Reason)
+		m.kill.Since = state.KillSwitch.Since
+	}
+	return nil
+}
+
+// RunStatePersistence saves the state every interval until ctx is done,
+// and once more on the way out
+func (m *Manager) RunStatePersistence(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if err := m.SaveState(context.Background()); err != nil {
+				m.logger.Error("Failed to save risk state on shutdown", zap.Error(err))
+			}
+			return
+		case <-ticker.C:
+			if err := m.SaveState(ctx); err != nil {
+				m.logger.Error("Failed to save risk state", zap.Error(err))
+			}
+		}
+	}
+}
+
+// activeHalts returns the symbols halted at now, sorted. Must be called
+// with m.mu held.
+func (m *Manager) activeHalts(now time.Time) []Halt {
+	halts := []Halt{}
+	for symbol, breaker := range m.breakers {
+		if now.Before(breaker.haltedUntil) {
+			halts = append(halts, Halt{Symbol: symbol, Until: breaker.haltedUntil, Resuming: breaker.resuming})
+		}
+	}
+	sort.Slice(halts, func(i, j int) bool {
+		return halts[i].Symbol < halts[j].Symbol
+	})
+	return halts
+}
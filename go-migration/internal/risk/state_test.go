@@ -0,0 +1,114 @@
+package risk
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+// failingStateStore fails every load and save
+type failingStateStore struct{}
+
+func (failingStateStore) LoadRiskState(ctx context.Context) (*RiskState, error) {
+	return nil, errors.New("store down")
+}
+
+func (failingStateStore) SaveRiskState(ctx context.Context, state *RiskState) error {
+	return errors.New("store down")
+}
+
+func TestManager_StatePersistence(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	limits := testLimits()
+	limits.CircuitBreaker = CircuitBreakerConfig{MaxMove: 0.3, Window: time.Minute, Cooldown: 5 * time.Minute}
+	limits.MinOrderInterval = time.Minute
+	store := NewMemoryStateStore()
+
+	manager := NewManager(limits, zap.NewNop())
+	manager.now = clock
+	manager.SetStateStore(store)
+
+	// Trip the breaker on TEST/SOL and start a cooldown on OTHER/SOL
+	manager.UpdatePrice(&types.PriceUpdate{Symbol: "TEST/SOL", Price: 100})
+	manager.UpdatePrice(&types.PriceUpdate{Symbol: "TEST/SOL", Price: 150})
+	order := &types.Order{ID: "o1", UserID: "user1", Symbol: "TEST/SOL", Quantity: 1}
+	other := &types.Order{ID: "o2", UserID: "user1", Symbol: "OTHER/SOL", Quantity: 1}
+	require.ErrorIs(t, manager.CheckOrderRisk(ctx, order), ErrTradingHalted)
+	require.NoError(t, manager.CheckOrderRisk(ctx, other))
+	manager.RecordOrder(other.UserID, other.Symbol)
+	require.NoError(t, manager.SaveState(ctx))
+
+	// A restarted manager picks the lockout back up from the store
+	now = now.Add(30 * time.Second)
+	restarted := NewManager(limits, zap.NewNop())
+	restarted.now = clock
+	restarted.SetStateStore(store)
+	require.NoError(t, restarted.LoadState(ctx))
+	assert.ErrorIs(t, restarted.CheckOrderRisk(ctx, order), ErrTradingHalted)
+	assert.ErrorIs(t, restarted.CheckOrderRisk(ctx, other), ErrOrderCooldown)
+
+	state := restarted.State()
+	assert.Equal(t, []Halt{{Symbol: "TEST/SOL", Until: now.Add(-30*time.Second + 5*time.Minute)}}, state.Halts)
+	assert.Len(t, state.Cooldowns, 1)
+
+	// Once the halt expires it is no longer persisted
+	now = now.Add(10 * time.Minute)
+	assert.NoError(t, restarted.CheckOrderRisk(ctx, order))
+	assert.Empty(t, restarted.State().Halts)
+
+	t.Run("DefaultStoreIsEmpty", func(t *testing.T) {
+		fresh := NewManager(limits, zap.NewNop())
+		assert.NoError(t, fresh.LoadState(ctx))
+		assert.Empty(t, fresh.State().Halts)
+	})
+
+	t.Run("ResumingHalt", func(t *testing.T) {
+		limits := testLimits()
+		limits.CircuitBreaker = CircuitBreakerConfig{
+			MaxMove:       0.3,
+			Window:        time.Minute,
+			Cooldown:      5 * time.Minute,
+			ResumeMaxMove: 0.05,
+			ResumeWindow:  time.Minute,
+		}
+		store := NewMemoryStateStore()
+		manager := NewManager(limits, zap.NewNop())
+		manager.now = clock
+		manager.SetStateStore(store)
+		manager.UpdatePrice(&types.PriceUpdate{Symbol: "TEST/SOL", Price: 100})
+		manager.UpdatePrice(&types.PriceUpdate{Symbol: "TEST/SOL", Price: 150})
+		require.NoError(t, manager.SaveState(ctx))
+		until := now.Add(5 * time.Minute)
+
+		// After a restart, volatility near the end of the halt still
+		// extends it
+		restarted := NewManager(limits, zap.NewNop())
+		restarted.now = clock
+		restarted.SetStateStore(store)
+		require.NoError(t, restarted.LoadState(ctx))
+		assert.Equal(t, []Halt{{Symbol: "TEST/SOL", Until: until, Resuming: true}}, restarted.State().Halts)
+
+		now = until.Add(-10 * time.Second)
+		restarted.UpdatePrice(&types.PriceUpdate{Symbol: "TEST/SOL", Price: 150})
+		now = now.Add(5 * time.Second)
+		restarted.UpdatePrice(&types.PriceUpdate{Symbol: "TEST/SOL", Price: 165})
+		assert.Equal(t, now.Add(time.Minute), restarted.HaltedUntil("TEST/SOL"))
+	})
+
+	t.Run("StoreErrors", func(t *testing.T) {
+		broken := NewManager(limits, zap.NewNop())
+		broken.SetStateStore(failingStateStore{})
+		assert.ErrorContains(t, broken.LoadState(ctx), "store down")
+		assert.ErrorContains(t, broken.SaveState(ctx), "store down")
+	})
+}
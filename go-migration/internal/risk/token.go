@@ -0,0 +1,47 @@
+package risk
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+// ErrAuthorityNotRenounced is returned for tokens whose creator can still
+// mint supply or freeze holder accounts
+var ErrAuthorityNotRenounced = errors.New("token authority not renounced")
+
+// CheckTokenMetadata rejects tokens whose mint or freeze authority is
+// still held, since either lets the creator rug holders
+func (m *Manager) CheckTokenMetadata(metadata *types.TokenMetadata) error {
+	if !metadata.MintAuthorityRenounced {
+		return fmt.Errorf("%w: %s mint authority held by %q",
+			ErrAuthorityNotRenounced, metadata.Mint, metadata.MintAuthority)
+	}
+	if !metadata.FreezeAuthorityRenounced {
+		return fmt.Errorf("%w: %s freeze authority held by %q",
+			ErrAuthorityNotRenounced, metadata.Mint, metadata.FreezeAuthority)
+	}
+	return nil
+}
+
+// CheckLiquidity rejects tokens whose liquidity is below
+// MinLiquidityToMarketCap of their market cap. A large cap backed by
+// almost no liquidity cannot be exited at anything near its quoted price.
+func (m *Manager) CheckLiquidity(symbol string, liquidity, marketCap float64) error {
+	m.mu.Lock()
+	minRatio := m.limits.MinLiquidityToMarketCap
+	m.mu.Unlock()
+
+	if minRatio <= 0 || marketCap <= 0 {
+		return nil
+	}
+
+	ratio := liquidity / marketCap
+	if ratio < minRatio {
+		return newLimitError(LimitMinLiquidityToMarketCap, ratio, minRatio,
+			"%s liquidity %f is %.4f of market cap %f, below limit %.4f",
+			symbol, liquidity, ratio, marketCap, minRatio)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package risk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+func TestManager_CheckTokenMetadata(t *testing.T) {
+	manager := NewManager(testLimits(), zap.NewNop())
+
+	renounced := &types.TokenMetadata{
+		Mint:                     "mint1",
+		Symbol:                   "SAFE",
+		Decimals:                 6,
+		MintAuthorityRenounced:   true,
+		FreezeAuthorityRenounced: true,
+	}
+	assert.NoError(t, manager.CheckTokenMetadata(renounced))
+
+	mintable := *renounced
+	mintable.MintAuthorityRenounced = false
+	mintable.MintAuthority = "creator1"
+	assert.ErrorIs(t, manager.CheckTokenMetadata(&mintable), ErrAuthorityNotRenounced)
+
+	freezable := *renounced
+	freezable.FreezeAuthorityRenounced = false
+	freezable.FreezeAuthority = "creator1"
+	assert.ErrorIs(t, manager.CheckTokenMetadata(&freezable), ErrAuthorityNotRenounced)
+}
+
+func TestManager_CheckLiquidity(t *testing.T) {
+	limits := testLimits()
+	limits.MinLiquidityToMarketCap = 0.05
+	manager := NewManager(limits, zap.NewNop())
+
+	assert.NoError(t, manager.CheckLiquidity("DEEP", 10000, 100000))
+
+	err := manager.CheckLiquidity("TRAP", 500, 1000000)
+	assert.ErrorIs(t, err, ErrLiquidityTooLow, "a high cap with thin liquidity is rejected")
+	var limitErr *LimitError
+	if assert.ErrorAs(t, err, &limitErr) {
+		assert.InDelta(t, 0.0005, limitErr.Observed, 1e-12)
+		assert.Equal(t, 0.05, limitErr.Threshold)
+	}
+
+	disabled := NewManager(testLimits(), zap.NewNop())
+	assert.NoError(t, disabled.CheckLiquidity("TRAP", 500, 1000000))
+}
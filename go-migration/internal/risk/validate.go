@@ -0,0 +1,185 @@
+package risk
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+// Unlimited disables a limit where zero would block everything. Zero
+// MaxPositionSize, MaxDrawdown or MaxDailyLoss rejects every order or
+// position with any size or loss, so Validate treats those as mistakes;
+// set the limit to Unlimited to turn it off instead. Other limits are
+// disabled by leaving them zero, as documented on each field.
+const Unlimited = math.MaxFloat64
+
+// namedLimit pairs a limit value with the name used in errors
+type namedLimit struct {
+	name  string
+	value float64
+}
+
+// Validate reports every nonsensical value or combination in l. A nil
+// result means the limits can be used as is.
+func (l Limits) Validate() error {
+	var errs []error
+	if err := l.checkNonNegative(); err != nil {
+		errs = append(errs, err)
+	}
+
+	blocking := []namedLimit{
+		{LimitMaxPositionSize, l.MaxPositionSize},
+		{LimitMaxDrawdown, l.MaxDrawdown},
+		{LimitMaxDailyLoss, l.MaxDailyLoss},
+	}
+	for _, v := range blocking {
+		if v.value == 0 {
+			errs = append(errs, fmt.Errorf("invalid limit %s: zero blocks everything, use Unlimited to disable it", v.name))
+		}
+	}
+
+	fractions := []namedLimit{
+		{LimitMaxConcentration, l.MaxConcentration},
+		{LimitMinLiquidityToMarketCap, l.MinLiquidityToMarketCap},
+		{"warn_ratio", l.WarnRatio},
+		{LimitMaxSocialScoreDecline, l.MaxSocialScoreDecline},
+		{"margin_rate", l.MarginRate},
+		{LimitMaxGasToNotional, l.MaxGasToNotional},
+		{"holder_scaling.min_fraction", l.HolderScaling.MinFraction},
+		{LimitMaxPriceImpact, l.MaxPriceImpact},
+	}
+	if l.MaxDrawdown != Unlimited {
+		fractions = append(fractions, namedLimit{LimitMaxDrawdown, l.MaxDrawdown})
+	}
+	for _, v := range fractions {
+		if v.value > 1 {
+			errs = append(errs, fmt.Errorf("invalid limit %s: fraction must be at most 1, got %v", v.name, v.value))
+		}
+	}
+	for category, value := range l.MaxCategoryConcentration {
+		if value > 1 {
+			errs = append(errs, fmt.Errorf("invalid limit %s[%s]: fraction must be at most 1, got %v",
+				LimitMaxCategoryConcentration, category, value))
+		}
+	}
+
+	if l.CircuitBreaker.MaxMove > 0 && l.CircuitBreaker.Window <= 0 {
+		errs = append(errs, errors.New("invalid circuit breaker: max_move is set without a window"))
+	}
+	if l.CircuitBreaker.ResumeMaxMove > 0 && l.CircuitBreaker.ResumeWindow <= 0 {
+		errs = append(errs, errors.New("invalid circuit breaker: resume_max_move is set without a resume_window"))
+	}
+
+	scaling := l.SlippageScaling
+	if scaling.ReferenceVolatility > 0 && l.MaxSlippage <= 0 {
+		errs = append(errs, errors.New("invalid slippage scaling: reference_volatility is set without max_slippage"))
+	}
+	if scaling.MinSlippage > 0 && scaling.MaxScaledSlippage > 0 && scaling.MinSlippage > scaling.MaxScaledSlippage {
+		errs = append(errs, fmt.Errorf("invalid slippage scaling: min_slippage %v exceeds max_scaled_slippage %v",
+			scaling.MinSlippage, scaling.MaxScaledSlippage))
+	}
+
+	if err := validateGraduationTiers(l.GraduationTiers); err != nil {
+		errs = append(errs, err)
+	}
+	if err := l.Sessions.validate(); err != nil {
+		errs = append(errs, err)
+	}
+	for symbol, schedule := range l.SymbolSessions {
+		if err := schedule.validate(); err != nil {
+			errs = append(errs, fmt.Errorf("symbol %s: %w", symbol, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// checkNonNegative reports every limit with a negative value
+func (l Limits) checkNonNegative() error {
+	values := []namedLimit{
+		{LimitMaxPositionSize, l.MaxPositionSize},
+		{LimitMaxDrawdown, l.MaxDrawdown},
+		{LimitMaxDailyLoss, l.MaxDailyLoss},
+		{LimitMaxLeverage, l.MaxLeverage},
+		{LimitMinMarginLevel, l.MinMarginLevel},
+		{LimitMaxConcentration, l.MaxConcentration},
+		{LimitMinLiquidityToMarketCap, l.MinLiquidityToMarketCap},
+		{LimitMaxSlippage, l.MaxSlippage},
+		{"warn_ratio", l.WarnRatio},
+		{"circuit_breaker.max_move", l.CircuitBreaker.MaxMove},
+		{"circuit_breaker.window", float64(l.CircuitBreaker.Window)},
+		{"circuit_breaker.cooldown", float64(l.CircuitBreaker.Cooldown)},
+		{"circuit_breaker.resume_max_move", l.CircuitBreaker.ResumeMaxMove},
+		{"circuit_breaker.resume_window", float64(l.CircuitBreaker.ResumeWindow)},
+		{"min_order_interval", float64(l.MinOrderInterval)},
+		{"max_holding_period", float64(l.MaxHoldingPeriod)},
+		{"slippage_scaling.reference_volatility", l.SlippageScaling.ReferenceVolatility},
+		{"slippage_scaling.min_slippage", l.SlippageScaling.MinSlippage},
+		{"slippage_scaling.max_scaled_slippage", l.SlippageScaling.MaxScaledSlippage},
+		{LimitMinSocialScore, l.MinSocialScore},
+		{"social_score_window", float64(l.SocialScoreWindow)},
+		{LimitMaxSocialScoreDecline, l.MaxSocialScoreDecline},
+		{LimitMaxBookImbalance, l.MaxBookImbalance},
+		{"margin_rate", l.MarginRate},
+		{LimitMaxGasToNotional, l.MaxGasToNotional},
+		{"holder_scaling.full_size_holders", float64(l.HolderScaling.FullSizeHolders)},
+		{"holder_scaling.min_fraction", l.HolderScaling.MinFraction},
+		{LimitMaxPriceImpact, l.MaxPriceImpact},
+		{LimitMaxSpread, l.MaxSpread},
+		{LimitMaxPoolShare, l.MaxPoolShare},
+	}
+	var errs []error
+	for _, v := range values {
+		if v.value < 0 {
+			errs = append(errs, fmt.Errorf("invalid limit %s: must not be negative, got %v", v.name, v.value))
+		}
+	}
+	for category, value := range l.MaxCategoryConcentration {
+		if value < 0 {
+			errs = append(errs, fmt.Errorf("invalid limit %s[%s]: must not be negative, got %v",
+				LimitMaxCategoryConcentration, category, value))
+		}
+	}
+	for limit, value := range l.HysteresisBuffers {
+		if value < 0 || value >= 1 {
+			errs = append(errs, fmt.Errorf("invalid hysteresis buffer for %s: must be in [0, 1), got %v", limit, value))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// isFinite reports whether v is neither NaN nor infinite
+func isFinite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
+
+// validateOrderInput rejects orders whose quantity is not positive, whose
+// price is negative or whose numeric fields are NaN or infinite. Limit
+// orders must have a price.
+func validateOrderInput(order *types.Order) error {
+	if !isFinite(order.Quantity) || order.Quantity <= 0 {
+		return fmt.Errorf("%w: order %s quantity %f must be positive", ErrInvalidInput, order.ID, order.Quantity)
+	}
+	if !isFinite(order.Price) || order.Price < 0 {
+		return fmt.Errorf("%w: order %s price %f must not be negative", ErrInvalidInput, order.ID, order.Price)
+	}
+	if order.Type == types.OrderTypeLimit && order.Price == 0 {
+		return fmt.Errorf("%w: limit order %s has no price", ErrInvalidInput, order.ID)
+	}
+	if !isFinite(order.Slippage) || !isFinite(order.PriceImpact) {
+		return fmt.Errorf("%w: order %s slippage or price impact is not finite", ErrInvalidInput, order.ID)
+	}
+	return nil
+}
+
+// validatePositionInput rejects positions with NaN or infinite values
+func validatePositionInput(position *types.Position) error {
+	if !isFinite(position.Quantity) || !isFinite(position.AvgPrice) || position.AvgPrice < 0 ||
+		!isFinite(position.UnrealizedPnL) || !isFinite(position.RealizedPnL) {
+		return fmt.Errorf("%w: position %s has quantity %f, average price %f, unrealized PnL %f",
+			ErrInvalidInput, position.Symbol, position.Quantity, position.AvgPrice, position.UnrealizedPnL)
+	}
+	return nil
+}
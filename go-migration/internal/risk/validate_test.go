@@ -0,0 +1,96 @@
+package risk
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+func TestLimits_Validate(t *testing.T) {
+	assert.NoError(t, testLimits().Validate())
+	assert.NoError(t, DefaultLimits(TradingModeDEX).Validate())
+	assert.NoError(t, DefaultLimits(TradingModePumpFun).Validate())
+
+	unlimited := testLimits()
+	unlimited.MaxPositionSize = Unlimited
+	unlimited.MaxDrawdown = Unlimited
+	unlimited.MaxDailyLoss = Unlimited
+	assert.NoError(t, unlimited.Validate(), "Unlimited disables a limit explicitly")
+
+	invalid := map[string]func(l *Limits){
+		"zero position size": func(l *Limits) { l.MaxPositionSize = 0 },
+		"zero daily loss":    func(l *Limits) { l.MaxDailyLoss = 0 },
+		"negative drawdown":  func(l *Limits) { l.MaxDrawdown = -0.1 },
+		"drawdown over 100%": func(l *Limits) { l.MaxDrawdown = 1.5 },
+		"concentration":      func(l *Limits) { l.MaxConcentration = 2 },
+		"category":           func(l *Limits) { l.MaxCategoryConcentration = map[string]float64{"meme": 1.2} },
+		"warn ratio":         func(l *Limits) { l.WarnRatio = 1.5 },
+		"negative interval":  func(l *Limits) { l.MinOrderInterval = -time.Second },
+		"breaker window":     func(l *Limits) { l.CircuitBreaker = CircuitBreakerConfig{MaxMove: 0.2} },
+		"scaling base":       func(l *Limits) { l.SlippageScaling.ReferenceVolatility = 0.05 },
+		"scaling band": func(l *Limits) {
+			l.MaxSlippage = 0.01
+			l.SlippageScaling = SlippageScaling{ReferenceVolatility: 0.05, MinSlippage: 0.05, MaxScaledSlippage: 0.02}
+		},
+	}
+	for name, mutate := range invalid {
+		limits := testLimits()
+		mutate(&limits)
+		assert.Error(t, limits.Validate(), name)
+	}
+
+	// Every problem is reported, not just the first
+	limits := testLimits()
+	limits.MaxPositionSize = 0
+	limits.MaxConcentration = 2
+	err := limits.Validate()
+	assert.ErrorContains(t, err, LimitMaxPositionSize)
+	assert.ErrorContains(t, err, LimitMaxConcentration)
+
+	// Including every negative limit
+	limits = testLimits()
+	limits.MaxDrawdown = -0.1
+	limits.MaxSpread = -1
+	limits.HysteresisBuffers = map[string]float64{LimitMaxDrawdown: 2}
+	err = limits.Validate()
+	assert.ErrorContains(t, err, LimitMaxDrawdown+": must not be negative")
+	assert.ErrorContains(t, err, LimitMaxSpread+": must not be negative")
+	assert.ErrorContains(t, err, "invalid hysteresis buffer")
+}
+
+func TestManager_MalformedInputs(t *testing.T) {
+	ctx := context.Background()
+	manager := NewManager(testLimits(), zap.NewNop())
+
+	orders := map[string]*types.Order{
+		"zero quantity":     {Type: types.OrderTypeMarket, Quantity: 0},
+		"negative quantity": {Type: types.OrderTypeMarket, Quantity: -1},
+		"NaN quantity":      {Type: types.OrderTypeMarket, Quantity: math.NaN()},
+		"zero-price limit":  {Type: types.OrderTypeLimit, Quantity: 1},
+		"negative price":    {Type: types.OrderTypeLimit, Price: -1, Quantity: 1},
+		"infinite price":    {Type: types.OrderTypeLimit, Price: math.Inf(1), Quantity: 1},
+		"NaN slippage":      {Type: types.OrderTypeMarket, Quantity: 1, Slippage: math.NaN()},
+	}
+	for name, order := range orders {
+		order.ID, order.Symbol = name, "TEST/SOL"
+		assert.ErrorIs(t, manager.CheckOrderRisk(ctx, order), ErrInvalidInput, name)
+	}
+
+	nanPosition := &types.Position{Symbol: "TEST/SOL", Quantity: 10, AvgPrice: math.NaN()}
+	assert.ErrorIs(t, manager.CheckPositionRisk(ctx, nanPosition), ErrInvalidInput)
+	assert.ErrorIs(t, manager.CheckPortfolioRisk(ctx, []*types.Position{nanPosition}), ErrInvalidInput)
+
+	// A zero average price would make drawdown infinite; it is skipped
+	free := &types.Position{Symbol: "TEST/SOL", Quantity: 10, UnrealizedPnL: -5}
+	assert.NoError(t, manager.CheckPositionRisk(ctx, free))
+
+	current := []*types.Position{{Symbol: "TEST/SOL", Quantity: 1, AvgPrice: 10}}
+	bad := []*types.Order{{ID: "bad", Symbol: "TEST/SOL", Side: types.OrderSideBuy, Quantity: math.Inf(1)}}
+	assert.ErrorIs(t, manager.CheckProposedPortfolio(ctx, current, bad), ErrInvalidInput)
+}
@@ -0,0 +1,57 @@
+package trading
+
+// StrategyPerformance is the PnL of the trades attributed to one strategy,
+// computed as if the strategy held its own positions apart from the
+// engine's shared ones
+type StrategyPerformance struct {
+	StrategyID string `json:"strategy_id"`
+	// RealizedPnL is net of Fees; UnrealizedPnL is marked at the mark
+	// price, and is zero without a MarkPricer
+	RealizedPnL   float64     `json:"realized_pnl"`
+	UnrealizedPnL float64     `json:"unrealized_pnl"`
+	Fees          float64     `json:"fees"`
+	Trades        int         `json:"trades"`
+	Positions     []*Position `json:"positions"`
+}
+
+// StrategyPnL replays the trades tagged with strategyID into per-symbol
+// positions and returns their combined PnL. Open positions are included
+// in Positions; flat ones only contribute to RealizedPnL.
+func (e *Engine) StrategyPnL(strategyID string) StrategyPerformance {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	perf := StrategyPerformance{StrategyID: strategyID}
+	positions := make(map[string]*Position)
+	var symbols []string
+	for _, trade := range e.trades {
+		if trade.StrategyID != strategyID {
+			continue
+		}
+		pos, exists := positions[trade.Symbol]
+		if !exists {
+			pos = &Position{UserID: trade.UserID, Symbol: trade.Symbol}
+			positions[trade.Symbol] = pos
+			symbols = append(symbols, trade.Symbol)
+		}
+		e.applyToPosition(pos, trade)
+		perf.Fees += trade.Fee
+		perf.Trades++
+	}
+
+	for _, symbol := range symbols {
+		pos := positions[symbol]
+		perf.RealizedPnL += pos.RealizedPnL
+		if pos.Quantity == 0 {
+			continue
+		}
+		if e.markPrices != nil {
+			if mark, err := e.markPrices.MarkPrice(symbol); err == nil {
+				pos.UnrealizedPnL = (mark - pos.AvgPrice) * pos.Quantity
+				perf.UnrealizedPnL += pos.UnrealizedPnL
+			}
+		}
+		perf.Positions = append(perf.Positions, pos)
+	}
+	return perf
+}
@@ -0,0 +1,52 @@
+package trading
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngine_StrategyPnL(t *testing.T) {
+	engine, storage := newTestEngine(t)
+	place := func(id, strategy string, side OrderSide, qty float64) {
+		require.NoError(t, engine.PlaceOrder(&Order{
+			ID: id, UserID: "user1", Symbol: "TEST/SOL", Side: side, Type: OrderTypeMarket,
+			Quantity: qty, Status: OrderStatusNew, StrategyID: strategy, Tags: []string{"test"},
+		}))
+	}
+
+	place("momentum-buy", "momentum", OrderSideBuy, 10)
+	require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: "momentum-buy", Price: 100, Quantity: 10, Fee: 1}))
+	place("revert-buy", "revert", OrderSideBuy, 5)
+	require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: "revert-buy", Price: 110, Quantity: 5, Fee: 0.5}))
+	place("momentum-sell", "momentum", OrderSideSell, 10)
+	require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: "momentum-sell", Price: 120, Quantity: 10, Fee: 1}))
+	place("manual", "", OrderSideBuy, 1)
+	require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: "manual", Price: 120, Quantity: 1}))
+
+	assert.Equal(t, "momentum", storage.trades[0].StrategyID)
+	assert.Equal(t, []string{"test"}, storage.trades[0].Tags)
+
+	// The shared position blends both strategies' entries
+	assert.Equal(t, 6.0, engine.GetPosition("TEST/SOL").Quantity)
+
+	engine.SetMarkPricer(staticMarks{"TEST/SOL": 130})
+
+	momentum := engine.StrategyPnL("momentum")
+	assert.Equal(t, 2, momentum.Trades)
+	assert.InDelta(t, 2.0, momentum.Fees, 1e-9)
+	assert.InDelta(t, 198.0, momentum.RealizedPnL, 1e-9)
+	assert.Zero(t, momentum.UnrealizedPnL)
+	assert.Empty(t, momentum.Positions)
+
+	revert := engine.StrategyPnL("revert")
+	assert.Equal(t, 1, revert.Trades)
+	assert.InDelta(t, -0.5, revert.RealizedPnL, 1e-9)
+	assert.InDelta(t, 100.0, revert.UnrealizedPnL, 1e-9)
+	require.Len(t, revert.Positions, 1)
+	assert.Equal(t, 5.0, revert.Positions[0].Quantity)
+	assert.Equal(t, 110.0, revert.Positions[0].AvgPrice)
+
+	assert.Zero(t, engine.StrategyPnL("unknown").Trades)
+}
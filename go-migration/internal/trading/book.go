@@ -0,0 +1,96 @@
+package trading
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+// PostOnlyPolicy controls what happens to post-only orders that would
+// cross the book at placement
+type PostOnlyPolicy string
+
+const (
+	// PostOnlyReject rejects crossing post-only orders with
+	// ErrPostOnlyWouldCross. This is the default.
+	PostOnlyReject PostOnlyPolicy = "reject"
+	// PostOnlyReprice moves a crossing post-only order to the best price
+	// on its own side of the book so it rests as a maker order
+	PostOnlyReprice PostOnlyPolicy = "reprice"
+)
+
+// UpdateOrderBook records the latest order book for its symbol
+func (e *Engine) UpdateOrderBook(book *OrderBook) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.books[book.Symbol] = book
+}
+
+// GetOrderBook returns the latest order book recorded for symbol
+func (e *Engine) GetOrderBook(symbol string) (*OrderBook, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	book, exists := e.books[symbol]
+	if !exists {
+		return nil, fmt.Errorf("no order book for %s", symbol)
+	}
+	return book, nil
+}
+
+// BestBidAsk returns the highest bid and lowest ask in book, zero for an
+// empty side
+func (b *OrderBook) BestBidAsk() (bid, ask float64) {
+	for _, level := range b.Bids {
+		if level.Price > bid {
+			bid = level.Price
+		}
+	}
+	for _, level := range b.Asks {
+		if ask == 0 || level.Price < ask {
+			ask = level.Price
+		}
+	}
+	return bid, ask
+}
+
+// checkPostOnly rejects or reprices a post-only limit order that would
+// immediately match the current book
+func (e *Engine) checkPostOnly(order *Order) error {
+	if !order.PostOnly {
+		return nil
+	}
+	if order.Type != OrderTypeLimit || order.Price <= 0 {
+		return fmt.Errorf("post-only order %s must be a limit order with a price", order.ID)
+	}
+
+	e.mu.RLock()
+	book := e.books[order.Symbol]
+	e.mu.RUnlock()
+	if book == nil {
+		return fmt.Errorf("no order book for post-only order on %s", order.Symbol)
+	}
+
+	bid, ask := book.BestBidAsk()
+	var crosses bool
+	var rest float64
+	if order.Side == OrderSideBuy {
+		crosses, rest = ask > 0 && order.Price >= ask, bid
+	} else {
+		crosses, rest = bid > 0 && order.Price <= bid, ask
+	}
+	if !crosses {
+		return nil
+	}
+
+	if e.config.PostOnlyPolicy != PostOnlyReprice || rest <= 0 {
+		return fmt.Errorf("%w: %s %s at %f (bid %f, ask %f)",
+			ErrPostOnlyWouldCross, order.ID, order.Side, order.Price, bid, ask)
+	}
+
+	e.logLifecycle("Repriced post-only order", order,
+		zap.Float64("from", order.Price),
+		zap.Float64("to", rest))
+	order.Price = rest
+	return nil
+}
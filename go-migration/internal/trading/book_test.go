@@ -0,0 +1,66 @@
+package trading
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func postOnlyOrder(id string, side OrderSide, price float64) *Order {
+	return &Order{
+		ID:       id,
+		UserID:   "user1",
+		Symbol:   "TEST/SOL",
+		Side:     side,
+		Type:     OrderTypeLimit,
+		Price:    price,
+		Quantity: 1,
+		Status:   OrderStatusNew,
+		PostOnly: true,
+	}
+}
+
+func TestEngine_PostOnly(t *testing.T) {
+	engine, _ := newTestEngine(t)
+	engine.UpdateOrderBook(&OrderBook{
+		Symbol: "TEST/SOL",
+		Bids:   []OrderBookLevel{{Price: 99, Quantity: 5}, {Price: 98, Quantity: 5}},
+		Asks:   []OrderBookLevel{{Price: 101, Quantity: 5}, {Price: 102, Quantity: 5}},
+	})
+
+	err := engine.PlaceOrder(postOnlyOrder("cross-buy", OrderSideBuy, 101))
+	assert.ErrorIs(t, err, ErrPostOnlyWouldCross)
+	err = engine.PlaceOrder(postOnlyOrder("cross-sell", OrderSideSell, 98))
+	assert.ErrorIs(t, err, ErrPostOnlyWouldCross)
+	_, err = engine.GetOrder("cross-buy")
+	assert.ErrorIs(t, err, ErrOrderNotFound)
+
+	resting := postOnlyOrder("rest-buy", OrderSideBuy, 100)
+	require.NoError(t, engine.PlaceOrder(resting))
+	assert.Equal(t, 100.0, resting.Price)
+	assert.Equal(t, OrderStatusNew, resting.Status)
+
+	t.Run("Reprice", func(t *testing.T) {
+		engine.config.PostOnlyPolicy = PostOnlyReprice
+		defer func() { engine.config.PostOnlyPolicy = "" }()
+
+		buy := postOnlyOrder("reprice-buy", OrderSideBuy, 105)
+		require.NoError(t, engine.PlaceOrder(buy))
+		assert.Equal(t, 99.0, buy.Price, "buys rest at the best bid")
+
+		sell := postOnlyOrder("reprice-sell", OrderSideSell, 95)
+		require.NoError(t, engine.PlaceOrder(sell))
+		assert.Equal(t, 101.0, sell.Price, "sells rest at the best ask")
+	})
+
+	t.Run("RequiresBookAndLimit", func(t *testing.T) {
+		order := postOnlyOrder("no-book", OrderSideBuy, 100)
+		order.Symbol = "OTHER/SOL"
+		assert.Error(t, engine.PlaceOrder(order))
+
+		order = postOnlyOrder("market", OrderSideBuy, 100)
+		order.Type = OrderTypeMarket
+		assert.Error(t, engine.PlaceOrder(order))
+	})
+}
@@ -0,0 +1,46 @@
+package trading
+
+// CostBreakdown splits what a fill cost relative to the price the order
+// expected, in quote currency; positive amounts are costs and negative
+// ones improvements. Market is how far the reference price moved from
+// the expected price before execution, Impact how far the fill landed from
+// the reference price and Fee the fee charged. Total is their sum: the
+// fill's value against the expected value, plus the fee.
+type CostBreakdown struct {
+	ExpectedPrice float64 `json:"expected_price"`
+	Market        float64 `json:"market"`
+	Impact        float64 `json:"impact"`
+	Fee           float64 `json:"fee"`
+	Total         float64 `json:"total"`
+}
+
+// attributeCosts breaks down the cost of trade filling order. The expected
+// price is the order's price, or the trade's reference price for orders
+// without one; without a reference price the whole price difference is
+// counted as impact. Returns nil when there is nothing to compare against.
+func attributeCosts(order *Order, trade *Trade) *CostBreakdown {
+	expected := order.Price
+	if expected <= 0 {
+		expected = trade.RefPrice
+	}
+	if expected <= 0 {
+		return nil
+	}
+	ref := trade.RefPrice
+	if ref <= 0 {
+		ref = expected
+	}
+
+	direction := 1.0
+	if trade.Side == OrderSideSell {
+		direction = -1.0
+	}
+	costs := &CostBreakdown{
+		ExpectedPrice: expected,
+		Market:        (ref - expected) * trade.Quantity * direction,
+		Impact:        (trade.Price - ref) * trade.Quantity * direction,
+		Fee:           trade.Fee,
+	}
+	costs.Total = costs.Market + costs.Impact + costs.Fee
+	return costs
+}
@@ -0,0 +1,54 @@
+package trading
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestEngine_CostBreakdown(t *testing.T) {
+	engine, _ := newTestEngine(t)
+
+	// Expected 100, the market moved to 101 and the fill took 101.5
+	require.NoError(t, engine.PlaceOrder(&Order{ID: "buy1", UserID: "user1", Symbol: "TEST/SOL",
+		Side: OrderSideBuy, Type: OrderTypeLimit, Price: 100, Quantity: 10}))
+	trade := &Trade{OrderID: "buy1", Price: 101.5, Quantity: 10, Fee: 1.015, RefPrice: 101}
+	require.NoError(t, engine.ExecuteTrade(trade))
+
+	costs := trade.Costs
+	require.NotNil(t, costs)
+	assert.Equal(t, 100.0, costs.ExpectedPrice)
+	assert.InDelta(t, 10.0, costs.Market, 1e-9)
+	assert.InDelta(t, 5.0, costs.Impact, 1e-9)
+	assert.InDelta(t, 1.015, costs.Fee, 1e-9)
+	assert.InDelta(t, costs.Market+costs.Impact+costs.Fee, costs.Total, 1e-9)
+	assert.InDelta(t, (trade.Price-100)*trade.Quantity+trade.Fee, costs.Total, 1e-9)
+
+	t.Run("PaperSell", func(t *testing.T) {
+		config := testConfig()
+		config.Commission = 0.001
+		engine := NewEngine(config, zap.NewNop(), &memStorage{})
+		paper := NewPaperExecutor(engine, FixedBpsSlippage{Bps: 10}, zap.NewNop())
+
+		placeTestOrder(t, engine, "sell1", OrderSideSell, 100)
+		trade, err := paper.Fill("sell1", 50, nil)
+		require.NoError(t, err)
+
+		// A market order expects the reference price, so selling below it
+		// is all impact
+		require.NotNil(t, trade.Costs)
+		assert.Zero(t, trade.Costs.Market)
+		assert.InDelta(t, (50-trade.Price)*100, trade.Costs.Impact, 1e-9)
+		assert.InDelta(t, trade.Fee, trade.Costs.Fee, 1e-9)
+		assert.InDelta(t, trade.Costs.Impact+trade.Costs.Fee, trade.Costs.Total, 1e-9)
+	})
+
+	t.Run("NoExpectedPrice", func(t *testing.T) {
+		placeTestOrder(t, engine, "buy2", OrderSideBuy, 1)
+		trade := &Trade{OrderID: "buy2", Price: 100, Quantity: 1}
+		require.NoError(t, engine.ExecuteTrade(trade))
+		assert.Nil(t, trade.Costs)
+	})
+}
@@ -0,0 +1,86 @@
+package trading
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// DustSweepTag tags the trades SweepDust records
+const DustSweepTag = "dust_sweep"
+
+// SweepDust flattens open positions whose notional at the mark price (or
+// the entry price without a mark source) is below threshold. Each residual
+// is closed by a dust-sweep trade at that price, tagged DustSweepTag and
+// with no order behind it, booking its PnL as realized. Returns the
+// recorded trades sorted by symbol.
+func (e *Engine) SweepDust(threshold float64) ([]*Trade, error) {
+	if !(threshold > 0) || math.IsInf(threshold, 0) {
+		return nil, fmt.Errorf("invalid dust threshold %f", threshold)
+	}
+
+	e.mu.Lock()
+	var symbols []string
+	for symbol, pos := range e.positions {
+		if pos.Quantity != 0 {
+			symbols = append(symbols, symbol)
+		}
+	}
+	sort.Strings(symbols)
+
+	now := time.Now()
+	var swept []*Trade
+	var positions []*Position
+	for _, symbol := range symbols {
+		pos := e.positions[symbol]
+		price := pos.AvgPrice
+		if e.markPrices != nil {
+			if mark, err := e.markPrices.MarkPrice(symbol); err == nil {
+				price = mark
+			}
+		}
+		if math.Abs(pos.Quantity)*price >= threshold {
+			continue
+		}
+
+		side := OrderSideSell
+		if pos.Quantity < 0 {
+			side = OrderSideBuy
+		}
+		trade := &Trade{
+			ID:        fmt.Sprintf("dust-%s-%d", symbol, len(e.trades)+1),
+			UserID:    pos.UserID,
+			Symbol:    symbol,
+			Side:      side,
+			Price:     price,
+			Quantity:  math.Abs(pos.Quantity),
+			Timestamp: now,
+			Tags:      []string{DustSweepTag},
+		}
+		e.applyToPosition(pos, trade)
+		e.trades = append(e.trades, trade)
+		e.recordEvent(&Event{Type: EventDustSwept, Timestamp: now, Trade: trade})
+		swept = append(swept, trade)
+		positions = append(positions, pos)
+
+		e.logger.Info("Swept dust position",
+			zap.String("symbol", symbol),
+			zap.Float64("quantity", trade.Quantity),
+			zap.Float64("price", price),
+			zap.Float64("realized_pnl", pos.RealizedPnL))
+	}
+	e.mu.Unlock()
+
+	for i, trade := range swept {
+		if err := e.storage.SaveTrade(trade); err != nil {
+			return swept, fmt.Errorf("failed to save dust sweep for %s: %w", trade.Symbol, err)
+		}
+		if err := e.storage.SavePosition(positions[i]); err != nil {
+			return swept, fmt.Errorf("failed to save swept position %s: %w", trade.Symbol, err)
+		}
+	}
+	return swept, nil
+}
@@ -0,0 +1,56 @@
+package trading
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngine_SweepDust(t *testing.T) {
+	engine, storage := newTestEngine(t)
+	log := NewMemoryEventLog()
+	engine.SetEventLog(log)
+	engine.SetMarkPricer(staticMarks{"DUST/SOL": 2, "KEEP/SOL": 2})
+
+	fill := func(id, symbol string, side OrderSide, price, qty float64) {
+		t.Helper()
+		require.NoError(t, engine.PlaceOrder(&Order{ID: id, UserID: "user1", Symbol: symbol,
+			Side: side, Type: OrderTypeMarket, Quantity: qty}))
+		require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: id, Price: price, Quantity: qty}))
+	}
+
+	// 0.05 DUST left after selling most of it, worth 0.1 at the mark
+	fill("d-buy", "DUST/SOL", OrderSideBuy, 1, 10)
+	fill("d-sell", "DUST/SOL", OrderSideSell, 2, 9.95)
+	fill("k-buy", "KEEP/SOL", OrderSideBuy, 1, 10)
+
+	swept, err := engine.SweepDust(1)
+	require.NoError(t, err)
+	require.Len(t, swept, 1)
+	trade := swept[0]
+	assert.Equal(t, "DUST/SOL", trade.Symbol)
+	assert.Equal(t, OrderSideSell, trade.Side)
+	assert.InDelta(t, 0.05, trade.Quantity, 1e-9)
+	assert.Equal(t, 2.0, trade.Price)
+	assert.Equal(t, []string{DustSweepTag}, trade.Tags)
+	assert.Same(t, trade, storage.trades[len(storage.trades)-1])
+
+	dust := engine.GetPosition("DUST/SOL")
+	assert.Zero(t, dust.Quantity)
+	assert.InDelta(t, 10.0, dust.RealizedPnL, 1e-9, "the residual's gain is realized")
+	assert.Equal(t, 10.0, engine.GetPosition("KEEP/SOL").Quantity)
+
+	// Flat positions aren't swept again
+	swept, err = engine.SweepDust(1)
+	require.NoError(t, err)
+	assert.Empty(t, swept)
+
+	replayed, _ := newTestEngine(t)
+	require.NoError(t, replayed.ReplayFrom(log))
+	assert.Zero(t, replayed.GetPosition("DUST/SOL").Quantity)
+	assert.InDelta(t, 10.0, replayed.GetPosition("DUST/SOL").RealizedPnL, 1e-9)
+
+	_, err = engine.SweepDust(0)
+	assert.Error(t, err)
+}
@@ -1,12 +1,30 @@
 package trading
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"math"
+	"math/rand"
+	"sort"
 	"sync"
+	"time"
 
 	"go.uber.org/zap"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
 )
 
+// MarkPricer resolves the price positions are marked at
+type MarkPricer interface {
+	MarkPrice(symbol string) (float64, error)
+}
+
+// BalanceProvider supplies account margin for pre-trade buying power checks
+type BalanceProvider interface {
+	GetRiskMetrics(userID string) (*types.RiskMetrics, error)
+}
+
 // Engine manages trading operations
 type Engine struct {
 	logger     *zap.Logger
@@ -14,47 +32,218 @@ type Engine struct {
 	storage    Storage
 	positions  map[string]*Position
 	orders     map[string]*Order
-	mu         sync.RWMutex
+	terminal   map[string]*Order
+	trades     []*Trade
+	fillSubs   map[chan *Trade]struct{}
+	funding    []*FundingEntry
+	schedules  map[string]*sliceSchedule
+	spreads    map[string]*Spread
+	markPrices MarkPricer
+	balances   BalanceProvider
+	fees       FeeModel
+	// pendingSaves are orders accepted under StoragePolicyRetry that
+	// storage has not yet saved
+	pendingSaves []*Order
+	walMu        sync.Mutex
+	// buckets are the per-user order rate limiters and rateQueue holds
+	// rate-limited orders waiting to be resubmitted
+	buckets   map[string]*tokenBucket
+	rateQueue []*queuedOrder
+	books     map[string]*OrderBook
+	risk      OrderRiskChecker
+	// events receives every state change; replaying suppresses it while
+	// ReplayFrom rebuilds state
+	events    EventLog
+	eventSeq  uint64
+	replaying bool
+	// execQueue holds fills queued by QueueExecution; execSeq numbers
+	// them in arrival order
+	execQueue PriorityQueue
+	execSeq   uint64
+	// submitters are the venues SubmitOrder can send orders through and
+	// rng draws every random delay the engine applies
+	submitters map[string]Submitter
+	rng        *rand.Rand
+	// priceSeen is when each symbol last had a price update and poller
+	// the fallback used once a symbol's stream stalls
+	priceSeen map[string]time.Time
+	poller    PricePoller
+	mu        sync.RWMutex
 }
 
 // NewEngine creates a new trading engine
 func NewEngine(config Config, logger *zap.Logger, storage Storage) *Engine {
 	return &Engine{
-		logger:    logger,
-		config:    config,
-		storage:   storage,
-		positions: make(map[string]*Position),
-		orders:    make(map[string]*Order),
+		logger:     logger,
+		config:     config,
+		storage:    storage,
+		positions:  make(map[string]*Position),
+		orders:     make(map[string]*Order),
+		terminal:   make(map[string]*Order),
+		fillSubs:   make(map[chan *Trade]struct{}),
+		schedules:  make(map[string]*sliceSchedule),
+		spreads:    make(map[string]*Spread),
+		buckets:    make(map[string]*tokenBucket),
+		books:      make(map[string]*OrderBook),
+		submitters: make(map[string]Submitter),
+		rng:        rand.New(rand.NewSource(time.Now().UnixNano())),
+		priceSeen:  make(map[string]time.Time),
 	}
 }
 
 // PlaceOrder places a new order
 func (e *Engine) PlaceOrder(order *Order) error {
+	return e.placeOrder(context.Background(), order)
+}
+
+// placeOrder validates and risk-checks order under ctx and places it, or
+// queues it when its user is rate limited
+func (e *Engine) placeOrder(ctx context.Context, order *Order) error {
+	if err := e.screenOrder(ctx, order); err != nil {
+		return err
+	}
+
+	if !e.takeToken(order.UserID) {
+		return e.queueRateLimited(order)
+	}
+	return e.placeValidated(order)
+}
+
+// screenOrder resolves and validates order and runs it through the risk
+// checker, logging it as rejected when any of them fails
+func (e *Engine) screenOrder(ctx context.Context, order *Order) error {
+	if order.CorrelationID == "" {
+		order.CorrelationID = newCorrelationID()
+	}
+
+	if isPegged(order) {
+		if err := e.validatePeg(order); err != nil {
+			e.logLifecycle("Order rejected", order, zap.Error(err))
+			return err
+		}
+	}
+
+	if err := e.resolveQuoteAmount(order); err != nil {
+		e.logLifecycle("Order rejected", order, zap.Error(err))
+		return err
+	}
+
 	// Validate order
 	if err := e.validateOrder(order); err != nil {
+		e.logLifecycle("Order rejected", order, zap.Error(err))
+		return err
+	}
+
+	if err := e.checkRisk(ctx, order); err != nil {
+		e.logLifecycle("Order rejected", order, zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// placeValidated runs the pre-trade checks on a validated order and stores
+// it
+func (e *Engine) placeValidated(order *Order) error {
+	if err := e.checkPreTrade(order); err != nil {
+		e.logLifecycle("Order rejected", order, zap.Error(err))
 		return err
 	}
 
+	if order.Type == OrderTypeIceberg {
+		order.DisplayQty = math.Min(order.VisibleQty, order.Quantity-order.FilledQty)
+	}
+
 	// Store order
 	e.mu.Lock()
+	if err := e.admitOrder(order); err != nil {
+		e.mu.Unlock()
+		if !errors.Is(err, ErrDuplicateOrder) {
+			e.logLifecycle("Order rejected", order, zap.Error(err))
+		}
+		return err
+	}
 	e.orders[order.ID] = order
+	e.recordOrder(EventOrderPlaced, order)
 	e.mu.Unlock()
 
-	return e.storage.SaveOrder(order)
+	e.logLifecycle("Order placed", order,
+		zap.String("side", string(order.Side)),
+		zap.String("type", string(order.Type)),
+		zap.Float64("quantity", order.Quantity),
+		zap.Float64("price", order.Price))
+
+	// The risk checker only learns of orders storage or the WAL has taken,
+	// so a rolled back order doesn't start a cooldown
+	if err := e.storage.SaveOrder(order); err != nil {
+		if err := e.handleSaveFailure(order, err); err != nil {
+			return err
+		}
+	}
+	e.recordAccepted(order)
+	return nil
+}
+
+// checkPreTrade runs the checks every order passes before it is stored:
+// post-only, buying power and the exit guard
+func (e *Engine) checkPreTrade(order *Order) error {
+	if err := e.checkPostOnly(order); err != nil {
+		return err
+	}
+	if err := e.checkBuyingPower(order); err != nil {
+		return err
+	}
+	return e.checkExitGuard(order)
 }
 
-// CancelOrder cancels an existing order
+// admitOrder rejects orders with an ID already in use or that would break
+// the open position and per-symbol order caps. Must be called with e.mu
+// held.
+func (e *Engine) admitOrder(order *Order) error {
+	if _, exists := e.lookupOrder(order.ID); exists {
+		return fmt.Errorf("%w: %s", ErrDuplicateOrder, order.ID)
+	}
+	if err := e.checkMaxPositions(order); err != nil {
+		return err
+	}
+	return e.checkMaxSymbolOrders(order)
+}
+
+// CancelOrder cancels an existing order. Canceling an order that is
+// already filled, canceled or rejected is a no-op so retried cancels
+// succeed; the order keeps its terminal status.
 func (e *Engine) CancelOrder(orderID string) error {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
+	if order, exists := e.terminal[orderID]; exists {
+		e.logger.Debug("Order already terminal, ignoring cancel",
+			zap.String("order_id", orderID),
+			zap.String("status", string(order.Status)))
+		return nil
+	}
+
 	order, exists := e.orders[orderID]
 	if !exists {
-		return fmt.Errorf("order not found: %s", orderID)
+		return fmt.Errorf("%w: %s", ErrOrderNotFound, orderID)
 	}
 
+	if spread, ok := e.spreads[order.SpreadID]; ok {
+		return e.cancelSpread(spread, time.Now())
+	}
+
+	from := order.Status
 	order.Status = OrderStatusCanceled
-	delete(e.orders, orderID)
+	order.UpdatedAt = time.Now()
+	e.retireOrder(order)
+	e.logTransition(order, from)
+	e.recordOrder(EventOrderCanceled, order)
+	e.finishParent(order)
+
+	if schedule, ok := e.schedules[orderID]; ok {
+		if err := e.cancelChildren(schedule, order.UpdatedAt); err != nil {
+			return err
+		}
+	}
 
 	return e.storage.SaveOrder(order)
 }
@@ -64,9 +253,9 @@ func (e *Engine) GetOrder(orderID string) (*Order, error) {
 	e.mu.RLock()
 	defer e.mu.RUnlock()
 
-	order, exists := e.orders[orderID]
+	order, exists := e.lookupOrder(orderID)
 	if !exists {
-		return nil, fmt.Errorf("order not found: %s", orderID)
+		return nil, fmt.Errorf("%w: %s", ErrOrderNotFound, orderID)
 	}
 	return order, nil
 }
@@ -76,13 +265,262 @@ func (e *Engine) GetOrders(userID string) ([]*Order, error) {
 	e.mu.RLock()
 	defer e.mu.RUnlock()
 
-	var orders []*Order
-	for _, order := range e.orders {
-		if order.UserID == userID {
-			orders = append(orders, order)
+	return e.queryOrders(OrderFilter{UserID: userID}), nil
+}
+
+// OrderFilter selects orders in QueryOrders. Empty fields match any value.
+type OrderFilter struct {
+	UserID string
+	Symbol string
+	Status OrderStatus
+}
+
+// QueryOrders returns open and retained terminal orders matching filter
+func (e *Engine) QueryOrders(filter OrderFilter) []*Order {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.queryOrders(filter)
+}
+
+// EvictTerminalOrders drops terminal orders last updated more than maxAge
+// before now and returns how many were evicted. Evicted orders remain in
+// storage.
+func (e *Engine) EvictTerminalOrders(now time.Time, maxAge time.Duration) int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	evicted := 0
+	for id, order := range e.terminal {
+		if now.Sub(order.UpdatedAt) > maxAge {
+			delete(e.terminal, id)
+			e.recordOrder(EventOrderEvicted, order)
+			evicted++
 		}
 	}
-	return orders, nil
+	return evicted
+}
+
+// RunOrderEviction periodically evicts terminal orders older than
+// Config.OrderRetention until ctx is done. It returns immediately when no
+// retention is configured.
+func (e *Engine) RunOrderEviction(ctx context.Context) {
+	if e.config.OrderRetention <= 0 {
+		return
+	}
+
+	interval := e.config.UpdateInterval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if n := e.EvictTerminalOrders(now, e.config.OrderRetention); n > 0 {
+				e.logger.Debug("Evicted terminal orders", zap.Int("count", n))
+			}
+		}
+	}
+}
+
+// ExecuteTrade applies a fill to its order and updates the resulting position
+func (e *Engine) ExecuteTrade(trade *Trade) error {
+	if err := e.revalidateAged(trade); err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	order, err := e.checkFill(trade)
+	if err == nil && order.SpreadID != "" {
+		err = fmt.Errorf("%w: %s is a spread leg, fill it with ExecuteSpread",
+			ErrInvalidFill, order.ID)
+	}
+	if err == nil {
+		if err = e.checkMinFill(order, trade); err != nil && e.cancelIOC(order) {
+			e.mu.Unlock()
+			e.logLifecycle("IOC order canceled below minimum fill", order)
+			if saveErr := e.storage.SaveOrder(order); saveErr != nil {
+				return saveErr
+			}
+			return err
+		}
+	}
+	if err != nil {
+		e.mu.Unlock()
+		return err
+	}
+	fill := e.applyFill(order, trade)
+	e.cancelIOC(order)
+	e.mu.Unlock()
+
+	return e.saveFill(fill)
+}
+
+// appliedFill holds everything a fill changed, for persisting after the
+// engine lock is released
+type appliedFill struct {
+	trade    *Trade
+	order    *Order
+	parent   *Order
+	position *Position
+}
+
+// checkFill returns the order trade applies to, or an error if the fill
+// is not allowed. Must be called with e.mu held.
+func (e *Engine) checkFill(trade *Trade) (*Order, error) {
+	order, exists := e.lookupOrder(trade.OrderID)
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrOrderNotFound, trade.OrderID)
+	}
+
+	if order.Status.IsTerminal() {
+		return nil, fmt.Errorf("%w: %s is %s", ErrOrderTerminal, order.ID, order.Status)
+	}
+
+	if isStop(order) && !order.Triggered {
+		return nil, fmt.Errorf("%w: stop order %s has not triggered",
+			ErrInvalidFill, order.ID)
+	}
+
+	if _, isParent := e.schedules[order.ID]; isParent {
+		return nil, fmt.Errorf("%w: %s is a sliced parent, fill its children",
+			ErrInvalidFill, order.ID)
+	}
+
+	if !isFinite(trade.Price) || trade.Price <= 0 {
+		return nil, fmt.Errorf("%w: price %f must be positive", ErrInvalidFill, trade.Price)
+	}
+
+	remaining := order.Quantity - order.FilledQty
+	if order.Type == OrderTypeIceberg {
+		remaining = order.DisplayQty
+	}
+	if trade.Quantity <= 0 || trade.Quantity > remaining {
+		return nil, fmt.Errorf("%w: %f (remaining %f)",
+			ErrInvalidFill, trade.Quantity, remaining)
+	}
+	return order, nil
+}
+
+// applyFill applies a checked trade to order, its parent and the position.
+// Must be called with e.mu held.
+func (e *Engine) applyFill(order *Order, trade *Trade) appliedFill {
+	if trade.ID == "" {
+		trade.ID = fmt.Sprintf("%s-%d", order.ID, len(e.trades)+1)
+	}
+	if trade.Timestamp.IsZero() {
+		trade.Timestamp = time.Now()
+	}
+	trade.UserID = order.UserID
+	trade.Symbol = order.Symbol
+	trade.Side = order.Side
+	trade.StrategyID = order.StrategyID
+	if trade.Tags == nil {
+		trade.Tags = order.Tags
+	}
+	trade.Costs = attributeCosts(order, trade)
+
+	from := order.Status
+	order.FilledQty += trade.Quantity
+	order.UpdatedAt = trade.Timestamp
+	if order.FilledQty >= order.Quantity {
+		order.Status = OrderStatusFilled
+		e.retireOrder(order)
+	} else {
+		order.Status = OrderStatusPartial
+	}
+	e.logLifecycle("Order fill applied", order,
+		zap.String("trade_id", trade.ID),
+		zap.Float64("price", trade.Price),
+		zap.Float64("quantity", trade.Quantity),
+		zap.Float64("filled_qty", order.FilledQty))
+	e.logTransition(order, from)
+	if order.Type == OrderTypeIceberg {
+		e.replenishIceberg(order, trade.Quantity)
+	}
+	parent := e.aggregateChildFill(order, trade)
+
+	position := e.updatePosition(trade)
+	e.trades = append(e.trades, trade)
+	e.recordEvent(&Event{Type: EventOrderFilled, Timestamp: trade.Timestamp, Trade: trade})
+	if parent != nil {
+		e.recordOrder(EventOrderUpdated, parent)
+	}
+	if e.events != nil && !e.replaying {
+		snapshot := *position
+		snapshot.Lots = append([]Lot(nil), position.Lots...)
+		e.recordEvent(&Event{Type: EventPositionUpdated, Timestamp: trade.Timestamp, Position: &snapshot})
+	}
+	e.logLifecycle("Position updated", order,
+		zap.Float64("position_qty", position.Quantity),
+		zap.Float64("avg_price", position.AvgPrice),
+		zap.Float64("realized_pnl", position.RealizedPnL))
+
+	if !e.replaying {
+		for sub := range e.fillSubs {
+			select {
+			case sub <- trade:
+			default:
+				e.logger.Warn("Fill subscriber channel full",
+					zap.String("order_id", trade.OrderID))
+			}
+		}
+	}
+
+	return appliedFill{trade: trade, order: order, parent: parent, position: position}
+}
+
+// saveFill persists the trade and the records it changed
+func (e *Engine) saveFill(fill appliedFill) error {
+	if err := e.storage.SaveTrade(fill.trade); err != nil {
+		return err
+	}
+	if err := e.storage.SaveOrder(fill.order); err != nil {
+		return err
+	}
+	if fill.parent != nil {
+		if err := e.storage.SaveOrder(fill.parent); err != nil {
+			return err
+		}
+	}
+	return e.storage.SavePosition(fill.position)
+}
+
+// GetTrades returns all executed trades for a user
+func (e *Engine) GetTrades(userID string) ([]*Trade, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	var trades []*Trade
+	for _, trade := range e.trades {
+		if trade.UserID == userID {
+			trades = append(trades, trade)
+		}
+	}
+	return trades, nil
+}
+
+// SubscribeFills returns a channel receiving every executed trade until ctx is done
+func (e *Engine) SubscribeFills(ctx context.Context) <-chan *Trade {
+	fills := make(chan *Trade, 100)
+
+	e.mu.Lock()
+	e.fillSubs[fills] = struct{}{}
+	e.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		e.mu.Lock()
+		delete(e.fillSubs, fills)
+		close(fills)
+		e.mu.Unlock()
+	}()
+
+	return fills
 }
 
 // GetPosition returns current position for a symbol
@@ -104,16 +542,462 @@ func (e *Engine) GetPositions() []*Position {
 	return positions
 }
 
+// ClosePosition places a reduce-only market order closing fraction of the
+// user's position in symbol, 1 for a full close. It returns the placed
+// order, or nil without error when the position is flat.
+func (e *Engine) ClosePosition(userID, symbol string, fraction float64) (*Order, error) {
+	if fraction <= 0 || fraction > 1 {
+		return nil, fmt.Errorf("invalid close fraction %f", fraction)
+	}
+
+	e.mu.RLock()
+	pos, exists := e.positions[symbol]
+	var qty float64
+	if exists && pos.UserID == userID {
+		qty = pos.Quantity
+	}
+	e.mu.RUnlock()
+
+	if qty == 0 {
+		return nil, nil
+	}
+
+	side := OrderSideSell
+	if qty < 0 {
+		side = OrderSideBuy
+	}
+	now := time.Now()
+	order := &Order{
+		ID:         "close-" + newCorrelationID(),
+		UserID:     userID,
+		Symbol:     symbol,
+		Side:       side,
+		Type:       OrderTypeMarket,
+		Quantity:   math.Abs(qty) * fraction,
+		Status:     OrderStatusNew,
+		ReduceOnly: true,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	if err := e.PlaceOrder(order); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+// StalePositions returns open positions not updated within olderThan,
+// oldest first
+func (e *Engine) StalePositions(olderThan time.Duration) []*Position {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	cutoff := time.Now().Add(-olderThan)
+	var stale []*Position
+	for _, pos := range e.positions {
+		if pos.Quantity != 0 && pos.UpdatedAt.Before(cutoff) {
+			stale = append(stale, pos)
+		}
+	}
+	sort.Slice(stale, func(i, j int) bool {
+		return stale[i].UpdatedAt.Before(stale[j].UpdatedAt)
+	})
+	return stale
+}
+
+// SetBalanceProvider enables the pre-trade buying power check in PlaceOrder
+func (e *Engine) SetBalanceProvider(balances BalanceProvider) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.balances = balances
+}
+
+// SetMarkPricer sets the mark price source used by MarkPositions
+func (e *Engine) SetMarkPricer(pricer MarkPricer) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.markPrices = pricer
+}
+
+// MarkPositions recomputes unrealized PnL for all open positions at the
+// mark price. Positions without a resolvable mark keep their last value.
+func (e *Engine) MarkPositions() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.markPrices == nil {
+		return
+	}
+
+	for symbol, pos := range e.positions {
+		if pos.Quantity == 0 {
+			pos.UnrealizedPnL = 0
+			continue
+		}
+
+		mark, err := e.markPrices.MarkPrice(symbol)
+		if err != nil {
+			e.logger.Warn("Failed to resolve mark price",
+				zap.String("symbol", symbol),
+				zap.Error(err))
+			continue
+		}
+		pos.UnrealizedPnL = (mark - pos.AvgPrice) * pos.Quantity
+	}
+}
+
+// ApplyFunding charges the funding rate to the open position for symbol.
+// Longs pay shorts when rate is positive and receive when it is negative.
+// The payment is based on the position's notional at the mark price (or
+// its entry price without a mark source) and, when FundingInterval is
+// set, pro-rated by the time since the last funding.
+func (e *Engine) ApplyFunding(symbol string, rate float64, now time.Time) error {
+	e.mu.Lock()
+	pos, exists := e.positions[symbol]
+	if !exists || pos.Quantity == 0 {
+		e.mu.Unlock()
+		return nil
+	}
+
+	price := pos.AvgPrice
+	if e.markPrices != nil {
+		if mark, err := e.markPrices.MarkPrice(symbol); err == nil {
+			price = mark
+		}
+	}
+
+	fraction := 1.0
+	if e.config.FundingInterval > 0 && !pos.LastFundingAt.IsZero() {
+		fraction = float64(now.Sub(pos.LastFundingAt)) / float64(e.config.FundingInterval)
+		if fraction < 0 {
+			fraction = 0
+		}
+	}
+
+	amount := pos.Quantity * price * rate * fraction
+	pos.FundingPaid += amount
+	pos.RealizedPnL -= amount
+	pos.LastFundingAt = now
+	pos.UpdatedAt = now
+
+	entry := &FundingEntry{
+		UserID:    pos.UserID,
+		Symbol:    symbol,
+		Rate:      rate,
+		Quantity:  pos.Quantity,
+		Price:     price,
+		Amount:    amount,
+		Timestamp: now,
+	}
+	e.funding = append(e.funding, entry)
+	e.recordEvent(&Event{Type: EventFundingApplied, Timestamp: now, Funding: entry})
+	e.mu.Unlock()
+
+	e.logger.Debug("Applied funding",
+		zap.String("symbol", symbol),
+		zap.Float64("rate", rate),
+		zap.Float64("amount", amount))
+
+	return e.storage.SavePosition(pos)
+}
+
+// GetFundingHistory returns the funding entries recorded for symbol
+func (e *Engine) GetFundingHistory(symbol string) []*FundingEntry {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	var entries []*FundingEntry
+	for _, entry := range e.funding {
+		if entry.Symbol == symbol {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
 // Internal methods
 
+// checkBuyingPower rejects orders whose required margin and commission
+// exceed the account's available margin. Reduce-only orders and orders
+// that only close part of an existing position are not checked.
+func (e *Engine) checkBuyingPower(order *Order) error {
+	e.mu.RLock()
+	balances := e.balances
+	pricer := e.markPrices
+	pos := e.positions[order.Symbol]
+	e.mu.RUnlock()
+
+	if balances == nil || order.ReduceOnly || reducesPosition(pos, order) {
+		return nil
+	}
+
+	price := order.Price
+	if price <= 0 && pricer != nil {
+		if mark, err := pricer.MarkPrice(order.Symbol); err == nil {
+			price = mark
+		}
+	}
+	if price <= 0 {
+		return fmt.Errorf("no price available for buying power check on %s", order.Symbol)
+	}
+
+	account, err := balances.GetRiskMetrics(order.UserID)
+	if err != nil {
+		return fmt.Errorf("failed to get account balance: %w", err)
+	}
+
+	notional := order.Quantity * price
+	margin := notional
+	if e.config.MarginRate > 0 {
+		margin = notional * e.config.MarginRate
+	}
+	required := margin + notional*e.config.Commission
+
+	if required > account.AvailableMargin {
+		return fmt.Errorf("%w: order %s requires %f, available %f",
+			ErrInsufficientFunds, order.ID, required, account.AvailableMargin)
+	}
+	return nil
+}
+
+// checkMaxPositions rejects orders that would open a position in a new
+// symbol once Config.MaxPositions symbols are open or have pending opening
+// orders. Adds to existing positions and reduce-only orders always pass.
+// Must be called with e.mu held.
+func (e *Engine) checkMaxPositions(order *Order) error {
+	max := e.config.MaxPositions
+	if max <= 0 || order.ReduceOnly {
+		return nil
+	}
+
+	symbols := make(map[string]struct{})
+	for symbol, pos := range e.positions {
+		if pos.Quantity != 0 {
+			symbols[symbol] = struct{}{}
+		}
+	}
+	if _, open := symbols[order.Symbol]; open {
+		return nil
+	}
+	for _, pending := range e.orders {
+		if !pending.ReduceOnly {
+			symbols[pending.Symbol] = struct{}{}
+		}
+	}
+	if _, pending := symbols[order.Symbol]; pending {
+		return nil
+	}
+
+	if len(symbols) >= max {
+		return fmt.Errorf("%w: %d of %d open, order %s would open %s",
+			ErrMaxPositions, len(symbols), max, order.ID, order.Symbol)
+	}
+	return nil
+}
+
+// checkMaxSymbolOrders rejects orders in a symbol that already has
+// Config.MaxOrdersPerSymbol open orders. TWAP/VWAP parents don't rest on
+// the book and aren't counted; their released children are. Must be
+// called with e.mu held.
+func (e *Engine) checkMaxSymbolOrders(order *Order) error {
+	max := e.config.MaxOrdersPerSymbol
+	if max <= 0 {
+		return nil
+	}
+
+	open := 0
+	for id, pending := range e.orders {
+		if _, isParent := e.schedules[id]; isParent {
+			continue
+		}
+		if pending.Symbol == order.Symbol {
+			open++
+		}
+	}
+	if open >= max {
+		return fmt.Errorf("%w: %d of %d open in %s, rejecting order %s",
+			ErrMaxSymbolOrders, open, max, order.Symbol, order.ID)
+	}
+	return nil
+}
+
+// reducesPosition reports whether order only shrinks pos without flipping it
+func reducesPosition(pos *Position, order *Order) bool {
+	if pos == nil || pos.Quantity == 0 {
+		return false
+	}
+	if order.Side == OrderSideSell {
+		return pos.Quantity > 0 && order.Quantity <= pos.Quantity
+	}
+	return pos.Quantity < 0 && order.Quantity <= -pos.Quantity
+}
+
+// lookupOrder finds an order among open and terminal orders.
+// Must be called with e.mu held.
+func (e *Engine) lookupOrder(orderID string) (*Order, bool) {
+	if order, exists := e.orders[orderID]; exists {
+		return order, true
+	}
+	order, exists := e.terminal[orderID]
+	return order, exists
+}
+
+// retireOrder moves an order from the active map to the terminal map.
+// Must be called with e.mu held.
+func (e *Engine) retireOrder(order *Order) {
+	delete(e.orders, order.ID)
+	e.terminal[order.ID] = order
+}
+
+// queryOrders must be called with e.mu held
+func (e *Engine) queryOrders(filter OrderFilter) []*Order {
+	var orders []*Order
+	for _, set := range []map[string]*Order{e.orders, e.terminal} {
+		for _, order := range set {
+			if filter.UserID != "" && order.UserID != filter.UserID {
+				continue
+			}
+			if filter.Symbol != "" && order.Symbol != filter.Symbol {
+				continue
+			}
+			if filter.Status != "" && order.Status != filter.Status {
+				continue
+			}
+			orders = append(orders, order)
+		}
+	}
+	return orders
+}
+
 func (e *Engine) validateOrder(order *Order) error {
-	if order.Quantity < e.config.MinOrderSize {
-		return fmt.Errorf("order size too small: %f < %f",
-			order.Quantity, e.config.MinOrderSize)
+	if !order.Type.Valid() {
+		return fmt.Errorf("%w: %w %q", ErrInvalidOrder, ErrUnknownOrderType, order.Type)
+	}
+	if !order.Side.Valid() {
+		return fmt.Errorf("%w: %w %q", ErrInvalidOrder, ErrUnknownOrderSide, order.Side)
+	}
+	if !isFinite(order.Quantity) || order.Quantity <= 0 {
+		return fmt.Errorf("%w: quantity %f must be positive", ErrInvalidOrder, order.Quantity)
+	}
+	if !isFinite(order.Price) || order.Price < 0 {
+		return fmt.Errorf("%w: price %f must not be negative", ErrInvalidOrder, order.Price)
+	}
+	if order.Type == OrderTypeLimit && order.Price == 0 {
+		return fmt.Errorf("%w: limit order %s has no price", ErrInvalidOrder, order.ID)
+	}
+	if !isFinite(order.MinFillQty) || order.MinFillQty < 0 {
+		return fmt.Errorf("%w: min fill %f must not be negative", ErrInvalidOrder, order.MinFillQty)
+	}
+	if !isFinite(order.WorstPrice) || order.WorstPrice < 0 {
+		return fmt.Errorf("%w: worst price %f must not be negative", ErrInvalidOrder, order.WorstPrice)
+	}
+	minSize, maxSize := e.orderSizeBounds(order.Symbol)
+	if order.Quantity < minSize {
+		return fmt.Errorf("%w: %f < %f",
+			ErrOrderTooSmall, order.Quantity, minSize)
 	}
-	if order.Quantity > e.config.MaxOrderSize {
-		return fmt.Errorf("order size too large: %f > %f",
-			order.Quantity, e.config.MaxOrderSize)
+	if order.Quantity > maxSize {
+		return fmt.Errorf("%w: %f > %f",
+			ErrOrderTooLarge, order.Quantity, maxSize)
+	}
+	switch {
+	case order.Type == OrderTypeIceberg:
+		return validateIceberg(order)
+	case isStop(order):
+		return validateStop(order)
 	}
 	return nil
 }
+
+// orderSizeBounds returns the order quantity bounds for symbol: its
+// override in Config.SymbolOrderSizes where set, else the global ones
+func (e *Engine) orderSizeBounds(symbol string) (minSize, maxSize float64) {
+	minSize, maxSize = e.config.MinOrderSize, e.config.MaxOrderSize
+	if limits, ok := e.config.SymbolOrderSizes[symbol]; ok {
+		if limits.Min > 0 {
+			minSize = limits.Min
+		}
+		if limits.Max > 0 {
+			maxSize = limits.Max
+		}
+	}
+	return minSize, maxSize
+}
+
+// updatePosition applies a fill to the position for its symbol.
+// Must be called with e.mu held.
+func (e *Engine) updatePosition(trade *Trade) *Position {
+	pos, exists := e.positions[trade.Symbol]
+	if !exists {
+		pos = &Position{
+			UserID: trade.UserID,
+			Symbol: trade.Symbol,
+		}
+		e.positions[trade.Symbol] = pos
+	}
+
+	e.applyToPosition(pos, trade)
+	return pos
+}
+
+// applyToPosition updates pos for trade: blending the average price when
+// opening or adding, realizing PnL when reducing, and charging the fee
+func (e *Engine) applyToPosition(pos *Position, trade *Trade) {
+	qty := trade.Quantity
+	if trade.Side == OrderSideSell {
+		qty = -qty
+	}
+
+	// Positions saved before TotalCost was tracked start from their
+	// average price
+	if pos.TotalCost == 0 && pos.Quantity != 0 {
+		pos.TotalCost = pos.AvgPrice * math.Abs(pos.Quantity)
+	}
+
+	if pos.Quantity == 0 || (pos.Quantity > 0) == (qty > 0) {
+		// Opening or adding: the average entry price is derived from the
+		// running cost rather than re-blended, so it doesn't drift
+		pos.Quantity += qty
+		pos.TotalCost += trade.Price * math.Abs(qty)
+		pos.AvgPrice = pos.TotalCost / math.Abs(pos.Quantity)
+		if e.config.LotMethod != LotMethodNone {
+			pos.Lots = append(pos.Lots, Lot{Price: trade.Price, Quantity: math.Abs(qty), OpenedAt: trade.Timestamp})
+		}
+	} else {
+		// Reducing: realize PnL on the closed quantity
+		closed := math.Min(math.Abs(qty), math.Abs(pos.Quantity))
+		direction := 1.0
+		if pos.Quantity < 0 {
+			direction = -1.0
+		}
+		if e.config.LotMethod != LotMethodNone {
+			pos.RealizedPnL += consumeLots(pos, closed, trade.Price, direction, e.config.LotMethod)
+		} else {
+			pos.RealizedPnL += (trade.Price - pos.AvgPrice) * closed * direction
+		}
+
+		wasLong := pos.Quantity > 0
+		pos.Quantity += qty
+		switch {
+		case pos.Quantity == 0:
+			pos.AvgPrice = 0
+			pos.Lots = nil
+		case (pos.Quantity > 0) != wasLong:
+			// Flipped through flat: the remainder opens at the fill price
+			pos.AvgPrice = trade.Price
+			if e.config.LotMethod != LotMethodNone {
+				pos.Lots = []Lot{{Price: trade.Price, Quantity: math.Abs(pos.Quantity), OpenedAt: trade.Timestamp}}
+			}
+		case e.config.LotMethod != LotMethodNone:
+			pos.AvgPrice = lotsAvgPrice(pos.Lots)
+		}
+		pos.TotalCost = pos.AvgPrice * math.Abs(pos.Quantity)
+	}
+
+	pos.RealizedPnL -= trade.Fee
+	pos.UpdatedAt = trade.Timestamp
+}
+
+// isFinite reports whether v is neither NaN nor infinite
+func isFinite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
@@ -0,0 +1,667 @@
+package trading
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+// memStorage is an in-memory Storage used by engine tests
+type memStorage struct {
+	mu        sync.Mutex
+	orders    []*Order
+	trades    []*Trade
+	positions []*Position
+}
+
+func (s *memStorage) SaveOrder(order *Order) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.orders = append(s.orders, order)
+	return nil
+}
+
+func (s *memStorage) SaveTrade(trade *Trade) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.trades = append(s.trades, trade)
+	return nil
+}
+
+func (s *memStorage) SavePosition(position *Position) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.positions = append(s.positions, position)
+	return nil
+}
+
+func testConfig() Config {
+	return Config{
+		MinOrderSize: 0.01,
+		MaxOrderSize: 1000,
+	}
+}
+
+func newTestEngine(t *testing.T) (*Engine, *memStorage) {
+	t.Helper()
+	storage := &memStorage{}
+	return NewEngine(testConfig(), zap.NewNop(), storage), storage
+}
+
+func placeTestOrder(t *testing.T, engine *Engine, id string, side OrderSide, qty float64) *Order {
+	t.Helper()
+	order := &Order{
+		ID:       id,
+		UserID:   "user1",
+		Symbol:   "TEST/SOL",
+		Side:     side,
+		Type:     OrderTypeMarket,
+		Quantity: qty,
+		Status:   OrderStatusNew,
+	}
+	require.NoError(t, engine.PlaceOrder(order))
+	return order
+}
+
+func TestEngine_ExecuteTrade(t *testing.T) {
+	engine, storage := newTestEngine(t)
+
+	buy := placeTestOrder(t, engine, "buy1", OrderSideBuy, 10)
+	require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: "buy1", Price: 100, Quantity: 4}))
+	assert.Equal(t, OrderStatusPartial, buy.Status)
+
+	require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: "buy1", Price: 110, Quantity: 6}))
+	assert.Equal(t, OrderStatusFilled, buy.Status)
+
+	pos := engine.GetPosition("TEST/SOL")
+	require.NotNil(t, pos)
+	assert.Equal(t, 10.0, pos.Quantity)
+	assert.InDelta(t, 106.0, pos.AvgPrice, 1e-9)
+
+	placeTestOrder(t, engine, "sell1", OrderSideSell, 5)
+	require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: "sell1", Price: 120, Quantity: 5, Fee: 1}))
+	assert.Equal(t, 5.0, pos.Quantity)
+	assert.InDelta(t, 106.0, pos.AvgPrice, 1e-9)
+	assert.InDelta(t, 69.0, pos.RealizedPnL, 1e-9)
+
+	trades, err := engine.GetTrades("user1")
+	require.NoError(t, err)
+	assert.Len(t, trades, 3)
+	assert.Len(t, storage.trades, 3)
+
+	t.Run("Overfill", func(t *testing.T) {
+		err := engine.ExecuteTrade(&Trade{OrderID: "sell1", Price: 120, Quantity: 1})
+		assert.Error(t, err)
+	})
+
+	t.Run("UnknownOrder", func(t *testing.T) {
+		err := engine.ExecuteTrade(&Trade{OrderID: "missing", Price: 120, Quantity: 1})
+		assert.Error(t, err)
+	})
+}
+
+func TestEngine_ExecuteTrade_FlipsPosition(t *testing.T) {
+	engine, _ := newTestEngine(t)
+
+	placeTestOrder(t, engine, "buy1", OrderSideBuy, 2)
+	require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: "buy1", Price: 100, Quantity: 2}))
+
+	placeTestOrder(t, engine, "sell1", OrderSideSell, 5)
+	require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: "sell1", Price: 90, Quantity: 5}))
+
+	pos := engine.GetPosition("TEST/SOL")
+	assert.Equal(t, -3.0, pos.Quantity)
+	assert.Equal(t, 90.0, pos.AvgPrice)
+	assert.InDelta(t, -20.0, pos.RealizedPnL, 1e-9)
+}
+
+func TestEngine_SubscribeFills(t *testing.T) {
+	engine, _ := newTestEngine(t)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	fills := engine.SubscribeFills(ctx)
+	placeTestOrder(t, engine, "buy1", OrderSideBuy, 1)
+	require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: "buy1", Price: 100, Quantity: 1}))
+
+	select {
+	case trade := <-fills:
+		assert.Equal(t, "buy1", trade.OrderID)
+		assert.Equal(t, "TEST/SOL", trade.Symbol)
+	case <-time.After(time.Second):
+		t.Fatal("fill not delivered")
+	}
+
+	cancel()
+	select {
+	case _, ok := <-fills:
+		assert.False(t, ok)
+	case <-time.After(time.Second):
+		t.Fatal("fill channel not closed")
+	}
+}
+
+type staticMarks map[string]float64
+
+func (m staticMarks) MarkPrice(symbol string) (float64, error) {
+	price, ok := m[symbol]
+	if !ok {
+		return 0, fmt.Errorf("no mark for %s", symbol)
+	}
+	return price, nil
+}
+
+func TestEngine_MarkPositions(t *testing.T) {
+	engine, _ := newTestEngine(t)
+
+	placeTestOrder(t, engine, "buy1", OrderSideBuy, 10)
+	require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: "buy1", Price: 100, Quantity: 10}))
+
+	// Without a mark source positions are left untouched
+	engine.MarkPositions()
+	assert.Zero(t, engine.GetPosition("TEST/SOL").UnrealizedPnL)
+
+	engine.SetMarkPricer(staticMarks{"TEST/SOL": 95})
+	engine.MarkPositions()
+	assert.InDelta(t, -50.0, engine.GetPosition("TEST/SOL").UnrealizedPnL, 1e-9)
+
+	engine.SetMarkPricer(staticMarks{})
+	engine.MarkPositions()
+	assert.InDelta(t, -50.0, engine.GetPosition("TEST/SOL").UnrealizedPnL, 1e-9)
+}
+
+func TestEngine_ApplyFunding(t *testing.T) {
+	now := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+
+	t.Run("LongPaysPositiveRate", func(t *testing.T) {
+		engine, storage := newTestEngine(t)
+		placeTestOrder(t, engine, "buy1", OrderSideBuy, 10)
+		require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: "buy1", Price: 100, Quantity: 10}))
+
+		require.NoError(t, engine.ApplyFunding("TEST/SOL", 0.001, now))
+		pos := engine.GetPosition("TEST/SOL")
+		assert.InDelta(t, 1.0, pos.FundingPaid, 1e-9)
+		assert.InDelta(t, -1.0, pos.RealizedPnL, 1e-9)
+
+		entries := engine.GetFundingHistory("TEST/SOL")
+		require.Len(t, entries, 1)
+		assert.InDelta(t, 1.0, entries[0].Amount, 1e-9)
+		assert.Equal(t, now, entries[0].Timestamp)
+		assert.Len(t, storage.positions, 2)
+	})
+
+	t.Run("ShortReceivesPositiveRate", func(t *testing.T) {
+		engine, _ := newTestEngine(t)
+		placeTestOrder(t, engine, "sell1", OrderSideSell, 10)
+		require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: "sell1", Price: 100, Quantity: 10}))
+
+		require.NoError(t, engine.ApplyFunding("TEST/SOL", 0.001, now))
+		pos := engine.GetPosition("TEST/SOL")
+		assert.InDelta(t, -1.0, pos.FundingPaid, 1e-9)
+		assert.InDelta(t, 1.0, pos.RealizedPnL, 1e-9)
+	})
+
+	t.Run("LongReceivesNegativeRate", func(t *testing.T) {
+		engine, _ := newTestEngine(t)
+		engine.SetMarkPricer(staticMarks{"TEST/SOL": 120})
+		placeTestOrder(t, engine, "buy1", OrderSideBuy, 10)
+		require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: "buy1", Price: 100, Quantity: 10}))
+
+		require.NoError(t, engine.ApplyFunding("TEST/SOL", -0.001, now))
+		assert.InDelta(t, -1.2, engine.GetPosition("TEST/SOL").FundingPaid, 1e-9)
+	})
+
+	t.Run("ProRatedByInterval", func(t *testing.T) {
+		config := testConfig()
+		config.FundingInterval = 8 * time.Hour
+		engine := NewEngine(config, zap.NewNop(), &memStorage{})
+		placeTestOrder(t, engine, "buy1", OrderSideBuy, 10)
+		require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: "buy1", Price: 100, Quantity: 10}))
+
+		require.NoError(t, engine.ApplyFunding("TEST/SOL", 0.001, now))
+		require.NoError(t, engine.ApplyFunding("TEST/SOL", 0.001, now.Add(4*time.Hour)))
+		assert.InDelta(t, 1.5, engine.GetPosition("TEST/SOL").FundingPaid, 1e-9)
+	})
+
+	t.Run("NoPosition", func(t *testing.T) {
+		engine, _ := newTestEngine(t)
+		require.NoError(t, engine.ApplyFunding("TEST/SOL", 0.001, now))
+		assert.Empty(t, engine.GetFundingHistory("TEST/SOL"))
+	})
+}
+
+func TestEngine_CancelOrder_Idempotent(t *testing.T) {
+	t.Run("DoubleCancel", func(t *testing.T) {
+		engine, storage := newTestEngine(t)
+		order := placeTestOrder(t, engine, "buy1", OrderSideBuy, 10)
+
+		require.NoError(t, engine.CancelOrder("buy1"))
+		require.NoError(t, engine.CancelOrder("buy1"))
+		assert.Equal(t, OrderStatusCanceled, order.Status)
+
+		// Only the placement and the first cancel are persisted
+		assert.Len(t, storage.orders, 2)
+
+		err := engine.ExecuteTrade(&Trade{OrderID: "buy1", Price: 100, Quantity: 1})
+		assert.Error(t, err)
+	})
+
+	t.Run("CancelAfterFill", func(t *testing.T) {
+		engine, _ := newTestEngine(t)
+		order := placeTestOrder(t, engine, "buy1", OrderSideBuy, 10)
+		require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: "buy1", Price: 100, Quantity: 10}))
+
+		require.NoError(t, engine.CancelOrder("buy1"))
+		assert.Equal(t, OrderStatusFilled, order.Status)
+	})
+
+	t.Run("UnknownOrder", func(t *testing.T) {
+		engine, _ := newTestEngine(t)
+		assert.Error(t, engine.CancelOrder("missing"))
+	})
+}
+
+func TestEngine_TerminalOrdersQueryable(t *testing.T) {
+	engine, _ := newTestEngine(t)
+	placeTestOrder(t, engine, "buy1", OrderSideBuy, 10)
+	placeTestOrder(t, engine, "buy2", OrderSideBuy, 10)
+	require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: "buy2", Price: 100, Quantity: 10}))
+	placeTestOrder(t, engine, "buy3", OrderSideBuy, 10)
+
+	require.NoError(t, engine.CancelOrder("buy1"))
+
+	order, err := engine.GetOrder("buy1")
+	require.NoError(t, err)
+	assert.Equal(t, OrderStatusCanceled, order.Status)
+
+	assert.Len(t, engine.QueryOrders(OrderFilter{UserID: "user1"}), 3)
+	canceled := engine.QueryOrders(OrderFilter{Status: OrderStatusCanceled})
+	require.Len(t, canceled, 1)
+	assert.Equal(t, "buy1", canceled[0].ID)
+
+	t.Run("Eviction", func(t *testing.T) {
+		now := time.Now()
+		assert.Zero(t, engine.EvictTerminalOrders(now, time.Hour))
+		assert.Equal(t, 2, engine.EvictTerminalOrders(now.Add(2*time.Hour), time.Hour))
+
+		_, err := engine.GetOrder("buy1")
+		assert.Error(t, err)
+
+		// Open orders are never evicted
+		_, err = engine.GetOrder("buy3")
+		assert.NoError(t, err)
+	})
+}
+
+type staticBalance struct {
+	available float64
+}
+
+func (b staticBalance) GetRiskMetrics(userID string) (*types.RiskMetrics, error) {
+	return &types.RiskMetrics{UserID: userID, AvailableMargin: b.available}, nil
+}
+
+func TestEngine_BuyingPower(t *testing.T) {
+	engine, _ := newTestEngine(t)
+	engine.SetBalanceProvider(staticBalance{available: 1000})
+
+	newOrder := func(id string, side OrderSide, qty, price float64) *Order {
+		return &Order{ID: id, UserID: "user1", Symbol: "TEST/SOL", Side: side,
+			Type: OrderTypeLimit, Quantity: qty, Price: price, Status: OrderStatusNew}
+	}
+
+	err := engine.PlaceOrder(newOrder("big", OrderSideBuy, 20, 100))
+	require.Error(t, err)
+	assert.ErrorIs(t, err, ErrInsufficientFunds)
+	_, err = engine.GetOrder("big")
+	assert.Error(t, err)
+
+	require.NoError(t, engine.PlaceOrder(newOrder("small", OrderSideBuy, 5, 100)))
+	require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: "small", Price: 100, Quantity: 5}))
+
+	t.Run("ClosingSkipsCheck", func(t *testing.T) {
+		engine.SetBalanceProvider(staticBalance{available: 0})
+		assert.NoError(t, engine.PlaceOrder(newOrder("close", OrderSideSell, 5, 100)))
+
+		flip := newOrder("flip", OrderSideSell, 6, 100)
+		assert.ErrorIs(t, engine.PlaceOrder(flip), ErrInsufficientFunds)
+
+		flip.ReduceOnly = true
+		assert.NoError(t, engine.PlaceOrder(flip))
+	})
+
+	t.Run("MarginRate", func(t *testing.T) {
+		config := testConfig()
+		config.MarginRate = 0.1
+		engine := NewEngine(config, zap.NewNop(), &memStorage{})
+		engine.SetBalanceProvider(staticBalance{available: 1000})
+		assert.NoError(t, engine.PlaceOrder(newOrder("lev", OrderSideBuy, 20, 100)))
+	})
+}
+
+func TestEngine_TypedErrors(t *testing.T) {
+	engine, _ := newTestEngine(t)
+	placeTestOrder(t, engine, "buy1", OrderSideBuy, 1)
+
+	order := func(id string, qty float64) *Order {
+		return &Order{ID: id, UserID: "user1", Symbol: "TEST/SOL", Side: OrderSideBuy,
+			Type: OrderTypeMarket, Quantity: qty}
+	}
+
+	assert.ErrorIs(t, engine.PlaceOrder(order("tiny", 0.001)), ErrOrderTooSmall)
+	assert.ErrorIs(t, engine.PlaceOrder(order("huge", 5000)), ErrOrderTooLarge)
+	assert.ErrorIs(t, engine.PlaceOrder(order("buy1", 1)), ErrDuplicateOrder)
+
+	_, err := engine.GetOrder("missing")
+	assert.ErrorIs(t, err, ErrOrderNotFound)
+	assert.ErrorIs(t, engine.CancelOrder("missing"), ErrOrderNotFound)
+	assert.ErrorIs(t, engine.ExecuteTrade(&Trade{OrderID: "missing", Quantity: 1}), ErrOrderNotFound)
+
+	assert.ErrorIs(t, engine.ExecuteTrade(&Trade{OrderID: "buy1", Price: 1, Quantity: 2}), ErrInvalidFill)
+	require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: "buy1", Price: 1, Quantity: 1}))
+	assert.ErrorIs(t, engine.ExecuteTrade(&Trade{OrderID: "buy1", Price: 1, Quantity: 1}), ErrOrderTerminal)
+
+	// Terminal orders still count as duplicates
+	assert.ErrorIs(t, engine.PlaceOrder(order("buy1", 1)), ErrDuplicateOrder)
+}
+
+func TestEngine_MalformedOrders(t *testing.T) {
+	config := testConfig()
+	config.MinOrderSize = 0
+	engine := NewEngine(config, zap.NewNop(), &memStorage{})
+
+	malformed := map[string]*Order{
+		"zero quantity":     {Type: OrderTypeMarket, Quantity: 0},
+		"negative quantity": {Type: OrderTypeMarket, Quantity: -1},
+		"NaN quantity":      {Type: OrderTypeMarket, Quantity: math.NaN()},
+		"infinite quantity": {Type: OrderTypeMarket, Quantity: math.Inf(1)},
+		"zero-price limit":  {Type: OrderTypeLimit, Quantity: 1},
+		"negative price":    {Type: OrderTypeLimit, Price: -5, Quantity: 1},
+		"NaN price":         {Type: OrderTypeLimit, Price: math.NaN(), Quantity: 1},
+	}
+	for name, order := range malformed {
+		order.ID, order.UserID, order.Symbol, order.Side = name, "user1", "TEST/SOL", OrderSideBuy
+		assert.ErrorIs(t, engine.PlaceOrder(order), ErrInvalidOrder, name)
+	}
+	assert.Empty(t, engine.QueryOrders(OrderFilter{}))
+
+	placeTestOrder(t, engine, "buy1", OrderSideBuy, 1)
+	for _, price := range []float64{0, -1, math.NaN(), math.Inf(1)} {
+		err := engine.ExecuteTrade(&Trade{OrderID: "buy1", Price: price, Quantity: 1})
+		assert.ErrorIs(t, err, ErrInvalidFill, "fill price %f", price)
+	}
+	assert.Nil(t, engine.GetPosition("TEST/SOL"), "no position is opened at a bad price")
+}
+
+func TestEngine_UnknownTypeAndSide(t *testing.T) {
+	engine, _ := newTestEngine(t)
+
+	bogus := &Order{ID: "bogus", UserID: "user1", Symbol: "TEST/SOL", Side: OrderSideBuy,
+		Type: "limt", Price: 1, Quantity: 1}
+	err := engine.PlaceOrder(bogus)
+	assert.ErrorIs(t, err, ErrUnknownOrderType)
+	assert.ErrorIs(t, err, ErrInvalidOrder)
+
+	err = engine.PlaceOrder(&Order{ID: "sideways", UserID: "user1", Symbol: "TEST/SOL", Side: "short",
+		Type: OrderTypeMarket, Quantity: 1})
+	assert.ErrorIs(t, err, ErrUnknownOrderSide)
+
+	err = engine.PlaceOrder(&Order{ID: "untyped", UserID: "user1", Symbol: "TEST/SOL", Side: OrderSideSell,
+		Quantity: 1})
+	assert.ErrorIs(t, err, ErrUnknownOrderType, "the zero type is not a market order")
+	assert.Empty(t, engine.QueryOrders(OrderFilter{}))
+}
+
+func TestEngine_StalePositions(t *testing.T) {
+	engine, _ := newTestEngine(t)
+
+	placeTestOrder(t, engine, "buy1", OrderSideBuy, 1)
+	require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: "buy1", Price: 100, Quantity: 1,
+		Timestamp: time.Now().Add(-48 * time.Hour)}))
+
+	require.NoError(t, engine.PlaceOrder(&Order{
+		ID:       "buy2",
+		UserID:   "user1",
+		Symbol:   "FRESH/SOL",
+		Side:     OrderSideBuy,
+		Type:     OrderTypeMarket,
+		Quantity: 1,
+	}))
+	require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: "buy2", Price: 100, Quantity: 1}))
+
+	stale := engine.StalePositions(24 * time.Hour)
+	require.Len(t, stale, 1)
+	assert.Equal(t, "TEST/SOL", stale[0].Symbol)
+
+	assert.Empty(t, engine.StalePositions(72*time.Hour))
+}
+
+func TestEngine_MaxPositions(t *testing.T) {
+	config := testConfig()
+	config.MaxPositions = 2
+	engine := NewEngine(config, zap.NewNop(), &memStorage{})
+
+	open := func(id, symbol string, side OrderSide) error {
+		return engine.PlaceOrder(&Order{
+			ID:       id,
+			UserID:   "user1",
+			Symbol:   symbol,
+			Side:     side,
+			Type:     OrderTypeMarket,
+			Quantity: 1,
+		})
+	}
+	fill := func(id string) {
+		require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: id, Price: 1, Quantity: 1}))
+	}
+
+	require.NoError(t, open("a1", "AAA/SOL", OrderSideBuy))
+	fill("a1")
+	require.NoError(t, open("b1", "BBB/SOL", OrderSideBuy))
+
+	// The pending order for BBB counts toward the cap
+	assert.ErrorIs(t, open("c1", "CCC/SOL", OrderSideBuy), ErrMaxPositions)
+
+	fill("b1")
+	assert.ErrorIs(t, open("c2", "CCC/SOL", OrderSideBuy), ErrMaxPositions)
+
+	// Adding to or reducing existing positions is allowed
+	require.NoError(t, open("a2", "AAA/SOL", OrderSideBuy))
+	require.NoError(t, open("b2", "BBB/SOL", OrderSideSell))
+	fill("b2")
+
+	// Closing BBB frees a slot
+	require.NoError(t, open("c3", "CCC/SOL", OrderSideBuy))
+
+	t.Run("SlicedParent", func(t *testing.T) {
+		engine := NewEngine(config, zap.NewNop(), &memStorage{})
+		require.NoError(t, engine.PlaceOrder(&Order{ID: "a1", UserID: "user1", Symbol: "AAA/SOL",
+			Side: OrderSideBuy, Type: OrderTypeMarket, Quantity: 1}))
+		require.NoError(t, engine.PlaceOrder(&Order{ID: "b1", UserID: "user1", Symbol: "BBB/SOL",
+			Side: OrderSideBuy, Type: OrderTypeMarket, Quantity: 1}))
+
+		// The parent takes the symbol's slot for the children it releases
+		assert.ErrorIs(t, engine.PlaceTWAP(parentOrder("twap1", 2), 2, time.Hour), ErrMaxPositions)
+	})
+
+	t.Run("SpreadLegs", func(t *testing.T) {
+		engine := NewEngine(config, zap.NewNop(), &memStorage{})
+		require.NoError(t, engine.PlaceOrder(&Order{ID: "a1", UserID: "user1", Symbol: "AAA/SOL",
+			Side: OrderSideBuy, Type: OrderTypeMarket, Quantity: 1}))
+
+		// The first leg takes the last slot, so the second is refused and
+		// neither is placed
+		_, err := engine.PlaceSpread([]*Order{
+			{ID: "leg1", UserID: "user1", Symbol: "BBB/SOL", Side: OrderSideBuy, Type: OrderTypeMarket, Quantity: 1},
+			{ID: "leg2", UserID: "user1", Symbol: "CCC/SOL", Side: OrderSideSell, Type: OrderTypeMarket, Quantity: 1},
+		}, []float64{1, 1})
+		assert.ErrorIs(t, err, ErrMaxPositions)
+		assert.Len(t, engine.QueryOrders(OrderFilter{}), 1)
+		require.NoError(t, engine.PlaceOrder(&Order{ID: "leg1", UserID: "user1", Symbol: "BBB/SOL",
+			Side: OrderSideBuy, Type: OrderTypeMarket, Quantity: 1}))
+	})
+}
+
+func TestEngine_MaxOrdersPerSymbol(t *testing.T) {
+	config := testConfig()
+	config.MaxOrdersPerSymbol = 2
+	engine := NewEngine(config, zap.NewNop(), &memStorage{})
+
+	place := func(id, symbol string) error {
+		return engine.PlaceOrder(&Order{
+			ID:       id,
+			UserID:   "user1",
+			Symbol:   symbol,
+			Side:     OrderSideBuy,
+			Type:     OrderTypeLimit,
+			Price:    1,
+			Quantity: 1,
+		})
+	}
+
+	require.NoError(t, place("a1", "AAA/SOL"))
+	require.NoError(t, place("a2", "AAA/SOL"))
+	assert.ErrorIs(t, place("a3", "AAA/SOL"), ErrMaxSymbolOrders)
+
+	// Other symbols have their own slots
+	require.NoError(t, place("b1", "BBB/SOL"))
+
+	// Canceling or filling an order frees a slot
+	require.NoError(t, engine.CancelOrder("a1"))
+	require.NoError(t, place("a3", "AAA/SOL"))
+	assert.ErrorIs(t, place("a4", "AAA/SOL"), ErrMaxSymbolOrders)
+
+	require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: "a2", Price: 1, Quantity: 1}))
+	require.NoError(t, place("a4", "AAA/SOL"))
+
+	t.Run("SlicesAndSpreadLegs", func(t *testing.T) {
+		engine := NewEngine(config, zap.NewNop(), &memStorage{})
+
+		// The parent doesn't rest on the book, so both released children
+		// fit and fill the two slots
+		require.NoError(t, engine.PlaceTWAP(parentOrder("twap1", 2), 2, time.Millisecond))
+		waitReleased(t, engine, 3)
+		children, err := engine.GetChildOrders("twap1")
+		require.NoError(t, err)
+		for _, child := range children {
+			order, err := engine.GetOrder(child.ID)
+			require.NoError(t, err)
+			assert.Equal(t, OrderStatusNew, order.Status)
+		}
+
+		_, err = engine.PlaceSpread([]*Order{
+			{ID: "leg1", UserID: "user1", Symbol: "AAA/SOL", Side: OrderSideBuy, Type: OrderTypeMarket, Quantity: 1},
+			{ID: "leg2", UserID: "user1", Symbol: "TEST/SOL", Side: OrderSideSell, Type: OrderTypeMarket, Quantity: 1},
+		}, []float64{1, 1})
+		assert.ErrorIs(t, err, ErrMaxSymbolOrders)
+		assert.Len(t, engine.QueryOrders(OrderFilter{Symbol: "AAA/SOL"}), 0)
+	})
+}
+
+func TestEngine_ClosePosition(t *testing.T) {
+	engine, _ := newTestEngine(t)
+
+	order, err := engine.ClosePosition("user1", "TEST/SOL", 1)
+	require.NoError(t, err)
+	assert.Nil(t, order, "closing a flat position is a no-op")
+
+	t.Run("FullCloseLong", func(t *testing.T) {
+		placeTestOrder(t, engine, "buy1", OrderSideBuy, 10)
+		require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: "buy1", Price: 100, Quantity: 10}))
+
+		order, err := engine.ClosePosition("user1", "TEST/SOL", 1)
+		require.NoError(t, err)
+		require.NotNil(t, order)
+		assert.Equal(t, OrderSideSell, order.Side)
+		assert.Equal(t, OrderTypeMarket, order.Type)
+		assert.Equal(t, 10.0, order.Quantity)
+		assert.True(t, order.ReduceOnly)
+
+		require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: order.ID, Price: 105, Quantity: 10}))
+		assert.Zero(t, engine.GetPosition("TEST/SOL").Quantity)
+	})
+
+	t.Run("HalfCloseShort", func(t *testing.T) {
+		placeTestOrder(t, engine, "sell1", OrderSideSell, 8)
+		require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: "sell1", Price: 100, Quantity: 8}))
+
+		order, err := engine.ClosePosition("user1", "TEST/SOL", 0.5)
+		require.NoError(t, err)
+		require.NotNil(t, order)
+		assert.Equal(t, OrderSideBuy, order.Side)
+		assert.Equal(t, 4.0, order.Quantity)
+
+		require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: order.ID, Price: 95, Quantity: 4}))
+		assert.Equal(t, -4.0, engine.GetPosition("TEST/SOL").Quantity)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		_, err := engine.ClosePosition("user1", "TEST/SOL", 1.5)
+		assert.Error(t, err)
+
+		order, err := engine.ClosePosition("user2", "TEST/SOL", 1)
+		require.NoError(t, err)
+		assert.Nil(t, order, "other users' positions are not closed")
+	})
+}
+
+func TestEngine_SymbolOrderSizes(t *testing.T) {
+	config := testConfig()
+	config.SymbolOrderSizes = map[string]OrderSizeLimits{
+		"THIN/SOL": {Max: 50},
+		"SOL/USDC": {Min: 1},
+	}
+	engine := NewEngine(config, zap.NewNop(), &memStorage{})
+
+	place := func(id, symbol string, qty float64) error {
+		return engine.PlaceOrder(&Order{ID: id, UserID: "user1", Symbol: symbol, Side: OrderSideBuy,
+			Type: OrderTypeMarket, Quantity: qty, Status: OrderStatusNew})
+	}
+
+	// 100 is within the global max but not the thin token's
+	assert.ErrorIs(t, place("thin", "THIN/SOL", 100), ErrOrderTooLarge)
+	assert.NoError(t, place("thin-ok", "THIN/SOL", 50))
+	assert.NoError(t, place("global", "TEST/SOL", 100))
+
+	// A min-only override keeps the global max
+	assert.ErrorIs(t, place("sol-small", "SOL/USDC", 0.5), ErrOrderTooSmall)
+	assert.ErrorIs(t, place("sol-big", "SOL/USDC", 2000), ErrOrderTooLarge)
+	assert.NoError(t, place("sol", "SOL/USDC", 1))
+}
+
+func TestEngine_AvgPriceManyFills(t *testing.T) {
+	engine, _ := newTestEngine(t)
+	pos := &Position{Symbol: "TEST/SOL"}
+
+	// Sum the fills exactly for the reference average price
+	cost, qty := new(big.Rat), new(big.Rat)
+	for i := 0; i < 100000; i++ {
+		price := 0.00001234 + float64(i%97)*1e-9
+		quantity := 0.5 + float64(i%13)*0.01
+		engine.applyToPosition(pos, &Trade{Symbol: "TEST/SOL", Side: OrderSideBuy, Price: price, Quantity: quantity})
+
+		fillCost := new(big.Rat).SetFloat64(price)
+		fillCost.Mul(fillCost, new(big.Rat).SetFloat64(quantity))
+		cost.Add(cost, fillCost)
+		qty.Add(qty, new(big.Rat).SetFloat64(quantity))
+	}
+
+	exact, _ := new(big.Rat).Quo(cost, qty).Float64()
+	assert.InEpsilon(t, exact, pos.AvgPrice, 1e-12)
+	assert.InDelta(t, pos.TotalCost/pos.Quantity, pos.AvgPrice, 1e-18)
+
+	// Reducing keeps the average and scales the cost down with the quantity
+	avg := pos.AvgPrice
+	engine.applyToPosition(pos, &Trade{Symbol: "TEST/SOL", Side: OrderSideSell, Price: 0.00002, Quantity: pos.Quantity / 2})
+	assert.Equal(t, avg, pos.AvgPrice)
+	assert.InEpsilon(t, avg*pos.Quantity, pos.TotalCost, 1e-15)
+}
@@ -0,0 +1,29 @@
+package trading
+
+import "errors"
+
+// Engine errors; match with errors.Is
+var (
+	ErrOrderNotFound      = errors.New("order not found")
+	ErrDuplicateOrder     = errors.New("duplicate order")
+	ErrOrderTooSmall      = errors.New("order size too small")
+	ErrOrderTooLarge      = errors.New("order size too large")
+	ErrOrderTerminal      = errors.New("order is terminal")
+	ErrInvalidFill        = errors.New("invalid fill quantity")
+	ErrInsufficientFunds  = errors.New("insufficient funds")
+	ErrNetLossExit        = errors.New("exit is a net loss after fees")
+	ErrMaxPositions       = errors.New("max open positions reached")
+	ErrRateLimited        = errors.New("order rate limit exceeded")
+	ErrPostOnlyWouldCross = errors.New("post-only order would cross the book")
+	ErrOrderStale         = errors.New("aged order failed re-validation")
+	ErrInvalidOrder       = errors.New("invalid order")
+	ErrFillTooSmall       = errors.New("fill below minimum fill quantity")
+	ErrNoSubmitter        = errors.New("no submitter registered")
+	ErrUnknownOrderType   = errors.New("unknown order type")
+	ErrUnknownOrderSide   = errors.New("unknown order side")
+	ErrNoPrice            = errors.New("no price available")
+	ErrRiskRejected       = errors.New("rejected by risk checker")
+	ErrMaxSymbolOrders    = errors.New("max open orders per symbol reached")
+	ErrActiveGroups       = errors.New("active TWAP/VWAP schedules or spreads")
+	ErrPendingWork        = errors.New("queued orders, fills or saves pending")
+)
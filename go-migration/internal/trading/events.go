@@ -0,0 +1,247 @@
+package trading
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// EventType identifies a state-changing engine operation
+type EventType string
+
+const (
+	EventOrderPlaced     EventType = "order_placed"
+	EventOrderTriggered  EventType = "order_triggered"
+	EventOrderUpdated    EventType = "order_updated"
+	EventOrderCanceled   EventType = "order_canceled"
+	EventOrderRejected   EventType = "order_rejected"
+	EventOrderRolledBack EventType = "order_rolled_back"
+	EventOrderEvicted    EventType = "order_evicted"
+	EventOrderFilled     EventType = "order_filled"
+	EventPositionUpdated EventType = "position_updated"
+	EventFundingApplied  EventType = "funding_applied"
+	EventDustSwept       EventType = "dust_swept"
+)
+
+// Event is one entry in the engine's event log. Order and Position are
+// copies taken when the event happened; Trade and Funding are the records
+// the engine stored.
+type Event struct {
+	Seq       uint64        `json:"seq"`
+	Type      EventType     `json:"type"`
+	Timestamp time.Time     `json:"timestamp"`
+	Order     *Order        `json:"order,omitempty"`
+	Trade     *Trade        `json:"trade,omitempty"`
+	Position  *Position     `json:"position,omitempty"`
+	Funding   *FundingEntry `json:"funding,omitempty"`
+}
+
+// EventLog is an append-only log of engine events
+type EventLog interface {
+	// Append records event after all previously appended events
+	Append(event *Event) error
+	// Events returns every event in append order
+	Events() ([]*Event, error)
+}
+
+// MemoryEventLog is an in-memory EventLog
+type MemoryEventLog struct {
+	events []*Event
+	mu     sync.RWMutex
+}
+
+// NewMemoryEventLog creates an empty in-memory event log
+func NewMemoryEventLog() *MemoryEventLog {
+	return &MemoryEventLog{}
+}
+
+// Append implements EventLog
+func (l *MemoryEventLog) Append(event *Event) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, event)
+	return nil
+}
+
+// Events implements EventLog
+func (l *MemoryEventLog) Events() ([]*Event, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return append([]*Event(nil), l.events...), nil
+}
+
+// SetEventLog sets the log every state-changing operation is appended to
+func (e *Engine) SetEventLog(log EventLog) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.events = log
+}
+
+// recordEvent appends event to the event log, if any. A failed append is
+// logged and does not fail the operation. Must be called with e.mu held.
+func (e *Engine) recordEvent(event *Event) {
+	if e.events == nil || e.replaying {
+		return
+	}
+	e.eventSeq++
+	event.Seq = e.eventSeq
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+	if err := e.events.Append(event); err != nil {
+		e.logger.Warn("Failed to append engine event",
+			zap.String("type", string(event.Type)),
+			zap.Uint64("seq", event.Seq),
+			zap.Error(err))
+	}
+}
+
+// recordOrder appends an event carrying a copy of order. Must be called
+// with e.mu held.
+func (e *Engine) recordOrder(eventType EventType, order *Order) {
+	if e.events == nil || e.replaying {
+		return
+	}
+	snapshot := *order
+	e.recordEvent(&Event{Type: eventType, Timestamp: order.UpdatedAt, Order: &snapshot})
+}
+
+// ReplayFrom replaces the engine state with the state rebuilt by applying
+// every event in log in order. Order events restore the recorded order,
+// fills are re-applied through the normal fill path, dust sweeps are
+// applied to their position and funding entries are charged again;
+// position events are informational. Fill subscribers are not notified
+// of replayed fills. Like Restore, it can't rebuild TWAP/VWAP schedules
+// or spreads: it fails with ErrActiveGroups while the engine has open
+// ones or when the log leaves slices or legs open, and with
+// ErrPendingWork while orders, fills or saves are queued. The engine
+// keeps its previous state when any event fails to apply.
+func (e *Engine) ReplayFrom(log EventLog) error {
+	events, err := log.Events()
+	if err != nil {
+		return fmt.Errorf("failed to read event log: %w", err)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.checkNoActiveGroups(); err != nil {
+		return err
+	}
+	if err := e.checkNoPendingWork(); err != nil {
+		return err
+	}
+
+	orders, terminal, positions := e.orders, e.terminal, e.positions
+	trades, funding, seq := e.trades, e.funding, e.eventSeq
+	rollback := func() {
+		e.orders, e.terminal, e.positions = orders, terminal, positions
+		e.trades, e.funding, e.eventSeq = trades, funding, seq
+	}
+
+	e.orders = make(map[string]*Order)
+	e.terminal = make(map[string]*Order)
+	e.positions = make(map[string]*Position)
+	e.trades = nil
+	e.funding = nil
+
+	e.replaying = true
+	defer func() { e.replaying = false }()
+
+	for _, event := range events {
+		if err := e.applyEvent(event); err != nil {
+			rollback()
+			return fmt.Errorf("failed to replay event %d (%s): %w", event.Seq, event.Type, err)
+		}
+		if event.Seq > e.eventSeq {
+			e.eventSeq = event.Seq
+		}
+	}
+	if err := checkNoGroupOrders(e.orders); err != nil {
+		rollback()
+		return err
+	}
+
+	e.stopSchedules()
+	e.schedules = make(map[string]*sliceSchedule)
+	e.spreads = make(map[string]*Spread)
+
+	e.logger.Info("Replayed engine event log",
+		zap.Int("events", len(events)),
+		zap.Int("orders", len(e.orders)+len(e.terminal)),
+		zap.Int("positions", len(e.positions)),
+		zap.Int("trades", len(e.trades)))
+	return nil
+}
+
+// applyEvent applies one replayed event. Must be called with e.mu held.
+func (e *Engine) applyEvent(event *Event) error {
+	switch event.Type {
+	case EventOrderPlaced, EventOrderTriggered, EventOrderUpdated, EventOrderCanceled, EventOrderRejected:
+		if event.Order == nil {
+			return fmt.Errorf("missing order")
+		}
+		order := *event.Order
+		delete(e.orders, order.ID)
+		delete(e.terminal, order.ID)
+		if order.Status.IsTerminal() {
+			e.terminal[order.ID] = &order
+		} else {
+			e.orders[order.ID] = &order
+		}
+
+	case EventOrderRolledBack, EventOrderEvicted:
+		if event.Order == nil {
+			return fmt.Errorf("missing order")
+		}
+		delete(e.orders, event.Order.ID)
+		delete(e.terminal, event.Order.ID)
+
+	case EventOrderFilled:
+		if event.Trade == nil {
+			return fmt.Errorf("missing trade")
+		}
+		trade := *event.Trade
+		order, err := e.checkFill(&trade)
+		if err != nil {
+			return err
+		}
+		e.applyFill(order, &trade)
+
+	case EventPositionUpdated:
+		// Derived from the fill before it
+
+	case EventFundingApplied:
+		if event.Funding == nil {
+			return fmt.Errorf("missing funding entry")
+		}
+		entry := *event.Funding
+		pos, exists := e.positions[entry.Symbol]
+		if !exists {
+			return fmt.Errorf("no position for %s", entry.Symbol)
+		}
+		pos.FundingPaid += entry.Amount
+		pos.RealizedPnL -= entry.Amount
+		pos.LastFundingAt = entry.Timestamp
+		pos.UpdatedAt = entry.Timestamp
+		e.funding = append(e.funding, &entry)
+
+	case EventDustSwept:
+		if event.Trade == nil {
+			return fmt.Errorf("missing trade")
+		}
+		trade := *event.Trade
+		pos, exists := e.positions[trade.Symbol]
+		if !exists {
+			return fmt.Errorf("no position for %s", trade.Symbol)
+		}
+		e.applyToPosition(pos, &trade)
+		e.trades = append(e.trades, &trade)
+
+	default:
+		return fmt.Errorf("unknown event type %q", event.Type)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package trading
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestEngine_ReplayFrom(t *testing.T) {
+	log := NewMemoryEventLog()
+	engine, _ := newTestEngine(t)
+	engine.SetEventLog(log)
+
+	placeTestOrder(t, engine, "buy1", OrderSideBuy, 10)
+	require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: "buy1", Price: 100, Quantity: 4, Fee: 0.4}))
+	require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: "buy1", Price: 102, Quantity: 6, Fee: 0.6}))
+
+	placeTestOrder(t, engine, "cancel1", OrderSideSell, 2)
+	require.NoError(t, engine.CancelOrder("cancel1"))
+
+	require.NoError(t, engine.PlaceOrder(&Order{
+		ID: "stop1", UserID: "user1", Symbol: "TEST/SOL", Side: OrderSideSell,
+		Type: OrderTypeStopLimit, StopPrice: 95, Price: 94, Quantity: 3, Status: OrderStatusNew,
+	}))
+	require.Len(t, engine.OnPriceUpdate("TEST/SOL", 94), 1)
+	require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: "stop1", Price: 94, Quantity: 1}))
+
+	require.NoError(t, engine.PlaceOrder(&Order{
+		ID: "ice1", UserID: "user1", Symbol: "ICE/SOL", Side: OrderSideBuy,
+		Type: OrderTypeIceberg, Price: 10, Quantity: 10, VisibleQty: 3, Status: OrderStatusNew,
+	}))
+	require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: "ice1", Price: 10, Quantity: 3}))
+
+	require.NoError(t, engine.ApplyFunding("TEST/SOL", 0.001, time.Now()))
+
+	events, err := log.Events()
+	require.NoError(t, err)
+	require.NotEmpty(t, events)
+	for i, event := range events {
+		assert.Equal(t, uint64(i+1), event.Seq)
+	}
+
+	replayed := NewEngine(testConfig(), zap.NewNop(), &memStorage{})
+	require.NoError(t, replayed.ReplayFrom(log))
+
+	assert.Equal(t, engine.orders, replayed.orders)
+	assert.Equal(t, engine.terminal, replayed.terminal)
+	assert.Equal(t, engine.positions, replayed.positions)
+	assert.Equal(t, engine.trades, replayed.trades)
+	assert.Equal(t, engine.funding, replayed.funding)
+
+	// Replaying does not append to the log being replayed
+	after, err := log.Events()
+	require.NoError(t, err)
+	assert.Len(t, after, len(events))
+
+	t.Run("InvalidLog", func(t *testing.T) {
+		bad := NewMemoryEventLog()
+		require.NoError(t, bad.Append(&Event{Seq: 1, Type: EventOrderFilled, Trade: &Trade{OrderID: "missing", Quantity: 1}}))
+		err := replayed.ReplayFrom(bad)
+		assert.ErrorIs(t, err, ErrOrderNotFound)
+
+		// The failed replay leaves the previous state in place
+		assert.Equal(t, engine.orders, replayed.orders)
+		assert.Equal(t, engine.positions, replayed.positions)
+	})
+
+	t.Run("ActiveGroups", func(t *testing.T) {
+		grouped := NewMemoryEventLog()
+		source, _ := newTestEngine(t)
+		source.SetEventLog(grouped)
+		_, err := source.PlaceSpread([]*Order{
+			{ID: "leg1", UserID: "user1", Symbol: "AAA/SOL", Side: OrderSideBuy, Type: OrderTypeMarket, Quantity: 1},
+			{ID: "leg2", UserID: "user1", Symbol: "BBB/SOL", Side: OrderSideSell, Type: OrderTypeMarket, Quantity: 1},
+		}, []float64{1, 1})
+		require.NoError(t, err)
+
+		// Open legs would be left without their spread, and the source's
+		// own spread would be dropped
+		assert.ErrorIs(t, replayed.ReplayFrom(grouped), ErrActiveGroups)
+		assert.Equal(t, engine.orders, replayed.orders)
+		assert.ErrorIs(t, source.ReplayFrom(log), ErrActiveGroups)
+	})
+}
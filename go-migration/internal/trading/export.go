@@ -0,0 +1,181 @@
+package trading
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Export formats
+const (
+	ExportFormatCSV  = "csv"
+	ExportFormatJSON = "json"
+)
+
+// TradeFilter selects trades for export. Empty fields match any value.
+type TradeFilter struct {
+	UserID string
+	Symbol string
+	From   time.Time
+	To     time.Time
+}
+
+func (f TradeFilter) matches(trade *Trade) bool {
+	if f.UserID != "" && trade.UserID != f.UserID {
+		return false
+	}
+	if f.Symbol != "" && trade.Symbol != f.Symbol {
+		return false
+	}
+	if !f.From.IsZero() && trade.Timestamp.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && !trade.Timestamp.Before(f.To) {
+		return false
+	}
+	return true
+}
+
+// TradeCSVHeader is the column order of CSV trade exports
+var TradeCSVHeader = []string{
+	"id", "order_id", "user_id", "symbol", "side", "price", "quantity", "fee", "slippage", "timestamp",
+}
+
+// OrderCSVHeader is the column order of CSV order exports
+var OrderCSVHeader = []string{
+	"id", "user_id", "symbol", "side", "type", "price", "quantity", "filled_qty", "status", "created_at", "updated_at",
+}
+
+// ExportTrades writes trades matching filter to w in the given format,
+// streaming one record at a time
+func (e *Engine) ExportTrades(w io.Writer, format string, filter TradeFilter) error {
+	e.mu.RLock()
+	trades := make([]*Trade, 0, len(e.trades))
+	for _, trade := range e.trades {
+		if filter.matches(trade) {
+			trades = append(trades, trade)
+		}
+	}
+	e.mu.RUnlock()
+
+	switch format {
+	case ExportFormatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(TradeCSVHeader); err != nil {
+			return fmt.Errorf("failed to write header: %w", err)
+		}
+		for _, trade := range trades {
+			if err := cw.Write(tradeRecord(trade)); err != nil {
+				return fmt.Errorf("failed to write trade %s: %w", trade.ID, err)
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	case ExportFormatJSON:
+		items := make([]interface{}, len(trades))
+		for i, trade := range trades {
+			items[i] = trade
+		}
+		return writeJSONArray(w, items)
+	default:
+		return fmt.Errorf("unsupported export format: %s", format)
+	}
+}
+
+// ExportOrders writes open and retained terminal orders matching filter to
+// w in the given format, ordered by creation time and ID, streaming one
+// record at a time
+func (e *Engine) ExportOrders(w io.Writer, format string, filter OrderFilter) error {
+	orders := e.QueryOrders(filter)
+	sort.Slice(orders, func(i, j int) bool {
+		if !orders[i].CreatedAt.Equal(orders[j].CreatedAt) {
+			return orders[i].CreatedAt.Before(orders[j].CreatedAt)
+		}
+		return orders[i].ID < orders[j].ID
+	})
+
+	switch format {
+	case ExportFormatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(OrderCSVHeader); err != nil {
+			return fmt.Errorf("failed to write header: %w", err)
+		}
+		for _, order := range orders {
+			if err := cw.Write(orderRecord(order)); err != nil {
+				return fmt.Errorf("failed to write order %s: %w", order.ID, err)
+			}
+		}
+		cw.Flush()
+		return cw.Error()
+	case ExportFormatJSON:
+		items := make([]interface{}, len(orders))
+		for i, order := range orders {
+			items[i] = order
+		}
+		return writeJSONArray(w, items)
+	default:
+		return fmt.Errorf("unsupported export format: %s", format)
+	}
+}
+
+func tradeRecord(trade *Trade) []string {
+	return []string{
+		trade.ID,
+		trade.OrderID,
+		trade.UserID,
+		trade.Symbol,
+		string(trade.Side),
+		formatFloat(trade.Price),
+		formatFloat(trade.Quantity),
+		formatFloat(trade.Fee),
+		formatFloat(trade.Slippage),
+		trade.Timestamp.UTC().Format(time.RFC3339Nano),
+	}
+}
+
+func orderRecord(order *Order) []string {
+	return []string{
+		order.ID,
+		order.UserID,
+		order.Symbol,
+		string(order.Side),
+		string(order.Type),
+		formatFloat(order.Price),
+		formatFloat(order.Quantity),
+		formatFloat(order.FilledQty),
+		string(order.Status),
+		order.CreatedAt.UTC().Format(time.RFC3339Nano),
+		order.UpdatedAt.UTC().Format(time.RFC3339Nano),
+	}
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// writeJSONArray encodes items as a JSON array one element at a time
+func writeJSONArray(w io.Writer, items []interface{}) error {
+	if _, err := io.WriteString(w, "["); err != nil {
+		return err
+	}
+	for i, item := range items {
+		if i > 0 {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		data, err := json.Marshal(item)
+		if err != nil {
+			return fmt.Errorf("failed to encode record: %w", err)
+		}
+		if _, err := w.Write(data); err != nil {
+			return err
+		}
+	}
+	_, err := io.WriteString(w, "]\n")
+	return err
+}
@@ -0,0 +1,92 @@
+package trading
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngine_ExportTrades_CSVRoundTrip(t *testing.T) {
+	engine, _ := newTestEngine(t)
+	start := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	placeTestOrder(t, engine, "buy1", OrderSideBuy, 10)
+	require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: "buy1", Price: 100.5, Quantity: 4,
+		Fee: 0.25, Slippage: 0.001, Timestamp: start}))
+	require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: "buy1", Price: 101, Quantity: 6,
+		Fee: 0.3, Timestamp: start.Add(time.Minute)}))
+	placeTestOrder(t, engine, "sell1", OrderSideSell, 2)
+	require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: "sell1", Price: 99.75, Quantity: 2,
+		Timestamp: start.Add(2 * time.Minute)}))
+
+	var buf bytes.Buffer
+	require.NoError(t, engine.ExportTrades(&buf, ExportFormatCSV, TradeFilter{UserID: "user1"}))
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 4)
+	assert.Equal(t, TradeCSVHeader, records[0])
+
+	trades, err := engine.GetTrades("user1")
+	require.NoError(t, err)
+	for i, record := range records[1:] {
+		want := trades[i]
+		assert.Equal(t, want.ID, record[0])
+		assert.Equal(t, want.OrderID, record[1])
+		assert.Equal(t, string(want.Side), record[4])
+
+		price, err := strconv.ParseFloat(record[5], 64)
+		require.NoError(t, err)
+		assert.Equal(t, want.Price, price)
+		fee, err := strconv.ParseFloat(record[7], 64)
+		require.NoError(t, err)
+		assert.Equal(t, want.Fee, fee)
+		slippage, err := strconv.ParseFloat(record[8], 64)
+		require.NoError(t, err)
+		assert.Equal(t, want.Slippage, slippage)
+
+		ts, err := time.Parse(time.RFC3339Nano, record[9])
+		require.NoError(t, err)
+		assert.True(t, want.Timestamp.Equal(ts))
+	}
+
+	t.Run("Filter", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, engine.ExportTrades(&buf, ExportFormatCSV, TradeFilter{
+			From: start.Add(time.Minute),
+			To:   start.Add(2 * time.Minute),
+		}))
+		records, err := csv.NewReader(&buf).ReadAll()
+		require.NoError(t, err)
+		require.Len(t, records, 2)
+		assert.Equal(t, "101", records[1][5])
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, engine.ExportTrades(&buf, ExportFormatJSON, TradeFilter{}))
+		var decoded []*Trade
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+		require.Len(t, decoded, 3)
+		assert.Equal(t, 0.001, decoded[0].Slippage)
+	})
+
+	t.Run("Orders", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, engine.ExportOrders(&buf, ExportFormatCSV, OrderFilter{Status: OrderStatusFilled}))
+		records, err := csv.NewReader(&buf).ReadAll()
+		require.NoError(t, err)
+		assert.Equal(t, OrderCSVHeader, records[0])
+		assert.Len(t, records, 3)
+	})
+
+	t.Run("UnknownFormat", func(t *testing.T) {
+		assert.Error(t, engine.ExportTrades(&bytes.Buffer{}, "xml", TradeFilter{}))
+	})
+}
@@ -0,0 +1,111 @@
+package trading
+
+import (
+	"fmt"
+	"math"
+
+	"go.uber.org/zap"
+)
+
+// FeeModel estimates the fee charged for a fill
+type FeeModel interface {
+	Fee(side OrderSide, qty, price float64) float64
+}
+
+// CommissionFee charges Rate as a fraction of notional on both sides
+type CommissionFee struct {
+	Rate float64
+}
+
+// Fee implements FeeModel
+func (f CommissionFee) Fee(side OrderSide, qty, price float64) float64 {
+	return qty * price * f.Rate
+}
+
+// ExitGuardMode controls what the engine does with closing orders whose
+// expected net PnL after fees is negative
+type ExitGuardMode string
+
+const (
+	// ExitGuardOff disables the net PnL check
+	ExitGuardOff ExitGuardMode = ""
+	// ExitGuardWarn logs net-negative exits but places them
+	ExitGuardWarn ExitGuardMode = "warn"
+	// ExitGuardReject rejects net-negative exits with ErrNetLossExit
+	ExitGuardReject ExitGuardMode = "reject"
+)
+
+// SetFeeModel sets the fee model used by the exit guard. Without one the
+// engine charges Config.Commission on notional.
+func (e *Engine) SetFeeModel(model FeeModel) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.fees = model
+}
+
+// ExpectedExitPnL returns the net PnL of closing part of pos with order at
+// price: the gross PnL on the closed quantity less the entry fee on that
+// quantity and the exit fee. ok is false when order doesn't close pos.
+func ExpectedExitPnL(pos *Position, order *Order, price float64, fees FeeModel) (net float64, ok bool) {
+	if pos == nil || pos.Quantity == 0 {
+		return 0, false
+	}
+
+	direction := 1.0
+	entrySide := OrderSideBuy
+	if pos.Quantity < 0 {
+		direction = -1.0
+		entrySide = OrderSideSell
+	}
+	if order.Side == entrySide {
+		return 0, false
+	}
+
+	closed := math.Min(order.Quantity, math.Abs(pos.Quantity))
+	gross := (price - pos.AvgPrice) * closed * direction
+	net = gross - fees.Fee(entrySide, closed, pos.AvgPrice) - fees.Fee(order.Side, closed, price)
+	return net, true
+}
+
+// checkExitGuard applies Config.ExitGuard to closing orders. Orders with
+// ForceExit set, such as stop-outs, are never blocked.
+func (e *Engine) checkExitGuard(order *Order) error {
+	mode := e.config.ExitGuard
+	if mode == ExitGuardOff {
+		return nil
+	}
+
+	e.mu.RLock()
+	pos := e.positions[order.Symbol]
+	pricer := e.markPrices
+	fees := e.fees
+	e.mu.RUnlock()
+
+	if fees == nil {
+		fees = CommissionFee{Rate: e.config.Commission}
+	}
+
+	price := order.Price
+	if price <= 0 && pricer != nil {
+		if mark, err := pricer.MarkPrice(order.Symbol); err == nil {
+			price = mark
+		}
+	}
+	if price <= 0 {
+		return nil
+	}
+
+	net, closing := ExpectedExitPnL(pos, order, price, fees)
+	if !closing || net >= 0 {
+		return nil
+	}
+
+	if mode == ExitGuardReject && !order.ForceExit {
+		return fmt.Errorf("%w: order %s expects %f after fees", ErrNetLossExit, order.ID, net)
+	}
+	e.logger.Warn("Exit expects a net loss after fees",
+		append(orderFields(order),
+			zap.Float64("expected_net_pnl", net),
+			zap.Bool("forced", order.ForceExit))...)
+	return nil
+}
@@ -0,0 +1,72 @@
+package trading
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestEngine_ExitGuard(t *testing.T) {
+	newEngine := func(mode ExitGuardMode) *Engine {
+		config := testConfig()
+		config.Commission = 0.001
+		config.ExitGuard = mode
+		engine := NewEngine(config, zap.NewNop(), &memStorage{})
+
+		placeTestOrder(t, engine, "buy1", OrderSideBuy, 10)
+		require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: "buy1", Price: 100, Quantity: 10}))
+		return engine
+	}
+
+	// Selling at 100.1 is 1.0 gross, but 1.0 + 1.001 in fees
+	exit := func(id string) *Order {
+		return &Order{
+			ID:       id,
+			UserID:   "user1",
+			Symbol:   "TEST/SOL",
+			Side:     OrderSideSell,
+			Type:     OrderTypeLimit,
+			Price:    100.1,
+			Quantity: 10,
+			Status:   OrderStatusNew,
+		}
+	}
+
+	t.Run("Reject", func(t *testing.T) {
+		engine := newEngine(ExitGuardReject)
+		net, ok := ExpectedExitPnL(engine.GetPosition("TEST/SOL"), exit("x"), 100.1, CommissionFee{Rate: 0.001})
+		require.True(t, ok)
+		assert.InDelta(t, -1.001, net, 1e-9)
+
+		assert.ErrorIs(t, engine.PlaceOrder(exit("sell1")), ErrNetLossExit)
+
+		forced := exit("sell2")
+		forced.ForceExit = true
+		assert.NoError(t, engine.PlaceOrder(forced))
+
+		// A net-green exit passes
+		green := exit("sell3")
+		green.Quantity = 5
+		green.Price = 101
+		assert.NoError(t, engine.PlaceOrder(green))
+	})
+
+	t.Run("Warn", func(t *testing.T) {
+		engine := newEngine(ExitGuardWarn)
+		assert.NoError(t, engine.PlaceOrder(exit("sell1")))
+	})
+
+	t.Run("Off", func(t *testing.T) {
+		engine := newEngine(ExitGuardOff)
+		assert.NoError(t, engine.PlaceOrder(exit("sell1")))
+	})
+
+	t.Run("OpeningOrdersUnaffected", func(t *testing.T) {
+		engine := newEngine(ExitGuardReject)
+		buy := exit("buy2")
+		buy.Side = OrderSideBuy
+		assert.NoError(t, engine.PlaceOrder(buy))
+	})
+}
@@ -0,0 +1,45 @@
+package trading
+
+import (
+	"fmt"
+	"math"
+
+	"go.uber.org/zap"
+)
+
+// HiddenQty returns the quantity of an iceberg order held back from the
+// visible slice
+func (o *Order) HiddenQty() float64 {
+	if o.Type != OrderTypeIceberg {
+		return 0
+	}
+	return math.Max(o.Quantity-o.FilledQty-o.DisplayQty, 0)
+}
+
+func validateIceberg(order *Order) error {
+	if order.VisibleQty <= 0 || order.VisibleQty > order.Quantity {
+		return fmt.Errorf("invalid iceberg visible quantity %f for total %f",
+			order.VisibleQty, order.Quantity)
+	}
+	return nil
+}
+
+// replenishIceberg consumes qty from the visible slice and, once it is
+// exhausted, shows the next slice from the reserve. The final slice is
+// whatever remains when the reserve is smaller than VisibleQty.
+// Must be called with e.mu held.
+func (e *Engine) replenishIceberg(order *Order, qty float64) {
+	order.DisplayQty -= qty
+	if order.DisplayQty > 0 {
+		return
+	}
+
+	remaining := order.Quantity - order.FilledQty
+	order.DisplayQty = math.Min(order.VisibleQty, math.Max(remaining, 0))
+	if order.DisplayQty > 0 {
+		e.logger.Debug("Replenished iceberg slice",
+			zap.String("order_id", order.ID),
+			zap.Float64("display_qty", order.DisplayQty),
+			zap.Float64("hidden_qty", order.HiddenQty()))
+	}
+}
@@ -0,0 +1,58 @@
+package trading
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngine_IcebergOrder(t *testing.T) {
+	engine, _ := newTestEngine(t)
+	order := &Order{
+		ID:         "ice1",
+		UserID:     "user1",
+		Symbol:     "TEST/SOL",
+		Side:       OrderSideBuy,
+		Type:       OrderTypeIceberg,
+		Price:      100,
+		Quantity:   10,
+		VisibleQty: 4,
+		Status:     OrderStatusNew,
+	}
+	require.NoError(t, engine.PlaceOrder(order))
+	assert.Equal(t, 4.0, order.DisplayQty)
+	assert.Equal(t, 6.0, order.HiddenQty())
+
+	err := engine.ExecuteTrade(&Trade{OrderID: "ice1", Price: 100, Quantity: 5})
+	assert.ErrorIs(t, err, ErrInvalidFill, "fills are limited to the visible slice")
+
+	fills := []struct {
+		qty     float64
+		display float64
+		hidden  float64
+	}{
+		{qty: 1, display: 3, hidden: 6},
+		{qty: 3, display: 4, hidden: 2},
+		{qty: 4, display: 2, hidden: 0},
+		{qty: 2, display: 0, hidden: 0},
+	}
+	for i, fill := range fills {
+		require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: "ice1", Price: 100, Quantity: fill.qty}))
+		assert.InDelta(t, fill.display, order.DisplayQty, 1e-9, "fill %d display", i)
+		assert.InDelta(t, fill.hidden, order.HiddenQty(), 1e-9, "fill %d hidden", i)
+	}
+
+	assert.Equal(t, OrderStatusFilled, order.Status)
+	assert.Equal(t, 10.0, engine.GetPosition("TEST/SOL").Quantity)
+
+	t.Run("InvalidVisibleQty", func(t *testing.T) {
+		err := engine.PlaceOrder(&Order{
+			ID:         "ice2",
+			Type:       OrderTypeIceberg,
+			Quantity:   10,
+			VisibleQty: 20,
+		})
+		assert.Error(t, err)
+	})
+}
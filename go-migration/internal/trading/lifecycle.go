@@ -0,0 +1,45 @@
+package trading
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"go.uber.org/zap"
+)
+
+// newCorrelationID returns a random identifier for tracing an order
+func newCorrelationID() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(buf)
+}
+
+// orderFields returns the log fields identifying order
+func orderFields(order *Order) []zap.Field {
+	return []zap.Field{
+		zap.String("order_id", order.ID),
+		zap.String("correlation_id", order.CorrelationID),
+		zap.String("symbol", order.Symbol),
+	}
+}
+
+// logLifecycle logs an order stage when lifecycle logging is enabled
+func (e *Engine) logLifecycle(msg string, order *Order, fields ...zap.Field) {
+	if !e.config.LifecycleLogging {
+		return
+	}
+	e.logger.Info(msg, append(orderFields(order), fields...)...)
+}
+
+// logTransition logs an order's status change when lifecycle logging is
+// enabled
+func (e *Engine) logTransition(order *Order, from OrderStatus) {
+	if from == order.Status {
+		return
+	}
+	e.logLifecycle("Order status changed", order,
+		zap.String("from", string(from)),
+		zap.String("to", string(order.Status)))
+}
@@ -0,0 +1,75 @@
+package trading
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/kwanRoshi/B/go-migration/internal/risk"
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+func TestEngine_LifecycleLogging(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	logger := zap.New(core)
+
+	config := testConfig()
+	config.LifecycleLogging = true
+	engine := NewEngine(config, logger, &memStorage{})
+	manager := risk.NewManager(risk.Limits{MaxPositionSize: 100}, logger)
+
+	order := &Order{
+		ID:       "order1",
+		UserID:   "user1",
+		Symbol:   "TEST/SOL",
+		Side:     OrderSideBuy,
+		Type:     OrderTypeMarket,
+		Quantity: 10,
+		Status:   OrderStatusNew,
+	}
+	require.NoError(t, engine.PlaceOrder(order))
+	require.NotEmpty(t, order.CorrelationID)
+
+	require.NoError(t, manager.CheckOrderRisk(context.Background(), &types.Order{
+		ID:            order.ID,
+		Symbol:        order.Symbol,
+		Quantity:      order.Quantity,
+		CorrelationID: order.CorrelationID,
+	}))
+	require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: "order1", Price: 100, Quantity: 4}))
+	require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: "order1", Price: 100, Quantity: 6}))
+
+	stages := []string{
+		"Order placed",
+		"Order passed risk check",
+		"Order fill applied",
+		"Position updated",
+		"Order status changed",
+	}
+	for _, stage := range stages {
+		entries := logs.FilterMessage(stage).All()
+		require.NotEmpty(t, entries, stage)
+		for _, entry := range entries {
+			assert.Equal(t, order.CorrelationID, entry.ContextMap()["correlation_id"], stage)
+		}
+	}
+
+	transitions := logs.FilterMessage("Order status changed").All()
+	require.Len(t, transitions, 2)
+	assert.Equal(t, "new", transitions[0].ContextMap()["from"])
+	assert.Equal(t, "partial", transitions[0].ContextMap()["to"])
+	assert.Equal(t, "partial", transitions[1].ContextMap()["from"])
+	assert.Equal(t, "filled", transitions[1].ContextMap()["to"])
+
+	t.Run("Disabled", func(t *testing.T) {
+		core, logs := observer.New(zapcore.DebugLevel)
+		engine := NewEngine(testConfig(), zap.New(core), &memStorage{})
+		placeTestOrder(t, engine, "order2", OrderSideBuy, 1)
+		assert.Zero(t, logs.FilterMessage("Order placed").Len())
+	})
+}
@@ -0,0 +1,80 @@
+package trading
+
+import "math"
+
+// lotTolerance is the relative shortfall of lot quantities below the
+// position quantity treated as rounding rather than untracked quantity
+const lotTolerance = 1e-9
+
+// LotMethod selects which entry lots a reduction closes
+type LotMethod string
+
+const (
+	// LotMethodNone disables lot tracking
+	LotMethodNone LotMethod = ""
+	// LotMethodFIFO closes the oldest lots first
+	LotMethodFIFO LotMethod = "fifo"
+	// LotMethodLIFO closes the newest lots first
+	LotMethodLIFO LotMethod = "lifo"
+)
+
+// consumeLots removes qty from pos's lots in method order and returns the
+// PnL realized at price. direction is 1 for longs and -1 for shorts.
+// Quantity the lots don't cover, such as a position opened before lot
+// tracking was enabled, is closed as the oldest lot first.
+func consumeLots(pos *Position, qty, price, direction float64, method LotMethod) float64 {
+	backfillLots(pos)
+
+	var pnl float64
+	for qty > 0 && len(pos.Lots) > 0 {
+		i := 0
+		if method == LotMethodLIFO {
+			i = len(pos.Lots) - 1
+		}
+		lot := &pos.Lots[i]
+
+		closed := math.Min(qty, lot.Quantity)
+		pnl += (price - lot.Price) * closed * direction
+		lot.Quantity -= closed
+		qty -= closed
+
+		if lot.Quantity <= 0 {
+			pos.Lots = append(pos.Lots[:i], pos.Lots[i+1:]...)
+		}
+	}
+	return pnl
+}
+
+// backfillLots prepends a lot for the part of pos the lots don't cover,
+// priced so the lots keep the position's total cost, or at its average
+// price when that cost doesn't leave a positive price
+func backfillLots(pos *Position) {
+	var covered, notional float64
+	for _, lot := range pos.Lots {
+		covered += lot.Quantity
+		notional += lot.Price * lot.Quantity
+	}
+	uncovered := math.Abs(pos.Quantity) - covered
+	if uncovered <= lotTolerance*math.Abs(pos.Quantity) {
+		return
+	}
+
+	price := (pos.TotalCost - notional) / uncovered
+	if !(price > 0) || math.IsInf(price, 0) {
+		price = pos.AvgPrice
+	}
+	pos.Lots = append([]Lot{{Price: price, Quantity: uncovered}}, pos.Lots...)
+}
+
+// lotsAvgPrice returns the quantity-weighted entry price of lots
+func lotsAvgPrice(lots []Lot) float64 {
+	var qty, notional float64
+	for _, lot := range lots {
+		qty += lot.Quantity
+		notional += lot.Price * lot.Quantity
+	}
+	if qty == 0 {
+		return 0
+	}
+	return notional / qty
+}
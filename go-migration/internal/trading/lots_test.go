@@ -0,0 +1,86 @@
+package trading
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestEngine_LotMethods(t *testing.T) {
+	// Buy 10 @ 100, buy 10 @ 120, then sell 15 @ 130
+	run := func(t *testing.T, method LotMethod) *Position {
+		t.Helper()
+		config := testConfig()
+		config.LotMethod = method
+		engine := NewEngine(config, zap.NewNop(), &memStorage{})
+
+		start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+		placeTestOrder(t, engine, "buy1", OrderSideBuy, 10)
+		require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: "buy1", Price: 100, Quantity: 10, Timestamp: start}))
+		placeTestOrder(t, engine, "buy2", OrderSideBuy, 10)
+		require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: "buy2", Price: 120, Quantity: 10, Timestamp: start.Add(time.Hour)}))
+		placeTestOrder(t, engine, "sell1", OrderSideSell, 15)
+		require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: "sell1", Price: 130, Quantity: 15}))
+
+		return engine.GetPosition("TEST/SOL")
+	}
+
+	t.Run("FIFO", func(t *testing.T) {
+		pos := run(t, LotMethodFIFO)
+		// 10 * (130-100) + 5 * (130-120)
+		assert.InDelta(t, 350.0, pos.RealizedPnL, 1e-9)
+		require.Len(t, pos.Lots, 1)
+		assert.Equal(t, 120.0, pos.Lots[0].Price)
+		assert.Equal(t, 5.0, pos.Lots[0].Quantity)
+		assert.Equal(t, 120.0, pos.AvgPrice)
+	})
+
+	t.Run("LIFO", func(t *testing.T) {
+		pos := run(t, LotMethodLIFO)
+		// 10 * (130-120) + 5 * (130-100)
+		assert.InDelta(t, 250.0, pos.RealizedPnL, 1e-9)
+		require.Len(t, pos.Lots, 1)
+		assert.Equal(t, 100.0, pos.Lots[0].Price)
+		assert.Equal(t, 5.0, pos.Lots[0].Quantity)
+		assert.Equal(t, 100.0, pos.AvgPrice)
+	})
+
+	t.Run("Average", func(t *testing.T) {
+		pos := run(t, LotMethodNone)
+		// 15 * (130-110)
+		assert.InDelta(t, 300.0, pos.RealizedPnL, 1e-9)
+		assert.Empty(t, pos.Lots)
+	})
+}
+
+func TestEngine_LotsBackfilledFromAverage(t *testing.T) {
+	// A position opened before lot tracking was enabled has no lots
+	config := testConfig()
+	config.LotMethod = LotMethodFIFO
+	engine := NewEngine(config, zap.NewNop(), &memStorage{})
+	engine.positions["TEST/SOL"] = &Position{UserID: "user1", Symbol: "TEST/SOL", Quantity: 10, AvgPrice: 100}
+
+	placeTestOrder(t, engine, "buy1", OrderSideBuy, 10)
+	require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: "buy1", Price: 120, Quantity: 10}))
+	placeTestOrder(t, engine, "sell1", OrderSideSell, 15)
+	require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: "sell1", Price: 130, Quantity: 15}))
+
+	pos := engine.GetPosition("TEST/SOL")
+	// The untracked 10 @ 100 closes first, then 5 of the 10 @ 120
+	assert.InDelta(t, 350.0, pos.RealizedPnL, 1e-9)
+	require.Len(t, pos.Lots, 1)
+	assert.Equal(t, 5.0, pos.Lots[0].Quantity)
+	assert.Equal(t, 120.0, pos.AvgPrice)
+
+	// Without any lots, the whole position realizes against its average
+	engine.positions["TEST/SOL"].Lots = nil
+	placeTestOrder(t, engine, "sell2", OrderSideSell, 2)
+	require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: "sell2", Price: 110, Quantity: 2}))
+	pos = engine.GetPosition("TEST/SOL")
+	assert.InDelta(t, 330.0, pos.RealizedPnL, 1e-9)
+	assert.Equal(t, 120.0, pos.AvgPrice)
+	assert.Equal(t, 3.0, pos.Quantity)
+}
@@ -0,0 +1,51 @@
+package trading
+
+import (
+	"fmt"
+	"time"
+)
+
+// minFillQty returns the smallest fill order accepts: its own MinFillQty
+// or Config.MinFillQty
+func (e *Engine) minFillQty(order *Order) float64 {
+	if order.MinFillQty > 0 {
+		return order.MinFillQty
+	}
+	return e.config.MinFillQty
+}
+
+// checkMinFill rejects fills below the order's minimum fill size. A fill
+// that completes the rest of the order, or of an iceberg's visible slice,
+// is always accepted so no order is left unfillable. Must be called with
+// e.mu held.
+func (e *Engine) checkMinFill(order *Order, trade *Trade) error {
+	min := e.minFillQty(order)
+	if min <= 0 || trade.Quantity >= min {
+		return nil
+	}
+	remaining := order.Quantity - order.FilledQty
+	if order.Type == OrderTypeIceberg {
+		remaining = order.DisplayQty
+	}
+	if trade.Quantity >= remaining {
+		return nil
+	}
+	return fmt.Errorf("%w: %f < %f on %s", ErrFillTooSmall, trade.Quantity, min, order.ID)
+}
+
+// cancelIOC cancels what is left of an immediate-or-cancel order after its
+// execution attempt, reporting whether it did. Must be called with e.mu
+// held.
+func (e *Engine) cancelIOC(order *Order) bool {
+	if !order.ImmediateOrCancel || order.Status.IsTerminal() {
+		return false
+	}
+	from := order.Status
+	order.Status = OrderStatusCanceled
+	order.UpdatedAt = time.Now()
+	e.retireOrder(order)
+	e.logTransition(order, from)
+	e.recordOrder(EventOrderCanceled, order)
+	e.finishParent(order)
+	return true
+}
@@ -0,0 +1,61 @@
+package trading
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngine_MinFillQty(t *testing.T) {
+	engine, _ := newTestEngine(t)
+	engine.config.MinFillQty = 2
+
+	order := placeTestOrder(t, engine, "buy1", OrderSideBuy, 5)
+
+	err := engine.ExecuteTrade(&Trade{OrderID: "buy1", Price: 100, Quantity: 0.5})
+	assert.ErrorIs(t, err, ErrFillTooSmall)
+	assert.Equal(t, OrderStatusNew, order.Status)
+	assert.Nil(t, engine.GetPosition("TEST/SOL"))
+
+	require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: "buy1", Price: 100, Quantity: 4}))
+	assert.Equal(t, OrderStatusPartial, order.Status)
+
+	// The last unit is below the minimum but completes the order
+	require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: "buy1", Price: 100, Quantity: 1}))
+	assert.Equal(t, OrderStatusFilled, order.Status)
+
+	// The order's own minimum overrides the config
+	small := &Order{ID: "buy2", UserID: "user1", Symbol: "TEST/SOL", Side: OrderSideBuy,
+		Type: OrderTypeMarket, Quantity: 5, Status: OrderStatusNew, MinFillQty: 0.5}
+	require.NoError(t, engine.PlaceOrder(small))
+	require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: "buy2", Price: 100, Quantity: 0.5}))
+
+	bad := &Order{ID: "buy3", UserID: "user1", Symbol: "TEST/SOL", Side: OrderSideBuy,
+		Type: OrderTypeMarket, Quantity: 5, Status: OrderStatusNew, MinFillQty: -1}
+	assert.ErrorIs(t, engine.PlaceOrder(bad), ErrInvalidOrder)
+}
+
+func TestEngine_ImmediateOrCancel(t *testing.T) {
+	engine, storage := newTestEngine(t)
+	engine.config.MinFillQty = 2
+
+	tooSmall := &Order{ID: "ioc1", UserID: "user1", Symbol: "TEST/SOL", Side: OrderSideBuy,
+		Type: OrderTypeMarket, Quantity: 5, Status: OrderStatusNew, ImmediateOrCancel: true}
+	require.NoError(t, engine.PlaceOrder(tooSmall))
+
+	err := engine.ExecuteTrade(&Trade{OrderID: "ioc1", Price: 100, Quantity: 1})
+	assert.ErrorIs(t, err, ErrFillTooSmall)
+	assert.Equal(t, OrderStatusCanceled, tooSmall.Status)
+	assert.Equal(t, OrderStatusCanceled, storage.orders[len(storage.orders)-1].Status)
+	assert.ErrorIs(t, engine.ExecuteTrade(&Trade{OrderID: "ioc1", Price: 100, Quantity: 5}), ErrOrderTerminal)
+
+	// A partial fill above the minimum goes through and cancels the rest
+	partial := &Order{ID: "ioc2", UserID: "user1", Symbol: "TEST/SOL", Side: OrderSideBuy,
+		Type: OrderTypeMarket, Quantity: 5, Status: OrderStatusNew, ImmediateOrCancel: true}
+	require.NoError(t, engine.PlaceOrder(partial))
+	require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: "ioc2", Price: 100, Quantity: 3}))
+	assert.Equal(t, OrderStatusCanceled, partial.Status)
+	assert.Equal(t, 3.0, partial.FilledQty)
+	assert.Equal(t, 3.0, engine.GetPosition("TEST/SOL").Quantity)
+}
@@ -0,0 +1,106 @@
+package trading
+
+import (
+	"fmt"
+	"math"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// PaperExecutor simulates market order fills against the engine using a
+// slippage model per symbol
+type PaperExecutor struct {
+	logger       *zap.Logger
+	engine       *Engine
+	defaultModel SlippageModel
+	models       map[string]SlippageModel
+	mu           sync.RWMutex
+}
+
+// NewPaperExecutor creates a paper executor. A nil defaultModel uses the
+// engine's configured Slippage as a fixed fraction.
+func NewPaperExecutor(engine *Engine, defaultModel SlippageModel, logger *zap.Logger) *PaperExecutor {
+	if defaultModel == nil {
+		defaultModel = FixedBpsSlippage{Bps: engine.config.Slippage * 10000}
+	}
+
+	return &PaperExecutor{
+		logger:       logger,
+		engine:       engine,
+		defaultModel: defaultModel,
+		models:       make(map[string]SlippageModel),
+	}
+}
+
+// SetModel sets the slippage model used for symbol
+func (p *PaperExecutor) SetModel(symbol string, model SlippageModel) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.models[symbol] = model
+}
+
+// Fill executes the remaining quantity of a placed order, or the visible
+// slice of an iceberg, at a simulated price derived from refPrice and
+// book. book may be nil for models that don't need it.
+func (p *PaperExecutor) Fill(orderID string, refPrice float64, book *OrderBook) (*Trade, error) {
+	order, qty, err := p.engine.fillable(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if refPrice <= 0 {
+		return nil, fmt.Errorf("invalid reference price: %f", refPrice)
+	}
+
+	price, err := p.model(order.Symbol).FillPrice(order.Side, qty, refPrice, book)
+	if err != nil {
+		return nil, fmt.Errorf("failed to simulate fill for %s: %w", orderID, err)
+	}
+
+	trade := &Trade{
+		OrderID:  orderID,
+		Price:    price,
+		Quantity: qty,
+		Fee:      price * qty * p.engine.config.Commission,
+		Slippage: math.Abs(price-refPrice) / refPrice,
+		RefPrice: refPrice,
+	}
+	if err := p.engine.ExecuteTrade(trade); err != nil {
+		return nil, err
+	}
+
+	p.logger.Debug("Paper fill",
+		zap.String("order_id", orderID),
+		zap.Float64("ref_price", refPrice),
+		zap.Float64("fill_price", price),
+		zap.Float64("slippage", trade.Slippage))
+
+	return trade, nil
+}
+
+// fillable returns a copy of order orderID, taken under e.mu, and the most
+// one trade may fill of it: what is left, or an iceberg's visible slice
+func (e *Engine) fillable(orderID string) (Order, float64, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	order, exists := e.lookupOrder(orderID)
+	if !exists {
+		return Order{}, 0, fmt.Errorf("%w: %s", ErrOrderNotFound, orderID)
+	}
+	qty := order.Quantity - order.FilledQty
+	if order.Type == OrderTypeIceberg {
+		qty = math.Min(qty, order.DisplayQty)
+	}
+	return *order, qty, nil
+}
+
+func (p *PaperExecutor) model(symbol string) SlippageModel {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if model, ok := p.models[symbol]; ok {
+		return model
+	}
+	return p.defaultModel
+}
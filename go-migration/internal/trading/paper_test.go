@@ -0,0 +1,89 @@
+package trading
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestSlippageModels_FixedVsSize(t *testing.T) {
+	fixed := FixedBpsSlippage{Bps: 10}
+	sqrt := SqrtSlippage{Coefficient: 0.01, Liquidity: 10000}
+
+	// At 100 units both models move the price by 10bps
+	fixedSmall, err := fixed.FillPrice(OrderSideBuy, 100, 100, nil)
+	require.NoError(t, err)
+	sqrtSmall, err := sqrt.FillPrice(OrderSideBuy, 100, 100, nil)
+	require.NoError(t, err)
+	assert.InDelta(t, 100.1, fixedSmall, 1e-9)
+	assert.InDelta(t, 100.1, sqrtSmall, 1e-9)
+
+	// Quadrupling the size doubles the size-proportional slippage only
+	fixedLarge, err := fixed.FillPrice(OrderSideBuy, 400, 100, nil)
+	require.NoError(t, err)
+	sqrtLarge, err := sqrt.FillPrice(OrderSideBuy, 400, 100, nil)
+	require.NoError(t, err)
+	assert.InDelta(t, 100.1, fixedLarge, 1e-9)
+	assert.InDelta(t, 100.2, sqrtLarge, 1e-9)
+
+	sell, err := sqrt.FillPrice(OrderSideSell, 400, 100, nil)
+	require.NoError(t, err)
+	assert.InDelta(t, 99.8, sell, 1e-9)
+}
+
+func TestBookWalkSlippage(t *testing.T) {
+	book := &OrderBook{
+		Symbol: "TEST/SOL",
+		Asks:   []OrderBookLevel{{Price: 101, Quantity: 5}, {Price: 102, Quantity: 5}},
+		Bids:   []OrderBookLevel{{Price: 99, Quantity: 5}},
+	}
+
+	price, err := BookWalkSlippage{}.FillPrice(OrderSideBuy, 8, 100, book)
+	require.NoError(t, err)
+	assert.InDelta(t, (5*101.0+3*102.0)/8, price, 1e-9)
+
+	_, err = BookWalkSlippage{}.FillPrice(OrderSideSell, 8, 100, book)
+	assert.Error(t, err)
+}
+
+func TestPaperExecutor_Fill(t *testing.T) {
+	engine, _ := newTestEngine(t)
+	paper := NewPaperExecutor(engine, FixedBpsSlippage{Bps: 10}, zap.NewNop())
+	paper.SetModel("OTHER/SOL", SqrtSlippage{Coefficient: 0.01, Liquidity: 10000})
+
+	placeTestOrder(t, engine, "buy1", OrderSideBuy, 400)
+	trade, err := paper.Fill("buy1", 100, nil)
+	require.NoError(t, err)
+	assert.InDelta(t, 100.1, trade.Price, 1e-9)
+	assert.InDelta(t, 0.001, trade.Slippage, 1e-9)
+
+	order, err := engine.GetOrder("buy1")
+	require.NoError(t, err)
+	assert.Equal(t, OrderStatusFilled, order.Status)
+
+	require.NoError(t, engine.PlaceOrder(&Order{ID: "buy2", UserID: "user1", Symbol: "OTHER/SOL",
+		Side: OrderSideBuy, Type: OrderTypeMarket, Quantity: 400}))
+	trade, err = paper.Fill("buy2", 100, nil)
+	require.NoError(t, err)
+	assert.InDelta(t, 100.2, trade.Price, 1e-9)
+
+	_, err = paper.Fill("missing", 100, nil)
+	assert.ErrorIs(t, err, ErrOrderNotFound)
+
+	t.Run("Iceberg", func(t *testing.T) {
+		require.NoError(t, engine.PlaceOrder(&Order{ID: "ice1", UserID: "user1", Symbol: "TEST/SOL",
+			Side: OrderSideBuy, Type: OrderTypeIceberg, Price: 100, Quantity: 10, VisibleQty: 4}))
+
+		// Each paper fill takes only the visible slice, which then refills
+		for _, want := range []float64{4, 4, 2} {
+			trade, err := paper.Fill("ice1", 100, nil)
+			require.NoError(t, err)
+			assert.InDelta(t, want, trade.Quantity, 1e-9)
+		}
+		order, err := engine.GetOrder("ice1")
+		require.NoError(t, err)
+		assert.Equal(t, OrderStatusFilled, order.Status)
+	})
+}
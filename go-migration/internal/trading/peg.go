@@ -0,0 +1,108 @@
+package trading
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// PegReference is the book price a pegged order tracks
+type PegReference string
+
+const (
+	PegMid PegReference = "mid"
+	PegBid PegReference = "bid"
+	PegAsk PegReference = "ask"
+)
+
+// isPegged reports whether order re-prices with the market
+func isPegged(order *Order) bool {
+	return order.PegTo != ""
+}
+
+// pegPrice returns the price order should rest at given book and the last
+// traded price: its reference plus PegOffset. The mid falls back to last
+// when the book is missing or one-sided. Post-only orders are held back
+// at the best price on their own side rather than crossing. ok is false
+// when there is no reference price.
+func pegPrice(order *Order, book *OrderBook, last float64) (price float64, ok bool) {
+	var bid, ask float64
+	if book != nil {
+		bid, ask = book.BestBidAsk()
+	}
+
+	var ref float64
+	switch order.PegTo {
+	case PegMid:
+		ref = last
+		if bid > 0 && ask > 0 {
+			ref = (bid + ask) / 2
+		}
+	case PegBid:
+		ref = bid
+	case PegAsk:
+		ref = ask
+	}
+	if ref <= 0 {
+		return 0, false
+	}
+
+	price = ref + order.PegOffset
+	if order.PostOnly {
+		if order.Side == OrderSideBuy && ask > 0 && price >= ask {
+			price = bid
+		} else if order.Side == OrderSideSell && bid > 0 && price <= bid {
+			price = ask
+		}
+	}
+	return price, price > 0
+}
+
+// validatePeg checks a pegged order and sets its initial price from the
+// current book
+func (e *Engine) validatePeg(order *Order) error {
+	switch order.PegTo {
+	case PegMid, PegBid, PegAsk:
+	default:
+		return fmt.Errorf("%w: unknown peg reference %q", ErrInvalidOrder, order.PegTo)
+	}
+	if order.Type != OrderTypeLimit {
+		return fmt.Errorf("%w: pegged order %s must be a limit order", ErrInvalidOrder, order.ID)
+	}
+
+	e.mu.RLock()
+	book := e.books[order.Symbol]
+	e.mu.RUnlock()
+	price, ok := pegPrice(order, book, 0)
+	if !ok {
+		return fmt.Errorf("%w: no %s price to peg %s to", ErrInvalidOrder, order.PegTo, order.ID)
+	}
+	order.Price = price
+	return nil
+}
+
+// repegLocked moves resting pegged orders for symbol to their reference
+// price and returns the ones that changed. Must be called with e.mu held.
+func (e *Engine) repegLocked(symbol string, last float64, now time.Time) []*Order {
+	book := e.books[symbol]
+	var amended []*Order
+	for _, order := range e.orders {
+		if order.Symbol != symbol || !isPegged(order) || order.Status.IsTerminal() {
+			continue
+		}
+		price, ok := pegPrice(order, book, last)
+		if !ok || price == order.Price {
+			continue
+		}
+
+		e.logLifecycle("Pegged order repriced", order,
+			zap.Float64("from", order.Price),
+			zap.Float64("to", price))
+		order.Price = price
+		order.UpdatedAt = now
+		e.recordOrder(EventOrderUpdated, order)
+		amended = append(amended, order)
+	}
+	return amended
+}
@@ -0,0 +1,56 @@
+package trading
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngine_PeggedOrders(t *testing.T) {
+	engine, _ := newTestEngine(t)
+	setBook := func(bid, ask float64) {
+		engine.UpdateOrderBook(&OrderBook{
+			Symbol: "TEST/SOL",
+			Bids:   []OrderBookLevel{{Price: bid, Quantity: 10}},
+			Asks:   []OrderBookLevel{{Price: ask, Quantity: 10}},
+		})
+	}
+	setBook(99, 101)
+
+	pegged := &Order{ID: "peg", UserID: "user1", Symbol: "TEST/SOL", Side: OrderSideBuy, Type: OrderTypeLimit,
+		Quantity: 1, Status: OrderStatusNew, PegTo: PegMid, PegOffset: -0.5}
+	require.NoError(t, engine.PlaceOrder(pegged))
+	assert.Equal(t, 99.5, pegged.Price)
+
+	// Post-only pegged to the far side is held at the best bid
+	passive := &Order{ID: "passive", UserID: "user1", Symbol: "TEST/SOL", Side: OrderSideBuy, Type: OrderTypeLimit,
+		Quantity: 1, Status: OrderStatusNew, PegTo: PegAsk, PostOnly: true}
+	require.NoError(t, engine.PlaceOrder(passive))
+	assert.Equal(t, 99.0, passive.Price)
+
+	for _, mid := range []float64{105, 102, 110} {
+		setBook(mid-1, mid+1)
+		engine.OnPriceUpdate("TEST/SOL", mid)
+		assert.Equal(t, mid-0.5, pegged.Price)
+		assert.Equal(t, mid-1, passive.Price)
+	}
+
+	// A one-sided book pegs the mid to the last price
+	engine.UpdateOrderBook(&OrderBook{Symbol: "TEST/SOL", Bids: []OrderBookLevel{{Price: 90, Quantity: 1}}})
+	engine.OnPriceUpdate("TEST/SOL", 95)
+	assert.Equal(t, 94.5, pegged.Price)
+
+	// Filled orders stop tracking
+	require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: "peg", Price: 94.5, Quantity: 1}))
+	setBook(119, 121)
+	engine.OnPriceUpdate("TEST/SOL", 120)
+	assert.Equal(t, 94.5, pegged.Price)
+
+	bad := &Order{ID: "bad", UserID: "user1", Symbol: "TEST/SOL", Side: OrderSideBuy, Type: OrderTypeMarket,
+		Quantity: 1, Status: OrderStatusNew, PegTo: PegMid}
+	assert.ErrorIs(t, engine.PlaceOrder(bad), ErrInvalidOrder)
+	bad = &Order{ID: "bad", UserID: "user1", Symbol: "OTHER/SOL", Side: OrderSideBuy, Type: OrderTypeLimit,
+		Quantity: 1, Status: OrderStatusNew, PegTo: PegBid}
+	assert.ErrorIs(t, engine.PlaceOrder(bad), ErrInvalidOrder)
+}
@@ -0,0 +1,52 @@
+package trading
+
+import (
+	"fmt"
+	"time"
+)
+
+// RealizedPnL returns the PnL userID realized, net of fees, from trades
+// executed in [from, to)
+func (e *Engine) RealizedPnL(userID string, from, to time.Time) (float64, error) {
+	bySymbol, err := e.RealizedPnLBySymbol(userID, from, to)
+	if err != nil {
+		return 0, err
+	}
+	total := 0.0
+	for _, pnl := range bySymbol {
+		total += pnl
+	}
+	return total, nil
+}
+
+// RealizedPnLBySymbol returns the PnL userID realized, net of fees, from
+// trades executed in [from, to), keyed by symbol. Every trade of the user
+// is replayed so reductions in the window are measured against cost basis
+// built up before it; symbols with no trades in the window are left out.
+func (e *Engine) RealizedPnLBySymbol(userID string, from, to time.Time) (map[string]float64, error) {
+	if to.Before(from) {
+		return nil, fmt.Errorf("invalid PnL window: %s is before %s", to, from)
+	}
+
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	positions := make(map[string]*Position)
+	bySymbol := make(map[string]float64)
+	for _, trade := range e.trades {
+		if trade.UserID != userID || !trade.Timestamp.Before(to) {
+			continue
+		}
+		pos, exists := positions[trade.Symbol]
+		if !exists {
+			pos = &Position{UserID: trade.UserID, Symbol: trade.Symbol}
+			positions[trade.Symbol] = pos
+		}
+		before := pos.RealizedPnL
+		e.applyToPosition(pos, trade)
+		if !trade.Timestamp.Before(from) {
+			bySymbol[trade.Symbol] += pos.RealizedPnL - before
+		}
+	}
+	return bySymbol, nil
+}
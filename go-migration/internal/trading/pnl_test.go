@@ -0,0 +1,57 @@
+package trading
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngine_RealizedPnL(t *testing.T) {
+	engine, _ := newTestEngine(t)
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	fill := func(id, symbol string, side OrderSide, price, qty float64, at time.Time) {
+		t.Helper()
+		require.NoError(t, engine.PlaceOrder(&Order{ID: id, UserID: "user1", Symbol: symbol,
+			Side: side, Type: OrderTypeMarket, Quantity: qty}))
+		require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: id, Price: price, Quantity: qty, Timestamp: at}))
+	}
+
+	// Entries on day one, exits on days two and three
+	fill("a-buy", "AAA/SOL", OrderSideBuy, 10, 10, day.Add(time.Hour))
+	fill("b-buy", "BBB/SOL", OrderSideBuy, 5, 10, day.Add(2*time.Hour))
+	fill("a-sell1", "AAA/SOL", OrderSideSell, 12, 5, day.Add(25*time.Hour))
+	fill("b-sell", "BBB/SOL", OrderSideSell, 4, 10, day.Add(26*time.Hour))
+	fill("a-sell2", "AAA/SOL", OrderSideSell, 15, 5, day.Add(49*time.Hour))
+
+	dayTwo, dayThree := day.Add(24*time.Hour), day.Add(48*time.Hour)
+
+	// AAA gains 10 and BBB loses 10 on day two
+	pnl, err := engine.RealizedPnL("user1", dayTwo, dayThree)
+	require.NoError(t, err)
+	assert.InDelta(t, 0.0, pnl, 1e-9)
+
+	bySymbol, err := engine.RealizedPnLBySymbol("user1", dayTwo, dayThree)
+	require.NoError(t, err)
+	assert.InDelta(t, 10.0, bySymbol["AAA/SOL"], 1e-9)
+	assert.InDelta(t, -10.0, bySymbol["BBB/SOL"], 1e-9)
+
+	// The day three exit is measured against the day one entry
+	pnl, err = engine.RealizedPnL("user1", dayThree, dayThree.Add(24*time.Hour))
+	require.NoError(t, err)
+	assert.InDelta(t, 25.0, pnl, 1e-9)
+
+	// Entries alone realize nothing
+	bySymbol, err = engine.RealizedPnLBySymbol("user1", day, dayTwo)
+	require.NoError(t, err)
+	assert.InDelta(t, 0.0, bySymbol["AAA/SOL"], 1e-9)
+
+	pnl, err = engine.RealizedPnL("other", day, dayThree.Add(24*time.Hour))
+	require.NoError(t, err)
+	assert.Zero(t, pnl)
+
+	_, err = engine.RealizedPnL("user1", dayThree, dayTwo)
+	assert.Error(t, err)
+}
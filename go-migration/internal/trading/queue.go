@@ -0,0 +1,206 @@
+package trading
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// PendingExecution is a fill waiting in the execution queue. Reducing is
+// set when the order was reduce-only or shrank the existing position at
+// the time the fill was queued.
+type PendingExecution struct {
+	Trade    *Trade
+	Order    *Order
+	Reducing bool
+	QueuedAt time.Time
+	seq      uint64
+}
+
+// PriorityQueue orders pending executions. Pop returns nil when the queue
+// is empty.
+type PriorityQueue interface {
+	Push(exec *PendingExecution)
+	Pop() *PendingExecution
+	Len() int
+}
+
+// PriorityFunc reports whether a should execute before b. Executions it
+// doesn't order run in the order they were queued.
+type PriorityFunc func(a, b *PendingExecution) bool
+
+// ReducingFirst runs reduce-only and closing fills before opening ones
+func ReducingFirst(a, b *PendingExecution) bool {
+	return a.Reducing && !b.Reducing
+}
+
+// LargerFeeFirst runs fills paying the larger fee first
+func LargerFeeFirst(a, b *PendingExecution) bool {
+	return a.Trade.Fee > b.Trade.Fee
+}
+
+// FIFOQueue executes fills in the order they were queued
+type FIFOQueue struct {
+	items []*PendingExecution
+}
+
+func (q *FIFOQueue) Push(exec *PendingExecution) {
+	q.items = append(q.items, exec)
+}
+
+func (q *FIFOQueue) Pop() *PendingExecution {
+	if len(q.items) == 0 {
+		return nil
+	}
+	exec := q.items[0]
+	q.items[0] = nil
+	q.items = q.items[1:]
+	return exec
+}
+
+func (q *FIFOQueue) Len() int {
+	return len(q.items)
+}
+
+// priorityHeap executes fills by less, falling back to queue order
+type priorityHeap struct {
+	less  PriorityFunc
+	items []*PendingExecution
+}
+
+// NewPriorityQueue returns a queue that pops executions ordered by less.
+// Ties keep queue order, so a nil less behaves like FIFOQueue.
+func NewPriorityQueue(less PriorityFunc) PriorityQueue {
+	return &priorityHeap{less: less}
+}
+
+func (h *priorityHeap) Push(exec *PendingExecution) {
+	heap.Push((*heapItems)(h), exec)
+}
+
+func (h *priorityHeap) Pop() *PendingExecution {
+	if len(h.items) == 0 {
+		return nil
+	}
+	return heap.Pop((*heapItems)(h)).(*PendingExecution)
+}
+
+func (h *priorityHeap) Len() int {
+	return len(h.items)
+}
+
+// heapItems adapts priorityHeap to container/heap
+type heapItems priorityHeap
+
+func (h *heapItems) Len() int { return len(h.items) }
+
+func (h *heapItems) Less(i, j int) bool {
+	a, b := h.items[i], h.items[j]
+	if h.less != nil {
+		if h.less(a, b) {
+			return true
+		}
+		if h.less(b, a) {
+			return false
+		}
+	}
+	return a.seq < b.seq
+}
+
+func (h *heapItems) Swap(i, j int) { h.items[i], h.items[j] = h.items[j], h.items[i] }
+
+func (h *heapItems) Push(x interface{}) {
+	h.items = append(h.items, x.(*PendingExecution))
+}
+
+func (h *heapItems) Pop() interface{} {
+	last := len(h.items) - 1
+	exec := h.items[last]
+	h.items[last] = nil
+	h.items = h.items[:last]
+	return exec
+}
+
+// SetExecutionQueue replaces the queue QueueExecution feeds. Executions
+// already queued are moved to the new queue. Without one the engine uses
+// NewPriorityQueue(ReducingFirst), which is FIFO apart from running
+// reducing fills first.
+func (e *Engine) SetExecutionQueue(queue PriorityQueue) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.execQueue != nil {
+		for exec := e.execQueue.Pop(); exec != nil; exec = e.execQueue.Pop() {
+			queue.Push(exec)
+		}
+	}
+	e.execQueue = queue
+}
+
+// QueueExecution holds trade until ExecutePending runs it. The order must
+// still be open; the fill itself is checked when it executes.
+func (e *Engine) QueueExecution(trade *Trade) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	order, exists := e.orders[trade.OrderID]
+	if !exists {
+		if _, done := e.terminal[trade.OrderID]; done {
+			return fmt.Errorf("%w: %s", ErrOrderTerminal, trade.OrderID)
+		}
+		return fmt.Errorf("%w: %s", ErrOrderNotFound, trade.OrderID)
+	}
+
+	if e.execQueue == nil {
+		e.execQueue = NewPriorityQueue(ReducingFirst)
+	}
+	e.execSeq++
+	e.execQueue.Push(&PendingExecution{
+		Trade:    trade,
+		Order:    order,
+		Reducing: order.ReduceOnly || reducesPosition(e.positions[order.Symbol], order),
+		QueuedAt: time.Now(),
+		seq:      e.execSeq,
+	})
+	return nil
+}
+
+// PendingExecutions returns the number of fills waiting in the queue
+func (e *Engine) PendingExecutions() int {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.execQueue == nil {
+		return 0
+	}
+	return e.execQueue.Len()
+}
+
+// ExecutePending runs up to max queued fills in priority order, or all of
+// them when max is zero or less; the rest stay queued. Fills that fail
+// are dropped from the queue and their errors joined into the result.
+func (e *Engine) ExecutePending(max int) ([]*Trade, error) {
+	var executed []*Trade
+	var errs []error
+	for max <= 0 || len(executed)+len(errs) < max {
+		e.mu.Lock()
+		var exec *PendingExecution
+		if e.execQueue != nil {
+			exec = e.execQueue.Pop()
+		}
+		e.mu.Unlock()
+		if exec == nil {
+			break
+		}
+
+		if err := e.ExecuteTrade(exec.Trade); err != nil {
+			e.logger.Warn("Queued execution failed",
+				append(orderFields(exec.Order), zap.Error(err))...)
+			errs = append(errs, fmt.Errorf("order %s: %w", exec.Trade.OrderID, err))
+			continue
+		}
+		executed = append(executed, exec.Trade)
+	}
+	return executed, errors.Join(errs...)
+}
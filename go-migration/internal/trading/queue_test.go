@@ -0,0 +1,78 @@
+package trading
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngine_ExecutePending_ReducingFirst(t *testing.T) {
+	engine, _ := newTestEngine(t)
+	placeTestOrder(t, engine, "entry", OrderSideBuy, 10)
+	require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: "entry", Price: 100, Quantity: 10}))
+
+	placeTestOrder(t, engine, "open", OrderSideBuy, 5)
+	reduce := &Order{
+		ID: "reduce", UserID: "user1", Symbol: "TEST/SOL", Side: OrderSideSell,
+		Type: OrderTypeMarket, Quantity: 5, Status: OrderStatusNew, ReduceOnly: true,
+	}
+	require.NoError(t, engine.PlaceOrder(reduce))
+
+	require.NoError(t, engine.QueueExecution(&Trade{OrderID: "open", Price: 100, Quantity: 5}))
+	require.NoError(t, engine.QueueExecution(&Trade{OrderID: "reduce", Price: 100, Quantity: 5}))
+	assert.Equal(t, 2, engine.PendingExecutions())
+
+	// Only one fill fits the budget; the reduce-only one goes first even
+	// though it was queued second
+	executed, err := engine.ExecutePending(1)
+	require.NoError(t, err)
+	require.Len(t, executed, 1)
+	assert.Equal(t, "reduce", executed[0].OrderID)
+	assert.Equal(t, 5.0, engine.GetPosition("TEST/SOL").Quantity)
+	assert.Equal(t, 1, engine.PendingExecutions())
+
+	executed, err = engine.ExecutePending(0)
+	require.NoError(t, err)
+	require.Len(t, executed, 1)
+	assert.Equal(t, "open", executed[0].OrderID)
+	assert.Equal(t, 10.0, engine.GetPosition("TEST/SOL").Quantity)
+}
+
+func TestEngine_ExecutionQueues(t *testing.T) {
+	engine, _ := newTestEngine(t)
+	placeTestOrder(t, engine, "a", OrderSideBuy, 1)
+	placeTestOrder(t, engine, "b", OrderSideBuy, 1)
+	placeTestOrder(t, engine, "c", OrderSideBuy, 1)
+
+	engine.SetExecutionQueue(NewPriorityQueue(LargerFeeFirst))
+	require.NoError(t, engine.QueueExecution(&Trade{OrderID: "a", Price: 10, Quantity: 1, Fee: 0.1}))
+	require.NoError(t, engine.QueueExecution(&Trade{OrderID: "b", Price: 10, Quantity: 1, Fee: 0.3}))
+	require.NoError(t, engine.QueueExecution(&Trade{OrderID: "c", Price: 10, Quantity: 1, Fee: 0.3}))
+
+	// Switching queues keeps what is pending and re-orders it
+	engine.SetExecutionQueue(&FIFOQueue{})
+	engine.SetExecutionQueue(NewPriorityQueue(LargerFeeFirst))
+
+	executed, err := engine.ExecutePending(0)
+	require.NoError(t, err)
+	require.Len(t, executed, 3)
+	assert.Equal(t, "b", executed[0].OrderID)
+	assert.Equal(t, "c", executed[1].OrderID)
+	assert.Equal(t, "a", executed[2].OrderID)
+
+	assert.ErrorIs(t, engine.QueueExecution(&Trade{OrderID: "a", Price: 10, Quantity: 1}), ErrOrderTerminal)
+	assert.ErrorIs(t, engine.QueueExecution(&Trade{OrderID: "missing", Price: 10, Quantity: 1}), ErrOrderNotFound)
+
+	// A fill that fails is dropped and reported without stopping the rest
+	placeTestOrder(t, engine, "d", OrderSideBuy, 1)
+	placeTestOrder(t, engine, "e", OrderSideBuy, 1)
+	engine.SetExecutionQueue(&FIFOQueue{})
+	require.NoError(t, engine.QueueExecution(&Trade{OrderID: "d", Price: 10, Quantity: 5}))
+	require.NoError(t, engine.QueueExecution(&Trade{OrderID: "e", Price: 10, Quantity: 1}))
+	executed, err = engine.ExecutePending(0)
+	assert.ErrorIs(t, err, ErrInvalidFill)
+	require.Len(t, executed, 1)
+	assert.Equal(t, "e", executed[0].OrderID)
+	assert.Equal(t, 0, engine.PendingExecutions())
+}
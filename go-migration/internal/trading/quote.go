@@ -0,0 +1,44 @@
+package trading
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+// resolveQuoteAmount sets the quantity of an order given as a quote
+// amount: QuoteAmount divided by the order's price, or the mark price for
+// orders without one. Orders that already have a quantity are left alone,
+// so it is safe to call more than once.
+func (e *Engine) resolveQuoteAmount(order *Order) error {
+	if order.QuoteAmount == 0 || order.Quantity != 0 {
+		return nil
+	}
+	if !isFinite(order.QuoteAmount) || order.QuoteAmount < 0 {
+		return fmt.Errorf("%w: quote amount %f must be positive", ErrInvalidOrder, order.QuoteAmount)
+	}
+
+	price := order.Price
+	if price <= 0 {
+		e.mu.RLock()
+		pricer := e.markPrices
+		e.mu.RUnlock()
+		if pricer == nil {
+			return fmt.Errorf("%w: no price to convert quote amount for %s", ErrNoPrice, order.Symbol)
+		}
+		mark, err := pricer.MarkPrice(order.Symbol)
+		if err != nil {
+			return fmt.Errorf("%w: %s: %w", ErrNoPrice, order.Symbol, err)
+		}
+		price = mark
+	}
+	if !isFinite(price) || price <= 0 {
+		return fmt.Errorf("%w: %s price %f", ErrNoPrice, order.Symbol, price)
+	}
+
+	order.Quantity = order.QuoteAmount / price
+	e.logLifecycle("Quote amount converted", order,
+		zap.Float64("quote_amount", order.QuoteAmount),
+		zap.Float64("price", price))
+	return nil
+}
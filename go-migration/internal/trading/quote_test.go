@@ -0,0 +1,37 @@
+package trading
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngine_QuoteAmount(t *testing.T) {
+	engine, _ := newTestEngine(t)
+
+	buy := &Order{ID: "quote", UserID: "user1", Symbol: "TEST/SOL", Side: OrderSideBuy,
+		Type: OrderTypeMarket, QuoteAmount: 100, Status: OrderStatusNew}
+	assert.ErrorIs(t, engine.PlaceOrder(buy), ErrNoPrice, "no mark price source")
+	assert.Zero(t, buy.Quantity)
+
+	engine.SetMarkPricer(staticMarks{"TEST/SOL": 0.25})
+	require.NoError(t, engine.PlaceOrder(buy))
+	assert.InDelta(t, 400, buy.Quantity, 1e-9)
+	assert.Equal(t, 100.0, buy.QuoteAmount, "kept for display")
+
+	// Limit orders convert at their own price
+	limit := &Order{ID: "limit", UserID: "user1", Symbol: "TEST/SOL", Side: OrderSideBuy,
+		Type: OrderTypeLimit, Price: 0.5, QuoteAmount: 100, Status: OrderStatusNew}
+	require.NoError(t, engine.PlaceOrder(limit))
+	assert.InDelta(t, 200, limit.Quantity, 1e-9)
+
+	// The converted quantity still goes through the size limits
+	tooSmall := &Order{ID: "small", UserID: "user1", Symbol: "TEST/SOL", Side: OrderSideBuy,
+		Type: OrderTypeLimit, Price: 100, QuoteAmount: 0.5, Status: OrderStatusNew}
+	assert.ErrorIs(t, engine.PlaceOrder(tooSmall), ErrOrderTooSmall)
+
+	unpriced := &Order{ID: "unpriced", UserID: "user1", Symbol: "OTHER/SOL", Side: OrderSideBuy,
+		Type: OrderTypeMarket, QuoteAmount: 100, Status: OrderStatusNew}
+	assert.ErrorIs(t, engine.PlaceOrder(unpriced), ErrNoPrice)
+}
@@ -0,0 +1,196 @@
+package trading
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// defaultRateLimitMaxWait bounds how long a queued order waits when
+// RateLimitMaxWait is unset
+const defaultRateLimitMaxWait = 5 * time.Second
+
+// tokenBucket refills at OrderRateLimit tokens per second up to OrderBurst
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// queuedOrder is a rate-limited order waiting for its user's bucket
+type queuedOrder struct {
+	order    *Order
+	queuedAt time.Time
+}
+
+// QueuedOrders returns the number of rate-limited orders waiting to be
+// resubmitted
+func (e *Engine) QueuedOrders() int {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return len(e.rateQueue)
+}
+
+// takeToken consumes one order token for userID, reporting whether one
+// was available
+func (e *Engine) takeToken(userID string) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.takeTokenLocked(userID, time.Now())
+}
+
+// takeTokens consumes one token per order for its user, either for every
+// order or, returning the first order whose user ran out, for none
+func (e *Engine) takeTokens(orders []*Order) *Order {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := time.Now()
+	need := make(map[string]float64)
+	for _, order := range orders {
+		b := e.refillLocked(order.UserID, now)
+		if b == nil {
+			return nil
+		}
+		need[order.UserID]++
+		if b.tokens < need[order.UserID] {
+			return order
+		}
+	}
+	for userID, n := range need {
+		e.buckets[userID].tokens -= n
+	}
+	return nil
+}
+
+// takeTokenLocked must be called with e.mu held
+func (e *Engine) takeTokenLocked(userID string, now time.Time) bool {
+	b := e.refillLocked(userID, now)
+	if b == nil {
+		return true
+	}
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// refillLocked tops up userID's bucket for the time since it was last
+// used and returns it, or nil when rate limiting is off. Must be called
+// with e.mu held.
+func (e *Engine) refillLocked(userID string, now time.Time) *tokenBucket {
+	rate := e.config.OrderRateLimit
+	if rate <= 0 {
+		return nil
+	}
+	burst := float64(e.config.OrderBurst)
+	if burst < 1 {
+		burst = 1
+	}
+
+	b, exists := e.buckets[userID]
+	if !exists {
+		b = &tokenBucket{tokens: burst, last: now}
+		e.buckets[userID] = b
+	}
+	b.tokens = math.Min(burst, b.tokens+now.Sub(b.last).Seconds()*rate)
+	b.last = now
+	return b
+}
+
+// queueRateLimited holds a rate-limited order for resubmission, or rejects
+// it when queueing is disabled or the queue is full
+func (e *Engine) queueRateLimited(order *Order) error {
+	e.mu.Lock()
+	if len(e.rateQueue) >= e.config.RateLimitQueueDepth {
+		e.mu.Unlock()
+		err := fmt.Errorf("%w: user %s", ErrRateLimited, order.UserID)
+		e.logLifecycle("Order rejected", order, zap.Error(err))
+		return err
+	}
+
+	e.rateQueue = append(e.rateQueue, &queuedOrder{order: order, queuedAt: time.Now()})
+	start := len(e.rateQueue) == 1
+	e.mu.Unlock()
+
+	e.logLifecycle("Order queued by rate limit", order)
+	if start {
+		go e.drainRateQueue()
+	}
+	return nil
+}
+
+// drainRateQueue resubmits queued orders as their users' buckets refill
+// and rejects those that waited longer than RateLimitMaxWait. It exits
+// once the queue is empty.
+func (e *Engine) drainRateQueue() {
+	maxWait := e.config.RateLimitMaxWait
+	if maxWait <= 0 {
+		maxWait = defaultRateLimitMaxWait
+	}
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / e.config.OrderRateLimit))
+	defer ticker.Stop()
+
+	for now := range ticker.C {
+		var ready, expired []*Order
+
+		e.mu.Lock()
+		keep := e.rateQueue[:0]
+		for _, queued := range e.rateQueue {
+			switch {
+			case now.Sub(queued.queuedAt) > maxWait:
+				expired = append(expired, queued.order)
+			case e.takeTokenLocked(queued.order.UserID, now):
+				ready = append(ready, queued.order)
+			default:
+				keep = append(keep, queued)
+			}
+		}
+		e.rateQueue = keep
+		empty := len(keep) == 0
+		e.mu.Unlock()
+
+		for _, order := range expired {
+			e.logger.Warn("Rate-limited order expired in queue",
+				append(orderFields(order), zap.Duration("max_wait", maxWait))...)
+			e.rejectQueued(order, now)
+		}
+		for _, order := range ready {
+			err := e.placeValidated(order)
+			if err == nil {
+				continue
+			}
+			e.logger.Warn("Failed to resubmit rate-limited order",
+				append(orderFields(order), zap.Error(err))...)
+			// The ID belongs to another order, which must not be replaced
+			if !errors.Is(err, ErrDuplicateOrder) {
+				e.rejectQueued(order, now)
+			}
+		}
+
+		if empty {
+			return
+		}
+	}
+}
+
+// rejectQueued records a queued order that expired or failed resubmission
+// as rejected and saves it
+func (e *Engine) rejectQueued(order *Order, now time.Time) {
+	e.mu.Lock()
+	from := order.Status
+	order.Status = OrderStatusRejected
+	order.UpdatedAt = now
+	e.terminal[order.ID] = order
+	e.logTransition(order, from)
+	e.recordOrder(EventOrderRejected, order)
+	e.mu.Unlock()
+
+	if err := e.storage.SaveOrder(order); err != nil {
+		e.logger.Error("Failed to save rejected order",
+			append(orderFields(order), zap.Error(err))...)
+	}
+}
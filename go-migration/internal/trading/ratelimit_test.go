@@ -0,0 +1,139 @@
+package trading
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func rateLimitedEngine(rate float64, burst, depth int, maxWait time.Duration) *Engine {
+	config := testConfig()
+	config.OrderRateLimit = rate
+	config.OrderBurst = burst
+	config.RateLimitQueueDepth = depth
+	config.RateLimitMaxWait = maxWait
+	return NewEngine(config, zap.NewNop(), &memStorage{})
+}
+
+func burstOrder(i int) *Order {
+	return &Order{
+		ID:       fmt.Sprintf("o%d", i),
+		UserID:   "user1",
+		Symbol:   "TEST/SOL",
+		Side:     OrderSideBuy,
+		Type:     OrderTypeMarket,
+		Quantity: 1,
+	}
+}
+
+func TestEngine_RateLimitQueue(t *testing.T) {
+	t.Run("BurstAcceptedOverTime", func(t *testing.T) {
+		engine := rateLimitedEngine(100, 2, 10, time.Second)
+		for i := 0; i < 6; i++ {
+			require.NoError(t, engine.PlaceOrder(burstOrder(i)))
+		}
+
+		// The burst allowance is placed at once, the rest is queued
+		assert.Len(t, engine.QueryOrders(OrderFilter{}), 2)
+		assert.Equal(t, 4, engine.QueuedOrders())
+
+		require.Eventually(t, func() bool {
+			return len(engine.QueryOrders(OrderFilter{})) == 6
+		}, time.Second, 5*time.Millisecond)
+		assert.Zero(t, engine.QueuedOrders())
+	})
+
+	t.Run("RejectedWithoutQueue", func(t *testing.T) {
+		engine := rateLimitedEngine(1, 2, 0, 0)
+		require.NoError(t, engine.PlaceOrder(burstOrder(0)))
+		require.NoError(t, engine.PlaceOrder(burstOrder(1)))
+		assert.ErrorIs(t, engine.PlaceOrder(burstOrder(2)), ErrRateLimited)
+
+		// Other users have their own bucket
+		other := burstOrder(3)
+		other.UserID = "user2"
+		assert.NoError(t, engine.PlaceOrder(other))
+	})
+
+	t.Run("QueueFull", func(t *testing.T) {
+		engine := rateLimitedEngine(1, 1, 1, time.Minute)
+		require.NoError(t, engine.PlaceOrder(burstOrder(0)))
+		require.NoError(t, engine.PlaceOrder(burstOrder(1)))
+		assert.ErrorIs(t, engine.PlaceOrder(burstOrder(2)), ErrRateLimited)
+	})
+
+	t.Run("ExpiresAfterMaxWait", func(t *testing.T) {
+		engine := rateLimitedEngine(20, 1, 5, 10*time.Millisecond)
+		require.NoError(t, engine.PlaceOrder(burstOrder(0)))
+		for i := 1; i < 4; i++ {
+			require.NoError(t, engine.PlaceOrder(burstOrder(i)))
+		}
+
+		require.Eventually(t, func() bool {
+			return engine.QueuedOrders() == 0
+		}, time.Second, 5*time.Millisecond)
+		rejected := engine.QueryOrders(OrderFilter{Status: OrderStatusRejected})
+		assert.NotEmpty(t, rejected)
+
+		// Expired orders are saved like any other rejection
+		storage := engine.storage.(*memStorage)
+		storage.mu.Lock()
+		defer storage.mu.Unlock()
+		assert.Len(t, storage.orders, 4)
+	})
+
+	t.Run("FailedResubmitRejected", func(t *testing.T) {
+		config := testConfig()
+		config.OrderRateLimit = 50
+		config.OrderBurst = 1
+		config.RateLimitQueueDepth = 5
+		config.RateLimitMaxWait = time.Second
+		config.MaxOrdersPerSymbol = 1
+		storage := &memStorage{}
+		engine := NewEngine(config, zap.NewNop(), storage)
+
+		// The queued order no longer fits once its turn comes
+		require.NoError(t, engine.PlaceOrder(burstOrder(0)))
+		require.NoError(t, engine.PlaceOrder(burstOrder(1)))
+		require.Eventually(t, func() bool {
+			order, err := engine.GetOrder("o1")
+			return err == nil && order.Status == OrderStatusRejected
+		}, time.Second, 5*time.Millisecond)
+
+		storage.mu.Lock()
+		defer storage.mu.Unlock()
+		require.Len(t, storage.orders, 2)
+		assert.Equal(t, "o1", storage.orders[1].ID)
+	})
+
+	t.Run("SlicesAndSpreadLegs", func(t *testing.T) {
+		// Children and legs can't be queued, so they are rejected outright
+		engine := rateLimitedEngine(0.001, 1, 10, time.Second)
+		require.NoError(t, engine.PlaceTWAP(parentOrder("twap1", 2), 2, time.Millisecond))
+		children, err := engine.GetChildOrders("twap1")
+		require.NoError(t, err)
+		require.Eventually(t, func() bool {
+			child, err := engine.GetOrder(children[1].ID)
+			return err == nil && child.Status == OrderStatusRejected
+		}, time.Second, time.Millisecond)
+
+		_, err = engine.PlaceSpread([]*Order{burstOrder(0), burstOrder(1)}, []float64{1, 1})
+		assert.ErrorIs(t, err, ErrRateLimited)
+	})
+
+	t.Run("SpreadTakesAllTokensOrNone", func(t *testing.T) {
+		engine := rateLimitedEngine(0.001, 2, 0, time.Second)
+		legs := []*Order{burstOrder(0), burstOrder(1), burstOrder(2)}
+		_, err := engine.PlaceSpread(legs, []float64{1, 1, 1})
+		assert.ErrorIs(t, err, ErrRateLimited)
+
+		// The refused spread left both tokens in the bucket
+		require.NoError(t, engine.PlaceOrder(burstOrder(3)))
+		require.NoError(t, engine.PlaceOrder(burstOrder(4)))
+		assert.ErrorIs(t, engine.PlaceOrder(burstOrder(5)), ErrRateLimited)
+	})
+}
@@ -0,0 +1,57 @@
+package trading
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ReduceOrder lowers the quantity of a resting order by reduceBy without
+// canceling it, so the rest keeps its place in the queue. The quantity is
+// never reduced below what has already filled; a reduction that leaves
+// nothing open cancels the order. Iceberg slices shrink with it. Spread
+// legs, sliced parents and their TWAP/VWAP children can't be reduced
+// since their quantity is tied to other orders.
+func (e *Engine) ReduceOrder(orderID string, reduceBy float64) error {
+	if !(reduceBy > 0) || math.IsInf(reduceBy, 0) {
+		return fmt.Errorf("%w: reduce quantity must be positive, got %f", ErrInvalidOrder, reduceBy)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if _, terminal := e.terminal[orderID]; terminal {
+		return fmt.Errorf("%w: %s", ErrOrderTerminal, orderID)
+	}
+	order, exists := e.orders[orderID]
+	if !exists {
+		return fmt.Errorf("%w: %s", ErrOrderNotFound, orderID)
+	}
+	if _, isParent := e.schedules[orderID]; isParent || order.SpreadID != "" || order.ParentID != "" {
+		return fmt.Errorf("%w: cannot reduce spread leg or sliced order %s", ErrInvalidOrder, orderID)
+	}
+
+	from := order.Quantity
+	order.Quantity = math.Max(order.Quantity-reduceBy, order.FilledQty)
+	order.UpdatedAt = time.Now()
+	if order.Type == OrderTypeIceberg {
+		order.DisplayQty = math.Min(order.DisplayQty, order.Quantity-order.FilledQty)
+	}
+	e.logLifecycle("Order reduced", order,
+		zap.Float64("from", from),
+		zap.Float64("to", order.Quantity))
+
+	if order.Quantity-order.FilledQty <= 0 {
+		status := order.Status
+		order.Status = OrderStatusCanceled
+		e.retireOrder(order)
+		e.logTransition(order, status)
+		e.recordOrder(EventOrderCanceled, order)
+	} else {
+		e.recordOrder(EventOrderUpdated, order)
+	}
+
+	return e.storage.SaveOrder(order)
+}
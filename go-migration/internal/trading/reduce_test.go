@@ -0,0 +1,53 @@
+package trading
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngine_ReduceOrder(t *testing.T) {
+	engine, storage := newTestEngine(t)
+
+	order := placeTestOrder(t, engine, "buy1", OrderSideBuy, 10)
+	require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: "buy1", Price: 100, Quantity: 3}))
+
+	require.NoError(t, engine.ReduceOrder("buy1", 4))
+	assert.Equal(t, 6.0, order.Quantity)
+	assert.Equal(t, 3.0, order.Quantity-order.FilledQty)
+	assert.Equal(t, OrderStatusPartial, order.Status)
+	assert.Same(t, order, storage.orders[len(storage.orders)-1])
+
+	// The remaining quantity can still fill
+	require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: "buy1", Price: 100, Quantity: 2}))
+	assert.Equal(t, OrderStatusPartial, order.Status)
+
+	// Reducing past the filled quantity stops there and cancels the rest
+	require.NoError(t, engine.ReduceOrder("buy1", 100))
+	assert.Equal(t, 5.0, order.Quantity)
+	assert.Equal(t, 5.0, order.FilledQty)
+	assert.Equal(t, OrderStatusCanceled, order.Status)
+	assert.ErrorIs(t, engine.ReduceOrder("buy1", 1), ErrOrderTerminal)
+
+	assert.ErrorIs(t, engine.ReduceOrder("missing", 1), ErrOrderNotFound)
+
+	placeTestOrder(t, engine, "buy2", OrderSideBuy, 10)
+	assert.ErrorIs(t, engine.ReduceOrder("buy2", 0), ErrInvalidOrder)
+	assert.ErrorIs(t, engine.ReduceOrder("buy2", -1), ErrInvalidOrder)
+
+	t.Run("SlicedOrders", func(t *testing.T) {
+		engine, _ := newTestEngine(t)
+		require.NoError(t, engine.PlaceTWAP(parentOrder("twap1", 10), 2, time.Hour))
+		waitReleased(t, engine, 2)
+		children, err := engine.GetChildOrders("twap1")
+		require.NoError(t, err)
+
+		// Shrinking a child would leave the parent expecting more than
+		// its children can fill
+		assert.ErrorIs(t, engine.ReduceOrder("twap1", 1), ErrInvalidOrder)
+		assert.ErrorIs(t, engine.ReduceOrder(children[0].ID, 1), ErrInvalidOrder)
+		assert.Equal(t, 5.0, children[0].Quantity)
+	})
+}
@@ -0,0 +1,167 @@
+package trading
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+// SetRiskChecker sets the checker every placed order runs through,
+// including TWAP/VWAP slices as they are released and spread legs, and
+// that re-validates orders older than Config.MaxOrderAge before they
+// fill. A *risk.Manager can be passed directly. A Runner driving an
+// engine with a checker leaves the checking to the engine.
+func (e *Engine) SetRiskChecker(checker OrderRiskChecker) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.risk = checker
+}
+
+// hasRiskChecker reports whether a risk checker is set
+func (e *Engine) hasRiskChecker() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.risk != nil
+}
+
+// OrderRecorder is implemented by risk checkers that track accepted
+// orders, such as the risk manager's order cooldown
+type OrderRecorder interface {
+	RecordOrder(userID, symbol string)
+}
+
+// checkRisk runs order through the risk checker, if one is set
+func (e *Engine) checkRisk(ctx context.Context, order *Order) error {
+	e.mu.RLock()
+	checker := e.risk
+	e.mu.RUnlock()
+	if checker == nil {
+		return nil
+	}
+
+	riskOrder := toRiskOrder(order)
+	riskOrder.CorrelationID = order.CorrelationID
+	if err := checker.CheckOrderRisk(ctx, riskOrder); err != nil {
+		return fmt.Errorf("%w: %w", ErrRiskRejected, err)
+	}
+	return nil
+}
+
+// OrderRevalidator is implemented by risk checkers that can re-check an
+// already accepted order without the checks, such as an order cooldown,
+// that placing it has already tripped
+type OrderRevalidator interface {
+	RevalidateOrderRisk(ctx context.Context, order *types.Order) error
+}
+
+// recordAccepted tells the risk checker, if it tracks accepted orders,
+// that order has been placed
+func (e *Engine) recordAccepted(order *Order) {
+	e.mu.RLock()
+	checker := e.risk
+	e.mu.RUnlock()
+	if recorder, ok := checker.(OrderRecorder); ok {
+		recorder.RecordOrder(order.UserID, order.Symbol)
+	}
+}
+
+// revalidateAged re-runs the risk check on the order trade fills when the
+// order is older than MaxOrderAge, with its price-dependent fields
+// refreshed from the current book. An order that no longer passes is
+// rejected. Checkers implementing OrderRevalidator re-check through it,
+// so the risk manager's order cooldown doesn't reject the order that
+// started it.
+func (e *Engine) revalidateAged(trade *Trade) error {
+	e.mu.RLock()
+	order, exists := e.lookupOrder(trade.OrderID)
+	e.mu.RUnlock()
+	if !exists {
+		return nil
+	}
+
+	age, err := e.checkAged(order)
+	if err == nil {
+		return nil
+	}
+
+	e.mu.Lock()
+	from := order.Status
+	rejected := !order.Status.IsTerminal()
+	if rejected {
+		order.Status = OrderStatusRejected
+		order.UpdatedAt = time.Now()
+		e.retireOrder(order)
+		e.logTransition(order, from)
+		e.recordOrder(EventOrderRejected, order)
+		e.finishParent(order)
+	}
+	e.mu.Unlock()
+
+	e.logger.Warn("Aged order failed re-validation",
+		append(orderFields(order),
+			zap.Duration("age", age),
+			zap.Error(err))...)
+
+	if rejected {
+		if saveErr := e.storage.SaveOrder(order); saveErr != nil {
+			return saveErr
+		}
+	}
+	return fmt.Errorf("%w: %s aged %s: %w", ErrOrderStale, order.ID, age.Round(time.Second), err)
+}
+
+// checkAged re-runs the risk check on order if it is open and older than
+// MaxOrderAge, returning its age and the check's result without acting on
+// it
+func (e *Engine) checkAged(order *Order) (time.Duration, error) {
+	maxAge := e.config.MaxOrderAge
+	if maxAge <= 0 {
+		return 0, nil
+	}
+
+	e.mu.RLock()
+	checker := e.risk
+	if checker == nil || order.CreatedAt.IsZero() || order.Status.IsTerminal() {
+		e.mu.RUnlock()
+		return 0, nil
+	}
+	age := time.Since(order.CreatedAt)
+	if age <= maxAge {
+		e.mu.RUnlock()
+		return age, nil
+	}
+	riskOrder := toRiskOrder(order)
+	riskOrder.CorrelationID = order.CorrelationID
+	refreshPriceFields(riskOrder, e.books[order.Symbol])
+	e.mu.RUnlock()
+
+	if revalidator, ok := checker.(OrderRevalidator); ok {
+		return age, revalidator.RevalidateOrderRisk(context.Background(), riskOrder)
+	}
+	return age, checker.CheckOrderRisk(context.Background(), riskOrder)
+}
+
+// refreshPriceFields updates the risk order's price-dependent fields from
+// book: market orders are priced at the side they would take and expected
+// slippage is the half-spread relative to the mid price
+func refreshPriceFields(order *types.Order, book *OrderBook) {
+	if book == nil {
+		return
+	}
+	bid, ask := book.BestBidAsk()
+	if bid <= 0 || ask <= 0 {
+		return
+	}
+
+	if order.Type == types.OrderTypeMarket {
+		order.Price = ask
+		if order.Side == types.OrderSideSell {
+			order.Price = bid
+		}
+	}
+	order.Slippage = (ask - bid) / (ask + bid)
+}
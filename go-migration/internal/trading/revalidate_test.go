@@ -0,0 +1,189 @@
+package trading
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/kwanRoshi/B/go-migration/internal/risk"
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+func TestEngine_RevalidateAgedOrders(t *testing.T) {
+	config := testConfig()
+	config.MaxOrderAge = time.Minute
+	storage := &memStorage{}
+	engine := NewEngine(config, zap.NewNop(), storage)
+	engine.SetRiskChecker(risk.NewManager(risk.Limits{MaxPositionSize: 100, MaxSlippage: 0.01}, zap.NewNop()))
+
+	newOrder := func(id string, createdAt time.Time) *Order {
+		return &Order{
+			ID: id, UserID: "user1", Symbol: "TEST/SOL", Side: OrderSideBuy,
+			Type: OrderTypeMarket, Quantity: 1, Status: OrderStatusNew, CreatedAt: createdAt,
+		}
+	}
+
+	// The spread was tight when the orders were placed and has since widened
+	engine.UpdateOrderBook(&OrderBook{
+		Symbol: "TEST/SOL",
+		Bids:   []OrderBookLevel{{Price: 90, Quantity: 10}},
+		Asks:   []OrderBookLevel{{Price: 110, Quantity: 10}},
+	})
+
+	aged := newOrder("aged", time.Now().Add(-time.Hour))
+	require.NoError(t, engine.PlaceOrder(aged))
+	err := engine.ExecuteTrade(&Trade{OrderID: "aged", Price: 110, Quantity: 1})
+	assert.ErrorIs(t, err, ErrOrderStale)
+	assert.ErrorIs(t, err, risk.ErrSlippageExceeded)
+	assert.Equal(t, OrderStatusRejected, aged.Status)
+	assert.Zero(t, aged.FilledQty)
+	assert.Nil(t, engine.GetPosition("TEST/SOL"))
+
+	// Fresh orders fill without re-validation
+	fresh := newOrder("fresh", time.Now())
+	require.NoError(t, engine.PlaceOrder(fresh))
+	require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: "fresh", Price: 110, Quantity: 1}))
+	assert.Equal(t, OrderStatusFilled, fresh.Status)
+
+	t.Run("PassesWhenConditionsHold", func(t *testing.T) {
+		engine.UpdateOrderBook(&OrderBook{
+			Symbol: "TEST/SOL",
+			Bids:   []OrderBookLevel{{Price: 99.9, Quantity: 10}},
+			Asks:   []OrderBookLevel{{Price: 100.1, Quantity: 10}},
+		})
+		order := newOrder("aged2", time.Now().Add(-time.Hour))
+		require.NoError(t, engine.PlaceOrder(order))
+		require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: "aged2", Price: 100.1, Quantity: 1}))
+	})
+}
+
+func TestEngine_RevalidateAgedSpread(t *testing.T) {
+	config := testConfig()
+	config.MaxOrderAge = time.Minute
+	engine := NewEngine(config, zap.NewNop(), &memStorage{})
+	engine.SetRiskChecker(risk.NewManager(risk.Limits{MaxPositionSize: 100, MaxSlippage: 0.01}, zap.NewNop()))
+
+	aged := time.Now().Add(-time.Hour)
+	long := spreadLeg("long", "AAA/SOL", OrderSideBuy, 1)
+	short := spreadLeg("short", "BBB/SOL", OrderSideSell, 1)
+	long.CreatedAt, short.CreatedAt = aged, aged
+	spread, err := engine.PlaceSpread([]*Order{long, short}, []float64{1, 1})
+	require.NoError(t, err)
+
+	// Only the second leg's book has widened, but neither leg may fill
+	engine.UpdateOrderBook(&OrderBook{
+		Symbol: "BBB/SOL",
+		Bids:   []OrderBookLevel{{Price: 90, Quantity: 10}},
+		Asks:   []OrderBookLevel{{Price: 110, Quantity: 10}},
+	})
+	err = engine.ExecuteSpread(spread.ID, []*Trade{
+		{OrderID: "long", Price: 100, Quantity: 1},
+		{OrderID: "short", Price: 90, Quantity: 1},
+	})
+	assert.ErrorIs(t, err, ErrOrderStale)
+	assert.ErrorIs(t, err, risk.ErrSlippageExceeded)
+	assert.Equal(t, OrderStatusRejected, spread.Status)
+	for _, leg := range spread.Legs {
+		assert.Equal(t, OrderStatusRejected, leg.Status)
+		assert.Zero(t, leg.FilledQty)
+	}
+	assert.Nil(t, engine.GetPosition("AAA/SOL"))
+}
+
+func TestEngine_RevalidateSkipsCooldown(t *testing.T) {
+	config := testConfig()
+	config.MaxOrderAge = time.Minute
+	engine := NewEngine(config, zap.NewNop(), &memStorage{})
+	engine.SetRiskChecker(risk.NewManager(risk.Limits{MaxPositionSize: 100, MinOrderInterval: time.Hour}, zap.NewNop()))
+
+	// The order's own placement starts a cooldown that outlasts MaxOrderAge
+	aged := &Order{ID: "aged", UserID: "user1", Symbol: "TEST/SOL", Side: OrderSideBuy,
+		Type: OrderTypeMarket, Quantity: 1, CreatedAt: time.Now().Add(-time.Hour)}
+	require.NoError(t, engine.PlaceOrder(aged))
+	require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: "aged", Price: 100, Quantity: 1}))
+	assert.Equal(t, OrderStatusFilled, aged.Status)
+}
+
+func TestEngine_PlaceOrder_RiskChecker(t *testing.T) {
+	engine, storage := newTestEngine(t)
+
+	var checked []string
+	engine.SetRiskChecker(riskCheckerFunc(func(ctx context.Context, order *types.Order) error {
+		checked = append(checked, order.ID)
+		if order.Quantity > 10 {
+			return errors.New("too large")
+		}
+		return nil
+	}))
+
+	placeTestOrder(t, engine, "small", OrderSideBuy, 5)
+
+	big := &Order{ID: "big", UserID: "user1", Symbol: "TEST/SOL", Side: OrderSideBuy,
+		Type: OrderTypeMarket, Quantity: 50, Status: OrderStatusNew}
+	err := engine.PlaceOrder(big)
+	assert.ErrorIs(t, err, ErrRiskRejected)
+	assert.ErrorContains(t, err, "too large")
+	assert.Equal(t, []string{"small", "big"}, checked)
+
+	_, err = engine.GetOrder("big")
+	assert.ErrorIs(t, err, ErrOrderNotFound, "rejected orders are not stored")
+	assert.Len(t, storage.orders, 1)
+
+	rejectLarge := riskCheckerFunc(func(ctx context.Context, order *types.Order) error {
+		if order.Quantity > 10 {
+			return errors.New("too large")
+		}
+		return nil
+	})
+
+	t.Run("Slices", func(t *testing.T) {
+		engine, _ := newTestEngine(t)
+		engine.SetRiskChecker(rejectLarge)
+
+		require.NoError(t, engine.PlaceTWAP(parentOrder("twap1", 40), 2, time.Hour))
+		children, err := engine.GetChildOrders("twap1")
+		require.NoError(t, err)
+		require.Eventually(t, func() bool {
+			child, err := engine.GetOrder(children[0].ID)
+			return err == nil && child.Status == OrderStatusRejected
+		}, time.Second, time.Millisecond)
+	})
+
+	t.Run("SpreadLegs", func(t *testing.T) {
+		engine, _ := newTestEngine(t)
+		engine.SetRiskChecker(rejectLarge)
+
+		_, err := engine.PlaceSpread([]*Order{
+			{ID: "leg1", UserID: "user1", Symbol: "AAA/SOL", Side: OrderSideBuy, Type: OrderTypeMarket, Quantity: 5},
+			{ID: "leg2", UserID: "user1", Symbol: "BBB/SOL", Side: OrderSideSell, Type: OrderTypeMarket, Quantity: 20},
+		}, []float64{1, 4})
+		assert.ErrorIs(t, err, ErrRiskRejected)
+		assert.Empty(t, engine.QueryOrders(OrderFilter{}))
+	})
+}
+
+func TestEngine_RiskCooldownStartsOnAccept(t *testing.T) {
+	config := testConfig()
+	config.MaxOrdersPerSymbol = 1
+	engine := NewEngine(config, zap.NewNop(), &memStorage{})
+	engine.SetRiskChecker(risk.NewManager(risk.Limits{MaxPositionSize: 100, MinOrderInterval: time.Hour}, zap.NewNop()))
+
+	newOrder := func(id, userID string) *Order {
+		return &Order{ID: id, UserID: userID, Symbol: "TEST/SOL", Side: OrderSideBuy,
+			Type: OrderTypeLimit, Price: 1, Quantity: 1}
+	}
+
+	require.NoError(t, engine.PlaceOrder(newOrder("a1", "user1")))
+	assert.ErrorIs(t, engine.PlaceOrder(newOrder("a2", "user1")), risk.ErrOrderCooldown)
+
+	// Rejected by the engine after passing risk: no cooldown for user2
+	assert.ErrorIs(t, engine.PlaceOrder(newOrder("b1", "user2")), ErrMaxSymbolOrders)
+	require.NoError(t, engine.CancelOrder("a1"))
+	require.NoError(t, engine.PlaceOrder(newOrder("b1", "user2")))
+	assert.ErrorIs(t, engine.PlaceOrder(newOrder("b2", "user2")), risk.ErrOrderCooldown)
+}
@@ -0,0 +1,138 @@
+package trading
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+// Strategy turns market data into orders and reacts to fills
+type Strategy interface {
+	// OnPrice is called for every price update and returns orders to submit
+	OnPrice(update *types.PriceUpdate) []*Order
+
+	// OnFill is called for every trade executed by the engine
+	OnFill(trade *Trade)
+}
+
+// PriceSubscriber provides a live price stream
+type PriceSubscriber interface {
+	SubscribePrices(ctx context.Context, symbols []string) (<-chan *types.PriceUpdate, error)
+}
+
+// OrderRiskChecker validates orders against risk limits
+type OrderRiskChecker interface {
+	CheckOrderRisk(ctx context.Context, order *types.Order) error
+}
+
+// OrderExecutor accepts orders and reports their fills
+type OrderExecutor interface {
+	PlaceOrder(order *Order) error
+	SubscribeFills(ctx context.Context) <-chan *Trade
+}
+
+// Runner wires a price stream through a strategy and risk checks into the engine
+type Runner struct {
+	logger   *zap.Logger
+	strategy Strategy
+	prices   PriceSubscriber
+	risk     OrderRiskChecker
+	engine   OrderExecutor
+}
+
+// NewRunner creates a new strategy runner
+func NewRunner(strategy Strategy, prices PriceSubscriber, risk OrderRiskChecker, engine OrderExecutor, logger *zap.Logger) *Runner {
+	return &Runner{
+		logger:   logger,
+		strategy: strategy,
+		prices:   prices,
+		risk:     risk,
+		engine:   engine,
+	}
+}
+
+// Run processes price updates and fills until ctx is canceled or the
+// price stream closes
+func (r *Runner) Run(ctx context.Context, symbols []string) error {
+	updates, err := r.prices.SubscribePrices(ctx, symbols)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to prices: %w", err)
+	}
+
+	fills := r.engine.SubscribeFills(ctx)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case update, ok := <-updates:
+			if !ok {
+				return nil
+			}
+			r.handlePrice(ctx, update)
+		case trade, ok := <-fills:
+			if !ok {
+				fills = nil
+				continue
+			}
+			r.strategy.OnFill(trade)
+		}
+	}
+}
+
+func (r *Runner) handlePrice(ctx context.Context, update *types.PriceUpdate) {
+	// An engine with its own risk checker checks every order it places, so
+	// checking here too would run the checks twice
+	preCheck := r.risk != nil
+	if engine, ok := r.engine.(*Engine); ok && engine.hasRiskChecker() {
+		preCheck = false
+	}
+
+	for _, order := range r.strategy.OnPrice(update) {
+		if preCheck {
+			if err := r.risk.CheckOrderRisk(ctx, toRiskOrder(order)); err != nil {
+				r.logger.Warn("Order rejected by risk manager",
+					zap.String("order_id", order.ID),
+					zap.String("symbol", order.Symbol),
+					zap.Error(err))
+				order.Status = OrderStatusRejected
+				continue
+			}
+		}
+
+		if err := r.engine.PlaceOrder(order); err != nil {
+			r.logger.Error("Failed to place order",
+				zap.String("order_id", order.ID),
+				zap.String("symbol", order.Symbol),
+				zap.Error(err))
+			continue
+		}
+		if recorder, ok := r.risk.(OrderRecorder); ok && preCheck {
+			recorder.RecordOrder(order.UserID, order.Symbol)
+		}
+	}
+}
+
+// toRiskOrder converts an engine order into the shared order type used by
+// the risk manager
+func toRiskOrder(order *Order) *types.Order {
+	return &types.Order{
+		ID:         order.ID,
+		UserID:     order.UserID,
+		Symbol:     order.Symbol,
+		Side:       types.OrderSide(order.Side),
+		Type:       types.OrderType(order.Type),
+		Price:      order.Price,
+		Quantity:   order.Quantity,
+		FilledQty:  order.FilledQty,
+		Status:     types.OrderStatus(order.Status),
+		CreatedAt:  order.CreatedAt,
+		UpdatedAt:  order.UpdatedAt,
+		ReduceOnly: order.ReduceOnly,
+		StrategyID: order.StrategyID,
+		Tags:       order.Tags,
+	}
+}
@@ -0,0 +1,183 @@
+package trading
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+type MockPriceSubscriber struct {
+	mock.Mock
+}
+
+func (m *MockPriceSubscriber) SubscribePrices(ctx context.Context, symbols []string) (<-chan *types.PriceUpdate, error) {
+	args := m.Called(ctx, symbols)
+	return args.Get(0).(<-chan *types.PriceUpdate), args.Error(1)
+}
+
+type MockRiskChecker struct {
+	mock.Mock
+}
+
+func (m *MockRiskChecker) CheckOrderRisk(ctx context.Context, order *types.Order) error {
+	args := m.Called(ctx, order)
+	return args.Error(0)
+}
+
+type MockExecutor struct {
+	mock.Mock
+}
+
+func (m *MockExecutor) PlaceOrder(order *Order) error {
+	args := m.Called(order)
+	return args.Error(0)
+}
+
+func (m *MockExecutor) SubscribeFills(ctx context.Context) <-chan *Trade {
+	args := m.Called(ctx)
+	return args.Get(0).(<-chan *Trade)
+}
+
+// buyOnceStrategy buys a fixed quantity on every price update above a threshold
+type buyOnceStrategy struct {
+	mu        sync.Mutex
+	threshold float64
+	fills     []*Trade
+}
+
+func (s *buyOnceStrategy) OnPrice(update *types.PriceUpdate) []*Order {
+	if update.Price < s.threshold {
+		return nil
+	}
+	return []*Order{{
+		ID:       "order-" + update.Symbol,
+		UserID:   "user1",
+		Symbol:   update.Symbol,
+		Side:     OrderSideBuy,
+		Type:     OrderTypeMarket,
+		Quantity: 5,
+	}}
+}
+
+func (s *buyOnceStrategy) OnFill(trade *Trade) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fills = append(s.fills, trade)
+}
+
+func (s *buyOnceStrategy) fillCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.fills)
+}
+
+func TestRunner_OrderFlowsThrough(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	prices := make(chan *types.PriceUpdate, 2)
+	fills := make(chan *Trade, 1)
+
+	provider := new(MockPriceSubscriber)
+	provider.On("SubscribePrices", mock.Anything, []string{"TEST/SOL", "BAD/SOL"}).
+		Return((<-chan *types.PriceUpdate)(prices), nil)
+
+	riskMgr := new(MockRiskChecker)
+	riskMgr.On("CheckOrderRisk", mock.Anything, mock.MatchedBy(func(o *types.Order) bool {
+		return o.Symbol == "TEST/SOL"
+	})).Return(nil)
+	riskMgr.On("CheckOrderRisk", mock.Anything, mock.MatchedBy(func(o *types.Order) bool {
+		return o.Symbol == "BAD/SOL"
+	})).Return(errors.New("order size exceeds limit"))
+
+	placed := make(chan *Order, 2)
+	engine := new(MockExecutor)
+	engine.On("SubscribeFills", mock.Anything).Return((<-chan *Trade)(fills))
+	engine.On("PlaceOrder", mock.Anything).Run(func(args mock.Arguments) {
+		placed <- args.Get(0).(*Order)
+	}).Return(nil)
+
+	strategy := &buyOnceStrategy{threshold: 1}
+	runner := NewRunner(strategy, provider, riskMgr, engine, zap.NewNop())
+
+	done := make(chan error, 1)
+	go func() {
+		done <- runner.Run(ctx, []string{"TEST/SOL", "BAD/SOL"})
+	}()
+
+	prices <- &types.PriceUpdate{Symbol: "BAD/SOL", Price: 2}
+	prices <- &types.PriceUpdate{Symbol: "TEST/SOL", Price: 2}
+
+	select {
+	case order := <-placed:
+		assert.Equal(t, "TEST/SOL", order.Symbol)
+		assert.Equal(t, 5.0, order.Quantity)
+	case <-time.After(time.Second):
+		t.Fatal("order was not placed")
+	}
+
+	fills <- &Trade{OrderID: "order-TEST/SOL", Symbol: "TEST/SOL", Quantity: 5, Price: 2}
+	assert.Eventually(t, func() bool { return strategy.fillCount() == 1 },
+		time.Second, 10*time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+
+	engine.AssertNumberOfCalls(t, "PlaceOrder", 1)
+	riskMgr.AssertNumberOfCalls(t, "CheckOrderRisk", 2)
+}
+
+func TestRunner_SubscribeError(t *testing.T) {
+	provider := new(MockPriceSubscriber)
+	provider.On("SubscribePrices", mock.Anything, mock.Anything).
+		Return((<-chan *types.PriceUpdate)(nil), errors.New("connection refused"))
+
+	runner := NewRunner(&buyOnceStrategy{}, provider, new(MockRiskChecker), new(MockExecutor), zap.NewNop())
+	err := runner.Run(context.Background(), []string{"TEST/SOL"})
+	assert.Error(t, err)
+}
+
+func TestRunner_EngineRiskChecker(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	prices := make(chan *types.PriceUpdate, 1)
+	provider := new(MockPriceSubscriber)
+	provider.On("SubscribePrices", mock.Anything, []string{"TEST/SOL"}).
+		Return((<-chan *types.PriceUpdate)(prices), nil)
+
+	var mu sync.Mutex
+	checks := 0
+	engine, _ := newTestEngine(t)
+	engine.SetRiskChecker(riskCheckerFunc(func(ctx context.Context, order *types.Order) error {
+		mu.Lock()
+		defer mu.Unlock()
+		checks++
+		return nil
+	}))
+
+	// The runner's own checker is skipped when the engine checks risk
+	runnerRisk := new(MockRiskChecker)
+	runner := NewRunner(&buyOnceStrategy{threshold: 1}, provider, runnerRisk, engine, zap.NewNop())
+	go runner.Run(ctx, []string{"TEST/SOL"})
+
+	prices <- &types.PriceUpdate{Symbol: "TEST/SOL", Price: 2}
+	require.Eventually(t, func() bool {
+		_, err := engine.GetOrder("order-TEST/SOL")
+		return err == nil
+	}, time.Second, time.Millisecond)
+
+	mu.Lock()
+	assert.Equal(t, 1, checks)
+	mu.Unlock()
+	runnerRisk.AssertNotCalled(t, "CheckOrderRisk", mock.Anything, mock.Anything)
+}
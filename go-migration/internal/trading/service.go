@@ -42,12 +42,12 @@ func (s *Service) GetOrders(ctx context.Context, userID string) ([]*Order, error
 
 // ExecuteTrade implements TradingEngine interface
 func (s *Service) ExecuteTrade(ctx context.Context, trade *Trade) error {
-	return nil // TODO: Implement trade execution
+	return s.engine.ExecuteTrade(trade)
 }
 
 // GetTrades implements TradingEngine interface
 func (s *Service) GetTrades(ctx context.Context, userID string) ([]*Trade, error) {
-	return nil, nil // TODO: Implement get trades
+	return s.engine.GetTrades(userID)
 }
 
 // GetPosition implements TradingEngine interface
@@ -66,7 +66,7 @@ func (s *Service) GetPositions(ctx context.Context, userID string) ([]*Position,
 
 // GetOrderBook implements TradingEngine interface
 func (s *Service) GetOrderBook(ctx context.Context, symbol string) (*OrderBook, error) {
-	return nil, nil // TODO: Implement get order book
+	return s.engine.GetOrderBook(symbol)
 }
 
 // SubscribeOrderBook implements TradingEngine interface
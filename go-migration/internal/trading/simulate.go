@@ -0,0 +1,84 @@
+package trading
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+// SimulateOrder returns the position and account metrics that would result
+// from order's remaining quantity filling at fillPrice. The fill goes
+// through the same position math as ExecuteTrade, with the fee from the
+// engine's fee model, but no engine state is changed.
+//
+// Metrics start from the balance provider's current figures when one is
+// set and are adjusted by the change in required margin and realized PnL.
+func (e *Engine) SimulateOrder(order *Order, fillPrice float64) (*Position, *types.RiskMetrics, error) {
+	if fillPrice <= 0 {
+		return nil, nil, fmt.Errorf("invalid fill price %f", fillPrice)
+	}
+	qty := order.Quantity - order.FilledQty
+	if qty <= 0 {
+		return nil, nil, fmt.Errorf("%w: order %s has nothing left to fill", ErrInvalidFill, order.ID)
+	}
+
+	e.mu.RLock()
+	before := Position{UserID: order.UserID, Symbol: order.Symbol}
+	if pos, exists := e.positions[order.Symbol]; exists {
+		before = *pos
+		before.Lots = append([]Lot(nil), pos.Lots...)
+	}
+	fees := e.fees
+	balances := e.balances
+	e.mu.RUnlock()
+
+	if fees == nil {
+		fees = CommissionFee{Rate: e.config.Commission}
+	}
+
+	now := time.Now()
+	after := before
+	after.Lots = append([]Lot(nil), before.Lots...)
+	e.applyToPosition(&after, &Trade{
+		OrderID:   order.ID,
+		UserID:    order.UserID,
+		Symbol:    order.Symbol,
+		Side:      order.Side,
+		Price:     fillPrice,
+		Quantity:  qty,
+		Fee:       fees.Fee(order.Side, qty, fillPrice),
+		Timestamp: now,
+	})
+
+	metrics := &types.RiskMetrics{UserID: order.UserID}
+	if balances != nil {
+		account, err := balances.GetRiskMetrics(order.UserID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get account balance: %w", err)
+		}
+		*metrics = *account
+	}
+
+	metrics.UsedMargin += e.positionMargin(&after) - e.positionMargin(&before)
+	metrics.TotalEquity += after.RealizedPnL - before.RealizedPnL
+	metrics.DailyPnL += after.RealizedPnL - before.RealizedPnL
+	metrics.AvailableMargin = metrics.TotalEquity - metrics.UsedMargin
+	metrics.MarginLevel = 0
+	if metrics.UsedMargin > 0 {
+		metrics.MarginLevel = metrics.TotalEquity / metrics.UsedMargin * 100
+	}
+	metrics.UpdateTime = now
+
+	return &after, metrics, nil
+}
+
+// positionMargin is the margin pos requires at its entry price
+func (e *Engine) positionMargin(pos *Position) float64 {
+	notional := math.Abs(pos.Quantity) * pos.AvgPrice
+	if e.config.MarginRate > 0 {
+		return notional * e.config.MarginRate
+	}
+	return notional
+}
@@ -0,0 +1,62 @@
+package trading
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+type fixedMetrics types.RiskMetrics
+
+func (m fixedMetrics) GetRiskMetrics(userID string) (*types.RiskMetrics, error) {
+	metrics := types.RiskMetrics(m)
+	return &metrics, nil
+}
+
+func TestEngine_SimulateOrder(t *testing.T) {
+	config := testConfig()
+	config.Commission = 0.001
+	config.MarginRate = 0.5
+	config.LotMethod = LotMethodFIFO
+	engine := NewEngine(config, zap.NewNop(), &memStorage{})
+
+	placeTestOrder(t, engine, "buy1", OrderSideBuy, 10)
+	require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: "buy1", Price: 100, Quantity: 10}))
+	engine.SetBalanceProvider(fixedMetrics{TotalEquity: 10000, UsedMargin: 500, AvailableMargin: 9500})
+
+	sell := placeTestOrder(t, engine, "sell1", OrderSideSell, 4)
+	simulated, metrics, err := engine.SimulateOrder(sell, 110)
+	require.NoError(t, err)
+
+	// Simulating leaves the engine untouched
+	current := engine.GetPosition("TEST/SOL")
+	assert.Equal(t, 10.0, current.Quantity)
+	assert.Zero(t, current.RealizedPnL)
+	assert.Len(t, current.Lots, 1)
+	assert.Zero(t, sell.FilledQty)
+
+	fee := config.Commission * 4 * 110
+	assert.InDelta(t, 300.0, metrics.UsedMargin, 1e-9)
+	assert.InDelta(t, 10000+40-fee, metrics.TotalEquity, 1e-9)
+	assert.InDelta(t, metrics.TotalEquity-300, metrics.AvailableMargin, 1e-9)
+	assert.InDelta(t, metrics.TotalEquity/300*100, metrics.MarginLevel, 1e-9)
+
+	require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: "sell1", Price: 110, Quantity: 4, Fee: fee}))
+	actual := engine.GetPosition("TEST/SOL")
+	assert.Equal(t, actual.Quantity, simulated.Quantity)
+	assert.InDelta(t, actual.AvgPrice, simulated.AvgPrice, 1e-9)
+	assert.InDelta(t, actual.RealizedPnL, simulated.RealizedPnL, 1e-9)
+	assert.Len(t, simulated.Lots, len(actual.Lots))
+
+	t.Run("Invalid", func(t *testing.T) {
+		_, _, err := engine.SimulateOrder(sell, 110)
+		assert.ErrorIs(t, err, ErrInvalidFill, "filled orders have nothing left")
+
+		_, _, err = engine.SimulateOrder(&Order{Symbol: "TEST/SOL", Quantity: 1}, 0)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,348 @@
+package trading
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// sliceSchedule tracks the child orders of a TWAP or VWAP parent
+type sliceSchedule struct {
+	parent   *Order
+	children []*Order
+	interval time.Duration
+	released int
+	stop     chan struct{}
+	stopped  bool
+}
+
+// PlaceTWAP splits order into slices equal child orders, releasing the first
+// immediately and one more every interval. The parent is tracked like any
+// other order but cannot be filled directly; fills of its children
+// accumulate into its FilledQty. If children are rejected or canceled, the
+// parent is canceled with its partial fill once the rest are done.
+func (e *Engine) PlaceTWAP(order *Order, slices int, interval time.Duration) error {
+	if slices <= 0 {
+		return fmt.Errorf("invalid slice count: %d", slices)
+	}
+
+	weights := make([]float64, slices)
+	for i := range weights {
+		weights[i] = 1
+	}
+	return e.placeSliced(order, weights, interval)
+}
+
+// PlaceVWAP splits order into one child per bucket of profile, sized in
+// proportion to the bucket's historical volume, and releases them every
+// interval like PlaceTWAP
+func (e *Engine) PlaceVWAP(order *Order, profile []float64, interval time.Duration) error {
+	if len(profile) == 0 {
+		return fmt.Errorf("empty volume profile")
+	}
+	for i, volume := range profile {
+		if volume <= 0 {
+			return fmt.Errorf("invalid volume %f in profile bucket %d", volume, i)
+		}
+	}
+	return e.placeSliced(order, profile, interval)
+}
+
+// GetChildOrders returns the child slices of a TWAP or VWAP parent in
+// release order, including those not yet released
+func (e *Engine) GetChildOrders(parentID string) ([]*Order, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	schedule, exists := e.schedules[parentID]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", ErrOrderNotFound, parentID)
+	}
+
+	children := make([]*Order, len(schedule.children))
+	copy(children, schedule.children)
+	return children, nil
+}
+
+func (e *Engine) placeSliced(order *Order, weights []float64, interval time.Duration) error {
+	if order.Quantity <= 0 {
+		return fmt.Errorf("%w: %f", ErrOrderTooSmall, order.Quantity)
+	}
+
+	if order.CorrelationID == "" {
+		order.CorrelationID = newCorrelationID()
+	}
+
+	now := time.Now()
+	quantities := sliceQuantities(order.Quantity, weights)
+	children := make([]*Order, len(quantities))
+	for i, qty := range quantities {
+		child := &Order{
+			ID:            fmt.Sprintf("%s-%d", order.ID, i+1),
+			UserID:        order.UserID,
+			Symbol:        order.Symbol,
+			Side:          order.Side,
+			Type:          order.Type,
+			Price:         order.Price,
+			Quantity:      qty,
+			Status:        OrderStatusNew,
+			CreatedAt:     now,
+			UpdatedAt:     now,
+			ReduceOnly:    order.ReduceOnly,
+			ParentID:      order.ID,
+			CorrelationID: order.CorrelationID,
+			StrategyID:    order.StrategyID,
+			Tags:          order.Tags,
+		}
+		if err := e.validateOrder(child); err != nil {
+			return fmt.Errorf("slice %d of %s: %w", i+1, order.ID, err)
+		}
+		children[i] = child
+	}
+
+	schedule := &sliceSchedule{
+		parent:   order,
+		children: children,
+		interval: interval,
+		stop:     make(chan struct{}),
+	}
+
+	order.Status = OrderStatusNew
+	if order.CreatedAt.IsZero() {
+		order.CreatedAt = now
+	}
+	order.UpdatedAt = now
+
+	e.mu.Lock()
+	if err := e.admitOrder(order); err != nil {
+		e.mu.Unlock()
+		return err
+	}
+	e.orders[order.ID] = order
+	e.schedules[order.ID] = schedule
+	e.recordOrder(EventOrderPlaced, order)
+	e.mu.Unlock()
+
+	if err := e.storage.SaveOrder(order); err != nil {
+		if err := e.handleSaveFailure(order, err); err != nil {
+			e.mu.Lock()
+			delete(e.schedules, order.ID)
+			e.mu.Unlock()
+			return err
+		}
+	}
+
+	e.logger.Info("Placed sliced order",
+		zap.String("order_id", order.ID),
+		zap.Int("slices", len(children)),
+		zap.Duration("interval", interval))
+
+	go e.runSchedule(schedule)
+	return nil
+}
+
+// runSchedule releases child orders on the schedule's interval until all
+// are released or the parent is canceled
+func (e *Engine) runSchedule(schedule *sliceSchedule) {
+	for i, child := range schedule.children {
+		if i > 0 {
+			timer := time.NewTimer(schedule.interval)
+			select {
+			case <-schedule.stop:
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+		}
+		e.releaseChild(schedule, child)
+	}
+}
+
+// releaseChild places a scheduled child order unless its parent has been
+// canceled. Children go through the same pre-trade, risk and rate limit
+// checks as any placed order; those failing are recorded as rejected.
+func (e *Engine) releaseChild(schedule *sliceSchedule, child *Order) {
+	err := e.validateOrder(child)
+	if err == nil {
+		err = e.checkRisk(context.Background(), child)
+	}
+	if err == nil && !e.takeToken(child.UserID) {
+		err = fmt.Errorf("%w: user %s", ErrRateLimited, child.UserID)
+	}
+	if err == nil {
+		err = e.checkPreTrade(child)
+	}
+
+	e.mu.Lock()
+	if schedule.stopped {
+		e.mu.Unlock()
+		return
+	}
+	schedule.released++
+	child.UpdatedAt = time.Now()
+	if err == nil {
+		err = e.admitOrder(child)
+	}
+	switch {
+	case errors.Is(err, ErrDuplicateOrder):
+		// The ID belongs to another order, which must not be replaced
+		child.Status = OrderStatusRejected
+		e.finishParent(child)
+		e.mu.Unlock()
+		e.logger.Warn("Rejected child order",
+			zap.String("order_id", child.ID),
+			zap.String("parent_id", child.ParentID),
+			zap.Error(err))
+		return
+	case err != nil:
+		child.Status = OrderStatusRejected
+		e.terminal[child.ID] = child
+		e.logTransition(child, OrderStatusNew)
+		e.recordOrder(EventOrderRejected, child)
+		e.finishParent(child)
+	default:
+		e.orders[child.ID] = child
+		e.recordOrder(EventOrderPlaced, child)
+		e.logLifecycle("Child order released", child,
+			zap.String("parent_id", child.ParentID),
+			zap.Float64("quantity", child.Quantity))
+	}
+	e.mu.Unlock()
+
+	if err == nil {
+		e.recordAccepted(child)
+	} else {
+		e.logger.Warn("Rejected child order",
+			zap.String("order_id", child.ID),
+			zap.String("parent_id", child.ParentID),
+			zap.Error(err))
+	}
+
+	if err := e.storage.SaveOrder(child); err != nil {
+		e.logger.Error("Failed to save child order",
+			zap.String("order_id", child.ID),
+			zap.Error(err))
+	}
+}
+
+// cancelChildren stops the release schedule and cancels every child not
+// already terminal. Must be called with e.mu held.
+func (e *Engine) cancelChildren(schedule *sliceSchedule, now time.Time) error {
+	if !schedule.stopped {
+		schedule.stopped = true
+		close(schedule.stop)
+	}
+
+	for i, child := range schedule.children {
+		if child.Status.IsTerminal() {
+			continue
+		}
+		from := child.Status
+		child.Status = OrderStatusCanceled
+		child.UpdatedAt = now
+		e.logTransition(child, from)
+		if i >= schedule.released {
+			continue
+		}
+		e.retireOrder(child)
+		e.recordOrder(EventOrderCanceled, child)
+		if err := e.storage.SaveOrder(child); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// aggregateChildFill adds a child's fill to its parent and returns the
+// parent, or nil when order has no parent. Must be called with e.mu held.
+func (e *Engine) aggregateChildFill(order *Order, trade *Trade) *Order {
+	if order.ParentID == "" {
+		return nil
+	}
+	schedule, exists := e.schedules[order.ParentID]
+	if !exists {
+		return nil
+	}
+
+	parent := schedule.parent
+	from := parent.Status
+	parent.FilledQty += trade.Quantity
+	parent.UpdatedAt = trade.Timestamp
+	switch {
+	case parent.FilledQty >= parent.Quantity:
+		parent.Status = OrderStatusFilled
+		e.retireOrder(parent)
+	case schedule.exhausted():
+		parent.Status = OrderStatusCanceled
+		e.retireOrder(parent)
+	default:
+		parent.Status = OrderStatusPartial
+	}
+	e.logTransition(parent, from)
+	return parent
+}
+
+// exhausted reports whether every child has been released and none is
+// left open, so the parent can't fill any further
+func (s *sliceSchedule) exhausted() bool {
+	if s.released < len(s.children) {
+		return false
+	}
+	for _, child := range s.children {
+		if !child.Status.IsTerminal() {
+			return false
+		}
+	}
+	return true
+}
+
+// finishParent cancels the TWAP/VWAP parent of a child that has just been
+// rejected or canceled, keeping its partial fill, once the schedule is
+// exhausted. Otherwise one failed slice would leave the parent partial
+// forever. Must be called with e.mu held.
+func (e *Engine) finishParent(child *Order) {
+	if child.ParentID == "" {
+		return
+	}
+	schedule, exists := e.schedules[child.ParentID]
+	if !exists || schedule.parent.Status.IsTerminal() || !schedule.exhausted() {
+		return
+	}
+
+	parent := schedule.parent
+	from := parent.Status
+	parent.Status = OrderStatusCanceled
+	parent.UpdatedAt = child.UpdatedAt
+	e.retireOrder(parent)
+	e.logTransition(parent, from)
+	e.recordOrder(EventOrderCanceled, parent)
+	if err := e.storage.SaveOrder(parent); err != nil {
+		e.logger.Error("Failed to save sliced order",
+			zap.String("order_id", parent.ID),
+			zap.Error(err))
+	}
+}
+
+// sliceQuantities divides total in proportion to weights. The last slice
+// absorbs rounding so the slices sum exactly to total.
+func sliceQuantities(total float64, weights []float64) []float64 {
+	var sum float64
+	for _, w := range weights {
+		sum += w
+	}
+
+	quantities := make([]float64, len(weights))
+	var allocated float64
+	for i, w := range weights {
+		if i == len(weights)-1 {
+			quantities[i] = total - allocated
+			break
+		}
+		quantities[i] = total * w / sum
+		allocated += quantities[i]
+	}
+	return quantities
+}
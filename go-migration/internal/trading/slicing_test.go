@@ -0,0 +1,185 @@
+package trading
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+func parentOrder(id string, qty float64) *Order {
+	return &Order{
+		ID:       id,
+		UserID:   "user1",
+		Symbol:   "TEST/SOL",
+		Side:     OrderSideBuy,
+		Type:     OrderTypeMarket,
+		Quantity: qty,
+	}
+}
+
+func waitReleased(t *testing.T, engine *Engine, n int) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		return len(engine.QueryOrders(OrderFilter{Status: OrderStatusNew})) >= n
+	}, time.Second, time.Millisecond)
+}
+
+func TestEngine_PlaceTWAP(t *testing.T) {
+	engine, _ := newTestEngine(t)
+	parent := parentOrder("twap1", 10)
+	require.NoError(t, engine.PlaceTWAP(parent, 4, time.Millisecond))
+
+	children, err := engine.GetChildOrders("twap1")
+	require.NoError(t, err)
+	require.Len(t, children, 4)
+
+	var total float64
+	for i, child := range children {
+		assert.Equal(t, "twap1", child.ParentID)
+		assert.Equal(t, parent.Side, child.Side)
+		assert.InDelta(t, 2.5, child.Quantity, 1e-9, "slice %d", i)
+		total += child.Quantity
+	}
+	assert.InDelta(t, 10.0, total, 1e-9)
+
+	waitReleased(t, engine, 5)
+	for _, child := range children {
+		require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: child.ID, Price: 100, Quantity: child.Quantity}))
+	}
+	assert.InDelta(t, 10.0, parent.FilledQty, 1e-9)
+	assert.Equal(t, OrderStatusFilled, parent.Status)
+
+	t.Run("ParentNotFillable", func(t *testing.T) {
+		engine, _ := newTestEngine(t)
+		require.NoError(t, engine.PlaceTWAP(parentOrder("twap2", 4), 2, time.Hour))
+
+		err := engine.ExecuteTrade(&Trade{OrderID: "twap2", Price: 100, Quantity: 1})
+		assert.ErrorIs(t, err, ErrInvalidFill)
+	})
+
+	t.Run("SliceTooSmall", func(t *testing.T) {
+		engine, _ := newTestEngine(t)
+		err := engine.PlaceTWAP(parentOrder("twap3", 0.05), 10, time.Hour)
+		assert.ErrorIs(t, err, ErrOrderTooSmall)
+	})
+}
+
+func TestEngine_PlaceVWAP(t *testing.T) {
+	engine, _ := newTestEngine(t)
+	require.NoError(t, engine.PlaceVWAP(parentOrder("vwap1", 12), []float64{1, 2, 3}, time.Hour))
+
+	children, err := engine.GetChildOrders("vwap1")
+	require.NoError(t, err)
+	require.Len(t, children, 3)
+	assert.InDelta(t, 2.0, children[0].Quantity, 1e-9)
+	assert.InDelta(t, 4.0, children[1].Quantity, 1e-9)
+	assert.InDelta(t, 6.0, children[2].Quantity, 1e-9)
+
+	assert.Error(t, engine.PlaceVWAP(parentOrder("vwap2", 12), []float64{1, 0}, time.Hour))
+}
+
+func TestEngine_CancelSlicedParent(t *testing.T) {
+	engine, _ := newTestEngine(t)
+	parent := parentOrder("twap1", 9)
+	require.NoError(t, engine.PlaceTWAP(parent, 3, time.Hour))
+
+	// The first slice is released immediately, the rest wait an hour
+	waitReleased(t, engine, 2)
+	children, err := engine.GetChildOrders("twap1")
+	require.NoError(t, err)
+	require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: children[0].ID, Price: 100, Quantity: 1}))
+	assert.Equal(t, OrderStatusPartial, parent.Status)
+
+	require.NoError(t, engine.CancelOrder("twap1"))
+	assert.Equal(t, OrderStatusCanceled, parent.Status)
+	assert.InDelta(t, 1.0, parent.FilledQty, 1e-9)
+	for _, child := range children {
+		assert.Equal(t, OrderStatusCanceled, child.Status)
+	}
+
+	_, err = engine.GetOrder(children[1].ID)
+	assert.ErrorIs(t, err, ErrOrderNotFound, "unreleased slices never reach the book")
+}
+
+func TestEngine_SlicedParentFinishesAfterRejectedChild(t *testing.T) {
+	rejectSlice := func(id string) riskCheckerFunc {
+		return func(ctx context.Context, order *types.Order) error {
+			if order.ID == id {
+				return errors.New("slice rejected")
+			}
+			return nil
+		}
+	}
+
+	// The rejected middle slice can never fill, so the parent is canceled
+	// with what the others filled once the last of them does
+	engine, _ := newTestEngine(t)
+	engine.SetRiskChecker(rejectSlice("twap1-2"))
+	parent := parentOrder("twap1", 9)
+	require.NoError(t, engine.PlaceTWAP(parent, 3, time.Millisecond))
+	children, err := engine.GetChildOrders("twap1")
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		_, err := engine.GetOrder(children[2].ID)
+		return err == nil
+	}, time.Second, time.Millisecond)
+	assert.Equal(t, OrderStatusRejected, children[1].Status)
+
+	require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: children[0].ID, Price: 100, Quantity: 3}))
+	assert.Equal(t, OrderStatusPartial, parent.Status)
+	require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: children[2].ID, Price: 100, Quantity: 3}))
+	assert.Equal(t, OrderStatusCanceled, parent.Status)
+	assert.InDelta(t, 6.0, parent.FilledQty, 1e-9)
+
+	_, err = engine.Snapshot()
+	assert.NoError(t, err, "a finished parent no longer blocks snapshots")
+
+	t.Run("LastSliceRejected", func(t *testing.T) {
+		engine, _ := newTestEngine(t)
+		engine.SetRiskChecker(rejectSlice("twap2-2"))
+		parent := parentOrder("twap2", 4)
+		require.NoError(t, engine.PlaceTWAP(parent, 2, 50*time.Millisecond))
+		waitReleased(t, engine, 2)
+		require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: "twap2-1", Price: 100, Quantity: 2}))
+
+		require.Eventually(t, func() bool {
+			return len(engine.QueryOrders(OrderFilter{Status: OrderStatusCanceled})) == 1
+		}, time.Second, time.Millisecond)
+		assert.Equal(t, OrderStatusCanceled, parent.Status)
+		assert.InDelta(t, 2.0, parent.FilledQty, 1e-9)
+	})
+
+	t.Run("CanceledSlice", func(t *testing.T) {
+		engine, _ := newTestEngine(t)
+		require.NoError(t, engine.PlaceTWAP(parentOrder("twap3", 2), 1, time.Hour))
+		waitReleased(t, engine, 2)
+
+		require.NoError(t, engine.CancelOrder("twap3-1"))
+		order, err := engine.GetOrder("twap3")
+		require.NoError(t, err)
+		assert.Equal(t, OrderStatusCanceled, order.Status)
+	})
+}
+
+func TestEngine_SlicedParentSaveFailure(t *testing.T) {
+	storage := &flakyStorage{down: true}
+	engine := NewEngine(testConfig(), zap.NewNop(), storage)
+
+	err := engine.PlaceTWAP(parentOrder("twap1", 4), 2, time.Millisecond)
+	assert.ErrorIs(t, err, errStorageDown)
+	_, err = engine.GetOrder("twap1")
+	assert.ErrorIs(t, err, ErrOrderNotFound)
+	_, err = engine.GetChildOrders("twap1")
+	assert.ErrorIs(t, err, ErrOrderNotFound, "the schedule is rolled back with its parent")
+
+	// The ID is free once storage recovers
+	storage.down = false
+	require.NoError(t, engine.PlaceTWAP(parentOrder("twap1", 4), 2, time.Millisecond))
+}
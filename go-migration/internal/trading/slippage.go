@@ -0,0 +1,93 @@
+package trading
+
+import (
+	"fmt"
+	"math"
+)
+
+// SlippageModel derives the fill price of a market order from its size,
+// a reference price and, when available, the order book
+type SlippageModel interface {
+	FillPrice(side OrderSide, qty, refPrice float64, book *OrderBook) (float64, error)
+}
+
+// FixedBpsSlippage moves the fill price a fixed number of basis points
+// against the order regardless of size
+type FixedBpsSlippage struct {
+	Bps float64
+}
+
+// FillPrice implements SlippageModel
+func (m FixedBpsSlippage) FillPrice(side OrderSide, qty, refPrice float64, book *OrderBook) (float64, error) {
+	return applySlippage(side, refPrice, m.Bps/10000), nil
+}
+
+// SqrtSlippage models market impact as Coefficient * sqrt(qty / liquidity).
+// Liquidity is the opposite side of the book when one is given, otherwise
+// the configured Liquidity.
+type SqrtSlippage struct {
+	Coefficient float64
+	Liquidity   float64
+}
+
+// FillPrice implements SlippageModel
+func (m SqrtSlippage) FillPrice(side OrderSide, qty, refPrice float64, book *OrderBook) (float64, error) {
+	liquidity := m.Liquidity
+	if book != nil {
+		if depth := bookDepth(oppositeLevels(side, book)); depth > 0 {
+			liquidity = depth
+		}
+	}
+	if liquidity <= 0 {
+		return 0, fmt.Errorf("no liquidity to estimate slippage")
+	}
+
+	return applySlippage(side, refPrice, m.Coefficient*math.Sqrt(qty/liquidity)), nil
+}
+
+// BookWalkSlippage fills against the opposite side of the order book level
+// by level and returns the volume-weighted average price
+type BookWalkSlippage struct{}
+
+// FillPrice implements SlippageModel
+func (m BookWalkSlippage) FillPrice(side OrderSide, qty, refPrice float64, book *OrderBook) (float64, error) {
+	if book == nil {
+		return 0, fmt.Errorf("order book required for book-walking slippage")
+	}
+
+	remaining := qty
+	cost := 0.0
+	for _, level := range oppositeLevels(side, book) {
+		take := math.Min(remaining, level.Quantity)
+		cost += take * level.Price
+		remaining -= take
+		if remaining <= 0 {
+			return cost / qty, nil
+		}
+	}
+	return 0, fmt.Errorf("insufficient book depth: %f of %f unfilled", remaining, qty)
+}
+
+// applySlippage moves price against the order by fraction
+func applySlippage(side OrderSide, price, fraction float64) float64 {
+	if side == OrderSideSell {
+		return price * (1 - fraction)
+	}
+	return price * (1 + fraction)
+}
+
+// oppositeLevels returns the levels a market order on side would consume
+func oppositeLevels(side OrderSide, book *OrderBook) []OrderBookLevel {
+	if side == OrderSideSell {
+		return book.Bids
+	}
+	return book.Asks
+}
+
+func bookDepth(levels []OrderBookLevel) float64 {
+	depth := 0.0
+	for _, level := range levels {
+		depth += level.Quantity
+	}
+	return depth
+}
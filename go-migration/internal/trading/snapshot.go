@@ -0,0 +1,183 @@
+package trading
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// snapshotVersion is the current engine snapshot schema version. Bump it
+// when the layout changes and keep Restore able to read older versions.
+const snapshotVersion = 1
+
+// engineSnapshot is the serialized form of the engine state
+type engineSnapshot struct {
+	Version        int             `json:"version"`
+	TakenAt        time.Time       `json:"taken_at"`
+	Config         Config          `json:"config"`
+	Orders         []*Order        `json:"orders"`
+	TerminalOrders []*Order        `json:"terminal_orders"`
+	Positions      []*Position     `json:"positions"`
+	Trades         []*Trade        `json:"trades"`
+	Funding        []*FundingEntry `json:"funding"`
+}
+
+// Snapshot serializes the engine's orders, positions, trades, funding
+// history and config as versioned JSON. TWAP/VWAP schedules and spreads
+// are not part of a snapshot, so it fails with ErrActiveGroups while any
+// is still open.
+func (e *Engine) Snapshot() ([]byte, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if err := e.checkNoActiveGroups(); err != nil {
+		return nil, err
+	}
+
+	snap := engineSnapshot{
+		Version:        snapshotVersion,
+		TakenAt:        time.Now().UTC(),
+		Config:         e.config,
+		Orders:         make([]*Order, 0, len(e.orders)),
+		TerminalOrders: make([]*Order, 0, len(e.terminal)),
+		Positions:      make([]*Position, 0, len(e.positions)),
+		Trades:         e.trades,
+		Funding:        e.funding,
+	}
+	for _, order := range e.orders {
+		snap.Orders = append(snap.Orders, order)
+	}
+	for _, order := range e.terminal {
+		snap.TerminalOrders = append(snap.TerminalOrders, order)
+	}
+	for _, pos := range e.positions {
+		snap.Positions = append(snap.Positions, pos)
+	}
+
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode snapshot: %w", err)
+	}
+	return data, nil
+}
+
+// Restore replaces the engine state with a snapshot produced by Snapshot.
+// Fill subscribers and injected providers are kept. Since schedules and
+// spreads can't be rebuilt, it fails with ErrActiveGroups while the
+// engine has open ones or when the snapshot holds open orders that
+// belong to one. Finished schedules and spreads are dropped. It fails
+// with ErrPendingWork while rate-limited orders, queued fills or retried
+// saves are waiting, since they refer to the state being replaced.
+func (e *Engine) Restore(data []byte) error {
+	var snap engineSnapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return fmt.Errorf("failed to decode snapshot: %w", err)
+	}
+	if snap.Version < 1 || snap.Version > snapshotVersion {
+		return fmt.Errorf("unsupported snapshot version: %d", snap.Version)
+	}
+
+	orders := make(map[string]*Order, len(snap.Orders))
+	for _, order := range snap.Orders {
+		orders[order.ID] = order
+	}
+	terminal := make(map[string]*Order, len(snap.TerminalOrders))
+	for _, order := range snap.TerminalOrders {
+		terminal[order.ID] = order
+	}
+	positions := make(map[string]*Position, len(snap.Positions))
+	for _, pos := range snap.Positions {
+		positions[pos.Symbol] = pos
+	}
+
+	if err := checkNoGroupOrders(orders); err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if err := e.checkNoActiveGroups(); err != nil {
+		return err
+	}
+	if err := e.checkNoPendingWork(); err != nil {
+		return err
+	}
+	e.stopSchedules()
+	e.schedules = make(map[string]*sliceSchedule)
+	e.spreads = make(map[string]*Spread)
+
+	e.config = snap.Config
+	e.orders = orders
+	e.terminal = terminal
+	e.positions = positions
+	e.trades = snap.Trades
+	e.funding = snap.Funding
+
+	e.logger.Info("Restored engine snapshot",
+		zap.Time("taken_at", snap.TakenAt),
+		zap.Int("orders", len(orders)+len(terminal)),
+		zap.Int("positions", len(positions)),
+		zap.Int("trades", len(snap.Trades)))
+
+	return nil
+}
+
+// stopSchedules stops every pending TWAP/VWAP schedule. Must be called
+// with e.mu held.
+func (e *Engine) stopSchedules() {
+	for _, schedule := range e.schedules {
+		if !schedule.stopped {
+			schedule.stopped = true
+			close(schedule.stop)
+		}
+	}
+}
+
+// checkNoActiveGroups fails with ErrActiveGroups while a TWAP/VWAP parent
+// or a spread is still open. Must be called with e.mu held.
+func (e *Engine) checkNoActiveGroups() error {
+	for id, schedule := range e.schedules {
+		if !schedule.parent.Status.IsTerminal() {
+			return fmt.Errorf("%w: sliced order %s is open", ErrActiveGroups, id)
+		}
+	}
+	for id, spread := range e.spreads {
+		if !spread.Status.IsTerminal() {
+			return fmt.Errorf("%w: spread %s is open", ErrActiveGroups, id)
+		}
+	}
+	return nil
+}
+
+// checkNoPendingWork fails with ErrPendingWork while rate-limited orders,
+// queued fills or pending saves are waiting. Must be called with e.mu
+// held.
+func (e *Engine) checkNoPendingWork() error {
+	if n := len(e.rateQueue); n > 0 {
+		return fmt.Errorf("%w: %d rate-limited orders queued", ErrPendingWork, n)
+	}
+	if e.execQueue != nil && e.execQueue.Len() > 0 {
+		return fmt.Errorf("%w: %d fills queued", ErrPendingWork, e.execQueue.Len())
+	}
+	if n := len(e.pendingSaves); n > 0 {
+		return fmt.Errorf("%w: %d order saves pending", ErrPendingWork, n)
+	}
+	return nil
+}
+
+// checkNoGroupOrders fails with ErrActiveGroups when any of the open
+// orders is a TWAP/VWAP child or a spread leg
+func checkNoGroupOrders(open map[string]*Order) error {
+	for id, order := range open {
+		if order.SpreadID != "" {
+			return fmt.Errorf("%w: order %s is a leg of spread %s", ErrActiveGroups, id, order.SpreadID)
+		}
+		if order.ParentID != "" {
+			return fmt.Errorf("%w: order %s is a slice of %s", ErrActiveGroups, id, order.ParentID)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package trading
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// canonical encodes v as JSON so results compare independently of pointer
+// identity and time zones
+func canonical(t *testing.T, v interface{}) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func sortedOrders(orders []*Order) []*Order {
+	sort.Slice(orders, func(i, j int) bool { return orders[i].ID < orders[j].ID })
+	return orders
+}
+
+func sortedPositions(positions []*Position) []*Position {
+	sort.Slice(positions, func(i, j int) bool { return positions[i].Symbol < positions[j].Symbol })
+	return positions
+}
+
+func TestEngine_SnapshotRestore(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	source, _ := newTestEngine(t)
+
+	placeTestOrder(t, source, "buy1", OrderSideBuy, 10)
+	require.NoError(t, source.ExecuteTrade(&Trade{OrderID: "buy1", Price: 100, Quantity: 10, Timestamp: now}))
+	placeTestOrder(t, source, "sell1", OrderSideSell, 4)
+	require.NoError(t, source.ExecuteTrade(&Trade{OrderID: "sell1", Price: 110, Quantity: 2, Timestamp: now}))
+	placeTestOrder(t, source, "buy2", OrderSideBuy, 1)
+	require.NoError(t, source.CancelOrder("buy2"))
+	require.NoError(t, source.ApplyFunding("TEST/SOL", 0.001, now))
+
+	data, err := source.Snapshot()
+	require.NoError(t, err)
+
+	restored := NewEngine(Config{}, zap.NewNop(), &memStorage{})
+	require.NoError(t, restored.Restore(data))
+
+	assert.Equal(t, testConfig(), restored.config)
+	assert.Equal(t,
+		canonical(t, sortedOrders(source.QueryOrders(OrderFilter{}))),
+		canonical(t, sortedOrders(restored.QueryOrders(OrderFilter{}))))
+	assert.Equal(t,
+		canonical(t, sortedPositions(source.GetPositions())),
+		canonical(t, sortedPositions(restored.GetPositions())))
+
+	sourceTrades, _ := source.GetTrades("user1")
+	restoredTrades, _ := restored.GetTrades("user1")
+	assert.Equal(t, canonical(t, sourceTrades), canonical(t, restoredTrades))
+	assert.Equal(t,
+		canonical(t, source.GetFundingHistory("TEST/SOL")),
+		canonical(t, restored.GetFundingHistory("TEST/SOL")))
+
+	// Indexes are rebuilt: open orders still fill and terminal ones don't
+	require.NoError(t, restored.ExecuteTrade(&Trade{OrderID: "sell1", Price: 110, Quantity: 2, Timestamp: now}))
+	assert.ErrorIs(t, restored.CancelOrder("missing"), ErrOrderNotFound)
+	assert.ErrorIs(t, restored.PlaceOrder(&Order{ID: "buy2", Side: OrderSideBuy, Type: OrderTypeMarket, Quantity: 1}), ErrDuplicateOrder)
+
+	t.Run("ActiveGroups", func(t *testing.T) {
+		engine, _ := newTestEngine(t)
+		require.NoError(t, engine.PlaceTWAP(parentOrder("twap1", 10), 2, time.Hour))
+		waitReleased(t, engine, 2)
+
+		// Open schedules can't be snapshotted or restored over
+		_, err := engine.Snapshot()
+		assert.ErrorIs(t, err, ErrActiveGroups)
+		assert.ErrorIs(t, engine.Restore(data), ErrActiveGroups)
+
+		require.NoError(t, engine.CancelOrder("twap1"))
+		data, err := engine.Snapshot()
+		require.NoError(t, err)
+		require.NoError(t, restored.Restore(data))
+
+		spreads, _ := newTestEngine(t)
+		_, err = spreads.PlaceSpread([]*Order{
+			{ID: "leg1", UserID: "user1", Symbol: "AAA/SOL", Side: OrderSideBuy, Type: OrderTypeMarket, Quantity: 1},
+			{ID: "leg2", UserID: "user1", Symbol: "BBB/SOL", Side: OrderSideSell, Type: OrderTypeMarket, Quantity: 1},
+		}, []float64{1, 1})
+		require.NoError(t, err)
+		_, err = spreads.Snapshot()
+		assert.ErrorIs(t, err, ErrActiveGroups)
+
+		// Nor can a snapshot holding open slices or legs be restored
+		leg := []byte(`{"version":1,"orders":[{"id":"leg1","spread_id":"spread-1","status":"new"}]}`)
+		assert.ErrorIs(t, restored.Restore(leg), ErrActiveGroups)
+	})
+
+	t.Run("PendingWork", func(t *testing.T) {
+		engine, _ := newTestEngine(t)
+		placeTestOrder(t, engine, "queued", OrderSideBuy, 1)
+		require.NoError(t, engine.QueueExecution(&Trade{OrderID: "queued", Price: 100, Quantity: 1}))
+
+		// The queued fill refers to an order the snapshot would replace
+		assert.ErrorIs(t, engine.Restore(data), ErrPendingWork)
+		assert.ErrorIs(t, engine.ReplayFrom(NewMemoryEventLog()), ErrPendingWork)
+		_, err := engine.GetOrder("queued")
+		assert.NoError(t, err)
+
+		_, err = engine.ExecutePending(0)
+		require.NoError(t, err)
+		require.NoError(t, engine.Restore(data))
+
+		limited := rateLimitedEngine(0.001, 1, 10, time.Minute)
+		require.NoError(t, limited.PlaceOrder(burstOrder(0)))
+		require.NoError(t, limited.PlaceOrder(burstOrder(1)))
+		require.Equal(t, 1, limited.QueuedOrders())
+		assert.ErrorIs(t, limited.Restore(data), ErrPendingWork)
+
+		config := testConfig()
+		config.StorageFailurePolicy = StoragePolicyRetry
+		config.WALPath = filepath.Join(t.TempDir(), "orders.wal")
+		unsaved := NewEngine(config, zap.NewNop(), &flakyStorage{down: true})
+		require.NoError(t, unsaved.PlaceOrder(burstOrder(0)))
+		assert.ErrorIs(t, unsaved.Restore(data), ErrPendingWork)
+	})
+
+	t.Run("UnsupportedVersion", func(t *testing.T) {
+		assert.Error(t, restored.Restore([]byte(`{"version":99}`)))
+		assert.Error(t, restored.Restore([]byte(`{}`)))
+	})
+}
@@ -0,0 +1,344 @@
+package trading
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// spreadTolerance is the relative error allowed when matching leg
+// quantities to ratios
+const spreadTolerance = 1e-9
+
+// Spread is a group of legs that fill together in fixed ratios. One unit
+// of the spread is Ratios[i] of every leg i.
+type Spread struct {
+	ID          string    `json:"id"`
+	Legs        []*Order  `json:"legs"`
+	Ratios      []float64 `json:"ratios"`
+	Units       float64   `json:"units"`
+	FilledUnits float64   `json:"filled_units"`
+	// LegAvgPrices is the average fill price of each leg
+	LegAvgPrices []float64   `json:"leg_avg_prices"`
+	Status       OrderStatus `json:"status"`
+	CreatedAt    time.Time   `json:"created_at"`
+	UpdatedAt    time.Time   `json:"updated_at"`
+}
+
+// EntryPrice returns the filled price of one spread unit: bought legs add
+// and sold legs subtract their ratio times average fill price
+func (s *Spread) EntryPrice() float64 {
+	var price float64
+	for i, leg := range s.Legs {
+		value := s.Ratios[i] * s.LegAvgPrices[i]
+		if leg.Side == OrderSideSell {
+			value = -value
+		}
+		price += value
+	}
+	return price
+}
+
+// PlaceSpread places legs as one spread. Leg quantities must be in the
+// given ratios. Either every leg passes pre-trade, risk and rate limit
+// checks and is placed, or none is; likewise a leg failing to save under
+// the rollback storage policy rolls back the whole spread. Legs can only
+// be filled through ExecuteSpread and canceling any leg cancels the whole
+// spread.
+func (e *Engine) PlaceSpread(legs []*Order, ratios []float64) (*Spread, error) {
+	if len(legs) < 2 {
+		return nil, fmt.Errorf("spread needs at least two legs, got %d", len(legs))
+	}
+	if len(ratios) != len(legs) {
+		return nil, fmt.Errorf("spread has %d legs but %d ratios", len(legs), len(ratios))
+	}
+	for i, ratio := range ratios {
+		if ratio <= 0 {
+			return nil, fmt.Errorf("invalid ratio %f for leg %d", ratio, i)
+		}
+	}
+
+	units := legs[0].Quantity / ratios[0]
+	for i, leg := range legs {
+		if !matchesRatio(leg.Quantity, units*ratios[i]) {
+			return nil, fmt.Errorf("leg %s quantity %f does not match ratio %f for %f units",
+				leg.ID, leg.Quantity, ratios[i], units)
+		}
+	}
+
+	now := time.Now()
+	spread := &Spread{
+		ID:           "spread-" + newCorrelationID(),
+		Legs:         legs,
+		Ratios:       ratios,
+		Units:        units,
+		LegAvgPrices: make([]float64, len(legs)),
+		Status:       OrderStatusNew,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+
+	for _, leg := range legs {
+		if err := e.validateOrder(leg); err != nil {
+			return nil, fmt.Errorf("spread leg %s: %w", leg.ID, err)
+		}
+		if leg.CorrelationID == "" {
+			leg.CorrelationID = spread.ID
+		}
+		if err := e.checkRisk(context.Background(), leg); err != nil {
+			return nil, fmt.Errorf("spread leg %s: %w", leg.ID, err)
+		}
+		if err := e.checkPreTrade(leg); err != nil {
+			return nil, fmt.Errorf("spread leg %s: %w", leg.ID, err)
+		}
+	}
+	if leg := e.takeTokens(legs); leg != nil {
+		return nil, fmt.Errorf("spread leg %s: %w: user %s", leg.ID, ErrRateLimited, leg.UserID)
+	}
+
+	// Legs are admitted one at a time so each counts against the caps the
+	// next is checked with, and backed out if any is refused
+	e.mu.Lock()
+	for i, leg := range legs {
+		if err := e.admitOrder(leg); err != nil {
+			for _, admitted := range legs[:i] {
+				delete(e.orders, admitted.ID)
+			}
+			e.mu.Unlock()
+			return nil, fmt.Errorf("spread leg %s: %w", leg.ID, err)
+		}
+		e.orders[leg.ID] = leg
+	}
+	for _, leg := range legs {
+		leg.SpreadID = spread.ID
+		leg.Status = OrderStatusNew
+		e.recordOrder(EventOrderPlaced, leg)
+	}
+	e.spreads[spread.ID] = spread
+	e.mu.Unlock()
+
+	for i, leg := range legs {
+		if err := e.storage.SaveOrder(leg); err != nil {
+			if err := e.handleSaveFailure(leg, err); err != nil {
+				e.rollbackSpread(spread, i)
+				return nil, fmt.Errorf("spread leg %s: %w", leg.ID, err)
+			}
+		}
+	}
+	for _, leg := range legs {
+		e.recordAccepted(leg)
+	}
+
+	e.logger.Info("Placed spread",
+		zap.String("spread_id", spread.ID),
+		zap.Int("legs", len(legs)),
+		zap.Float64("units", units))
+
+	return spread, nil
+}
+
+// rollbackSpread removes a spread whose leg failed rolled back on save,
+// taking the other legs with it. Legs saved before failed are canceled in
+// storage so it holds no open leg of a spread memory doesn't.
+func (e *Engine) rollbackSpread(spread *Spread, failed int) {
+	e.mu.Lock()
+	delete(e.spreads, spread.ID)
+	for i, leg := range spread.Legs {
+		if i == failed {
+			continue
+		}
+		delete(e.orders, leg.ID)
+		e.recordOrder(EventOrderRolledBack, leg)
+	}
+	e.mu.Unlock()
+
+	for _, leg := range spread.Legs[:failed] {
+		leg.Status = OrderStatusCanceled
+		leg.UpdatedAt = time.Now()
+		if err := e.storage.SaveOrder(leg); err != nil {
+			e.logger.Error("Failed to cancel saved spread leg",
+				zap.String("order_id", leg.ID),
+				zap.String("spread_id", spread.ID),
+				zap.Error(err))
+		}
+	}
+	e.logger.Warn("Spread rolled back",
+		zap.String("spread_id", spread.ID),
+		zap.String("order_id", spread.Legs[failed].ID))
+}
+
+// ExecuteSpread applies one fill per leg, fills[i] for leg i, as a single
+// atomic step. The fill quantities must keep the spread's ratios; if any
+// fill is invalid or out of ratio, none is applied and the group keeps
+// its previous state. Aged legs are re-validated first, and one failing
+// rejects the whole spread.
+func (e *Engine) ExecuteSpread(spreadID string, fills []*Trade) error {
+	if err := e.revalidateSpread(spreadID); err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	spread, exists := e.spreads[spreadID]
+	if !exists {
+		e.mu.Unlock()
+		return fmt.Errorf("%w: spread %s", ErrOrderNotFound, spreadID)
+	}
+	if len(fills) != len(spread.Legs) {
+		e.mu.Unlock()
+		return fmt.Errorf("%w: spread %s has %d legs, got %d fills",
+			ErrInvalidFill, spreadID, len(spread.Legs), len(fills))
+	}
+
+	units := fills[0].Quantity / spread.Ratios[0]
+	orders := make([]*Order, len(fills))
+	for i, fill := range fills {
+		leg := spread.Legs[i]
+		if fill.OrderID == "" {
+			fill.OrderID = leg.ID
+		}
+		if fill.OrderID != leg.ID {
+			e.mu.Unlock()
+			return fmt.Errorf("%w: fill %d is for %s, expected leg %s",
+				ErrInvalidFill, i, fill.OrderID, leg.ID)
+		}
+		if !matchesRatio(fill.Quantity, units*spread.Ratios[i]) {
+			e.mu.Unlock()
+			return fmt.Errorf("%w: leg %s fill %f breaks ratio %f",
+				ErrInvalidFill, leg.ID, fill.Quantity, spread.Ratios[i])
+		}
+
+		order, err := e.checkFill(fill)
+		if err != nil {
+			e.mu.Unlock()
+			return err
+		}
+		orders[i] = order
+	}
+
+	applied := make([]appliedFill, len(fills))
+	for i, fill := range fills {
+		filled := orders[i].FilledQty
+		applied[i] = e.applyFill(orders[i], fill)
+		spread.LegAvgPrices[i] = (spread.LegAvgPrices[i]*filled + fill.Price*fill.Quantity) /
+			(filled + fill.Quantity)
+	}
+
+	spread.FilledUnits += units
+	spread.UpdatedAt = fills[0].Timestamp
+	if matchesRatio(spread.FilledUnits, spread.Units) {
+		spread.Status = OrderStatusFilled
+	} else {
+		spread.Status = OrderStatusPartial
+	}
+	e.mu.Unlock()
+
+	for _, fill := range applied {
+		if err := e.saveFill(fill); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetSpread returns a spread by ID
+func (e *Engine) GetSpread(spreadID string) (*Spread, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	spread, exists := e.spreads[spreadID]
+	if !exists {
+		return nil, fmt.Errorf("%w: spread %s", ErrOrderNotFound, spreadID)
+	}
+	return spread, nil
+}
+
+// cancelSpread cancels every open leg of spread. Must be called with e.mu
+// held.
+func (e *Engine) cancelSpread(spread *Spread, now time.Time) error {
+	for _, leg := range spread.Legs {
+		if leg.Status.IsTerminal() {
+			continue
+		}
+		from := leg.Status
+		leg.Status = OrderStatusCanceled
+		leg.UpdatedAt = now
+		e.retireOrder(leg)
+		e.logTransition(leg, from)
+		e.recordOrder(EventOrderCanceled, leg)
+		if err := e.storage.SaveOrder(leg); err != nil {
+			return err
+		}
+	}
+	spread.Status = OrderStatusCanceled
+	spread.UpdatedAt = now
+	return nil
+}
+
+// revalidateSpread re-validates every aged leg of a spread before any of
+// its fills is applied, like ExecuteTrade does for single orders. One leg
+// failing rejects the whole spread, since the others can't fill without
+// it.
+func (e *Engine) revalidateSpread(spreadID string) error {
+	e.mu.RLock()
+	spread, exists := e.spreads[spreadID]
+	e.mu.RUnlock()
+	if !exists {
+		return nil
+	}
+
+	for _, leg := range spread.Legs {
+		age, err := e.checkAged(leg)
+		if err == nil {
+			continue
+		}
+
+		e.mu.Lock()
+		rejected := e.rejectSpread(spread, time.Now())
+		e.mu.Unlock()
+
+		e.logger.Warn("Aged spread leg failed re-validation",
+			append(orderFields(leg),
+				zap.String("spread_id", spread.ID),
+				zap.Duration("age", age),
+				zap.Error(err))...)
+
+		for _, order := range rejected {
+			if saveErr := e.storage.SaveOrder(order); saveErr != nil {
+				return saveErr
+			}
+		}
+		return fmt.Errorf("%w: spread %s leg %s aged %s: %w",
+			ErrOrderStale, spread.ID, leg.ID, age.Round(time.Second), err)
+	}
+	return nil
+}
+
+// rejectSpread rejects every open leg of spread and the spread itself,
+// returning the legs to save. Must be called with e.mu held.
+func (e *Engine) rejectSpread(spread *Spread, now time.Time) []*Order {
+	var rejected []*Order
+	for _, leg := range spread.Legs {
+		if leg.Status.IsTerminal() {
+			continue
+		}
+		from := leg.Status
+		leg.Status = OrderStatusRejected
+		leg.UpdatedAt = now
+		e.retireOrder(leg)
+		e.logTransition(leg, from)
+		e.recordOrder(EventOrderRejected, leg)
+		rejected = append(rejected, leg)
+	}
+	if !spread.Status.IsTerminal() {
+		spread.Status = OrderStatusRejected
+		spread.UpdatedAt = now
+	}
+	return rejected
+}
+
+func matchesRatio(got, want float64) bool {
+	return math.Abs(got-want) <= spreadTolerance*math.Max(math.Abs(want), 1)
+}
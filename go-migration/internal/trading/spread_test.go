@@ -0,0 +1,98 @@
+package trading
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func spreadLeg(id, symbol string, side OrderSide, qty float64) *Order {
+	return &Order{
+		ID:       id,
+		UserID:   "user1",
+		Symbol:   symbol,
+		Side:     side,
+		Type:     OrderTypeMarket,
+		Quantity: qty,
+	}
+}
+
+func TestEngine_Spread(t *testing.T) {
+	engine, _ := newTestEngine(t)
+	long := spreadLeg("long", "AAA/SOL", OrderSideBuy, 10)
+	short := spreadLeg("short", "BBB/SOL", OrderSideSell, 20)
+
+	spread, err := engine.PlaceSpread([]*Order{long, short}, []float64{1, 2})
+	require.NoError(t, err)
+	assert.Equal(t, 10.0, spread.Units)
+	assert.Equal(t, spread.ID, long.SpreadID)
+
+	t.Run("LegsRejectDirectFills", func(t *testing.T) {
+		err := engine.ExecuteTrade(&Trade{OrderID: "long", Price: 1, Quantity: 1})
+		assert.ErrorIs(t, err, ErrInvalidFill)
+	})
+
+	t.Run("BrokenRatioHeldAtomically", func(t *testing.T) {
+		err := engine.ExecuteSpread(spread.ID, []*Trade{
+			{OrderID: "long", Price: 1, Quantity: 4},
+			{OrderID: "short", Price: 0.5, Quantity: 3},
+		})
+		assert.ErrorIs(t, err, ErrInvalidFill)
+		assert.Zero(t, long.FilledQty)
+		assert.Zero(t, short.FilledQty)
+		assert.Nil(t, engine.GetPosition("AAA/SOL"))
+	})
+
+	t.Run("OverfillHeldAtomically", func(t *testing.T) {
+		err := engine.ExecuteSpread(spread.ID, []*Trade{
+			{OrderID: "long", Price: 1, Quantity: 12},
+			{OrderID: "short", Price: 0.5, Quantity: 24},
+		})
+		assert.ErrorIs(t, err, ErrInvalidFill)
+		assert.Zero(t, long.FilledQty)
+	})
+
+	require.NoError(t, engine.ExecuteSpread(spread.ID, []*Trade{
+		{OrderID: "long", Price: 1, Quantity: 4},
+		{OrderID: "short", Price: 0.5, Quantity: 8},
+	}))
+	assert.Equal(t, OrderStatusPartial, spread.Status)
+	assert.Equal(t, 4.0, spread.FilledUnits)
+
+	require.NoError(t, engine.ExecuteSpread(spread.ID, []*Trade{
+		{OrderID: "long", Price: 1.5, Quantity: 6},
+		{OrderID: "short", Price: 0.5, Quantity: 12},
+	}))
+	assert.Equal(t, OrderStatusFilled, spread.Status)
+	assert.Equal(t, OrderStatusFilled, long.Status)
+	assert.Equal(t, OrderStatusFilled, short.Status)
+	assert.InDelta(t, 1.3, spread.LegAvgPrices[0], 1e-9)
+	assert.InDelta(t, 1.3-2*0.5, spread.EntryPrice(), 1e-9)
+
+	assert.Equal(t, 10.0, engine.GetPosition("AAA/SOL").Quantity)
+	assert.Equal(t, -20.0, engine.GetPosition("BBB/SOL").Quantity)
+
+	t.Run("CancelLegCancelsGroup", func(t *testing.T) {
+		engine, _ := newTestEngine(t)
+		a := spreadLeg("a", "AAA/SOL", OrderSideBuy, 1)
+		b := spreadLeg("b", "BBB/SOL", OrderSideSell, 1)
+		spread, err := engine.PlaceSpread([]*Order{a, b}, []float64{1, 1})
+		require.NoError(t, err)
+
+		require.NoError(t, engine.CancelOrder("a"))
+		assert.Equal(t, OrderStatusCanceled, b.Status)
+		assert.Equal(t, OrderStatusCanceled, spread.Status)
+	})
+
+	t.Run("RatioMismatchOnPlace", func(t *testing.T) {
+		engine, _ := newTestEngine(t)
+		_, err := engine.PlaceSpread([]*Order{
+			spreadLeg("a", "AAA/SOL", OrderSideBuy, 1),
+			spreadLeg("b", "BBB/SOL", OrderSideSell, 3),
+		}, []float64{1, 2})
+		assert.Error(t, err)
+		_, err = engine.GetOrder("a")
+		assert.ErrorIs(t, err, ErrOrderNotFound)
+	})
+}
@@ -0,0 +1,151 @@
+package trading
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// StaleLimitPolicy moves resting limit orders that haven't filled toward
+// the market so they stay relevant
+type StaleLimitPolicy struct {
+	// After is how long a limit order may go without a fill or reprice
+	// before it is stale; zero disables the policy
+	After time.Duration `json:"after"`
+	// Step is the fraction of its price a stale order moves toward the
+	// market each time, never past the best opposite price. MaxReprices
+	// caps how often one order is moved; zero is unlimited.
+	Step        float64 `json:"step"`
+	MaxReprices int     `json:"max_reprices"`
+	// Cancel cancels stale orders that can't be moved any further,
+	// including every stale order when Step is zero
+	Cancel bool `json:"cancel"`
+}
+
+// staleRepricePrice returns the price a stale order moves to, or ok false
+// when it can't move toward the market. Buys step up and sells down,
+// stopping at the best opposite price and at the order's WorstPrice.
+// A post-only step that would reach the opposite side goes to the best
+// price on its own side instead.
+func staleRepricePrice(order *Order, policy StaleLimitPolicy, book *OrderBook) (price float64, ok bool) {
+	if policy.Step <= 0 || book == nil {
+		return 0, false
+	}
+	if policy.MaxReprices > 0 && order.Reprices >= policy.MaxReprices {
+		return 0, false
+	}
+	bid, ask := book.BestBidAsk()
+
+	if order.Side == OrderSideBuy {
+		if ask <= 0 {
+			return 0, false
+		}
+		price = math.Min(order.Price*(1+policy.Step), ask)
+		if order.PostOnly && price >= ask {
+			price = bid
+		}
+		if order.WorstPrice > 0 {
+			price = math.Min(price, order.WorstPrice)
+		}
+		return price, price > order.Price
+	}
+
+	if bid <= 0 {
+		return 0, false
+	}
+	price = math.Max(order.Price*(1-policy.Step), bid)
+	if order.PostOnly && price <= bid {
+		price = ask
+	}
+	if order.WorstPrice > 0 {
+		price = math.Max(price, order.WorstPrice)
+	}
+	return price, price > 0 && price < order.Price
+}
+
+// RepriceStaleOrders applies Config.StaleLimit to the open limit orders
+// that have gone unfilled and unrepriced since before now minus After,
+// returning the orders it repriced and canceled. Pegged orders, spread
+// legs and sliced parents are left alone, and orders in symbols without a
+// book can only be canceled.
+func (e *Engine) RepriceStaleOrders(now time.Time) (repriced, canceled []*Order) {
+	policy := e.config.StaleLimit
+	if policy.After <= 0 {
+		return nil, nil
+	}
+
+	e.mu.Lock()
+	for _, order := range e.orders {
+		if order.Type != OrderTypeLimit || isPegged(order) || order.Status.IsTerminal() {
+			continue
+		}
+		if _, isParent := e.schedules[order.ID]; isParent || order.SpreadID != "" {
+			continue
+		}
+		if now.Sub(order.UpdatedAt) < policy.After {
+			continue
+		}
+
+		if price, ok := staleRepricePrice(order, policy, e.books[order.Symbol]); ok {
+			e.logLifecycle("Stale limit order repriced", order,
+				zap.Float64("from", order.Price),
+				zap.Float64("to", price),
+				zap.Int("reprices", order.Reprices+1))
+			order.Price = price
+			order.Reprices++
+			order.UpdatedAt = now
+			e.recordOrder(EventOrderUpdated, order)
+			repriced = append(repriced, order)
+		} else if policy.Cancel {
+			from := order.Status
+			order.Status = OrderStatusCanceled
+			order.UpdatedAt = now
+			e.retireOrder(order)
+			e.logTransition(order, from)
+			e.recordOrder(EventOrderCanceled, order)
+			e.finishParent(order)
+			canceled = append(canceled, order)
+		}
+	}
+	e.mu.Unlock()
+
+	for _, order := range append(repriced, canceled...) {
+		if err := e.storage.SaveOrder(order); err != nil {
+			e.logger.Error("Failed to save stale limit order",
+				zap.String("order_id", order.ID),
+				zap.Error(err))
+		}
+	}
+	return repriced, canceled
+}
+
+// RunStaleRepricing applies Config.StaleLimit every UpdateInterval until
+// ctx is done. It returns immediately when the policy is disabled.
+func (e *Engine) RunStaleRepricing(ctx context.Context) {
+	if e.config.StaleLimit.After <= 0 {
+		return
+	}
+
+	interval := e.config.UpdateInterval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			repriced, canceled := e.RepriceStaleOrders(now)
+			if len(repriced) > 0 || len(canceled) > 0 {
+				e.logger.Debug("Applied stale limit policy",
+					zap.Int("repriced", len(repriced)),
+					zap.Int("canceled", len(canceled)))
+			}
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package trading
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestEngine_RepriceStaleOrders(t *testing.T) {
+	config := testConfig()
+	config.StaleLimit = StaleLimitPolicy{After: time.Minute, Step: 0.02, MaxReprices: 5}
+	storage := &memStorage{}
+	engine := NewEngine(config, zap.NewNop(), storage)
+	engine.UpdateOrderBook(&OrderBook{
+		Symbol: "TEST/SOL",
+		Bids:   []OrderBookLevel{{Price: 99, Quantity: 10}},
+		Asks:   []OrderBookLevel{{Price: 101, Quantity: 10}},
+	})
+
+	buy := &Order{ID: "buy", UserID: "user1", Symbol: "TEST/SOL", Side: OrderSideBuy, Type: OrderTypeLimit,
+		Price: 96, Quantity: 1, Status: OrderStatusNew}
+	require.NoError(t, engine.PlaceOrder(buy))
+	now := buy.UpdatedAt
+
+	repriced, _ := engine.RepriceStaleOrders(now.Add(30 * time.Second))
+	assert.Empty(t, repriced, "order is not stale yet")
+
+	now = now.Add(time.Minute)
+	repriced, _ = engine.RepriceStaleOrders(now)
+	require.Len(t, repriced, 1)
+	assert.InDelta(t, 97.92, buy.Price, 1e-9)
+	assert.Equal(t, 1, buy.Reprices)
+
+	// The staleness clock restarts at each reprice
+	repriced, _ = engine.RepriceStaleOrders(now.Add(30 * time.Second))
+	assert.Empty(t, repriced)
+
+	// Steps stop at the best ask, where the order becomes marketable
+	for i := 0; i < 3; i++ {
+		now = now.Add(time.Minute)
+		engine.RepriceStaleOrders(now)
+	}
+	assert.Equal(t, 101.0, buy.Price)
+	assert.Equal(t, 3, buy.Reprices)
+	now = now.Add(time.Minute)
+	repriced, canceled := engine.RepriceStaleOrders(now)
+	assert.Empty(t, repriced)
+	assert.Empty(t, canceled, "without Cancel an order that can't move keeps resting")
+
+	require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: "buy", Price: 101, Quantity: 1}))
+	assert.Equal(t, OrderStatusFilled, buy.Status)
+	require.NotEmpty(t, storage.orders)
+	assert.Equal(t, 101.0, storage.orders[len(storage.orders)-1].Price)
+
+	t.Run("WorstPriceAndPostOnly", func(t *testing.T) {
+		engine := NewEngine(config, zap.NewNop(), &memStorage{})
+		engine.UpdateOrderBook(&OrderBook{
+			Symbol: "TEST/SOL",
+			Bids:   []OrderBookLevel{{Price: 99, Quantity: 10}},
+			Asks:   []OrderBookLevel{{Price: 101, Quantity: 10}},
+		})
+
+		bounded := &Order{ID: "bounded", UserID: "user1", Symbol: "TEST/SOL", Side: OrderSideSell, Type: OrderTypeLimit,
+			Price: 105, WorstPrice: 103, Quantity: 1, Status: OrderStatusNew}
+		passive := &Order{ID: "passive", UserID: "user1", Symbol: "TEST/SOL", Side: OrderSideBuy, Type: OrderTypeLimit,
+			Price: 98.5, Quantity: 1, Status: OrderStatusNew, PostOnly: true}
+		require.NoError(t, engine.PlaceOrder(bounded))
+		require.NoError(t, engine.PlaceOrder(passive))
+
+		now := passive.UpdatedAt
+		for i := 0; i < 3; i++ {
+			now = now.Add(time.Minute)
+			engine.RepriceStaleOrders(now)
+		}
+		assert.Equal(t, 103.0, bounded.Price, "never repriced past the worst price")
+		assert.InDelta(t, 100.47, passive.Price, 1e-9, "post-only stops short of the best ask")
+	})
+
+	t.Run("Cancel", func(t *testing.T) {
+		config := testConfig()
+		config.StaleLimit = StaleLimitPolicy{After: time.Minute, Step: 0.01, MaxReprices: 1, Cancel: true}
+		engine := NewEngine(config, zap.NewNop(), &memStorage{})
+		engine.UpdateOrderBook(&OrderBook{
+			Symbol: "TEST/SOL",
+			Bids:   []OrderBookLevel{{Price: 99, Quantity: 10}},
+			Asks:   []OrderBookLevel{{Price: 101, Quantity: 10}},
+		})
+
+		order := &Order{ID: "stale", UserID: "user1", Symbol: "TEST/SOL", Side: OrderSideBuy, Type: OrderTypeLimit,
+			Price: 90, Quantity: 1, Status: OrderStatusNew}
+		require.NoError(t, engine.PlaceOrder(order))
+
+		now := order.UpdatedAt.Add(time.Minute)
+		repriced, canceled := engine.RepriceStaleOrders(now)
+		assert.Len(t, repriced, 1)
+		assert.Empty(t, canceled)
+
+		repriced, canceled = engine.RepriceStaleOrders(now.Add(time.Minute))
+		assert.Empty(t, repriced)
+		require.Len(t, canceled, 1)
+		assert.Equal(t, OrderStatusCanceled, order.Status)
+	})
+}
@@ -0,0 +1,75 @@
+package trading
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// EngineStats is a point-in-time snapshot of engine health
+type EngineStats struct {
+	OpenOrders int `json:"open_orders"`
+	Positions  int `json:"positions"`
+	// Exposure is the absolute notional of each open position at its mark
+	// price, or its average price when no mark is available
+	Exposure      map[string]float64 `json:"exposure"`
+	UnrealizedPnL float64            `json:"unrealized_pnl"`
+	Timestamp     time.Time          `json:"timestamp"`
+}
+
+// Stats returns a snapshot of open orders, open positions, per-symbol
+// exposure and total unrealized PnL. Everything is read under a single
+// lock so the figures are consistent with each other.
+func (e *Engine) Stats() EngineStats {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	stats := EngineStats{
+		OpenOrders: len(e.orders),
+		Exposure:   make(map[string]float64),
+		Timestamp:  time.Now(),
+	}
+
+	for symbol, pos := range e.positions {
+		if pos.Quantity == 0 {
+			continue
+		}
+		stats.Positions++
+
+		price, unrealized := pos.AvgPrice, pos.UnrealizedPnL
+		if e.markPrices != nil {
+			if mark, err := e.markPrices.MarkPrice(symbol); err == nil {
+				price = mark
+				unrealized = (mark - pos.AvgPrice) * pos.Quantity
+			}
+		}
+		stats.Exposure[symbol] = math.Abs(pos.Quantity) * price
+		stats.UnrealizedPnL += unrealized
+	}
+
+	return stats
+}
+
+// ActiveSymbols returns the sorted symbols that have an open order or a
+// nonzero position
+func (e *Engine) ActiveSymbols() []string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	seen := make(map[string]struct{})
+	for _, order := range e.orders {
+		seen[order.Symbol] = struct{}{}
+	}
+	for symbol, pos := range e.positions {
+		if pos.Quantity != 0 {
+			seen[symbol] = struct{}{}
+		}
+	}
+
+	symbols := make([]string, 0, len(seen))
+	for symbol := range seen {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+	return symbols
+}
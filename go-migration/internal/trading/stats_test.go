@@ -0,0 +1,73 @@
+package trading
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngine_Stats(t *testing.T) {
+	engine, _ := newTestEngine(t)
+
+	stats := engine.Stats()
+	assert.Zero(t, stats.OpenOrders)
+	assert.Zero(t, stats.Positions)
+	assert.Empty(t, stats.Exposure)
+
+	placeTestOrder(t, engine, "buy1", OrderSideBuy, 10)
+	require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: "buy1", Price: 100, Quantity: 10}))
+
+	require.NoError(t, engine.PlaceOrder(&Order{
+		ID: "sell1", UserID: "user1", Symbol: "OTHER/SOL", Side: OrderSideSell,
+		Type: OrderTypeMarket, Quantity: 4, Status: OrderStatusNew,
+	}))
+	require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: "sell1", Price: 50, Quantity: 4}))
+
+	placeTestOrder(t, engine, "open1", OrderSideBuy, 1)
+	placeTestOrder(t, engine, "open2", OrderSideSell, 1)
+
+	engine.SetMarkPricer(staticMarks{"TEST/SOL": 110})
+	stats = engine.Stats()
+	assert.Equal(t, 2, stats.OpenOrders)
+	assert.Equal(t, 2, stats.Positions)
+	assert.InDelta(t, 1100.0, stats.Exposure["TEST/SOL"], 1e-9)
+	assert.InDelta(t, 200.0, stats.Exposure["OTHER/SOL"], 1e-9, "without a mark exposure uses the average price")
+	assert.InDelta(t, 100.0, stats.UnrealizedPnL, 1e-9)
+	assert.False(t, stats.Timestamp.IsZero())
+}
+
+func TestEngine_ActiveSymbols(t *testing.T) {
+	engine, _ := newTestEngine(t)
+	assert.Empty(t, engine.ActiveSymbols())
+
+	placeOrder := func(id, symbol string) {
+		require.NoError(t, engine.PlaceOrder(&Order{
+			ID: id, UserID: "user1", Symbol: symbol, Side: OrderSideBuy,
+			Type: OrderTypeMarket, Quantity: 1, Status: OrderStatusNew,
+		}))
+	}
+
+	// Position only
+	placeOrder("pos1", "POS/SOL")
+	require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: "pos1", Price: 10, Quantity: 1}))
+	// Position and open order
+	placeOrder("both1", "BOTH/SOL")
+	require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: "both1", Price: 10, Quantity: 1}))
+	placeOrder("both2", "BOTH/SOL")
+	// Open orders only
+	placeOrder("open1", "OPEN/SOL")
+	placeOrder("open2", "OPEN/SOL")
+	// Flat position and a canceled order
+	placeOrder("flat1", "FLAT/SOL")
+	require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: "flat1", Price: 10, Quantity: 1}))
+	require.NoError(t, engine.PlaceOrder(&Order{
+		ID: "flat2", UserID: "user1", Symbol: "FLAT/SOL", Side: OrderSideSell,
+		Type: OrderTypeMarket, Quantity: 1, Status: OrderStatusNew,
+	}))
+	require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: "flat2", Price: 10, Quantity: 1}))
+	placeOrder("flat3", "FLAT/SOL")
+	require.NoError(t, engine.CancelOrder("flat3"))
+
+	assert.Equal(t, []string{"BOTH/SOL", "OPEN/SOL", "POS/SOL"}, engine.ActiveSymbols())
+}
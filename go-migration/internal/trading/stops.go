@@ -0,0 +1,108 @@
+package trading
+
+import (
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+func isStop(order *Order) bool {
+	return order.Type == OrderTypeStop || order.Type == OrderTypeStopLimit
+}
+
+func validateStop(order *Order) error {
+	if order.StopPrice <= 0 {
+		return fmt.Errorf("invalid stop price: %f", order.StopPrice)
+	}
+	if order.Type == OrderTypeStopLimit && order.Price <= 0 {
+		return fmt.Errorf("invalid stop-limit limit price: %f", order.Price)
+	}
+	switch order.TriggerCondition {
+	case "", TriggerAbove, TriggerBelow:
+	default:
+		return fmt.Errorf("invalid trigger condition: %q", order.TriggerCondition)
+	}
+	return nil
+}
+
+// triggerSymbol returns the symbol whose price triggers a stop order
+func triggerSymbol(order *Order) string {
+	if order.TriggerSymbol != "" {
+		return order.TriggerSymbol
+	}
+	return order.Symbol
+}
+
+// stopTriggered reports whether price reaches the order's stop. Without a
+// TriggerCondition buy stops trigger at or above the stop price and sell
+// stops at or below it.
+func stopTriggered(order *Order, price float64) bool {
+	condition := order.TriggerCondition
+	if condition == "" {
+		condition = TriggerBelow
+		if order.Side == OrderSideBuy {
+			condition = TriggerAbove
+		}
+	}
+	if condition == TriggerAbove {
+		return price >= order.StopPrice
+	}
+	return price <= order.StopPrice
+}
+
+// OnPriceUpdate activates open stop orders triggered by symbol whose stop
+// price has been reached and returns them. Orders with a TriggerSymbol
+// are activated by that symbol's updates and then execute in their own
+// symbol. Stop-limit orders become resting limit orders at their limit
+// price and stop orders become market orders; both keep their ID and
+// StopPrice. Resting pegged orders are then moved to their reference
+// price.
+func (e *Engine) OnPriceUpdate(symbol string, price float64) []*Order {
+	e.mu.Lock()
+	var triggered []*Order
+	now := time.Now()
+	e.priceSeen[symbol] = now
+	for _, order := range e.orders {
+		if triggerSymbol(order) != symbol || !isStop(order) || order.Triggered {
+			continue
+		}
+		if !stopTriggered(order, price) {
+			continue
+		}
+
+		if order.Type == OrderTypeStopLimit {
+			order.Type = OrderTypeLimit
+		} else {
+			order.Type = OrderTypeMarket
+		}
+		order.Triggered = true
+		order.UpdatedAt = now
+		triggered = append(triggered, order)
+		e.recordOrder(EventOrderTriggered, order)
+
+		e.logLifecycle("Stop order triggered", order,
+			zap.Float64("stop_price", order.StopPrice),
+			zap.String("trigger_symbol", symbol),
+			zap.Float64("market_price", price),
+			zap.String("type", string(order.Type)))
+	}
+	amended := e.repegLocked(symbol, price, now)
+	e.mu.Unlock()
+
+	for _, order := range triggered {
+		if err := e.storage.SaveOrder(order); err != nil {
+			e.logger.Error("Failed to save triggered order",
+				zap.String("order_id", order.ID),
+				zap.Error(err))
+		}
+	}
+	for _, order := range amended {
+		if err := e.storage.SaveOrder(order); err != nil {
+			e.logger.Error("Failed to save repriced order",
+				zap.String("order_id", order.ID),
+				zap.Error(err))
+		}
+	}
+	return triggered
+}
@@ -0,0 +1,85 @@
+package trading
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEngine_StopLimitOrder(t *testing.T) {
+	engine, _ := newTestEngine(t)
+	order := &Order{
+		ID:        "stop1",
+		UserID:    "user1",
+		Symbol:    "TEST/SOL",
+		Side:      OrderSideSell,
+		Type:      OrderTypeStopLimit,
+		StopPrice: 95,
+		Price:     94,
+		Quantity:  2,
+		Status:    OrderStatusNew,
+	}
+	require.NoError(t, engine.PlaceOrder(order))
+
+	err := engine.ExecuteTrade(&Trade{OrderID: "stop1", Price: 94, Quantity: 2})
+	assert.ErrorIs(t, err, ErrInvalidFill, "untriggered stops cannot fill")
+
+	assert.Empty(t, engine.OnPriceUpdate("TEST/SOL", 96))
+	assert.Empty(t, engine.OnPriceUpdate("OTHER/SOL", 90))
+
+	triggered := engine.OnPriceUpdate("TEST/SOL", 95)
+	require.Len(t, triggered, 1)
+	assert.Equal(t, OrderTypeLimit, order.Type)
+	assert.Equal(t, 94.0, order.Price)
+	assert.True(t, order.Triggered)
+	assert.Equal(t, OrderStatusNew, order.Status, "the limit rests instead of filling at market")
+	assert.Zero(t, order.FilledQty)
+
+	// Already triggered orders are not activated twice
+	assert.Empty(t, engine.OnPriceUpdate("TEST/SOL", 90))
+
+	t.Run("RequiresPrices", func(t *testing.T) {
+		err := engine.PlaceOrder(&Order{ID: "stop2", Type: OrderTypeStopLimit, StopPrice: 95, Quantity: 1})
+		assert.Error(t, err)
+	})
+}
+
+func TestEngine_CrossSymbolTrigger(t *testing.T) {
+	engine, _ := newTestEngine(t)
+
+	// Buy the token once SOL drops to 95
+	order := &Order{
+		ID:               "cond1",
+		UserID:           "user1",
+		Symbol:           "PUMP/SOL",
+		Side:             OrderSideBuy,
+		Type:             OrderTypeStop,
+		StopPrice:        95,
+		Quantity:         10,
+		TriggerSymbol:    "SOL/USDC",
+		TriggerCondition: TriggerBelow,
+	}
+	require.NoError(t, engine.PlaceOrder(order))
+
+	// The order's own symbol no longer triggers it
+	assert.Empty(t, engine.OnPriceUpdate("PUMP/SOL", 50))
+	assert.Empty(t, engine.OnPriceUpdate("SOL/USDC", 100))
+
+	triggered := engine.OnPriceUpdate("SOL/USDC", 94)
+	require.Len(t, triggered, 1)
+	assert.Same(t, order, triggered[0])
+	assert.Equal(t, OrderTypeMarket, order.Type)
+	assert.Equal(t, "PUMP/SOL", order.Symbol)
+
+	// Once active it fills in its own symbol
+	require.NoError(t, engine.ExecuteTrade(&Trade{OrderID: "cond1", Price: 0.5, Quantity: 10}))
+	pos := engine.GetPosition("PUMP/SOL")
+	require.NotNil(t, pos)
+	assert.Equal(t, 10.0, pos.Quantity)
+	assert.Nil(t, engine.GetPosition("SOL/USDC"))
+
+	err := engine.PlaceOrder(&Order{ID: "cond2", UserID: "user1", Symbol: "PUMP/SOL", Side: OrderSideBuy,
+		Type: OrderTypeStop, StopPrice: 95, Quantity: 1, TriggerSymbol: "SOL/USDC", TriggerCondition: "sideways"})
+	assert.Error(t, err)
+}
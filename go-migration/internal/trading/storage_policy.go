@@ -0,0 +1,193 @@
+package trading
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"go.uber.org/zap"
+)
+
+// StorageFailurePolicy controls what PlaceOrder does when SaveOrder fails
+type StorageFailurePolicy string
+
+const (
+	// StoragePolicyRollback removes the order from memory and returns the
+	// storage error, so memory never holds orders storage doesn't. This is
+	// the default.
+	StoragePolicyRollback StorageFailurePolicy = "rollback"
+	// StoragePolicyRetry keeps the order in memory, appends it to the
+	// write-ahead log at Config.WALPath and queues it for RetryPendingSaves.
+	// PlaceOrder succeeds once the WAL write is durable.
+	StoragePolicyRetry StorageFailurePolicy = "retry"
+)
+
+// handleSaveFailure applies the storage failure policy to an order that
+// is already in memory but failed to save
+func (e *Engine) handleSaveFailure(order *Order, saveErr error) error {
+	if e.config.StorageFailurePolicy == StoragePolicyRetry {
+		e.walMu.Lock()
+		err := e.appendWAL(order)
+		if err == nil {
+			e.mu.Lock()
+			e.pendingSaves = append(e.pendingSaves, order)
+			e.mu.Unlock()
+		}
+		e.walMu.Unlock()
+
+		if err == nil {
+			e.logger.Warn("Order save failed, queued for retry",
+				append(orderFields(order), zap.Error(saveErr))...)
+			return nil
+		}
+		e.logger.Error("Failed to write order to WAL, rolling back",
+			append(orderFields(order), zap.Error(err))...)
+	}
+
+	e.mu.Lock()
+	delete(e.orders, order.ID)
+	e.recordOrder(EventOrderRolledBack, order)
+	e.mu.Unlock()
+
+	e.logLifecycle("Order rolled back", order, zap.Error(saveErr))
+	return fmt.Errorf("failed to save order %s: %w", order.ID, saveErr)
+}
+
+// PendingSaves returns the number of orders waiting to be saved
+func (e *Engine) PendingSaves() int {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return len(e.pendingSaves)
+}
+
+// RetryPendingSaves saves queued orders to storage, rewriting the WAL with
+// whatever still fails. It returns the first save error, if any.
+func (e *Engine) RetryPendingSaves() error {
+	e.mu.Lock()
+	pending := e.pendingSaves
+	e.pendingSaves = nil
+	e.mu.Unlock()
+
+	var failed []*Order
+	var firstErr error
+	for _, order := range pending {
+		if err := e.storage.SaveOrder(order); err != nil {
+			failed = append(failed, order)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	e.walMu.Lock()
+	defer e.walMu.Unlock()
+
+	e.mu.Lock()
+	e.pendingSaves = append(failed, e.pendingSaves...)
+	remaining := append([]*Order(nil), e.pendingSaves...)
+	e.mu.Unlock()
+
+	if err := e.rewriteWAL(remaining); err != nil {
+		return err
+	}
+	return firstErr
+}
+
+// LoadWAL queues orders left in the WAL by a previous run for saving and
+// returns them so the caller can restore them into the engine
+func (e *Engine) LoadWAL() ([]*Order, error) {
+	if e.config.WALPath == "" {
+		return nil, nil
+	}
+
+	file, err := os.Open(e.config.WALPath)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open WAL: %w", err)
+	}
+	defer file.Close()
+
+	var orders []*Order
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var order Order
+		if err := json.Unmarshal(scanner.Bytes(), &order); err != nil {
+			return nil, fmt.Errorf("failed to decode WAL entry: %w", err)
+		}
+		orders = append(orders, &order)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read WAL: %w", err)
+	}
+
+	e.mu.Lock()
+	e.pendingSaves = append(e.pendingSaves, orders...)
+	e.mu.Unlock()
+	return orders, nil
+}
+
+// appendWAL durably appends order to the WAL. Must be called with
+// e.walMu held.
+func (e *Engine) appendWAL(order *Order) error {
+	if e.config.WALPath == "" {
+		return fmt.Errorf("no WAL path configured")
+	}
+
+	data, err := json.Marshal(order)
+	if err != nil {
+		return fmt.Errorf("failed to encode order: %w", err)
+	}
+
+	file, err := os.OpenFile(e.config.WALPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open WAL: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write WAL: %w", err)
+	}
+	return file.Sync()
+}
+
+// rewriteWAL replaces the WAL contents with orders. Must be called with
+// e.walMu held.
+func (e *Engine) rewriteWAL(orders []*Order) error {
+	if e.config.WALPath == "" {
+		return nil
+	}
+
+	if len(orders) == 0 {
+		if err := os.Remove(e.config.WALPath); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to clear WAL: %w", err)
+		}
+		return nil
+	}
+
+	tmp := e.config.WALPath + ".tmp"
+	file, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open WAL: %w", err)
+	}
+	for _, order := range orders {
+		data, err := json.Marshal(order)
+		if err == nil {
+			_, err = file.Write(append(data, '\n'))
+		}
+		if err != nil {
+			file.Close()
+			return fmt.Errorf("failed to write WAL: %w", err)
+		}
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to sync WAL: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to close WAL: %w", err)
+	}
+	return os.Rename(tmp, e.config.WALPath)
+}
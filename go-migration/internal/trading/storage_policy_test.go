@@ -0,0 +1,140 @@
+package trading
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/kwanRoshi/B/go-migration/internal/risk"
+)
+
+// flakyStorage fails order saves while down is set, and always fails
+// those of the order failID
+type flakyStorage struct {
+	memStorage
+	down   bool
+	failID string
+}
+
+var errStorageDown = errors.New("storage down")
+
+func (s *flakyStorage) SaveOrder(order *Order) error {
+	s.mu.Lock()
+	down := s.down || order.ID == s.failID
+	s.mu.Unlock()
+	if down {
+		return errStorageDown
+	}
+	return s.memStorage.SaveOrder(order)
+}
+
+func TestEngine_StorageFailurePolicy(t *testing.T) {
+	order := func(id string) *Order {
+		return &Order{ID: id, UserID: "user1", Symbol: "TEST/SOL", Side: OrderSideBuy, Type: OrderTypeMarket, Quantity: 1}
+	}
+
+	t.Run("Rollback", func(t *testing.T) {
+		storage := &flakyStorage{down: true}
+		engine := NewEngine(testConfig(), zap.NewNop(), storage)
+
+		err := engine.PlaceOrder(order("o1"))
+		assert.ErrorIs(t, err, errStorageDown)
+
+		_, err = engine.GetOrder("o1")
+		assert.ErrorIs(t, err, ErrOrderNotFound, "failed orders must not stay in memory")
+
+		// The ID is free to retry once storage recovers
+		storage.down = false
+		assert.NoError(t, engine.PlaceOrder(order("o1")))
+	})
+
+	t.Run("RollbackSkipsCooldown", func(t *testing.T) {
+		storage := &flakyStorage{down: true}
+		engine := NewEngine(testConfig(), zap.NewNop(), storage)
+		engine.SetRiskChecker(risk.NewManager(risk.Limits{MaxPositionSize: 100, MinOrderInterval: time.Hour}, zap.NewNop()))
+
+		// The order storage never took doesn't start the cooldown
+		assert.ErrorIs(t, engine.PlaceOrder(order("o1")), errStorageDown)
+		storage.down = false
+		require.NoError(t, engine.PlaceOrder(order("o2")))
+		assert.ErrorIs(t, engine.PlaceOrder(order("o3")), risk.ErrOrderCooldown)
+	})
+
+	t.Run("Retry", func(t *testing.T) {
+		storage := &flakyStorage{down: true}
+		config := testConfig()
+		config.StorageFailurePolicy = StoragePolicyRetry
+		config.WALPath = filepath.Join(t.TempDir(), "orders.wal")
+		engine := NewEngine(config, zap.NewNop(), storage)
+
+		require.NoError(t, engine.PlaceOrder(order("o1")))
+		require.NoError(t, engine.PlaceOrder(order("o2")))
+		_, err := engine.GetOrder("o1")
+		assert.NoError(t, err)
+		assert.Equal(t, 2, engine.PendingSaves())
+
+		// A restarted engine recovers the queued orders from the WAL
+		recovered, err := NewEngine(config, zap.NewNop(), storage).LoadWAL()
+		require.NoError(t, err)
+		require.Len(t, recovered, 2)
+		assert.Equal(t, "o1", recovered[0].ID)
+
+		assert.ErrorIs(t, engine.RetryPendingSaves(), errStorageDown)
+		assert.Equal(t, 2, engine.PendingSaves())
+
+		storage.down = false
+		require.NoError(t, engine.RetryPendingSaves())
+		assert.Zero(t, engine.PendingSaves())
+		assert.Len(t, storage.orders, 2)
+
+		_, err = os.Stat(config.WALPath)
+		assert.True(t, os.IsNotExist(err), "WAL is cleared once everything is saved")
+	})
+
+	t.Run("SpreadRollback", func(t *testing.T) {
+		storage := &flakyStorage{failID: "leg2"}
+		engine := NewEngine(testConfig(), zap.NewNop(), storage)
+		leg1 := spreadLeg("leg1", "AAA/SOL", OrderSideBuy, 1)
+
+		_, err := engine.PlaceSpread([]*Order{leg1, spreadLeg("leg2", "BBB/SOL", OrderSideSell, 1)}, []float64{1, 1})
+		assert.ErrorIs(t, err, errStorageDown)
+		assert.Empty(t, engine.QueryOrders(OrderFilter{}), "no leg of the spread stays in memory")
+		_, err = engine.Snapshot()
+		assert.NoError(t, err, "the rolled back spread isn't left open")
+
+		// The leg saved before the failure is canceled in storage
+		assert.Equal(t, OrderStatusCanceled, leg1.Status)
+		assert.Same(t, leg1, storage.orders[len(storage.orders)-1])
+	})
+
+	t.Run("SpreadRetry", func(t *testing.T) {
+		config := testConfig()
+		config.StorageFailurePolicy = StoragePolicyRetry
+		config.WALPath = filepath.Join(t.TempDir(), "orders.wal")
+		engine := NewEngine(config, zap.NewNop(), &flakyStorage{failID: "leg2"})
+
+		_, err := engine.PlaceSpread([]*Order{
+			spreadLeg("leg1", "AAA/SOL", OrderSideBuy, 1),
+			spreadLeg("leg2", "BBB/SOL", OrderSideSell, 1),
+		}, []float64{1, 1})
+		require.NoError(t, err)
+		assert.Len(t, engine.QueryOrders(OrderFilter{}), 2)
+		assert.Equal(t, 1, engine.PendingSaves())
+	})
+
+	t.Run("RetryWithoutWALRollsBack", func(t *testing.T) {
+		config := testConfig()
+		config.StorageFailurePolicy = StoragePolicyRetry
+		engine := NewEngine(config, zap.NewNop(), &flakyStorage{down: true})
+
+		assert.ErrorIs(t, engine.PlaceOrder(order("o1")), errStorageDown)
+		_, err := engine.GetOrder("o1")
+		assert.ErrorIs(t, err, ErrOrderNotFound)
+	})
+}
@@ -0,0 +1,120 @@
+package trading
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// Conventional submitter names
+const (
+	// SubmitterPublic sends transactions through the public mempool
+	SubmitterPublic = "public"
+	// SubmitterPrivateRelay sends transactions through a private relay
+	// that keeps them out of the public mempool until they land
+	SubmitterPrivateRelay = "private_relay"
+)
+
+// Submitter sends an accepted order to its venue
+type Submitter interface {
+	Submit(ctx context.Context, order *Order) error
+}
+
+// SubmitterFunc adapts a function to Submitter
+type SubmitterFunc func(ctx context.Context, order *Order) error
+
+func (f SubmitterFunc) Submit(ctx context.Context, order *Order) error {
+	return f(ctx, order)
+}
+
+// RegisterSubmitter makes submitter available under name for orders whose
+// Submitter field, or Config.DefaultSubmitter, selects it
+func (e *Engine) RegisterSubmitter(name string, submitter Submitter) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.submitters[name] = submitter
+}
+
+// SetRand replaces the time-seeded source the engine draws random delays
+// from, e.g. with a fixed seed for reproducible tests. rng is only used
+// under the engine's lock.
+func (e *Engine) SetRand(rng *rand.Rand) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.rng = rng
+}
+
+// SubmitOrder risk-checks and places order, waits Config.SubmitDelay plus
+// a random jitter of up to Config.SubmitJitter, then hands it to its
+// submitter. Randomizing when orders reach the chain makes them harder to
+// sandwich. The risk check runs before the wait, so a rejected order is
+// never delayed. If the wait is interrupted by ctx or the submitter fails,
+// the order is canceled and the error returned. Rate-limited orders are
+// rejected with ErrRateLimited rather than queued, since only a placed
+// order may be submitted.
+func (e *Engine) SubmitOrder(ctx context.Context, order *Order) error {
+	name := order.Submitter
+	if name == "" {
+		name = e.config.DefaultSubmitter
+	}
+	e.mu.RLock()
+	submitter, ok := e.submitters[name]
+	e.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrNoSubmitter, name)
+	}
+
+	if err := e.screenOrder(ctx, order); err != nil {
+		return err
+	}
+	if !e.takeToken(order.UserID) {
+		err := fmt.Errorf("%w: user %s", ErrRateLimited, order.UserID)
+		e.logLifecycle("Order rejected", order, zap.Error(err))
+		return err
+	}
+	if err := e.placeValidated(order); err != nil {
+		return err
+	}
+
+	delay := e.submitDelay()
+	if delay > 0 {
+		e.logLifecycle("Order submission delayed", order, zap.Duration("delay", delay))
+		timer := time.NewTimer(delay)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return e.cancelUnsubmitted(order, ctx.Err())
+		}
+	}
+
+	if err := submitter.Submit(ctx, order); err != nil {
+		return e.cancelUnsubmitted(order, fmt.Errorf("failed to submit order %s via %s: %w", order.ID, name, err))
+	}
+	e.logLifecycle("Order submitted", order, zap.String("submitter", name))
+	return nil
+}
+
+// submitDelay returns the fixed delay plus a uniformly random jitter
+func (e *Engine) submitDelay() time.Duration {
+	delay := e.config.SubmitDelay
+	if jitter := e.config.SubmitJitter; jitter > 0 {
+		e.mu.Lock()
+		delay += time.Duration(e.rng.Int63n(int64(jitter) + 1))
+		e.mu.Unlock()
+	}
+	return delay
+}
+
+// cancelUnsubmitted cancels an order that never reached its venue and
+// returns cause
+func (e *Engine) cancelUnsubmitted(order *Order, cause error) error {
+	if err := e.CancelOrder(order.ID); err != nil {
+		e.logger.Error("Failed to cancel unsubmitted order",
+			append(orderFields(order), zap.Error(err))...)
+	}
+	return cause
+}
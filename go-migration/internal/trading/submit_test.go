@@ -0,0 +1,140 @@
+package trading
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/kwanRoshi/B/go-migration/internal/types"
+)
+
+// riskCheckerFunc adapts a function to OrderRiskChecker
+type riskCheckerFunc func(ctx context.Context, order *types.Order) error
+
+func (f riskCheckerFunc) CheckOrderRisk(ctx context.Context, order *types.Order) error {
+	return f(ctx, order)
+}
+
+func TestEngine_SubmitOrder_Jitter(t *testing.T) {
+	config := testConfig()
+	config.SubmitDelay = 40 * time.Millisecond
+	config.SubmitJitter = 40 * time.Millisecond
+	config.DefaultSubmitter = SubmitterPublic
+	engine := NewEngine(config, zap.NewNop(), &memStorage{})
+
+	var checkedAt time.Time
+	engine.SetRiskChecker(riskCheckerFunc(func(ctx context.Context, order *types.Order) error {
+		checkedAt = time.Now()
+		if order.Quantity > 100 {
+			return errors.New("too large")
+		}
+		return nil
+	}))
+
+	submitted := make(map[string]time.Time)
+	var used []string
+	record := func(name string) Submitter {
+		return SubmitterFunc(func(ctx context.Context, order *Order) error {
+			submitted[order.ID] = time.Now()
+			used = append(used, name)
+			return nil
+		})
+	}
+	engine.RegisterSubmitter(SubmitterPublic, record(SubmitterPublic))
+	engine.RegisterSubmitter(SubmitterPrivateRelay, record(SubmitterPrivateRelay))
+
+	for id, submitter := range map[string]string{"default": "", "private": SubmitterPrivateRelay} {
+		order := &Order{ID: id, UserID: "user1", Symbol: "TEST/SOL", Side: OrderSideBuy,
+			Type: OrderTypeMarket, Quantity: 1, Status: OrderStatusNew, Submitter: submitter}
+		require.NoError(t, engine.SubmitOrder(context.Background(), order))
+
+		// Risk checked before the wait, submitted inside the jitter window
+		wait := submitted[order.ID].Sub(checkedAt)
+		assert.GreaterOrEqual(t, wait, config.SubmitDelay)
+		assert.Less(t, wait, config.SubmitDelay+config.SubmitJitter+50*time.Millisecond)
+	}
+	assert.ElementsMatch(t, []string{SubmitterPublic, SubmitterPrivateRelay}, used)
+
+	// Risk rejections return before any delay and never reach a submitter
+	start := time.Now()
+	big := &Order{ID: "big", UserID: "user1", Symbol: "TEST/SOL", Side: OrderSideBuy,
+		Type: OrderTypeMarket, Quantity: 500, Status: OrderStatusNew}
+	assert.Error(t, engine.SubmitOrder(context.Background(), big))
+	assert.Less(t, time.Since(start), config.SubmitDelay)
+	assert.Len(t, used, 2)
+
+	unknown := &Order{ID: "unknown", UserID: "user1", Symbol: "TEST/SOL", Side: OrderSideBuy,
+		Type: OrderTypeMarket, Quantity: 1, Status: OrderStatusNew, Submitter: "carrier_pigeon"}
+	assert.ErrorIs(t, engine.SubmitOrder(context.Background(), unknown), ErrNoSubmitter)
+}
+
+func TestEngine_SubmitOrder_CanceledWhileDelayed(t *testing.T) {
+	config := testConfig()
+	config.SubmitDelay = time.Second
+	engine := NewEngine(config, zap.NewNop(), &memStorage{})
+	engine.RegisterSubmitter("", SubmitterFunc(func(ctx context.Context, order *Order) error {
+		t.Fatal("order submitted after cancellation")
+		return nil
+	}))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	order := &Order{ID: "slow", UserID: "user1", Symbol: "TEST/SOL", Side: OrderSideBuy,
+		Type: OrderTypeMarket, Quantity: 1, Status: OrderStatusNew}
+	assert.ErrorIs(t, engine.SubmitOrder(ctx, order), context.DeadlineExceeded)
+	assert.Equal(t, OrderStatusCanceled, order.Status)
+}
+
+func TestEngine_SetRand(t *testing.T) {
+	config := testConfig()
+	config.SubmitDelay = time.Second
+	config.SubmitJitter = time.Hour
+
+	delays := func(seed int64) []time.Duration {
+		engine := NewEngine(config, zap.NewNop(), &memStorage{})
+		engine.SetRand(rand.New(rand.NewSource(seed)))
+		out := make([]time.Duration, 20)
+		for i := range out {
+			out[i] = engine.submitDelay()
+		}
+		return out
+	}
+
+	first := delays(42)
+	assert.Equal(t, first, delays(42), "same seed, same jitter")
+	assert.NotEqual(t, first, delays(7))
+	for _, delay := range first {
+		assert.GreaterOrEqual(t, delay, config.SubmitDelay)
+		assert.LessOrEqual(t, delay, config.SubmitDelay+config.SubmitJitter)
+	}
+}
+
+func TestEngine_SubmitOrder_RateLimited(t *testing.T) {
+	config := testConfig()
+	config.OrderRateLimit = 0.001
+	config.OrderBurst = 1
+	config.RateLimitQueueDepth = 10
+	config.RateLimitMaxWait = time.Second
+	engine := NewEngine(config, zap.NewNop(), &memStorage{})
+
+	var submitted []string
+	engine.RegisterSubmitter("", SubmitterFunc(func(ctx context.Context, order *Order) error {
+		submitted = append(submitted, order.ID)
+		return nil
+	}))
+
+	// The second order of the burst isn't queued, so it never reaches the
+	// venue without being placed
+	require.NoError(t, engine.SubmitOrder(context.Background(), burstOrder(0)))
+	assert.ErrorIs(t, engine.SubmitOrder(context.Background(), burstOrder(1)), ErrRateLimited)
+	assert.Equal(t, []string{"o0"}, submitted)
+	assert.Zero(t, engine.QueuedOrders())
+	_, err := engine.GetOrder("o1")
+	assert.ErrorIs(t, err, ErrOrderNotFound)
+}
@@ -34,6 +34,11 @@ const (
 	OrderSideSell OrderSide = "sell"
 )
 
+// Valid reports whether s is one of the known sides
+func (s OrderSide) Valid() bool {
+	return s == OrderSideBuy || s == OrderSideSell
+}
+
 // OrderType represents the type of an order
 type OrderType string
 
@@ -41,8 +46,33 @@ const (
 	OrderTypeMarket OrderType = "market"
 	OrderTypeLimit  OrderType = "limit"
 	OrderTypeStop   OrderType = "stop"
+	// OrderTypeStopLimit becomes a resting limit order at Price once the
+	// market reaches StopPrice
+	OrderTypeStopLimit OrderType = "stop_limit"
+	// OrderTypeIceberg rests VisibleQty at a time and refills it from the
+	// hidden reserve as it fills
+	OrderTypeIceberg OrderType = "iceberg"
+)
+
+// TriggerCondition is the direction a stop's trigger price must cross
+type TriggerCondition string
+
+const (
+	// TriggerAbove triggers at or above the stop price
+	TriggerAbove TriggerCondition = "above"
+	// TriggerBelow triggers at or below the stop price
+	TriggerBelow TriggerCondition = "below"
 )
 
+// Valid reports whether t is one of the known order types
+func (t OrderType) Valid() bool {
+	switch t {
+	case OrderTypeMarket, OrderTypeLimit, OrderTypeStop, OrderTypeStopLimit, OrderTypeIceberg:
+		return true
+	}
+	return false
+}
+
 // OrderStatus represents the status of an order
 type OrderStatus string
 
@@ -54,6 +84,11 @@ const (
 	OrderStatusRejected OrderStatus = "rejected"
 )
 
+// IsTerminal reports whether no further fills or cancels can apply
+func (s OrderStatus) IsTerminal() bool {
+	return s == OrderStatusFilled || s == OrderStatusCanceled || s == OrderStatusRejected
+}
+
 // Order represents a trading order
 type Order struct {
 	ID        string      `json:"id" bson:"_id"`
@@ -67,6 +102,61 @@ type Order struct {
 	Status    OrderStatus `json:"status" bson:"status"`
 	CreatedAt time.Time   `json:"created_at" bson:"created_at"`
 	UpdatedAt time.Time   `json:"updated_at" bson:"updated_at"`
+	// ReduceOnly orders may only shrink an existing position
+	ReduceOnly bool `json:"reduce_only,omitempty" bson:"reduce_only,omitempty"`
+	// ParentID links a child slice to the TWAP/VWAP parent it was cut from
+	ParentID string `json:"parent_id,omitempty" bson:"parent_id,omitempty"`
+	// VisibleQty is the slice size an iceberg order shows at a time and
+	// DisplayQty what remains of the currently shown slice
+	VisibleQty float64 `json:"visible_qty,omitempty" bson:"visible_qty,omitempty"`
+	DisplayQty float64 `json:"display_qty,omitempty" bson:"display_qty,omitempty"`
+	// CorrelationID ties together every log line about the order across
+	// the engine and risk manager; assigned on placement when empty
+	CorrelationID string `json:"correlation_id,omitempty" bson:"correlation_id,omitempty"`
+	// StopPrice triggers stop and stop-limit orders; Triggered is set once
+	// the order has been activated
+	StopPrice float64 `json:"stop_price,omitempty" bson:"stop_price,omitempty"`
+	Triggered bool    `json:"triggered,omitempty" bson:"triggered,omitempty"`
+	// ForceExit bypasses the exit guard, e.g. for stop-outs
+	ForceExit bool `json:"force_exit,omitempty" bson:"force_exit,omitempty"`
+	// SpreadID groups the legs of a spread order, which only fill together
+	SpreadID string `json:"spread_id,omitempty" bson:"spread_id,omitempty"`
+	// PostOnly limit orders must rest on the book rather than take
+	// liquidity at placement
+	PostOnly bool `json:"post_only,omitempty" bson:"post_only,omitempty"`
+	// MinFillQty rejects fills smaller than this unless they complete the
+	// order; zero uses Config.MinFillQty
+	MinFillQty float64 `json:"min_fill_qty,omitempty" bson:"min_fill_qty,omitempty"`
+	// ImmediateOrCancel orders cancel whatever their first execution
+	// leaves unfilled, including when it is rejected as below MinFillQty
+	ImmediateOrCancel bool `json:"immediate_or_cancel,omitempty" bson:"immediate_or_cancel,omitempty"`
+	// StrategyID attributes the order to the strategy that placed it and
+	// Tags carry free-form labels; both are copied to its trades and to
+	// slices cut from it
+	StrategyID string   `json:"strategy_id,omitempty" bson:"strategy_id,omitempty"`
+	Tags       []string `json:"tags,omitempty" bson:"tags,omitempty"`
+	// Submitter names the registered submitter SubmitOrder sends the
+	// order through; empty uses Config.DefaultSubmitter
+	Submitter string `json:"submitter,omitempty" bson:"submitter,omitempty"`
+	// PegTo makes a limit order track the mid or best bid or ask, resting
+	// at that price plus PegOffset and re-pricing on every price update
+	PegTo     PegReference `json:"peg_to,omitempty" bson:"peg_to,omitempty"`
+	PegOffset float64      `json:"peg_offset,omitempty" bson:"peg_offset,omitempty"`
+	// WorstPrice is the highest a buy or lowest a sell may be repriced to
+	// by the stale limit policy, which counts its moves in Reprices; zero
+	// leaves the order unbounded
+	WorstPrice float64 `json:"worst_price,omitempty" bson:"worst_price,omitempty"`
+	Reprices   int     `json:"reprices,omitempty" bson:"reprices,omitempty"`
+	// QuoteAmount sizes the order in the quote currency instead: when
+	// Quantity is zero it is set from QuoteAmount at the order's price or
+	// the mark price on placement. Both are kept for display.
+	QuoteAmount float64 `json:"quote_amount,omitempty" bson:"quote_amount,omitempty"`
+	// TriggerSymbol makes a stop or stop-limit order watch another
+	// symbol's price for StopPrice, e.g. buying a token once SOL drops.
+	// TriggerCondition sets which way that price must cross; empty uses
+	// the side's stop direction.
+	TriggerSymbol    string           `json:"trigger_symbol,omitempty" bson:"trigger_symbol,omitempty"`
+	TriggerCondition TriggerCondition `json:"trigger_condition,omitempty" bson:"trigger_condition,omitempty"`
 }
 
 // Trade represents an executed trade
@@ -79,7 +169,16 @@ type Trade struct {
 	Price     float64   `json:"price" bson:"price"`
 	Quantity  float64   `json:"quantity" bson:"quantity"`
 	Fee       float64   `json:"fee" bson:"fee"`
+	Slippage  float64   `json:"slippage,omitempty" bson:"slippage,omitempty"`
 	Timestamp time.Time `json:"timestamp" bson:"timestamp"`
+	// StrategyID and Tags are copied from the order the trade filled
+	StrategyID string   `json:"strategy_id,omitempty" bson:"strategy_id,omitempty"`
+	Tags       []string `json:"tags,omitempty" bson:"tags,omitempty"`
+	// RefPrice is the reference price, e.g. the mid, when the fill
+	// executed; Costs breaks the fill's cost down against the order's
+	// expected price and is filled in by the engine
+	RefPrice float64        `json:"ref_price,omitempty" bson:"ref_price,omitempty"`
+	Costs    *CostBreakdown `json:"costs,omitempty" bson:"costs,omitempty"`
 }
 
 // Position represents a trading position
@@ -90,7 +189,34 @@ type Position struct {
 	AvgPrice      float64   `json:"avg_price" bson:"avg_price"`
 	UnrealizedPnL float64   `json:"unrealized_pnl" bson:"unrealized_pnl"`
 	RealizedPnL   float64   `json:"realized_pnl" bson:"realized_pnl"`
+	FundingPaid   float64   `json:"funding_paid" bson:"funding_paid"`
+	LastFundingAt time.Time `json:"last_funding_at" bson:"last_funding_at"`
 	UpdatedAt     time.Time `json:"updated_at" bson:"updated_at"`
+	// Lots are the open entry lots, oldest first, when Config.LotMethod
+	// is set
+	Lots []Lot `json:"lots,omitempty" bson:"lots,omitempty"`
+	// TotalCost is the entry cost of the open quantity; AvgPrice is
+	// TotalCost over the absolute quantity
+	TotalCost float64 `json:"total_cost" bson:"total_cost"`
+}
+
+// Lot is a single entry into a position
+type Lot struct {
+	Price    float64   `json:"price" bson:"price"`
+	Quantity float64   `json:"quantity" bson:"quantity"`
+	OpenedAt time.Time `json:"opened_at" bson:"opened_at"`
+}
+
+// FundingEntry records a funding payment applied to a position. A positive
+// amount was paid by the position, a negative amount was received.
+type FundingEntry struct {
+	UserID    string    `json:"user_id" bson:"user_id"`
+	Symbol    string    `json:"symbol" bson:"symbol"`
+	Rate      float64   `json:"rate" bson:"rate"`
+	Quantity  float64   `json:"quantity" bson:"quantity"`
+	Price     float64   `json:"price" bson:"price"`
+	Amount    float64   `json:"amount" bson:"amount"`
+	Timestamp time.Time `json:"timestamp" bson:"timestamp"`
 }
 
 // OrderBook represents the current market state
@@ -113,8 +239,75 @@ type Config struct {
 	Slippage      float64       `json:"slippage"`
 	MaxOrderSize   float64       `json:"max_order_size"`
 	MinOrderSize   float64       `json:"min_order_size"`
+	// MaxPositions caps the number of symbols with open positions or
+	// pending opening orders; zero is unlimited
 	MaxPositions   int          `json:"max_positions"`
 	UpdateInterval time.Duration `json:"update_interval"`
+	// FundingInterval is the period a funding rate applies to; zero applies
+	// the full rate on every ApplyFunding call
+	FundingInterval time.Duration `json:"funding_interval"`
+	// OrderRetention is how long filled and canceled orders stay queryable
+	// in memory; zero keeps them indefinitely
+	OrderRetention time.Duration `json:"order_retention"`
+	// MarginRate is the fraction of notional reserved as margin by the
+	// buying power check; zero requires the full notional
+	MarginRate float64 `json:"margin_rate"`
+	// LifecycleLogging logs every order stage and status transition with
+	// the order's correlation ID
+	LifecycleLogging bool `json:"lifecycle_logging"`
+	// ExitGuard warns on or rejects closing orders expected to lose money
+	// after fees; off by default
+	ExitGuard ExitGuardMode `json:"exit_guard"`
+	// StorageFailurePolicy chooses between rolling back and queueing
+	// orders whose SaveOrder fails; empty means StoragePolicyRollback.
+	// WALPath is the write-ahead log used by StoragePolicyRetry.
+	StorageFailurePolicy StorageFailurePolicy `json:"storage_failure_policy"`
+	WALPath              string               `json:"wal_path"`
+	// LotMethod tracks entry lots per position and realizes PnL by FIFO
+	// or LIFO on reductions; empty uses the blended average price
+	LotMethod LotMethod `json:"lot_method"`
+	// OrderRateLimit is the sustained orders per second allowed per user,
+	// with bursts up to OrderBurst; zero disables rate limiting
+	OrderRateLimit float64 `json:"order_rate_limit"`
+	OrderBurst     int     `json:"order_burst"`
+	// RateLimitQueueDepth queues up to this many rate-limited orders for
+	// resubmission instead of rejecting them; each waits at most
+	// RateLimitMaxWait before being rejected for good
+	RateLimitQueueDepth int           `json:"rate_limit_queue_depth"`
+	RateLimitMaxWait    time.Duration `json:"rate_limit_max_wait"`
+	// PostOnlyPolicy rejects or reprices crossing post-only orders; empty
+	// means PostOnlyReject
+	PostOnlyPolicy PostOnlyPolicy `json:"post_only_policy"`
+	// MaxOrderAge re-runs the risk check on orders older than this before
+	// they fill; zero disables re-validation
+	MaxOrderAge time.Duration `json:"max_order_age"`
+	// MinFillQty is the default minimum fill size for orders that don't
+	// set their own; zero accepts fills of any size
+	MinFillQty float64 `json:"min_fill_qty"`
+	// SubmitDelay and SubmitJitter hold orders sent with SubmitOrder for
+	// the delay plus a random extra of up to the jitter before they are
+	// submitted. DefaultSubmitter is used for orders that don't name one.
+	SubmitDelay      time.Duration `json:"submit_delay"`
+	SubmitJitter     time.Duration `json:"submit_jitter"`
+	DefaultSubmitter string        `json:"default_submitter"`
+	// StaleLimit reprices or cancels limit orders that rest unfilled for
+	// too long; the zero policy is disabled
+	StaleLimit StaleLimitPolicy `json:"stale_limit"`
+	// PriceStallAfter alerts when a symbol with resting stop orders has
+	// had no price update for this long; zero disables the watchdog
+	PriceStallAfter time.Duration `json:"price_stall_after"`
+	// SymbolOrderSizes overrides MinOrderSize and MaxOrderSize per symbol
+	SymbolOrderSizes map[string]OrderSizeLimits `json:"symbol_order_sizes"`
+	// MaxOrdersPerSymbol caps the open orders resting in one symbol, as
+	// venues do per market; zero is unlimited
+	MaxOrdersPerSymbol int `json:"max_orders_per_symbol"`
+}
+
+// OrderSizeLimits bounds order quantity for one symbol; a zero bound
+// falls back to the global one
+type OrderSizeLimits struct {
+	Min float64 `json:"min"`
+	Max float64 `json:"max"`
 }
 
 // Storage defines interface for trading data persistence
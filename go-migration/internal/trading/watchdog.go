@@ -0,0 +1,102 @@
+package trading
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// PricePoller fetches the current price for a symbol, e.g. from a market
+// data provider, when the price stream has gone quiet
+type PricePoller interface {
+	GetPrice(ctx context.Context, symbol string) (float64, error)
+}
+
+// SetPricePoller sets the fallback CheckPriceStalls polls for symbols
+// whose price stream has stalled
+func (e *Engine) SetPricePoller(poller PricePoller) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.poller = poller
+}
+
+// CheckPriceStalls returns the symbols resting stop orders trigger on whose
+// last price update is older than Config.PriceStallAfter, sorted. Each one is
+// logged as an alert and, with a price poller set, its current price is
+// polled and fed through OnPriceUpdate so its stops still trigger. Symbols
+// that never had an update count from their oldest resting stop.
+func (e *Engine) CheckPriceStalls(ctx context.Context, now time.Time) []string {
+	after := e.config.PriceStallAfter
+	if after <= 0 {
+		return nil
+	}
+
+	e.mu.RLock()
+	quietSince := make(map[string]time.Time)
+	for _, order := range e.orders {
+		if !isStop(order) || order.Triggered || order.Status.IsTerminal() {
+			continue
+		}
+		symbol := triggerSymbol(order)
+		since, seen := e.priceSeen[symbol]
+		if !seen {
+			since = order.CreatedAt
+			if prev, ok := quietSince[symbol]; ok && prev.Before(since) {
+				since = prev
+			}
+		}
+		quietSince[symbol] = since
+	}
+	poller := e.poller
+	e.mu.RUnlock()
+
+	var stalled []string
+	for symbol, since := range quietSince {
+		if now.Sub(since) > after {
+			stalled = append(stalled, symbol)
+		}
+	}
+	sort.Strings(stalled)
+
+	for _, symbol := range stalled {
+		e.logger.Warn("Price stream stalled with resting stop orders",
+			zap.String("symbol", symbol),
+			zap.Duration("quiet_for", now.Sub(quietSince[symbol])),
+			zap.Bool("polling", poller != nil))
+		if poller == nil {
+			continue
+		}
+
+		price, err := poller.GetPrice(ctx, symbol)
+		if err != nil {
+			e.logger.Error("Failed to poll price for stalled symbol",
+				zap.String("symbol", symbol),
+				zap.Error(err))
+			continue
+		}
+		e.OnPriceUpdate(symbol, price)
+	}
+	return stalled
+}
+
+// RunPriceWatchdog runs CheckPriceStalls every half Config.PriceStallAfter
+// until ctx is done. It returns immediately when the watchdog is disabled.
+func (e *Engine) RunPriceWatchdog(ctx context.Context) {
+	if e.config.PriceStallAfter <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(e.config.PriceStallAfter / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			e.CheckPriceStalls(ctx, now)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package trading
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// pricePollerFunc adapts a function to PricePoller
+type pricePollerFunc func(ctx context.Context, symbol string) (float64, error)
+
+func (f pricePollerFunc) GetPrice(ctx context.Context, symbol string) (float64, error) {
+	return f(ctx, symbol)
+}
+
+func TestEngine_CheckPriceStalls(t *testing.T) {
+	ctx := context.Background()
+	config := testConfig()
+	config.PriceStallAfter = 10 * time.Second
+	engine := NewEngine(config, zap.NewNop(), &memStorage{})
+
+	stop := &Order{ID: "stop", UserID: "user1", Symbol: "TEST/SOL", Side: OrderSideSell,
+		Type: OrderTypeStop, StopPrice: 90, Quantity: 1, Status: OrderStatusNew}
+	require.NoError(t, engine.PlaceOrder(stop))
+	engine.OnPriceUpdate("TEST/SOL", 100)
+	engine.OnPriceUpdate("QUIET/SOL", 1)
+
+	// A fresh stream isn't stalled, nor are symbols without resting stops
+	now := time.Now()
+	assert.Empty(t, engine.CheckPriceStalls(ctx, now))
+	later := now.Add(time.Minute)
+
+	// Without a poller the stall is only reported
+	assert.Equal(t, []string{"TEST/SOL"}, engine.CheckPriceStalls(ctx, later))
+	assert.False(t, stop.Triggered)
+
+	// The fallback polls the price, which triggers the stop
+	var polled []string
+	engine.SetPricePoller(pricePollerFunc(func(ctx context.Context, symbol string) (float64, error) {
+		polled = append(polled, symbol)
+		return 85, nil
+	}))
+	assert.Equal(t, []string{"TEST/SOL"}, engine.CheckPriceStalls(ctx, later))
+	assert.Equal(t, []string{"TEST/SOL"}, polled)
+	assert.True(t, stop.Triggered)
+	assert.Equal(t, OrderTypeMarket, stop.Type)
+
+	// Once triggered the symbol has no resting stops left to watch
+	assert.Empty(t, engine.CheckPriceStalls(ctx, later.Add(time.Minute)))
+
+	t.Run("PollFails", func(t *testing.T) {
+		engine := NewEngine(config, zap.NewNop(), &memStorage{})
+		stop := &Order{ID: "stop", UserID: "user1", Symbol: "NEW/SOL", Side: OrderSideBuy,
+			Type: OrderTypeStop, StopPrice: 110, Quantity: 1, Status: OrderStatusNew, CreatedAt: now}
+		require.NoError(t, engine.PlaceOrder(stop))
+		engine.SetPricePoller(pricePollerFunc(func(ctx context.Context, symbol string) (float64, error) {
+			return 0, errors.New("provider down")
+		}))
+
+		// Never-updated symbols count from the stop's creation
+		assert.Empty(t, engine.CheckPriceStalls(ctx, now.Add(5*time.Second)))
+		assert.Equal(t, []string{"NEW/SOL"}, engine.CheckPriceStalls(ctx, later))
+		assert.False(t, stop.Triggered)
+	})
+}
@@ -135,6 +135,19 @@ type PriceUpdate struct {
 	Timestamp   time.Time `json:"timestamp"`
 }
 
+// Candle represents an OHLCV candle for a fixed interval
+type Candle struct {
+	Symbol    string    `json:"symbol"`
+	Open      float64   `json:"open"`
+	High      float64   `json:"high"`
+	Low       float64   `json:"low"`
+	Close     float64   `json:"close"`
+	Volume    float64   `json:"volume"`
+	Trades    int       `json:"trades"`
+	OpenTime  time.Time `json:"open_time"`
+	CloseTime time.Time `json:"close_time"`
+}
+
 // MarketDataProvider defines the market data provider interface
 type MarketDataProvider interface {
 	// GetPrice returns the current price for a symbol
@@ -158,6 +171,7 @@ type MarketDataProvider interface {
 
 // TokenInfo represents information about a token
 type TokenInfo struct {
+	Mint       string    `json:"mint,omitempty"`
 	Symbol     string    `json:"symbol"`
 	Name       string    `json:"name"`
 	MarketCap  float64   `json:"market_cap"`
@@ -167,6 +181,21 @@ type TokenInfo struct {
 	LaunchTime time.Time `json:"launch_time"`
 }
 
+// TokenMetadata describes a token's on-chain mint. An authority is
+// renounced when it has been set to none, so nobody can mint more supply
+// or freeze holder accounts.
+type TokenMetadata struct {
+	Mint                     string `json:"mint"`
+	Name                     string `json:"name"`
+	Symbol                   string `json:"symbol"`
+	Decimals                 uint8  `json:"decimals"`
+	Creator                  string `json:"creator"`
+	MintAuthority            string `json:"mint_authority,omitempty"`
+	FreezeAuthority          string `json:"freeze_authority,omitempty"`
+	MintAuthorityRenounced   bool   `json:"mint_authority_renounced"`
+	FreezeAuthorityRenounced bool   `json:"freeze_authority_renounced"`
+}
+
 // BondingCurve represents the bonding curve information for a token
 type BondingCurve struct {
 	Symbol       string    `json:"symbol"`
@@ -43,6 +43,23 @@ type Order struct {
 	Status    OrderStatus `json:"status" bson:"status"`
 	CreatedAt time.Time   `json:"created_at" bson:"created_at"`
 	UpdatedAt time.Time   `json:"updated_at" bson:"updated_at"`
+	// PriceImpact is the expected fractional price move caused by the order
+	PriceImpact float64 `json:"price_impact,omitempty" bson:"price_impact,omitempty"`
+	// Category groups symbols for concentration limits, e.g. "memecoins"
+	Category string `json:"category,omitempty" bson:"category,omitempty"`
+	// Venue and Slippage are set by routing to the selected venue's quote
+	Venue    string  `json:"venue,omitempty" bson:"venue,omitempty"`
+	Slippage float64 `json:"slippage,omitempty" bson:"slippage,omitempty"`
+	// CorrelationID ties together log lines about the order across services
+	CorrelationID string `json:"correlation_id,omitempty" bson:"correlation_id,omitempty"`
+	// PostOnly limit orders must not take liquidity at placement
+	PostOnly bool `json:"post_only,omitempty" bson:"post_only,omitempty"`
+	// ReduceOnly orders may only shrink an existing position
+	ReduceOnly bool `json:"reduce_only,omitempty" bson:"reduce_only,omitempty"`
+	// StrategyID attributes the order to the strategy that placed it and
+	// Tags carry free-form labels; both are copied to its trades
+	StrategyID string   `json:"strategy_id,omitempty" bson:"strategy_id,omitempty"`
+	Tags       []string `json:"tags,omitempty" bson:"tags,omitempty"`
 }
 
 // Trade represents an executed trade
@@ -56,6 +73,9 @@ type Trade struct {
 	Quantity  float64   `json:"quantity" bson:"quantity"`
 	Fee       float64   `json:"fee" bson:"fee"`
 	Timestamp time.Time `json:"timestamp" bson:"timestamp"`
+	// StrategyID and Tags are copied from the order the trade filled
+	StrategyID string   `json:"strategy_id,omitempty" bson:"strategy_id,omitempty"`
+	Tags       []string `json:"tags,omitempty" bson:"tags,omitempty"`
 }
 
 // Position represents a trading position
@@ -66,6 +86,8 @@ type Position struct {
 	AvgPrice      float64   `json:"avg_price" bson:"avg_price"`
 	UnrealizedPnL float64   `json:"unrealized_pnl" bson:"unrealized_pnl"`
 	RealizedPnL   float64   `json:"realized_pnl" bson:"realized_pnl"`
+	Category      string    `json:"category,omitempty" bson:"category,omitempty"`
+	OpenedAt      time.Time `json:"opened_at,omitempty" bson:"opened_at,omitempty"`
 	UpdatedAt     time.Time `json:"updated_at" bson:"updated_at"`
 }
 
@@ -78,4 +100,5 @@ type RiskMetrics struct {
 	MarginLevel     float64   `json:"margin_level"`
 	DailyPnL        float64   `json:"daily_pnl"`
 	UpdateTime      time.Time `json:"update_time"`
+	Currency        string    `json:"currency,omitempty"`
 }